@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	"utils/handlers"
+)
+
+// Handler handles catering report requests
+func Handler(w http.ResponseWriter, r *http.Request) {
+	handlers.WithCORS(handlers.AdminCateringReport)(w, r)
+}
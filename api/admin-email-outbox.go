@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	"utils/handlers"
+)
+
+// Handler handles admin email outbox requests
+func Handler(w http.ResponseWriter, r *http.Request) {
+	handlers.WithCORS(handlers.AdminEmailOutbox)(w, r)
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	"utils/handlers"
+)
+
+// Handler handles downloading the invite list as CSV
+func Handler(w http.ResponseWriter, r *http.Request) {
+	handlers.WithCORS(handlers.AdminExportGuests)(w, r)
+}
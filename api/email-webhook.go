@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	"utils/handlers"
+)
+
+// Handler handles Resend email webhook events
+func Handler(w http.ResponseWriter, r *http.Request) {
+	handlers.WithCORS(handlers.EmailWebhook)(w, r)
+}
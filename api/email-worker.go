@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	"utils/handlers"
+)
+
+// Handler handles scheduled email outbox runs
+func Handler(w http.ResponseWriter, r *http.Request) {
+	handlers.WithCORS(handlers.EmailWorker)(w, r)
+}
@@ -1,49 +1,12 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
-	"time"
 
-	"utils/shared"
+	"utils/handlers"
 )
 
 // Handler handles health check requests
 func Handler(w http.ResponseWriter, r *http.Request) {
-	// Set CORS headers
-	setCORSHeaders(w, r)
-
-	// Handle preflight requests
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-
-	// Load guest count for health check
-	db := shared.NewDatabase()
-	guests, err := db.LoadGuests()
-	guestCount := len(guests)
-
-	if err != nil {
-		guestCount = 0
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": time.Now().Unix(),
-		"guests":    guestCount,
-	})
-}
-
-// setCORSHeaders sets CORS headers for cross-origin requests
-func setCORSHeaders(w http.ResponseWriter, r *http.Request) {
-	origin := r.Header.Get("Origin")
-	if origin == "" {
-		origin = "*"
-	}
-	w.Header().Set("Access-Control-Allow-Origin", origin)
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	w.Header().Set("Access-Control-Allow-Credentials", "true")
+	handlers.WithCORS(handlers.Health)(w, r)
 }
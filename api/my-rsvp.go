@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	"utils/handlers"
+)
+
+// Handler handles guests viewing and amending their own RSVP
+func Handler(w http.ResponseWriter, r *http.Request) {
+	handlers.WithCORS(handlers.MyRSVP)(w, r)
+}
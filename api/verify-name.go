@@ -1,139 +1,12 @@
 package handler
 
 import (
-	"encoding/json"
-	"log"
 	"net/http"
-	"strings"
 
-	"utils/shared"
+	"utils/handlers"
 )
 
 // Handler handles name verification requests
 func Handler(w http.ResponseWriter, r *http.Request) {
-	// Set CORS headers
-	setCORSHeaders(w, r)
-
-	// Handle preflight requests
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	var req shared.RSVPRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
-			Success: false,
-			Message: "Invalid request format",
-		})
-		return
-	}
-
-	// Validate name is not empty
-	if strings.TrimSpace(req.Name) == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
-			Success: false,
-			Message: "Name cannot be empty",
-		})
-		return
-	}
-
-	// Load guest list
-	db := shared.NewDatabase()
-	guestList, err := db.LoadGuests()
-	if err != nil {
-		log.Printf("Error loading guests: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
-			Success: false,
-			Message: "Server error - please try again",
-		})
-		return
-	}
-
-	// Search for the guest
-	foundGuest := shared.FindGuest(req.Name, guestList)
-
-	w.Header().Set("Content-Type", "application/json")
-
-	if foundGuest != nil {
-		log.Printf("Guest found: %s (ID: %s, Address: %s)", foundGuest.Name, foundGuest.ID, foundGuest.Address)
-
-		// Find all family members with the same address
-		familyMembers := []shared.FamilyMember{}
-		// Normalize address for comparison
-		normalizedAddress := strings.ToLower(strings.TrimSpace(foundGuest.Address))
-		// Group by address only if address exists and is not "n/a" or variations
-		shouldGroup := foundGuest.Address != "" &&
-			normalizedAddress != "n/a" &&
-			normalizedAddress != "na" &&
-			normalizedAddress != "n.a." &&
-			normalizedAddress != "n.a"
-
-		if shouldGroup {
-			for _, guest := range guestList {
-				if guest.Address == foundGuest.Address {
-					familyMembers = append(familyMembers, shared.FamilyMember{
-						ID:   guest.ID,
-						Name: guest.Name,
-					})
-				}
-			}
-		} else {
-			// No grouping for empty or N/A addresses - only return this guest
-			familyMembers = append(familyMembers, shared.FamilyMember{
-				ID:   foundGuest.ID,
-				Name: foundGuest.Name,
-			})
-		}
-
-		log.Printf("Found %d family members at %s for %s", len(familyMembers), foundGuest.Address, foundGuest.Name)
-
-		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
-			Success:       true,
-			Message:       "Guest found",
-			FamilyMembers: familyMembers,
-		})
-	} else {
-		log.Printf("Guest not found (allowing as unverified): %s", req.Name)
-
-		// Send notification to admin about unlisted guest
-		ipAddress := r.RemoteAddr
-		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-			ipAddress = forwarded
-		}
-		userAgent := r.Header.Get("User-Agent")
-
-		go shared.SendUnlistedGuestNotification(req, ipAddress, userAgent)
-
-		// Allow the user to proceed but with empty family members
-		// They will be marked as unverified when they submit RSVP
-		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
-			Success:       true,
-			Message:       "Please proceed with your RSVP",
-			FamilyMembers: []shared.FamilyMember{},
-		})
-	}
-}
-
-// setCORSHeaders sets CORS headers for cross-origin requests
-func setCORSHeaders(w http.ResponseWriter, r *http.Request) {
-	origin := r.Header.Get("Origin")
-	if origin == "" {
-		origin = "*"
-	}
-	w.Header().Set("Access-Control-Allow-Origin", origin)
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	w.Header().Set("Access-Control-Allow-Credentials", "true")
+	handlers.WithCORS(handlers.VerifyName)(w, r)
 }
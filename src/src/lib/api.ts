@@ -6,17 +6,112 @@ const API_BASE = '/api';
 export interface VerifyNameRequest {
 	name: string;
 	email: string;
+	lateRequest?: boolean;
+	skipSuggestions?: boolean; // the guest rejected the "did you mean" names
+	challengeAnswer?: string; // postcode or invite code, when challengeType asks for one
+}
+
+export interface VerifyCodeRequest {
+	code: string; // the invite code printed on the invitation, e.g. "K7QD-M3XR"
+	lateRequest?: boolean;
 }
 
 export interface FamilyMember {
 	id: string;
 	name: string;
+	events?: string[]; // IDs of the events they're invited to
+}
+
+// One part of the wedding guests RSVP to, e.g. the ceremony
+export interface WeddingEvent {
+	id: string;
+	name: string;
+	venue: string;
+	starts_at?: string; // RFC 3339
+	capacity: number; // 0 for no limit
+	all_guests: boolean; // everyone on the invite list is invited
+}
+
+// The events one attending guest or plus-one is coming to
+export interface EventAttendance {
+	guestName: string;
+	events: string[]; // WeddingEvent IDs
 }
 
 export interface VerifyNameResponse {
 	success: boolean;
 	message?: string;
+	code?: string; // "did_you_mean" when suggestions are returned instead of a match, "challenge_required"/"challenge_failed" when a challengeAnswer is needed, "invalid_invite_code" from verifyCode
+	challengeType?: 'postcode' | 'code'; // what to ask for with a challenge code
+	matchedName?: string; // guest-list spelling of the name that was found
+	household?: string; // verifyCode only: the household the code belongs to
+	suggestions?: string[];
 	familyMembers?: FamilyMember[];
+	plusOneSlots?: number; // how many plus-ones the household may name
+	menu?: MenuOption[]; // dishes to choose meals from
+	events?: WeddingEvent[]; // every event, for naming those in familyMembers
+}
+
+export interface GuestDiet {
+	guestName: string;
+	categories?: string[]; // IDs from DIET_CATEGORIES
+	diet: string; // free-text notes
+}
+
+export interface DietCategory {
+	id: string;
+	label: string;
+	allergen?: boolean;
+}
+
+// The fixed dietary taxonomy, matching shared.DietCategories in the API:
+// diets, then the 14 UK allergens
+export const DIET_CATEGORIES: DietCategory[] = [
+	{ id: 'vegetarian', label: 'Vegetarian' },
+	{ id: 'vegan', label: 'Vegan' },
+	{ id: 'pescatarian', label: 'Pescatarian' },
+	{ id: 'halal', label: 'Halal' },
+	{ id: 'kosher', label: 'Kosher' },
+	{ id: 'celery', label: 'Celery', allergen: true },
+	{ id: 'gluten', label: 'Gluten', allergen: true },
+	{ id: 'crustaceans', label: 'Crustaceans', allergen: true },
+	{ id: 'eggs', label: 'Eggs', allergen: true },
+	{ id: 'fish', label: 'Fish', allergen: true },
+	{ id: 'lupin', label: 'Lupin', allergen: true },
+	{ id: 'milk', label: 'Milk', allergen: true },
+	{ id: 'molluscs', label: 'Molluscs', allergen: true },
+	{ id: 'mustard', label: 'Mustard', allergen: true },
+	{ id: 'tree_nuts', label: 'Tree nuts', allergen: true },
+	{ id: 'peanuts', label: 'Peanuts', allergen: true },
+	{ id: 'sesame', label: 'Sesame', allergen: true },
+	{ id: 'soya', label: 'Soya', allergen: true },
+	{ id: 'sulphites', label: 'Sulphites', allergen: true }
+];
+
+export type Course = 'starter' | 'main' | 'dessert';
+
+// Courses in serving order, matching shared.MenuCourses in the API
+export const COURSES: { id: Course; label: string }[] = [
+	{ id: 'starter', label: 'Starter' },
+	{ id: 'main', label: 'Main' },
+	{ id: 'dessert', label: 'Dessert' }
+];
+
+export interface MenuOption {
+	id: string;
+	course: Course;
+	name: string;
+	description?: string;
+	created_at?: string;
+}
+
+// One person's meal choice, as MenuOption IDs; a blank course hasn't been
+// chosen yet
+export interface GuestMeal {
+	guestName: string;
+	starter?: string;
+	main?: string;
+	dessert?: string;
 }
 
 export interface RSVPRequest {
@@ -24,12 +119,19 @@ export interface RSVPRequest {
 	email: string;
 	isAttending: boolean;
 	attendingGuests: string[];
-	diet?: string;
+	plusOnes?: string[]; // names of plus-ones, within the household's allowance
+	guestDiets?: GuestDiet[]; // per attending guest or plus-one
+	guestMeals?: GuestMeal[]; // per attending guest or plus-one
+	guestEvents?: EventAttendance[]; // per attending guest or plus-one; everyone left out comes to all they're invited to
+	diet?: string; // other notes for the whole party
+	lateRequest?: boolean; // RSVP after the deadline, pending approval
+	inviteCode?: string; // the code the household was found by, which verifies the RSVP
 }
 
 export interface RSVPResponse {
 	success: boolean;
 	message: string;
+	code?: string; // "late_rsvp_pending" when held for approval
 }
 
 export interface AvatarSelection {
@@ -60,20 +162,24 @@ export interface GetAvatarsResponse {
 }
 
 /**
- * Verify if a name exists on the guest list and get family members
+ * Verify if a name exists on the guest list and get family members.
+ * Pass lateRequest after the RSVP deadline, skipSuggestions once the
+ * guest has said none of the "did you mean" names are them, and
+ * challengeAnswer once asked for a postcode or invite code.
  */
-export async function verifyName(name: string, email: string): Promise<VerifyNameResponse> {
+export async function verifyName(name: string, email: string, lateRequest = false, skipSuggestions = false, challengeAnswer = ''): Promise<VerifyNameResponse> {
 	const response = await fetch(`${API_BASE}/verify-name`, {
 		method: 'POST',
 		headers: {
 			'Content-Type': 'application/json',
 		},
-		body: JSON.stringify({ name, email } as VerifyNameRequest),
+		body: JSON.stringify({ name, email, lateRequest, skipSuggestions, challengeAnswer: challengeAnswer || undefined } as VerifyNameRequest),
 	});
 
 	if (!response.ok) {
-		// Handle 404 (guest not found) gracefully
-		if (response.status === 404) {
+		// Handle 404 (guest not found), 403 (RSVPs closed) and 429 (too many
+		// attempts) gracefully
+		if (response.status === 404 || response.status === 403 || response.status === 429) {
 			const data = await response.json();
 			return data;
 		}
@@ -83,6 +189,32 @@ export async function verifyName(name: string, email: string): Promise<VerifyNam
 	return response.json();
 }
 
+/**
+ * Look up a household by the invite code on its invitation (the ?code= in
+ * its invite link). A match answers like a successful verifyName.
+ */
+export async function verifyCode(code: string, lateRequest = false): Promise<VerifyNameResponse> {
+	const response = await fetch(`${API_BASE}/verify-code`, {
+		method: 'POST',
+		headers: {
+			'Content-Type': 'application/json',
+		},
+		body: JSON.stringify({ code, lateRequest } as VerifyCodeRequest),
+	});
+
+	if (!response.ok) {
+		// Handle 404 (unknown code), 403 (RSVPs closed) and 429 (too many
+		// attempts) gracefully
+		if (response.status === 404 || response.status === 403 || response.status === 429) {
+			const data = await response.json();
+			return data;
+		}
+		throw new Error(`Failed to verify code: ${response.statusText}`);
+	}
+
+	return response.json();
+}
+
 /**
  * Submit RSVP
  */
@@ -154,3 +286,75 @@ export async function healthCheck(): Promise<{ status: string; timestamp: number
 
 	return response.json();
 }
+
+export interface GuestRSVP {
+	name: string;
+	email: string;
+	isAttending: boolean;
+	attendingGuests: string[];
+	plusOnes: string[];
+	guestDiets: GuestDiet[];
+	guestMeals: GuestMeal[];
+	guestEvents: EventAttendance[];
+	diet: string;
+	avatars: AvatarSelection[];
+	verified: boolean;
+	late: boolean;
+	familyMembers: FamilyMember[];
+	plusOneSlots: number; // plus-ones the family members may bring between them
+	editable: boolean; // false once RSVPs have closed
+	menu: MenuOption[]; // dishes to choose meals from
+	events: WeddingEvent[]; // every event, for naming those in familyMembers
+}
+
+export interface MyRSVPResponse {
+	success: boolean;
+	message?: string;
+	code?: string; // "invalid_link" when the token is bad or expired
+	rsvp?: GuestRSVP;
+}
+
+export interface MyRSVPUpdateRequest {
+	isAttending?: boolean;
+	attendingGuests?: string[];
+	plusOnes?: string[];
+	guestDiets?: GuestDiet[];
+	guestMeals?: GuestMeal[];
+	guestEvents?: EventAttendance[];
+	diet?: string;
+	avatars?: AvatarSelection[];
+}
+
+/**
+ * Get the RSVP for the signed link token from a confirmation email
+ */
+export async function getMyRSVP(token: string): Promise<MyRSVPResponse> {
+	const response = await fetch(`${API_BASE}/my-rsvp?token=${encodeURIComponent(token)}`);
+
+	if (!response.ok && response.status >= 500) {
+		throw new Error(`Failed to load RSVP: ${response.statusText}`);
+	}
+
+	// 401 (bad link) and 404 (no RSVP) carry a message for the guest
+	return response.json();
+}
+
+/**
+ * Amend the RSVP for the signed link token
+ */
+export async function updateMyRSVP(token: string, data: MyRSVPUpdateRequest): Promise<MyRSVPResponse> {
+	const response = await fetch(`${API_BASE}/my-rsvp?token=${encodeURIComponent(token)}`, {
+		method: 'POST',
+		headers: {
+			'Content-Type': 'application/json',
+		},
+		body: JSON.stringify(data),
+	});
+
+	if (!response.ok) {
+		const errorData = await response.json().catch(() => ({}));
+		throw new Error(errorData.message || `Failed to update RSVP: ${response.statusText}`);
+	}
+
+	return response.json();
+}
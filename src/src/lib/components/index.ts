@@ -1,3 +1,6 @@
 export { default as AvatarPlaza } from './AvatarPlaza.svelte';
 export { default as RSVPForm } from './RSVPForm.svelte';
 export { default as AvatarSelection } from './AvatarSelection.svelte';
+export { default as DietPicker } from './DietPicker.svelte';
+export { default as MealPicker } from './MealPicker.svelte';
+export { default as EventPicker } from './EventPicker.svelte';
@@ -1,6 +1,11 @@
 import { sveltekit } from '@sveltejs/kit/vite';
 import { defineConfig } from 'vite';
 
+// Set API_PROXY (e.g. http://localhost:8080) to forward /api to the
+// standalone Go server (`make serve`) instead of running `vercel dev`
+const apiProxy = process.env.API_PROXY;
+
 export default defineConfig({
-	plugins: [sveltekit()]
+	plugins: [sveltekit()],
+	server: apiProxy ? { proxy: { '/api': apiProxy } } : undefined
 });
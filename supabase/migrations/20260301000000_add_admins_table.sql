@@ -0,0 +1,18 @@
+-- Create admins table for named admin accounts
+CREATE TABLE IF NOT EXISTS admins (
+  username TEXT PRIMARY KEY,
+  password_hash TEXT NOT NULL,
+  role TEXT NOT NULL DEFAULT 'viewer' CHECK (role IN ('owner', 'editor', 'viewer')),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Enable Row Level Security
+ALTER TABLE admins ENABLE ROW LEVEL SECURITY;
+
+-- Create policy for admins table (allow all operations, matching the other
+-- tables - the API key is only ever used server-side)
+CREATE POLICY "Allow all operations on admins" ON admins
+  FOR ALL
+  USING (true)
+  WITH CHECK (true);
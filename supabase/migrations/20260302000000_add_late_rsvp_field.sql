@@ -0,0 +1,5 @@
+-- Add late field to rsvps table for RSVPs requested after the deadline
+ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS late BOOLEAN NOT NULL DEFAULT false;
+
+-- Create index on late field for finding pending late requests
+CREATE INDEX IF NOT EXISTS idx_rsvps_late ON rsvps(late);
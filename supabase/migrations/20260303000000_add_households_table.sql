@@ -0,0 +1,37 @@
+-- Create households table so guests are grouped by a stable ID rather than
+-- by comparing free-text addresses
+CREATE TABLE IF NOT EXISTS households (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Household names are matched case-insensitively on import
+CREATE UNIQUE INDEX IF NOT EXISTS idx_households_name ON households(lower(name));
+
+-- Enable Row Level Security
+ALTER TABLE households ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations on households" ON households
+  FOR ALL
+  USING (true)
+  WITH CHECK (true);
+
+-- Guests without a household (no usable address) RSVP on their own
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS household_id UUID REFERENCES households(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_guests_household_id ON guests(household_id);
+
+-- Backfill: one household per distinct address, ignoring blank and "n/a"
+-- style placeholders, which never grouped guests
+INSERT INTO households (name)
+SELECT DISTINCT ON (lower(trim(address))) trim(address)
+FROM guests
+WHERE household_id IS NULL
+  AND lower(trim(coalesce(address, ''))) NOT IN ('', 'n/a', 'na', 'n.a.', 'n.a')
+ON CONFLICT (lower(name)) DO NOTHING;
+
+UPDATE guests
+SET household_id = households.id
+FROM households
+WHERE guests.household_id IS NULL
+  AND lower(households.name) = lower(trim(guests.address));
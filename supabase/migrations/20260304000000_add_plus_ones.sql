@@ -0,0 +1,5 @@
+-- Add plus-one allowance to guests table: how many extra people a guest may bring
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS plus_ones INTEGER NOT NULL DEFAULT 0 CHECK (plus_ones >= 0);
+
+-- Add plus_ones to rsvps table: the names of plus-ones brought on an RSVP
+ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS plus_ones TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,4 @@
+-- Add guest_diets to rsvps table: dietary requirements per attending guest
+-- or plus-one, as [{"guestName": "...", "diet": "..."}]. Verified RSVPs also
+-- copy them to guests.dietary.
+ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS guest_diets JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,7 @@
+-- Add diet_categories to guests table: IDs from the fixed dietary taxonomy
+-- (vegetarian, vegan, ..., and the 14 UK allergens), copied from their
+-- latest verified RSVP alongside the free-text dietary notes
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS diet_categories TEXT[] NOT NULL DEFAULT '{}';
+
+-- Add table_name to guests table: the reception table a guest is seated at
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS table_name TEXT;
@@ -0,0 +1,21 @@
+-- Create menu_options table: the dishes guests choose from for each course
+-- of the reception meal, managed from the admin dashboard
+CREATE TABLE IF NOT EXISTS menu_options (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  course TEXT NOT NULL CHECK (course IN ('starter', 'main', 'dessert')),
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Enable Row Level Security
+ALTER TABLE menu_options ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations on menu_options" ON menu_options
+  FOR ALL
+  USING (true)
+  WITH CHECK (true);
+
+-- Add guest_meals to rsvps table: meal choices per attending guest or
+-- plus-one, as [{"guestName": "...", "starter": "<menu_options.id>", ...}]
+ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS guest_meals JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,6 @@
+-- Add guest_events to rsvps table: the events each attending guest or
+-- plus-one is coming to, as [{"guestName": "...", "events": ["ceremony", "reception"]}].
+-- Only guests with ceremony = true (and plus-ones coming with them) may
+-- choose the ceremony. Older RSVPs without it count everyone as coming to
+-- every event they are invited to.
+ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS guest_events JSONB NOT NULL DEFAULT '[]'::jsonb;
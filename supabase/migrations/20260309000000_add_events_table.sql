@@ -0,0 +1,32 @@
+-- Create events table: the parts of the wedding guests RSVP to, managed
+-- from the admin dashboard. capacity 0 means no limit; all_guests events
+-- are open to everyone on the invite list, the rest are by invitation.
+CREATE TABLE IF NOT EXISTS events (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  venue TEXT NOT NULL DEFAULT '',
+  starts_at TIMESTAMP WITH TIME ZONE,
+  capacity INTEGER NOT NULL DEFAULT 0 CHECK (capacity >= 0),
+  all_guests BOOLEAN NOT NULL DEFAULT false,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Enable Row Level Security
+ALTER TABLE events ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations on events" ON events
+  FOR ALL
+  USING (true)
+  WITH CHECK (true);
+
+INSERT INTO events (id, name, venue, starts_at, all_guests) VALUES
+  ('ceremony', 'Ceremony', 'Wandsworth Town Hall', '2026-08-01T16:00:00+01:00', false),
+  ('reception', 'Reception', 'Sands End', '2026-08-01T18:00:00+01:00', true)
+ON CONFLICT (id) DO NOTHING;
+
+-- Add events to guests table: IDs of the events.id a guest is invited to
+-- beyond the all_guests ones, read from the invite list's event columns.
+-- The ceremony column is kept in step for older clients.
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS events TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE guests SET events = ARRAY['ceremony'] WHERE ceremony = true AND NOT ('ceremony' = ANY(events));
@@ -0,0 +1,22 @@
+-- Create rate_limits table: attempt counts behind the limits on
+-- verify-name, shared by every serverless instance when
+-- RATE_LIMIT_BACKEND=store. key is e.g. 'ip:203.0.113.7' or
+-- 'name:john smith|ip:203.0.113.7'.
+CREATE TABLE IF NOT EXISTS rate_limits (
+  key TEXT PRIMARY KEY,
+  window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+  count INTEGER NOT NULL DEFAULT 0,
+  misses INTEGER NOT NULL DEFAULT 0,
+  blocked_until TIMESTAMP WITH TIME ZONE,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_updated_at ON rate_limits(updated_at);
+
+-- Enable Row Level Security
+ALTER TABLE rate_limits ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations on rate_limits" ON rate_limits
+  FOR ALL
+  USING (true)
+  WITH CHECK (true);
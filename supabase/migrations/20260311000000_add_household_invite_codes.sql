@@ -0,0 +1,7 @@
+-- Add invite_code to households: a code printed on each invitation, which
+-- verify-name can ask for before listing the household's members (see
+-- HOUSEHOLD_CHALLENGE). NULL until generated.
+ALTER TABLE households ADD COLUMN IF NOT EXISTS invite_code TEXT;
+
+-- Codes are compared case-insensitively
+CREATE UNIQUE INDEX IF NOT EXISTS idx_households_invite_code ON households(upper(invite_code));
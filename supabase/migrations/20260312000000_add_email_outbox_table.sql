@@ -0,0 +1,39 @@
+-- Create email_outbox table: every email the API sends is queued here with
+-- everything needed to send it, so failed sends are retried by the
+-- email-worker endpoint instead of lost. status is 'queued', 'sending',
+-- 'sent', 'failed' (after the last retry) or 'bounced' (reported by
+-- Resend's webhook). provider_id is Resend's ID for the email once sent.
+-- A sender claims an email by moving it to 'sending', with next_attempt_at
+-- as its lease, before handing it to Resend, so the email-worker and an
+-- admin resend can't both send it; a claim whose lease runs out (the
+-- sender died) is picked up again by the worker.
+CREATE TABLE IF NOT EXISTS email_outbox (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  kind TEXT NOT NULL DEFAULT '',
+  recipient TEXT NOT NULL,
+  subject TEXT NOT NULL DEFAULT '',
+  text_body TEXT NOT NULL DEFAULT '',
+  html_body TEXT NOT NULL DEFAULT '',
+  template TEXT NOT NULL DEFAULT '',
+  template_data JSONB,
+  status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'sending', 'sent', 'failed', 'bounced')),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  resends INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  provider_id TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  sent_at TIMESTAMP WITH TIME ZONE,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_email_outbox_provider_id ON email_outbox(provider_id);
+
+-- Enable Row Level Security
+ALTER TABLE email_outbox ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations on email_outbox" ON email_outbox
+  FOR ALL
+  USING (true)
+  WITH CHECK (true);
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"utils/handlers"
+	"utils/shared"
+)
+
+func main() {
+	defaultPort := os.Getenv("PORT")
+	if defaultPort == "" {
+		defaultPort = "8080"
+	}
+
+	// Parse command line flags
+	port := flag.String("port", defaultPort, "Port to listen on (defaults to $PORT or 8080)")
+	static := flag.String("static", "", "Optional directory of built frontend files to serve at /")
+	emailEvery := flag.Duration("email-worker", time.Minute, "How often to retry queued emails (0 to leave it to /api/email-worker)")
+	flag.Parse()
+
+	mux := http.NewServeMux()
+	for _, route := range handlers.Routes {
+		mux.HandleFunc(route.Path, handlers.WithLogging(handlers.WithRecover(handlers.WithCORS(route.Handler))))
+	}
+	if *static != "" {
+		mux.Handle("/", http.FileServer(http.Dir(*static)))
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + *port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("🚀 API server listening on http://localhost:%s (%d endpoints)", *port, len(handlers.Routes))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("❌ Server failed: %v", err)
+		}
+	}()
+
+	if *emailEvery > 0 {
+		go runEmailWorker(ctx, *emailEvery)
+	}
+
+	<-ctx.Done()
+	log.Println("🛑 Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Fatalf("❌ Graceful shutdown failed: %v", err)
+	}
+	log.Println("✅ Server stopped")
+}
+
+// runEmailWorker sends queued emails that are due a retry every interval
+// until ctx is done, doing the job of the email-worker cron on Vercel
+func runEmailWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		store, err := shared.NewStore()
+		if err != nil {
+			continue // reported by the handlers already
+		}
+		run, err := shared.ProcessEmailOutbox(store, 50)
+		if err != nil {
+			log.Printf("⚠️  Email worker failed: %v", err)
+		} else if run != (shared.EmailOutboxRun{}) {
+			log.Printf("✓ Email worker: %d sent, %d to retry, %d failed", run.Sent, run.Retrying, run.Failed)
+		}
+	}
+}
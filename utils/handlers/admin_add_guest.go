@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"utils/shared"
+)
+
+// AddGuestRequest is the request body for adding a new guest. The guest
+// joins HouseholdID if set; otherwise the household named Household, or
+// the one for Address, is used (and created if needed).
+type AddGuestRequest struct {
+	Name        string   `json:"name"`
+	Address     string   `json:"address"`
+	HouseholdID string   `json:"householdId,omitempty"`
+	Household   string   `json:"household,omitempty"`
+	PlusOnes    int      `json:"plusOnes,omitempty"` // plus-one allowance
+	Table       string   `json:"table,omitempty"`    // reception table
+	Events      []string `json:"events,omitempty"`   // IDs or names of the invitation-only events they're invited to
+}
+
+// AddGuestResponse is the response after adding a guest
+type AddGuestResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// AdminAddGuest handles adding a new guest to the invite list
+var AdminAddGuest = shared.RequireAdmin(shared.RoleEditor, adminAddGuest)
+
+func adminAddGuest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse body
+	var req AddGuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(AddGuestResponse{Success: false, Message: "Invalid request format"})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Address = strings.TrimSpace(req.Address)
+	if req.Name == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(AddGuestResponse{Success: false, Message: "Name is required"})
+		return
+	}
+	if req.PlusOnes < 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(AddGuestResponse{Success: false, Message: "Plus-ones can't be negative"})
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AddGuestResponse{Success: false, Message: "Database not configured"})
+		return
+	}
+
+	// Duplicate check (case-insensitive)
+	if existing, err := store.FindGuestByName(req.Name); err == nil && existing != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(AddGuestResponse{
+			Success: false,
+			Message: fmt.Sprintf("A guest named %q already exists on the invite list", req.Name),
+		})
+		return
+	}
+
+	events, err := store.ListEvents()
+	if err != nil {
+		log.Printf("Error fetching events: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AddGuestResponse{Success: false, Message: "Failed to add guest"})
+		return
+	}
+	invitations := []string{}
+	for _, name := range req.Events {
+		e := shared.FindEvent(name, events)
+		if e == nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(AddGuestResponse{Success: false, Message: fmt.Sprintf("Unknown event: %s", strings.TrimSpace(name))})
+			return
+		}
+		if !shared.HasEvent(invitations, e.ID) {
+			invitations = append(invitations, e.ID)
+		}
+	}
+
+	guest := shared.Guest{
+		Name: req.Name, Address: req.Address, Household: strings.TrimSpace(req.Household),
+		PlusOnes: req.PlusOnes, Table: strings.TrimSpace(req.Table), Events: invitations,
+	}
+	if req.HouseholdID = strings.TrimSpace(req.HouseholdID); req.HouseholdID != "" {
+		households, err := store.ListHouseholds()
+		if err != nil {
+			log.Printf("Error fetching households: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(AddGuestResponse{Success: false, Message: "Failed to add guest"})
+			return
+		}
+		if !householdExists(households, req.HouseholdID) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(AddGuestResponse{Success: false, Message: "That household no longer exists"})
+			return
+		}
+		guest.HouseholdID = req.HouseholdID
+	} else {
+		resolved := []shared.Guest{guest}
+		if err := shared.ResolveHouseholds(store, resolved); err != nil {
+			log.Printf("Error resolving household: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(AddGuestResponse{Success: false, Message: "Failed to add guest"})
+			return
+		}
+		guest = resolved[0]
+	}
+
+	if err := store.AddGuest(guest); err != nil {
+		log.Printf("Error inserting guest: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AddGuestResponse{Success: false, Message: "Failed to add guest"})
+		return
+	}
+
+	log.Printf("✓ Admin added guest: %s (address: %q, household: %q, plus-ones: %d, table: %q, events: %v)", req.Name, req.Address, guest.HouseholdID, guest.PlusOnes, guest.Table, guest.Events)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AddGuestResponse{
+		Success: true,
+		Message: fmt.Sprintf("%s has been added to the invite list", req.Name),
+	})
+}
+
+// householdExists reports whether id is one of households
+func householdExists(households []shared.Household, id string) bool {
+	for _, h := range households {
+		if h.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"utils/shared"
+)
+
+func TestAdminAddGuest(t *testing.T) {
+	store := testStore(t)
+	household, err := store.FindOrCreateHousehold("Add Test Household")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addGuests(t, shared.Guest{Name: "Add Existing"})
+
+	tests := []struct {
+		name          string
+		req           AddGuestRequest
+		wantStatus    int
+		wantHousehold string // "" for none, "new" for one created by the request
+	}{
+		{"into a household by id", AddGuestRequest{Name: "Add Ana", HouseholdID: household.ID}, http.StatusOK, household.ID},
+		{"into a household by name", AddGuestRequest{Name: "Add Bob", Household: "add test household"}, http.StatusOK, household.ID},
+		{"household from the address", AddGuestRequest{Name: "Add Cat", Address: "1 Add Street"}, http.StatusOK, "new"},
+		{"on their own", AddGuestRequest{Name: "Add Dan", Address: "n/a"}, http.StatusOK, ""},
+		{"household that doesn't exist", AddGuestRequest{Name: "Add Eve", HouseholdID: "missing"}, http.StatusBadRequest, ""},
+		{"already invited", AddGuestRequest{Name: "add existing"}, http.StatusConflict, ""},
+		{"no name", AddGuestRequest{Name: " "}, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(AdminAddGuest, http.MethodPost, "/api/admin-add-guest", tt.req, adminToken(t, shared.RoleEditor))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			guest, err := store.FindGuestByName(tt.req.Name)
+			if err != nil || guest == nil {
+				t.Fatalf("FindGuestByName() = %+v, %v", guest, err)
+			}
+			switch tt.wantHousehold {
+			case "new":
+				if guest.HouseholdID == "" || guest.HouseholdID == household.ID {
+					t.Errorf("household = %q, want a new one", guest.HouseholdID)
+				}
+			default:
+				if guest.HouseholdID != tt.wantHousehold {
+					t.Errorf("household = %q, want %q", guest.HouseholdID, tt.wantHousehold)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"utils/shared"
+)
+
+// CateringCount is the headcount of one group of attending guests and how
+// many of them have each dietary category
+type CateringCount struct {
+	Name       string         `json:"name"`
+	Headcount  int            `json:"headcount"`
+	Categories map[string]int `json:"categories"` // by category ID, every category included
+	WithNotes  int            `json:"withNotes"`  // people with free-text notes
+}
+
+// CateringPerson is one attending person with dietary requirements, or a
+// note for a whole party (Party) from an RSVP's free-text field
+type CateringPerson struct {
+	Name       string   `json:"name"`
+	Table      string   `json:"table"`
+	Events     []string `json:"events"`
+	Categories []string `json:"categories"`
+	Notes      string   `json:"notes"`
+	PlusOne    bool     `json:"plusOne,omitempty"`
+	Party      bool     `json:"party,omitempty"`
+}
+
+// CateringReportResponse is the catering report for the venue. Only
+// verified RSVPs count.
+type CateringReportResponse struct {
+	Success    bool                  `json:"success"`
+	Categories []shared.DietCategory `json:"categories"`
+	Total      CateringCount         `json:"total"`
+	Events     []CateringCount       `json:"events"`
+	Tables     []CateringCount       `json:"tables"` // by reception table, unassigned guests last
+	People     []CateringPerson      `json:"people"`
+}
+
+// AdminCateringReport returns catering counts per dietary category, event
+// and table. ?format=csv downloads them as a CSV file for the venue.
+var AdminCateringReport = shared.RequireAdmin(shared.RoleViewer, adminCateringReport)
+
+func adminCateringReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Database not configured"})
+		return
+	}
+
+	guests, err := store.ListGuests()
+	if err != nil {
+		log.Printf("Error fetching guests: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Failed to fetch guest list"})
+		return
+	}
+
+	rsvps, err := store.ListRSVPs()
+	if err != nil {
+		log.Printf("Error fetching RSVPs: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Failed to fetch RSVPs"})
+		return
+	}
+
+	events, err := store.ListEvents()
+	if err != nil {
+		log.Printf("Error fetching events: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Failed to fetch events"})
+		return
+	}
+
+	report := buildCateringReport(guests, rsvps, events)
+	log.Printf("Catering report for %s: %d attending, %d with dietary requirements",
+		shared.AdminFromContext(r.Context()), report.Total.Headcount, len(report.People))
+
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="catering-report.csv"`)
+		if err := writeCateringCSV(w, report); err != nil {
+			log.Printf("Error writing catering report CSV: %v", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// cateringAttendee is one person coming, with where they sit and eat
+type cateringAttendee struct {
+	CateringPerson
+	events []string // IDs of the events they come to
+}
+
+// buildCateringReport counts everyone on a verified, attending RSVP. rsvps
+// are newest first, so each person's newest RSVP decides whether they come
+// and to which events. Listed guests' requirements come from their guest
+// record; plus-ones' and unlisted guests' from the RSVP, and they sit at the
+// table of the first listed guest on it who has one.
+func buildCateringReport(guests []shared.Guest, rsvps []shared.RSVPRecord, events []shared.Event) CateringReportResponse {
+	guestByName := make(map[string]shared.Guest, len(guests))
+	for _, g := range guests {
+		guestByName[shared.NormalizeString(g.Name)] = g
+	}
+
+	decided := make(map[string]bool)
+	var attendees []cateringAttendee
+	var partyNotes []CateringPerson
+	for _, rsvp := range rsvps {
+		if !rsvp.Verified {
+			continue
+		}
+		if !rsvp.IsAttending {
+			decided[shared.NormalizeString(rsvp.Name)] = true
+			continue
+		}
+
+		diets := make(map[string]shared.GuestDiet, len(rsvp.GuestDiets))
+		for _, d := range rsvp.GuestDiets {
+			diets[shared.NormalizeString(d.GuestName)] = d
+		}
+		table := ""
+		for _, name := range rsvp.AttendingGuests {
+			if g, ok := guestByName[shared.NormalizeString(name)]; ok && g.Table != "" {
+				table = g.Table
+				break
+			}
+		}
+		partyEvents := make(map[string]bool)
+
+		// Attending guests come first, then plus-ones
+		for i, a := range shared.RSVPGuestEvents(rsvp, guests, events) {
+			key := shared.NormalizeString(a.GuestName)
+			if decided[key] {
+				continue
+			}
+			decided[key] = true
+			attendee := cateringAttendee{
+				CateringPerson: CateringPerson{
+					Name: a.GuestName, Table: table, Categories: diets[key].Categories, Notes: diets[key].Diet,
+					PlusOne: i >= len(rsvp.AttendingGuests),
+				},
+				events: a.Events,
+			}
+			if g, ok := guestByName[key]; ok && !attendee.PlusOne {
+				attendee.Name, attendee.Table = g.Name, g.Table
+				attendee.Categories, attendee.Notes = g.DietCategories, g.Dietary
+			}
+			for _, e := range attendee.events {
+				partyEvents[e] = true
+			}
+			attendees = append(attendees, attendee)
+		}
+
+		if note := strings.TrimSpace(rsvp.Diet); note != "" {
+			var ids []string
+			for _, e := range events {
+				if partyEvents[e.ID] {
+					ids = append(ids, e.ID)
+				}
+			}
+			partyNotes = append(partyNotes, CateringPerson{
+				Name: rsvp.Name, Table: table, Events: eventNames(ids, events), Categories: []string{}, Notes: note, Party: true,
+			})
+		}
+	}
+
+	report := CateringReportResponse{
+		Success:    true,
+		Categories: shared.DietCategories,
+		Total:      newCateringCount("All guests"),
+		Events:     make([]CateringCount, len(events)),
+		People:     make([]CateringPerson, 0),
+	}
+	for i, e := range events {
+		report.Events[i] = newCateringCount(e.Name)
+	}
+	tables := make(map[string]*CateringCount)
+	var tableNames []string
+	for _, a := range attendees {
+		a.Events = eventNames(a.events, events)
+		a.Categories = nonNilStrings(a.Categories)
+
+		counts := []*CateringCount{&report.Total}
+		for i, e := range events {
+			if shared.HasEvent(a.events, e.ID) {
+				counts = append(counts, &report.Events[i])
+			}
+		}
+		// Tables are reception seating
+		if shared.HasEvent(a.events, shared.EventReception) {
+			if tables[a.Table] == nil {
+				count := newCateringCount(a.Table)
+				tables[a.Table] = &count
+				tableNames = append(tableNames, a.Table)
+			}
+			counts = append(counts, tables[a.Table])
+		}
+		for _, c := range counts {
+			c.add(a.CateringPerson)
+		}
+
+		if len(a.Categories) > 0 || strings.TrimSpace(a.Notes) != "" {
+			report.People = append(report.People, a.CateringPerson)
+		}
+	}
+	report.People = append(report.People, partyNotes...)
+
+	sort.Slice(tableNames, func(i, j int) bool { return tableLess(tableNames[i], tableNames[j]) })
+	report.Tables = make([]CateringCount, 0, len(tableNames))
+	for _, name := range tableNames {
+		report.Tables = append(report.Tables, *tables[name])
+	}
+	sort.SliceStable(report.People, func(i, j int) bool {
+		return tableLess(report.People[i].Table, report.People[j].Table)
+	})
+	return report
+}
+
+// newCateringCount returns an empty count with every category at zero
+func newCateringCount(name string) CateringCount {
+	count := CateringCount{Name: name, Categories: make(map[string]int, len(shared.DietCategories))}
+	for _, c := range shared.DietCategories {
+		count.Categories[c.ID] = 0
+	}
+	return count
+}
+
+// add counts one attending person
+func (c *CateringCount) add(p CateringPerson) {
+	c.Headcount++
+	for _, id := range p.Categories {
+		c.Categories[id]++
+	}
+	if strings.TrimSpace(p.Notes) != "" {
+		c.WithNotes++
+	}
+}
+
+// eventNames returns the display names of event IDs
+func eventNames(ids []string, events []shared.Event) []string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = shared.EventName(id, events)
+	}
+	return names
+}
+
+// tableLess orders tables numerically where both are numbers, otherwise
+// alphabetically, with the unassigned table ("") last
+func tableLess(a, b string) bool {
+	if a == "" || b == "" {
+		return a != "" && b == ""
+	}
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
+
+// tableLabel names a table in the CSV
+func tableLabel(table string) string {
+	if table == "" {
+		return "Unassigned"
+	}
+	return "Table " + table
+}
+
+// writeCateringCSV writes the counts (one row per group, one column per
+// category), then a blank row, then everyone with requirements
+func writeCateringCSV(w http.ResponseWriter, report CateringReportResponse) error {
+	out := csv.NewWriter(w)
+
+	header := []string{"Group", "Headcount"}
+	for _, c := range report.Categories {
+		header = append(header, c.Label)
+	}
+	out.Write(append(header, "Other notes"))
+
+	writeCount := func(name string, c CateringCount) {
+		row := []string{name, strconv.Itoa(c.Headcount)}
+		for _, category := range report.Categories {
+			row = append(row, strconv.Itoa(c.Categories[category.ID]))
+		}
+		out.Write(append(row, strconv.Itoa(c.WithNotes)))
+	}
+	writeCount(report.Total.Name, report.Total)
+	for _, e := range report.Events {
+		writeCount(e.Name, e)
+	}
+	for _, t := range report.Tables {
+		writeCount(tableLabel(t.Name), t)
+	}
+
+	out.Write(nil)
+	out.Write([]string{"Name", "Table", "Events", "Requirements", "Notes"})
+	for _, p := range report.People {
+		name := p.Name
+		switch {
+		case p.PlusOne:
+			name += " (plus-one)"
+		case p.Party:
+			name += " (whole party)"
+		}
+		out.Write([]string{
+			name, tableLabel(p.Table), strings.Join(p.Events, ", "),
+			strings.Join(shared.DietCategoryLabels(p.Categories), ", "), p.Notes,
+		})
+	}
+
+	out.Flush()
+	return out.Error()
+}
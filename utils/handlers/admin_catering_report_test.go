@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+
+	"utils/shared"
+)
+
+func TestBuildCateringReport(t *testing.T) {
+	guests := []shared.Guest{
+		{ID: "1", Name: "Jane Smith", Events: []string{shared.EventCeremony}, Table: "2", DietCategories: []string{"vegan"}},
+		{ID: "2", Name: "John Smith", Events: []string{shared.EventCeremony}, Table: "2"},
+		{ID: "3", Name: "Bob Jones", Table: "10", Dietary: "no mushrooms"},
+		{ID: "4", Name: "Eve Brown", Table: "1", DietCategories: []string{"peanuts"}},
+	}
+	// Newest first: Eve's decline outweighs her older acceptance
+	rsvps := []shared.RSVPRecord{
+		{Name: "Eve Brown", Verified: true, AttendingGuests: []string{"Eve Brown"}},
+		{Name: "Jane Smith", Verified: true, IsAttending: true, AttendingGuests: []string{"Jane Smith", "John Smith"}, PlusOnes: []string{"Sam"},
+			GuestDiets: []shared.GuestDiet{{GuestName: "Sam", Categories: []string{"vegan", "peanuts"}}}, Diet: "no coriander"},
+		{Name: "Bob Jones", Verified: true, IsAttending: true, AttendingGuests: []string{"Bob Jones"}},
+		{Name: "Eve Brown", Verified: true, IsAttending: true, AttendingGuests: []string{"Eve Brown"}},
+		{Name: "Stranger", IsAttending: true, AttendingGuests: []string{"Stranger"}},
+	}
+
+	report := buildCateringReport(guests, rsvps, shared.DefaultEvents)
+	if report.Total.Headcount != 4 || report.Total.Categories["vegan"] != 2 || report.Total.Categories["peanuts"] != 1 || report.Total.WithNotes != 1 {
+		t.Errorf("total = %+v", report.Total)
+	}
+	if ceremony, reception := report.Events[0], report.Events[1]; ceremony.Headcount != 3 || reception.Headcount != 4 {
+		t.Errorf("events = %+v", report.Events)
+	}
+	var tables []string
+	for _, table := range report.Tables {
+		tables = append(tables, table.Name)
+	}
+	if got := strings.Join(tables, ","); got != "2,10" {
+		t.Errorf("tables = %s, want 2,10", got)
+	}
+	var people []string
+	for _, p := range report.People {
+		people = append(people, p.Name)
+	}
+	if got := strings.Join(people, ","); got != "Jane Smith,Sam,Jane Smith,Bob Jones" {
+		t.Errorf("people = %s", got)
+	}
+	if sam := report.People[1]; !sam.PlusOne || sam.Table != "2" || len(sam.Events) != 2 {
+		t.Errorf("plus-one = %+v, want at table 2 for both events", sam)
+	}
+	if party := report.People[2]; !party.Party || party.Notes != "no coriander" {
+		t.Errorf("party note = %+v", party)
+	}
+}
+
+func TestAdminCateringReport(t *testing.T) {
+	token := adminToken(t, shared.RoleViewer)
+	if w := serve(AdminCateringReport, http.MethodGet, "/api/admin-catering-report", nil, token); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	} else {
+		var resp CateringReportResponse
+		decode(t, w, &resp)
+		if !resp.Success || len(resp.Categories) != len(shared.DietCategories) || len(resp.Events) < 2 {
+			t.Errorf("response = %+v", resp)
+		}
+	}
+
+	w := serve(AdminCateringReport, http.MethodGet, "/api/admin-catering-report?format=csv", nil, token)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("CSV status = %d, type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	r := csv.NewReader(w.Body)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil || len(rows) < 4 {
+		t.Fatalf("CSV = %q, %v", rows, err)
+	}
+	if rows[0][0] != "Group" || rows[0][len(rows[0])-1] != "Other notes" || rows[1][0] != "All guests" {
+		t.Errorf("CSV starts %q", rows[:2])
+	}
+
+	if w := serve(AdminCateringReport, http.MethodPost, "/api/admin-catering-report", nil, token); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d", w.Code)
+	}
+}
@@ -0,0 +1,585 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"utils/shared"
+)
+
+// DashboardGuestMember represents a single invited guest and their RSVP status
+type DashboardGuestMember struct {
+	Name           string   `json:"name"`
+	RSVPStatus     string   `json:"rsvpStatus"` // "attending", "not_attending", "no_response"
+	Verified       bool     `json:"verified"`
+	Ceremony       bool     `json:"ceremony"`                 // whether the guest is invited to the ceremony
+	Invited        []string `json:"invited"`                  // events they are invited to
+	Events         []string `json:"events,omitempty"`         // events they are coming to, only for attending guests
+	PlusOnes       int      `json:"plusOnes"`                 // plus-one allowance
+	Dietary        string   `json:"dietary,omitempty"`        // only for attending guests
+	DietCategories []string `json:"dietCategories,omitempty"` // only for attending guests
+	Table          string   `json:"table,omitempty"`
+}
+
+// DashboardGuestGroup represents a household from the invite list, or a
+// single guest without one (empty HouseholdID)
+type DashboardGuestGroup struct {
+	HouseholdID string                 `json:"householdId,omitempty"`
+	Household   string                 `json:"household"`
+	Address     string                 `json:"address"`
+	Members     []DashboardGuestMember `json:"members"`
+}
+
+// DashboardPlusOne is a plus-one named on a verified, attending RSVP
+type DashboardPlusOne struct {
+	Name      string `json:"name"`
+	InvitedBy string `json:"invitedBy"` // name on the RSVP that brought them
+	Email     string `json:"email"`
+}
+
+// DashboardDietaryEntry is one attending person's dietary requirements,
+// or a note for a whole party (Party) from the RSVP's free-text field
+type DashboardDietaryEntry struct {
+	Name       string   `json:"name"`
+	Email      string   `json:"email"`
+	Categories []string `json:"categories"` // IDs from shared.DietCategories
+	Diet       string   `json:"diet"`       // free-text notes
+	Verified   bool     `json:"verified"`
+	PlusOne    bool     `json:"plusOne,omitempty"`
+	Party      bool     `json:"party,omitempty"`
+}
+
+// DashboardUnverifiedRSVP represents an RSVP needing admin review: either
+// unverified, or verified (e.g. via the old email-link path) but containing
+// names that match no guest-list entry — those RSVPs are otherwise invisible
+// in the dashboard because all correlation is name-based.
+type DashboardUnverifiedRSVP struct {
+	Name            string             `json:"name"`
+	Email           string             `json:"email"`
+	IsAttending     bool               `json:"isAttending"`
+	AttendingGuests []string           `json:"attendingGuests"`
+	PlusOnes        []string           `json:"plusOnes"`
+	GuestDiets      []shared.GuestDiet `json:"guestDiets"`
+	Diet            string             `json:"diet"`
+	SubmittedAt     string             `json:"submittedAt"`
+	Verified        bool               `json:"verified"`
+	Late            bool               `json:"late"` // sent after the RSVP deadline, awaiting approval
+}
+
+// DashboardMealTotal is how many people coming chose one dish
+type DashboardMealTotal struct {
+	Course   string `json:"course"`
+	OptionID string `json:"optionId"`
+	Name     string `json:"name"`
+	Count    int    `json:"count"`
+}
+
+// DashboardEventStats is the attendance at one event
+type DashboardEventStats struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Venue     string `json:"venue"`
+	StartsAt  string `json:"startsAt,omitempty"`
+	Capacity  int    `json:"capacity"`  // 0 for no limit
+	AllGuests bool   `json:"allGuests"` // everyone on the invite list is invited
+	Invited   int    `json:"invited"`   // guests on the invite list invited to it
+	Attending int    `json:"attending"` // people on verified RSVPs coming to it, plus-ones included
+	PlusOnes  int    `json:"plusOnes"`  // how many of attending are plus-ones
+}
+
+// DashboardStats represents summary statistics
+type DashboardStats struct {
+	TotalInvited      int `json:"totalInvited"`
+	TotalRSVPd        int `json:"totalRSVPd"`
+	NotAttending      int `json:"notAttending"`
+	NoResponse        int `json:"noResponse"`
+	WithDietary       int `json:"withDietary"`
+	UnverifiedCount   int `json:"unverifiedCount"`
+	LateRequestCount  int `json:"lateRequestCount"`  // late RSVPs awaiting approval (included in unverifiedCount)
+	PlusOnesAttending int `json:"plusOnesAttending"` // named plus-ones on verified RSVPs
+	PlusOneAllowance  int `json:"plusOneAllowance"`  // plus-ones allowed across the whole invite list
+	// Attendance at each event, in running order
+	Events []DashboardEventStats `json:"events"`
+	// Meal choices of everyone on a verified, attending RSVP, per dish in
+	// menu order, and how many of them haven't chosen every course
+	Meals              []DashboardMealTotal `json:"meals"`
+	MealChoicesMissing int                  `json:"mealChoicesMissing"`
+}
+
+// DashboardResponse is the full admin dashboard payload
+type DashboardResponse struct {
+	Success             bool                      `json:"success"`
+	Stats               DashboardStats            `json:"stats"`
+	GuestGroups         []DashboardGuestGroup     `json:"guestGroups"`
+	PlusOnes            []DashboardPlusOne        `json:"plusOnes"`
+	DietaryRequirements []DashboardDietaryEntry   `json:"dietaryRequirements"`
+	UnverifiedRSVPs     []DashboardUnverifiedRSVP `json:"unverifiedRSVPs"`
+}
+
+// AdminDashboard handles admin dashboard data requests
+var AdminDashboard = shared.RequireAdmin(shared.RoleViewer, adminDashboard)
+
+func adminDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	log.Printf("admin dashboard: authorised request from %s (%s)", shared.AdminFromContext(r.Context()), r.RemoteAddr)
+
+	resp, ok := loadDashboard(w)
+	if !ok {
+		return
+	}
+	log.Printf("Admin dashboard: %d invited, %d responded (+%d plus-ones), %d not attending, %d no response, %d dietary, %d unverified",
+		resp.Stats.TotalInvited, resp.Stats.TotalRSVPd, resp.Stats.PlusOnesAttending, resp.Stats.NotAttending,
+		resp.Stats.NoResponse, resp.Stats.WithDietary, resp.Stats.UnverifiedCount)
+	for _, e := range resp.Stats.Events {
+		log.Printf("Admin dashboard: %s - %d of %d invited attending (+%d plus-ones)", e.Name, e.Attending-e.PlusOnes, e.Invited, e.PlusOnes)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// loadDashboard fetches everything the dashboard is built from and builds
+// it. On failure it writes the JSON error response and returns false.
+func loadDashboard(w http.ResponseWriter) (DashboardResponse, bool) {
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Database not configured"})
+		return DashboardResponse{}, false
+	}
+
+	guests, err := store.ListGuests()
+	if err != nil {
+		log.Printf("Error fetching guests: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Failed to fetch guest list"})
+		return DashboardResponse{}, false
+	}
+
+	households, err := store.ListHouseholds()
+	if err != nil {
+		log.Printf("Error fetching households: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Failed to fetch households"})
+		return DashboardResponse{}, false
+	}
+
+	rsvps, err := store.ListRSVPs()
+	if err != nil {
+		log.Printf("Error fetching RSVPs: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Failed to fetch RSVPs"})
+		return DashboardResponse{}, false
+	}
+
+	menu, err := store.ListMenuOptions()
+	if err != nil {
+		log.Printf("Error fetching menu: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Failed to fetch menu"})
+		return DashboardResponse{}, false
+	}
+
+	events, err := store.ListEvents()
+	if err != nil {
+		log.Printf("Error fetching events: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Failed to fetch events"})
+		return DashboardResponse{}, false
+	}
+
+	return buildDashboard(guests, households, rsvps, menu, events), true
+}
+
+// buildDashboard constructs the full dashboard response from raw DB data
+func buildDashboard(guests []shared.Guest, households []shared.Household, rsvps []shared.RSVPRecord, menu []shared.MenuOption, events []shared.Event) DashboardResponse {
+	type rsvpResult struct {
+		attending bool
+		verified  bool
+		events    []string // events an attending guest is coming to
+	}
+
+	guestRSVPMap := make(map[string]rsvpResult)
+	// Initialise to empty (not nil) so JSON encodes [] not null — the
+	// frontend template accesses .length on these, which throws on null
+	dietaryEntries := make([]DashboardDietaryEntry, 0)
+	unverifiedRSVPs := make([]DashboardUnverifiedRSVP, 0)
+	plusOnes := make([]DashboardPlusOne, 0)
+	seenPlusOnes := make(map[string]bool)
+	seenDiets := make(map[string]bool)
+	// Newest verified RSVP email for each attending guest, to show
+	// alongside the dietary requirements stored against them
+	attendingEmail := make(map[string]string)
+	lateRequestCount := 0
+
+	// Canonical guest names, for orphan detection and invitations
+	guestByName := make(map[string]*shared.Guest, len(guests))
+	guestNameSet := make(map[string]bool, len(guests))
+	for i, g := range guests {
+		guestByName[shared.NormalizeString(g.Name)] = &guests[i]
+		guestNameSet[shared.NormalizeString(g.Name)] = true
+	}
+	// guestEvents returns the events gName, coming on rsvp, attends
+	guestEvents := func(rsvp shared.RSVPRecord, gName string) []string {
+		invited := shared.InvitedEvents(guestByName[shared.NormalizeString(gName)], events)
+		attended := []string{}
+		for _, e := range invited {
+			if shared.AttendsEvent(rsvp, gName, e, invited) {
+				attended = append(attended, e)
+			}
+		}
+		return attended
+	}
+
+	// hasOrphanNames reports whether an RSVP contains names that match no
+	// guest-list entry (attending guests for accepts, submitter for declines)
+	hasOrphanNames := func(rsvp shared.RSVPRecord) bool {
+		if rsvp.IsAttending {
+			for _, gName := range rsvp.AttendingGuests {
+				if !guestNameSet[shared.NormalizeString(gName)] {
+					return true
+				}
+			}
+			return false
+		}
+		return !guestNameSet[shared.NormalizeString(rsvp.Name)]
+	}
+
+	for _, rsvp := range rsvps {
+		if !rsvp.Verified {
+			unverifiedRSVPs = append(unverifiedRSVPs, DashboardUnverifiedRSVP{
+				Name: rsvp.Name, Email: rsvp.Email, IsAttending: rsvp.IsAttending,
+				AttendingGuests: rsvp.AttendingGuests, PlusOnes: nonNilStrings(rsvp.PlusOnes),
+				GuestDiets: nonNilDiets(rsvp.GuestDiets),
+				Diet:       rsvp.Diet, SubmittedAt: rsvp.SubmittedAt, Late: rsvp.Late,
+			})
+			if rsvp.Late {
+				lateRequestCount++
+			}
+			continue
+		}
+		// Verified RSVPs with unmatched names (e.g. verified via the old
+		// email link without name correction) also need review — they are
+		// otherwise dropped silently from all counts
+		if hasOrphanNames(rsvp) {
+			unverifiedRSVPs = append(unverifiedRSVPs, DashboardUnverifiedRSVP{
+				Name: rsvp.Name, Email: rsvp.Email, IsAttending: rsvp.IsAttending,
+				AttendingGuests: rsvp.AttendingGuests, PlusOnes: nonNilStrings(rsvp.PlusOnes),
+				GuestDiets: nonNilDiets(rsvp.GuestDiets),
+				Diet:       rsvp.Diet, SubmittedAt: rsvp.SubmittedAt, Verified: true,
+			})
+		}
+		if rsvp.IsAttending {
+			for _, gName := range rsvp.AttendingGuests {
+				key := shared.NormalizeString(gName)
+				guestRSVPMap[key] = rsvpResult{attending: true, verified: true, events: guestEvents(rsvp, gName)}
+				if _, ok := attendingEmail[key]; !ok {
+					attendingEmail[key] = rsvp.Email
+				}
+			}
+			// Listed guests' diets are stored against them (see below);
+			// plus-ones and unlisted names only have them on the RSVP
+			plusOneSet := make(map[string]bool, len(rsvp.PlusOnes))
+			for _, name := range rsvp.PlusOnes {
+				plusOneSet[shared.NormalizeString(name)] = true
+			}
+			for _, d := range rsvp.GuestDiets {
+				key := shared.NormalizeString(d.GuestName)
+				if guestNameSet[key] || seenDiets[key] {
+					continue
+				}
+				seenDiets[key] = true
+				dietaryEntries = append(dietaryEntries, DashboardDietaryEntry{
+					Name: d.GuestName, Email: rsvp.Email, Categories: nonNilStrings(d.Categories), Diet: d.Diet,
+					Verified: true, PlusOne: plusOneSet[key],
+				})
+			}
+			// A plus-one named on several RSVPs from the same household
+			// is one person
+			for _, name := range rsvp.PlusOnes {
+				if key := shared.NormalizeString(name); !seenPlusOnes[key] {
+					seenPlusOnes[key] = true
+					plusOnes = append(plusOnes, DashboardPlusOne{Name: name, InvitedBy: rsvp.Name, Email: rsvp.Email})
+				}
+			}
+		} else {
+			guestRSVPMap[shared.NormalizeString(rsvp.Name)] = rsvpResult{attending: false, verified: true}
+		}
+		if strings.TrimSpace(rsvp.Diet) != "" {
+			dietaryEntries = append(dietaryEntries, DashboardDietaryEntry{
+				Name: rsvp.Name, Email: rsvp.Email, Categories: []string{}, Diet: rsvp.Diet,
+				Verified: rsvp.Verified, Party: true,
+			})
+		}
+	}
+
+	guestHouseholdMap := make(map[string]string)
+	for _, g := range guests {
+		if g.HouseholdID != "" {
+			guestHouseholdMap[shared.NormalizeString(g.Name)] = g.HouseholdID
+		}
+	}
+
+	householdRSVPMap := make(map[string][]shared.RSVPRecord)
+	for _, rsvp := range rsvps {
+		if rsvp.Verified {
+			addRSVPToHouseholdMap(householdRSVPMap, guestHouseholdMap, rsvp)
+		}
+	}
+
+	householdNames := make(map[string]string, len(households))
+	for _, h := range households {
+		householdNames[h.ID] = h.Name
+	}
+
+	guestGroups := make([]DashboardGuestGroup, 0)
+	totalInvited, attending, notAttending, noResponse := 0, 0, 0, 0
+	plusOneAllowance := 0
+	eventInvited := make(map[string]int, len(events))
+
+	for _, members := range shared.GroupGuestsByHousehold(guests) {
+		householdID := members[0].HouseholdID
+		var householdRSVPs []shared.RSVPRecord
+		if householdID != "" {
+			householdRSVPs = householdRSVPMap[householdID]
+		}
+
+		var groupMembers []DashboardGuestMember
+		for _, g := range members {
+			totalInvited++
+			plusOneAllowance += g.PlusOnes
+			invited := shared.InvitedEvents(&g, events)
+			for _, e := range invited {
+				eventInvited[e]++
+			}
+			key := shared.NormalizeString(g.Name)
+			status, verified := "no_response", false
+			var attended []string
+
+			if res, found := guestRSVPMap[key]; found {
+				verified = res.verified
+				if res.attending {
+					status = "attending"
+					attending++
+					attended = res.events
+				} else {
+					status = "not_attending"
+					notAttending++
+				}
+			} else if len(householdRSVPs) > 0 {
+				listedAsAttending := false
+				for _, rsvp := range householdRSVPs {
+					if rsvp.IsAttending {
+						for _, ag := range rsvp.AttendingGuests {
+							if shared.NormalizeString(ag) == key {
+								listedAsAttending = true
+								attended = guestEvents(rsvp, g.Name)
+								break
+							}
+						}
+					}
+				}
+				verified = true
+				if listedAsAttending {
+					status = "attending"
+					attending++
+				} else {
+					status = "not_attending"
+					notAttending++
+				}
+			} else {
+				noResponse++
+			}
+
+			dietary, categories := "", []string(nil)
+			if status == "attending" && (strings.TrimSpace(g.Dietary) != "" || len(g.DietCategories) > 0) {
+				dietary, categories = g.Dietary, g.DietCategories
+				dietaryEntries = append(dietaryEntries, DashboardDietaryEntry{
+					Name: g.Name, Email: attendingEmail[key], Categories: nonNilStrings(categories), Diet: dietary,
+					Verified: verified,
+				})
+			}
+
+			groupMembers = append(groupMembers, DashboardGuestMember{
+				Name: g.Name, RSVPStatus: status, Verified: verified, Ceremony: g.Ceremony, Invited: invited, Events: attended, PlusOnes: g.PlusOnes,
+				Dietary: dietary, DietCategories: categories, Table: g.Table,
+			})
+		}
+		guestGroups = append(guestGroups, DashboardGuestGroup{
+			HouseholdID: householdID,
+			Household:   householdNames[householdID],
+			Address:     groupAddress(members),
+			Members:     groupMembers,
+		})
+	}
+
+	meals, mealChoicesMissing := mealTotals(rsvps, menu)
+	eventStats := eventAttendance(rsvps, guests, events)
+	for i := range eventStats {
+		eventStats[i].Invited = eventInvited[eventStats[i].ID]
+	}
+
+	return DashboardResponse{
+		Success: true,
+		Stats: DashboardStats{
+			TotalInvited: totalInvited, TotalRSVPd: attending + notAttending,
+			NotAttending: notAttending, NoResponse: noResponse,
+			WithDietary: len(dietaryEntries), UnverifiedCount: len(unverifiedRSVPs),
+			LateRequestCount:   lateRequestCount,
+			PlusOnesAttending:  len(plusOnes),
+			Events:             eventStats,
+			PlusOneAllowance:   plusOneAllowance,
+			Meals:              meals,
+			MealChoicesMissing: mealChoicesMissing,
+		},
+		GuestGroups: guestGroups, PlusOnes: plusOnes,
+		DietaryRequirements: dietaryEntries, UnverifiedRSVPs: unverifiedRSVPs,
+	}
+}
+
+// mealTotals counts the dishes chosen by everyone on a verified, attending
+// RSVP. rsvps are newest first, so each person's newest RSVP decides whether
+// they come and what they eat. It also returns how many of them haven't
+// chosen every course on the menu.
+func mealTotals(rsvps []shared.RSVPRecord, menu []shared.MenuOption) ([]DashboardMealTotal, int) {
+	totals := make([]DashboardMealTotal, len(menu))
+	index := make(map[string]int, len(menu))
+	for i, option := range menu {
+		totals[i] = DashboardMealTotal{Course: option.Course, OptionID: option.ID, Name: option.Name}
+		index[option.ID] = i
+	}
+
+	decided := make(map[string]bool)
+	missing := 0
+	for _, rsvp := range rsvps {
+		if !rsvp.Verified {
+			continue
+		}
+		if !rsvp.IsAttending {
+			decided[shared.NormalizeString(rsvp.Name)] = true
+			continue
+		}
+		meals := make(map[string]shared.GuestMeal, len(rsvp.GuestMeals))
+		for _, m := range rsvp.GuestMeals {
+			meals[shared.NormalizeString(m.GuestName)] = m
+		}
+		for _, name := range append(append([]string{}, rsvp.AttendingGuests...), rsvp.PlusOnes...) {
+			key := shared.NormalizeString(name)
+			if decided[key] {
+				continue
+			}
+			decided[key] = true
+			meal := meals[key]
+			for _, course := range shared.MenuCourses {
+				if i, ok := index[meal.Choice(course)]; ok && menu[i].Course == course {
+					totals[i].Count++
+				}
+			}
+			if len(shared.MissingCourses(meal, menu)) > 0 {
+				missing++
+			}
+		}
+	}
+	return totals, missing
+}
+
+// eventAttendance counts the people coming to each event, in running order.
+// rsvps are newest first, so each person's newest verified RSVP decides, as
+// for capacity (see shared.EventHeadcounts).
+func eventAttendance(rsvps []shared.RSVPRecord, guests []shared.Guest, events []shared.Event) []DashboardEventStats {
+	stats := make([]DashboardEventStats, len(events))
+	index := make(map[string]int, len(events))
+	for i, e := range events {
+		stats[i] = DashboardEventStats{ID: e.ID, Name: e.Name, Venue: e.Venue, StartsAt: e.StartsAt, Capacity: e.Capacity, AllGuests: e.AllGuests}
+		index[e.ID] = i
+	}
+
+	decided := make(map[string]bool)
+	for _, rsvp := range rsvps {
+		if !rsvp.Verified {
+			continue
+		}
+		if !rsvp.IsAttending {
+			decided[shared.NormalizeString(rsvp.Name)] = true
+			continue
+		}
+		for i, a := range shared.RSVPGuestEvents(rsvp, guests, events) {
+			key := shared.NormalizeString(a.GuestName)
+			if decided[key] {
+				continue
+			}
+			decided[key] = true
+			for _, e := range a.Events {
+				stats[index[e]].Attending++
+				if i >= len(rsvp.AttendingGuests) {
+					stats[index[e]].PlusOnes++
+				}
+			}
+		}
+	}
+	return stats
+}
+
+// nonNilStrings returns s, or an empty slice if s is nil, so it encodes
+// as []
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// nonNilDiets returns diets, or an empty slice if diets is nil, so it
+// encodes as []
+func nonNilDiets(diets []shared.GuestDiet) []shared.GuestDiet {
+	if diets == nil {
+		return []shared.GuestDiet{}
+	}
+	return diets
+}
+
+// groupAddress returns the first member address that isn't blank
+func groupAddress(members []shared.Guest) string {
+	for _, g := range members {
+		if addr := strings.TrimSpace(g.Address); addr != "" {
+			return addr
+		}
+	}
+	return ""
+}
+
+// addRSVPToHouseholdMap assigns an RSVP to the household(s) of its
+// submitter and attending guests in householdRSVPMap
+func addRSVPToHouseholdMap(householdRSVPMap map[string][]shared.RSVPRecord, guestHouseholdMap map[string]string, rsvp shared.RSVPRecord) {
+	addUnique := func(key string) {
+		for _, existing := range householdRSVPMap[key] {
+			if existing.ID == rsvp.ID {
+				return
+			}
+		}
+		householdRSVPMap[key] = append(householdRSVPMap[key], rsvp)
+	}
+	if householdID, ok := guestHouseholdMap[shared.NormalizeString(rsvp.Name)]; ok {
+		addUnique(householdID)
+	}
+	if rsvp.IsAttending {
+		for _, gName := range rsvp.AttendingGuests {
+			if householdID, ok := guestHouseholdMap[shared.NormalizeString(gName)]; ok {
+				addUnique(householdID)
+			}
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+
+	"utils/shared"
+)
+
+// exportSheet is one section of the dashboard export: a CSV block, or a
+// sheet of the XLSX workbook. The first row is the header.
+type exportSheet struct {
+	Name string
+	Rows [][]interface{}
+}
+
+// AdminDashboardExport downloads the dashboard for the venue or planner:
+// the guest list with RSVP statuses, plus-ones, dietary requirements and
+// RSVPs needing review. ?format=xlsx returns a workbook with one sheet per
+// section; otherwise it is a CSV file with the sections one after another,
+// or just one with ?section=summary|guests|plus-ones|dietary|review.
+var AdminDashboardExport = shared.RequireAdmin(shared.RoleViewer, adminDashboardExport)
+
+func adminDashboardExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		http.Error(w, "format must be csv or xlsx", http.StatusBadRequest)
+		return
+	}
+	sheets := []string{"summary", "guests", "plus-ones", "dietary", "review"}
+	if section := strings.ToLower(r.URL.Query().Get("section")); section != "" {
+		if !containsString(sheets, section) {
+			http.Error(w, "section must be one of "+strings.Join(sheets, ", "), http.StatusBadRequest)
+			return
+		}
+		sheets = []string{section}
+	}
+
+	resp, ok := loadDashboard(w)
+	if !ok {
+		return
+	}
+	log.Printf("Dashboard export (%s) for %s: %d invited, %d to review",
+		format, shared.AdminFromContext(r.Context()), resp.Stats.TotalInvited, resp.Stats.UnverifiedCount)
+
+	all := dashboardExportSheets(resp)
+	var export []exportSheet
+	for _, id := range sheets {
+		export = append(export, all[id])
+	}
+
+	filename := "guest-list"
+	if len(sheets) == 1 {
+		filename += "-" + sheets[0]
+	}
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, filename))
+		if err := writeExportXLSX(w, export); err != nil {
+			log.Printf("Error writing dashboard export workbook: %v", err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+	if err := writeExportCSV(w, export); err != nil {
+		log.Printf("Error writing dashboard export CSV: %v", err)
+	}
+}
+
+// dashboardExportSheets lays out each section of the dashboard as rows,
+// keyed by the ?section= name
+func dashboardExportSheets(resp DashboardResponse) map[string]exportSheet {
+	stats := resp.Stats
+	eventNames := make(map[string]string, len(stats.Events))
+	for _, e := range stats.Events {
+		eventNames[e.ID] = e.Name
+	}
+	names := func(ids []string) string {
+		labels := make([]string, len(ids))
+		for i, id := range ids {
+			if labels[i] = eventNames[id]; labels[i] == "" {
+				labels[i] = id
+			}
+		}
+		return strings.Join(labels, ", ")
+	}
+
+	summary := exportSheet{Name: "Summary", Rows: [][]interface{}{
+		{"", "Count"},
+		{"Invited", stats.TotalInvited},
+		{"Responded", stats.TotalRSVPd},
+		{"Not attending", stats.NotAttending},
+		{"No response", stats.NoResponse},
+		{"Plus-ones coming", stats.PlusOnesAttending},
+		{"Plus-ones allowed", stats.PlusOneAllowance},
+		{"With dietary requirements", stats.WithDietary},
+		{"Needing review", stats.UnverifiedCount},
+		{"Meals not chosen", stats.MealChoicesMissing},
+		{},
+		{"Event", "Venue", "Starts", "Invited", "Coming", "Plus-ones coming", "Capacity"},
+	}}
+	for _, e := range stats.Events {
+		capacity := interface{}("No limit")
+		if e.Capacity > 0 {
+			capacity = e.Capacity
+		}
+		summary.Rows = append(summary.Rows, []interface{}{e.Name, e.Venue, e.StartsAt, e.Invited, e.Attending, e.PlusOnes, capacity})
+	}
+
+	guests := exportSheet{Name: "Guests", Rows: [][]interface{}{
+		{"Household", "Address", "Name", "Status", "Verified", "Invited to", "Coming to", "Plus-ones allowed", "Table", "Dietary requirements", "Dietary notes"},
+	}}
+	for _, group := range resp.GuestGroups {
+		for _, m := range group.Members {
+			guests.Rows = append(guests.Rows, []interface{}{
+				group.Household, group.Address, m.Name, rsvpStatusLabel(m.RSVPStatus), yesNo(m.Verified),
+				names(m.Invited), names(m.Events), m.PlusOnes, m.Table,
+				strings.Join(shared.DietCategoryLabels(m.DietCategories), ", "), m.Dietary,
+			})
+		}
+	}
+
+	plusOnes := exportSheet{Name: "Plus-ones", Rows: [][]interface{}{{"Name", "Invited by", "Email"}}}
+	for _, p := range resp.PlusOnes {
+		plusOnes.Rows = append(plusOnes.Rows, []interface{}{p.Name, p.InvitedBy, p.Email})
+	}
+
+	dietary := exportSheet{Name: "Dietary", Rows: [][]interface{}{{"Name", "Email", "Requirements", "Notes", "Verified"}}}
+	for _, d := range resp.DietaryRequirements {
+		name := d.Name
+		switch {
+		case d.PlusOne:
+			name += " (plus-one)"
+		case d.Party:
+			name += " (whole party)"
+		}
+		dietary.Rows = append(dietary.Rows, []interface{}{
+			name, d.Email, strings.Join(shared.DietCategoryLabels(d.Categories), ", "), d.Diet, yesNo(d.Verified),
+		})
+	}
+
+	review := exportSheet{Name: "Needs review", Rows: [][]interface{}{
+		{"Name", "Email", "Attending", "Guests", "Plus-ones", "Dietary notes", "Submitted", "Reason"},
+	}}
+	for _, u := range resp.UnverifiedRSVPs {
+		reason := "Unverified"
+		switch {
+		case u.Late:
+			reason = "Late, awaiting approval"
+		case u.Verified:
+			reason = "Names not on the guest list"
+		}
+		review.Rows = append(review.Rows, []interface{}{
+			u.Name, u.Email, yesNo(u.IsAttending), strings.Join(u.AttendingGuests, ", "), strings.Join(u.PlusOnes, ", "),
+			u.Diet, u.SubmittedAt, reason,
+		})
+	}
+
+	return map[string]exportSheet{
+		"summary": summary, "guests": guests, "plus-ones": plusOnes, "dietary": dietary, "review": review,
+	}
+}
+
+// writeExportCSV writes the sheets one after another, each after a row
+// with its name and separated by a blank row. A single sheet is written
+// on its own, so it opens as a plain table.
+func writeExportCSV(w http.ResponseWriter, sheets []exportSheet) error {
+	out := csv.NewWriter(w)
+	for i, sheet := range sheets {
+		if len(sheets) > 1 {
+			if i > 0 {
+				out.Write(nil)
+			}
+			out.Write([]string{sheet.Name})
+		}
+		for _, row := range sheet.Rows {
+			record := make([]string, len(row))
+			for j, cell := range row {
+				record[j] = fmt.Sprint(cell)
+			}
+			out.Write(record)
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeExportXLSX writes a workbook with one sheet per section, with a
+// bold, frozen header row
+func writeExportXLSX(w http.ResponseWriter, sheets []exportSheet) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	for i, sheet := range sheets {
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheet.Name); err != nil {
+				return err
+			}
+		} else if _, err := f.NewSheet(sheet.Name); err != nil {
+			return err
+		}
+		widths := make(map[int]int)
+		for r, row := range sheet.Rows {
+			cell, err := excelize.CoordinatesToCellName(1, r+1)
+			if err != nil {
+				return err
+			}
+			if err := f.SetSheetRow(sheet.Name, cell, &row); err != nil {
+				return err
+			}
+			for c, value := range row {
+				if n := len(fmt.Sprint(value)); n > widths[c] {
+					widths[c] = n
+				}
+			}
+		}
+		for c, width := range widths {
+			col, err := excelize.ColumnNumberToName(c + 1)
+			if err != nil {
+				return err
+			}
+			f.SetColWidth(sheet.Name, col, col, float64(min(width, 50)+2))
+		}
+		if err := f.SetRowStyle(sheet.Name, 1, 1, bold); err != nil {
+			return err
+		}
+		f.SetPanes(sheet.Name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+	}
+	return f.Write(w)
+}
+
+// rsvpStatusLabel is how a DashboardGuestMember's RSVPStatus reads in an export
+func rsvpStatusLabel(status string) string {
+	switch status {
+	case "attending":
+		return "Attending"
+	case "not_attending":
+		return "Not attending"
+	}
+	return "No response"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+// containsString reports whether list includes s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
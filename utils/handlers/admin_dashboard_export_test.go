@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"utils/shared"
+)
+
+func TestAdminDashboardExport(t *testing.T) {
+	addGuests(t, shared.Guest{Name: "Export Ana", Address: "1 Export Street", Table: "7"})
+	testStore(t).SaveRSVP(shared.RSVPRequest{Name: "Export Ana", Email: "export@example.com", IsAttending: true, Verified: true,
+		AttendingGuests: []string{"Export Ana"}, PlusOnes: []string{"Export Sam"}})
+	token := adminToken(t, shared.RoleViewer)
+
+	t.Run("one CSV section", func(t *testing.T) {
+		w := serve(AdminDashboardExport, http.MethodGet, "/api/admin-dashboard-export?section=plus-ones", nil, token)
+		if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Disposition"), "guest-list-plus-ones.csv") {
+			t.Fatalf("status = %d, disposition %q", w.Code, w.Header().Get("Content-Disposition"))
+		}
+		rows, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(rows[0], []string{"Name", "Invited by", "Email"}) {
+			t.Errorf("header = %q", rows[0])
+		}
+		if !slices.ContainsFunc(rows, func(row []string) bool { return row[0] == "Export Sam" && row[2] == "export@example.com" }) {
+			t.Errorf("rows = %q, want Export Sam", rows)
+		}
+	})
+
+	t.Run("every CSV section", func(t *testing.T) {
+		w := serve(AdminDashboardExport, http.MethodGet, "/api/admin-dashboard-export", nil, token)
+		r := csv.NewReader(w.Body)
+		r.FieldsPerRecord = -1
+		rows, err := r.ReadAll()
+		if err != nil || len(rows) == 0 || rows[0][0] != "Summary" {
+			t.Fatalf("rows = %q, %v", rows, err)
+		}
+		var titles []string
+		for _, row := range rows {
+			if len(row) == 1 && row[0] != "" {
+				titles = append(titles, row[0])
+			}
+		}
+		if want := []string{"Summary", "Guests", "Plus-ones", "Dietary", "Needs review"}; !slices.Equal(titles, want) {
+			t.Errorf("sections = %q, want %q", titles, want)
+		}
+	})
+
+	t.Run("workbook", func(t *testing.T) {
+		w := serve(AdminDashboardExport, http.MethodGet, "/api/admin-dashboard-export?format=XLSX", nil, token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body)
+		}
+		f, err := excelize.OpenReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if want := []string{"Summary", "Guests", "Plus-ones", "Dietary", "Needs review"}; !slices.Equal(f.GetSheetList(), want) {
+			t.Errorf("sheets = %q, want %q", f.GetSheetList(), want)
+		}
+		rows, _ := f.GetRows("Guests")
+		if !slices.ContainsFunc(rows, func(row []string) bool {
+			return len(row) > 8 && row[2] == "Export Ana" && row[3] == "Attending" && row[8] == "7"
+		}) {
+			t.Errorf("Guests sheet = %q, want Export Ana attending at table 7", rows)
+		}
+	})
+
+	for _, target := range []string{"/api/admin-dashboard-export?format=pdf", "/api/admin-dashboard-export?section=menu"} {
+		if w := serve(AdminDashboardExport, http.MethodGet, target, nil, token); w.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want 400", target, w.Code)
+		}
+	}
+}
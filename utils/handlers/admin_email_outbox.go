@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"utils/shared"
+)
+
+// ResendEmailRequest is the request body for resending an outbox email
+type ResendEmailRequest struct {
+	ID string `json:"id"`
+}
+
+// AdminEmailOutboxResponse is returned by every method on
+// /api/admin-email-outbox. Emails leave out their bodies.
+type AdminEmailOutboxResponse struct {
+	Success bool                 `json:"success"`
+	Message string               `json:"message,omitempty"`
+	Emails  []shared.OutboxEmail `json:"emails"`
+}
+
+// AdminEmailOutbox lists emails in the outbox (GET), by default the ones
+// that failed or bounced; ?status= picks queued, sending, sent, failed,
+// bounced or all. Editors can send one again (POST), which also lists the emails.
+var AdminEmailOutbox = shared.RequireAdmin(shared.RoleViewer, adminEmailOutbox)
+
+func adminEmailOutbox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Method != http.MethodGet && !shared.AdminRoleFromContext(r.Context()).Allows(shared.RoleEditor) {
+		outboxErr(w, http.StatusForbidden, fmt.Sprintf("This action requires the %s role", shared.RoleEditor))
+		return
+	}
+
+	var statuses []string
+	switch status := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status"))); status {
+	case "":
+		statuses = []string{shared.EmailFailed, shared.EmailBounced}
+	case "all":
+	case shared.EmailQueued, shared.EmailSending, shared.EmailSent, shared.EmailFailed, shared.EmailBounced:
+		statuses = []string{status}
+	default:
+		outboxErr(w, http.StatusBadRequest, "status must be queued, sending, sent, failed, bounced or all")
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		outboxErr(w, http.StatusInternalServerError, "Database not configured")
+		return
+	}
+
+	message := ""
+	if r.Method == http.MethodPost {
+		var req ResendEmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.ID) == "" {
+			outboxErr(w, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+		email, err := store.GetOutboxEmail(strings.TrimSpace(req.ID))
+		if err != nil {
+			log.Printf("Error fetching outbox email %s: %v", req.ID, err)
+			outboxErr(w, http.StatusInternalServerError, "Failed to resend email")
+			return
+		}
+		if email == nil {
+			outboxErr(w, http.StatusNotFound, "That email no longer exists")
+			return
+		}
+
+		// Resending claims the email first, so it is refused while the
+		// worker (or another admin) has it queued or is sending it
+		resent, err := shared.ResendOutboxEmail(store, email.ID)
+		if resent == nil {
+			if err != nil {
+				log.Printf("Error claiming outbox email %s: %v", email.ID, err)
+				outboxErr(w, http.StatusInternalServerError, "Failed to resend email")
+				return
+			}
+			outboxErr(w, http.StatusConflict, "That email is already waiting to be sent")
+			return
+		}
+		email = resent
+		if err != nil {
+			log.Printf("⚠️  Admin %s resent %s email %s to %s, which failed and will be retried: %v",
+				shared.AdminFromContext(r.Context()), email.Kind, email.ID, email.To, err)
+			message = fmt.Sprintf("The email to %s couldn't be sent just now and will be retried", email.To)
+		} else {
+			log.Printf("✓ Admin %s resent %s email %s to %s", shared.AdminFromContext(r.Context()), email.Kind, email.ID, email.To)
+			message = fmt.Sprintf("The email to %s has been sent again", email.To)
+		}
+	}
+
+	emails, err := store.ListOutboxEmails(statuses...)
+	if err != nil {
+		log.Printf("Error fetching outbox emails: %v", err)
+		outboxErr(w, http.StatusInternalServerError, "Failed to fetch emails")
+		return
+	}
+	for i := range emails {
+		emails[i].Text, emails[i].HTML, emails[i].TemplateData = "", "", nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminEmailOutboxResponse{Success: true, Message: message, Emails: emails})
+}
+
+func outboxErr(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(AdminEmailOutboxResponse{Success: false, Message: message, Emails: []shared.OutboxEmail{}})
+}
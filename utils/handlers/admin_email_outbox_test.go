@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"utils/shared"
+)
+
+func TestAdminEmailOutbox(t *testing.T) {
+	store := testStore(t)
+	failed, err := store.EnqueueEmail(shared.OutboxEmail{
+		Kind: "meal-reminder", To: "outbox-ana@example.com", Subject: "Meals", Text: "Choose",
+		Status: shared.EmailFailed, Attempts: shared.EmailMaxAttempts, LastError: "timeout",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queued, err := store.EnqueueEmail(shared.OutboxEmail{Kind: "meal-reminder", To: "outbox-bob@example.com", Status: shared.EmailQueued})
+	if err != nil {
+		t.Fatal(err)
+	}
+	viewer, editor := adminToken(t, shared.RoleViewer), adminToken(t, shared.RoleEditor)
+
+	ids := func(resp AdminEmailOutboxResponse) []string {
+		var ids []string
+		for _, e := range resp.Emails {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	w := serve(AdminEmailOutbox, http.MethodGet, "/api/admin-email-outbox", nil, viewer)
+	var resp AdminEmailOutboxResponse
+	decode(t, w, &resp)
+	if w.Code != http.StatusOK || !slices.Contains(ids(resp), failed.ID) || slices.Contains(ids(resp), queued.ID) {
+		t.Fatalf("status = %d, emails %v, want the failed email but not the queued one", w.Code, ids(resp))
+	}
+	for _, e := range resp.Emails {
+		if e.Text != "" {
+			t.Errorf("email %s listed with its body", e.ID)
+		}
+	}
+	if w := serve(AdminEmailOutbox, http.MethodGet, "/api/admin-email-outbox?status=nope", nil, viewer); w.Code != http.StatusBadRequest {
+		t.Errorf("bad status: status = %d, want 400", w.Code)
+	}
+
+	tests := []struct {
+		name  string
+		id    string
+		token string
+		want  int
+	}{
+		{"viewer", failed.ID, viewer, http.StatusForbidden},
+		{"still queued", queued.ID, editor, http.StatusConflict},
+		{"unknown", "missing", editor, http.StatusNotFound},
+		{"failed", failed.ID, editor, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(AdminEmailOutbox, http.MethodPost, "/api/admin-email-outbox", ResendEmailRequest{ID: tt.id}, tt.token)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+	if email, _ := store.GetOutboxEmail(failed.ID); email.Status != shared.EmailSent || email.Resends != 1 || email.Attempts != 1 {
+		t.Errorf("resent email = %+v, want sent at the first attempt of its first resend", email)
+	}
+}
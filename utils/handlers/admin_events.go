@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"utils/shared"
+)
+
+// SaveEventRequest is the request body for adding or updating an event.
+// ID picks the event to update; without one the event is added, with an ID
+// made from its name.
+type SaveEventRequest struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Venue     string `json:"venue"`
+	StartsAt  string `json:"startsAt,omitempty"` // RFC 3339
+	Capacity  int    `json:"capacity"`           // 0 for no limit
+	AllGuests bool   `json:"allGuests"`
+}
+
+// AdminEventsResponse is returned by every method on /api/admin-events
+type AdminEventsResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message,omitempty"`
+	Events  []shared.Event `json:"events"`
+}
+
+// AdminEvents lists the events guests RSVP to (GET). Editors can add or
+// update one (POST). Events can't be removed, as RSVPs and invitations
+// refer to them; set a capacity instead to stop more people coming.
+var AdminEvents = shared.RequireAdmin(shared.RoleViewer, adminEvents)
+
+func adminEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Method != http.MethodGet && !shared.AdminRoleFromContext(r.Context()).Allows(shared.RoleEditor) {
+		eventsErr(w, http.StatusForbidden, fmt.Sprintf("This action requires the %s role", shared.RoleEditor))
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		eventsErr(w, http.StatusInternalServerError, "Database not configured")
+		return
+	}
+
+	message := ""
+	if r.Method == http.MethodPost {
+		var req SaveEventRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			eventsErr(w, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+		event := shared.Event{
+			ID: strings.TrimSpace(req.ID), Name: strings.TrimSpace(req.Name), Venue: strings.TrimSpace(req.Venue),
+			StartsAt: strings.TrimSpace(req.StartsAt), Capacity: req.Capacity, AllGuests: req.AllGuests,
+		}
+		if event.Name == "" {
+			eventsErr(w, http.StatusBadRequest, "Name is required")
+			return
+		}
+		if event.Capacity < 0 {
+			eventsErr(w, http.StatusBadRequest, "Capacity can't be negative")
+			return
+		}
+		if event.StartsAt != "" {
+			if _, err := time.Parse(time.RFC3339, event.StartsAt); err != nil {
+				eventsErr(w, http.StatusBadRequest, "Start time must be a date and time like 2026-08-01T18:00:00+01:00")
+				return
+			}
+		}
+
+		events, err := store.ListEvents()
+		if err != nil {
+			log.Printf("Error fetching events: %v", err)
+			eventsErr(w, http.StatusInternalServerError, "Failed to save event")
+			return
+		}
+		adding := event.ID == ""
+		if adding {
+			event.ID = shared.EventSlug(event.Name)
+			if event.ID == "" {
+				eventsErr(w, http.StatusBadRequest, "Name must contain a letter or digit")
+				return
+			}
+		} else if !eventExists(events, event.ID) {
+			eventsErr(w, http.StatusNotFound, "That event no longer exists")
+			return
+		}
+		// Invitations in the invite list refer to events by name as well
+		// as ID, so neither may be shared with another event
+		for _, existing := range events {
+			if existing.ID == event.ID && !adding {
+				continue
+			}
+			if existing.ID == event.ID || shared.NormalizeString(existing.Name) == shared.NormalizeString(event.Name) {
+				eventsErr(w, http.StatusConflict, fmt.Sprintf("There is already an event called %q", existing.Name))
+				return
+			}
+		}
+
+		if err := store.SaveEvent(event); err != nil {
+			log.Printf("Error saving event %s: %v", event.ID, err)
+			eventsErr(w, http.StatusInternalServerError, "Failed to save event")
+			return
+		}
+		if adding {
+			log.Printf("✓ Admin %s added event %s: %s at %s", shared.AdminFromContext(r.Context()), event.ID, event.Name, event.Venue)
+			message = fmt.Sprintf("The %s has been added", event.Name)
+		} else {
+			log.Printf("✓ Admin %s updated event %s: %s at %s (capacity %d)", shared.AdminFromContext(r.Context()), event.ID, event.Name, event.Venue, event.Capacity)
+			message = fmt.Sprintf("The %s has been updated", event.Name)
+		}
+	}
+
+	events, err := store.ListEvents()
+	if err != nil {
+		log.Printf("Error fetching events: %v", err)
+		eventsErr(w, http.StatusInternalServerError, "Failed to fetch events")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminEventsResponse{Success: true, Message: message, Events: events})
+}
+
+func eventsErr(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(AdminEventsResponse{Success: false, Message: message, Events: []shared.Event{}})
+}
+
+// eventExists reports whether id is one of events
+func eventExists(events []shared.Event, id string) bool {
+	for _, e := range events {
+		if e.ID == id {
+			return true
+		}
+	}
+	return false
+}
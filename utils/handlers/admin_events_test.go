@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"utils/shared"
+)
+
+func TestAdminEvents(t *testing.T) {
+	editor, viewer := adminToken(t, shared.RoleEditor), adminToken(t, shared.RoleViewer)
+
+	add := SaveEventRequest{Name: " Events Test Brunch ", Venue: "The Café", StartsAt: "2026-08-02T11:00:00+01:00", Capacity: 40}
+	w := serve(AdminEvents, http.MethodPost, "/api/admin-events", add, editor)
+	var resp AdminEventsResponse
+	decode(t, w, &resp)
+	if w.Code != http.StatusOK {
+		t.Fatalf("add = %d %+v", w.Code, resp)
+	}
+	brunch := shared.FindEvent("events-test-brunch", resp.Events)
+	if brunch == nil || brunch.Name != "Events Test Brunch" || brunch.Capacity != 40 {
+		t.Fatalf("events = %+v, want Events Test Brunch", resp.Events)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		body       interface{}
+		token      string
+		wantStatus int
+	}{
+		{"viewer lists", http.MethodGet, nil, viewer, http.StatusOK},
+		{"viewer can't save", http.MethodPost, SaveEventRequest{Name: "Events Test Picnic"}, viewer, http.StatusForbidden},
+		{"update", http.MethodPost, SaveEventRequest{ID: "events-test-brunch", Name: "Events Test Brunch", Capacity: 30}, editor, http.StatusOK},
+		{"update a missing event", http.MethodPost, SaveEventRequest{ID: "events-test-nothing", Name: "Events Test Nothing"}, editor, http.StatusNotFound},
+		{"name taken", http.MethodPost, SaveEventRequest{Name: "events test BRUNCH"}, editor, http.StatusConflict},
+		{"renamed onto another event", http.MethodPost, SaveEventRequest{ID: "events-test-brunch", Name: "Reception"}, editor, http.StatusConflict},
+		{"no name", http.MethodPost, SaveEventRequest{Venue: "Somewhere"}, editor, http.StatusBadRequest},
+		{"no letters", http.MethodPost, SaveEventRequest{Name: "!!!"}, editor, http.StatusBadRequest},
+		{"negative capacity", http.MethodPost, SaveEventRequest{Name: "Events Test Picnic", Capacity: -1}, editor, http.StatusBadRequest},
+		{"bad start time", http.MethodPost, SaveEventRequest{Name: "Events Test Picnic", StartsAt: "Sunday"}, editor, http.StatusBadRequest},
+		{"bad body", http.MethodPost, "{", editor, http.StatusBadRequest},
+		{"DELETE", http.MethodDelete, nil, editor, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serve(AdminEvents, tt.method, "/api/admin-events", tt.body, tt.token); w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+
+	var listed AdminEventsResponse
+	decode(t, serve(AdminEvents, http.MethodGet, "/api/admin-events", nil, viewer), &listed)
+	if brunch := shared.FindEvent("events-test-brunch", listed.Events); brunch == nil || brunch.Capacity != 30 || brunch.StartsAt != "" {
+		t.Errorf("updated brunch = %+v, want capacity 30 and no start time", brunch)
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"utils/shared"
+)
+
+// AdminExportGuests downloads the invite list as invite_list.csv, in the
+// format the CSV import reads (see shared.WriteGuestsCSV), so guests added
+// from the dashboard can be copied back into the spreadsheet
+var AdminExportGuests = shared.RequireAdmin(shared.RoleViewer, adminExportGuests)
+
+func adminExportGuests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		exportGuestsErr(w, "Database not configured")
+		return
+	}
+
+	guests, err := store.ListGuests()
+	if err != nil {
+		log.Printf("Error fetching guests: %v", err)
+		exportGuestsErr(w, "Failed to fetch guest list")
+		return
+	}
+
+	households, err := store.ListHouseholds()
+	if err != nil {
+		log.Printf("Error fetching households: %v", err)
+		exportGuestsErr(w, "Failed to fetch households")
+		return
+	}
+
+	events, err := store.ListEvents()
+	if err != nil {
+		log.Printf("Error fetching events: %v", err)
+		exportGuestsErr(w, "Failed to fetch events")
+		return
+	}
+
+	log.Printf("Guest list export for %s: %d guests", shared.AdminFromContext(r.Context()), len(guests))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="invite_list.csv"`)
+	if err := shared.WriteGuestsCSV(w, guests, households, events); err != nil {
+		log.Printf("Error writing guest list CSV: %v", err)
+	}
+}
+
+func exportGuestsErr(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": message})
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"slices"
+	"testing"
+
+	"utils/shared"
+)
+
+func TestAdminExportGuests(t *testing.T) {
+	addGuests(t, shared.Guest{Name: "Exported Ana", Address: "1 Exported Street", PlusOnes: 2})
+
+	w := serve(AdminExportGuests, http.MethodGet, "/api/admin-export-guests", nil, adminToken(t, shared.RoleViewer))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Disposition") != `attachment; filename="invite_list.csv"` {
+		t.Fatalf("status = %d, disposition %q", w.Code, w.Header().Get("Content-Disposition"))
+	}
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(rows, func(row []string) bool {
+		return row[0] == "Exported Ana" && row[1] == "1 Exported Street" && row[4] == "2"
+	}) {
+		t.Errorf("rows = %q, want Exported Ana", rows)
+	}
+
+	if w := serve(AdminExportGuests, http.MethodPost, "/api/admin-export-guests", nil, adminToken(t, shared.RoleViewer)); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d", w.Code)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"utils/shared"
+)
+
+// AdminLoginRequest represents a login request
+type AdminLoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// AdminLoginResponse represents a login response
+type AdminLoginResponse struct {
+	Success bool   `json:"success"`
+	Token   string `json:"token,omitempty"`
+	Role    string `json:"role,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// AdminLogin handles admin login requests
+func AdminLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AdminLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(AdminLoginResponse{
+			Success: false,
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AdminLoginResponse{
+			Success: false,
+			Message: "Server configuration error",
+		})
+		return
+	}
+
+	account, err := shared.AuthenticateAdmin(store, req.Username, req.Password)
+	if err != nil {
+		log.Printf("Error checking admin credentials: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AdminLoginResponse{
+			Success: false,
+			Message: "Server error - please try again",
+		})
+		return
+	}
+	if account == nil {
+		log.Printf("Failed admin login attempt for username: %s", req.Username)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(AdminLoginResponse{
+			Success: false,
+			Message: "Invalid username or password",
+		})
+		return
+	}
+
+	auth, err := shared.NewAdminAuth()
+	if err != nil {
+		log.Printf("Admin auth not configured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(AdminLoginResponse{
+			Success: false,
+			Message: "Server configuration error",
+		})
+		return
+	}
+
+	token := auth.IssueToken(account.Username, account.Role)
+	log.Printf("Admin login successful for: %s (%s)", account.Username, account.Role)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminLoginResponse{
+		Success: true,
+		Token:   token,
+		Role:    string(account.Role),
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"utils/shared"
+)
+
+// MealReminder is one guest emailed (or, on a dry run, to be emailed) about
+// the people on their RSVP who haven't chosen their meals
+type MealReminder struct {
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Guests []string `json:"guests"` // people still to choose
+	Sent   bool     `json:"sent"`
+}
+
+// MealRemindersResponse is the response from /api/admin-meal-reminders
+type MealRemindersResponse struct {
+	Success   bool           `json:"success"`
+	Message   string         `json:"message"`
+	Sent      int            `json:"sent"`
+	Reminders []MealReminder `json:"reminders"`
+}
+
+// AdminMealReminders emails every guest with a verified, attending RSVP
+// whose party hasn't chosen every course. ?dryRun=true lists who would be
+// emailed without sending anything.
+var AdminMealReminders = shared.RequireAdmin(shared.RoleEditor, adminMealReminders)
+
+func adminMealReminders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		writeMealRemindersErr(w, http.StatusInternalServerError, "Database not configured")
+		return
+	}
+
+	menu, err := store.ListMenuOptions()
+	if err != nil {
+		log.Printf("Error fetching menu: %v", err)
+		writeMealRemindersErr(w, http.StatusInternalServerError, "Failed to fetch menu")
+		return
+	}
+	if len(menu) == 0 {
+		writeMealRemindersErr(w, http.StatusBadRequest, "Add some dishes to the menu before sending reminders")
+		return
+	}
+
+	rsvps, err := store.ListRSVPs()
+	if err != nil {
+		log.Printf("Error fetching RSVPs: %v", err)
+		writeMealRemindersErr(w, http.StatusInternalServerError, "Failed to fetch RSVPs")
+		return
+	}
+
+	resp := MealRemindersResponse{Success: true, Reminders: []MealReminder{}}
+	for _, rsvp := range latestRSVPs(rsvps) {
+		// Admin overrides have no real inbox behind them
+		if !rsvp.Verified || !rsvp.IsAttending || strings.HasSuffix(rsvp.Email, shared.AdminOverrideEmailSuffix) {
+			continue
+		}
+		missing := shared.PeopleWithoutMeals(rsvp, menu)
+		if len(missing) == 0 {
+			continue
+		}
+
+		reminder := MealReminder{Name: rsvp.Name, Email: rsvp.Email, Guests: missing}
+		if !dryRun {
+			// Not using goroutines to ensure they complete before serverless function terminates
+			if err := shared.SendMealReminderEmail(rsvp, missing); err != nil {
+				log.Printf("Failed to send meal reminder to %s: %v", rsvp.Email, err)
+			} else {
+				reminder.Sent = true
+				resp.Sent++
+			}
+		}
+		resp.Reminders = append(resp.Reminders, reminder)
+	}
+
+	switch {
+	case dryRun:
+		resp.Message = fmt.Sprintf("%d guest(s) would be reminded", len(resp.Reminders))
+	case len(resp.Reminders) == 0:
+		resp.Message = "Everyone coming has chosen their meals"
+	case resp.Sent < len(resp.Reminders):
+		resp.Message = fmt.Sprintf("Sent %d of %d meal reminders - some failed to send", resp.Sent, len(resp.Reminders))
+	default:
+		resp.Message = fmt.Sprintf("Sent %d meal reminder(s)", resp.Sent)
+	}
+	log.Printf("Meal reminders by %s: %s", shared.AdminFromContext(r.Context()), resp.Message)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// latestRSVPs returns each email's newest RSVP. rsvps are newest first.
+func latestRSVPs(rsvps []shared.RSVPRecord) []shared.RSVPRecord {
+	seen := make(map[string]bool, len(rsvps))
+	latest := make([]shared.RSVPRecord, 0, len(rsvps))
+	for _, rsvp := range rsvps {
+		key := strings.ToLower(strings.TrimSpace(rsvp.Email))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		latest = append(latest, rsvp)
+	}
+	return latest
+}
+
+func writeMealRemindersErr(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": message})
+}
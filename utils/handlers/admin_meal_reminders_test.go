@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"utils/shared"
+)
+
+func TestAdminMealReminders(t *testing.T) {
+	store := testStore(t)
+	dish, err := store.AddMenuOption(shared.MenuOption{Course: shared.CourseMain, Name: "Reminder Test Stew"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveRSVP := func(rsvp shared.RSVPRequest) {
+		if err := store.SaveRSVP(rsvp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saveRSVP(shared.RSVPRequest{Name: "Remind Ana", Email: "remind-1@example.com", IsAttending: true, Verified: true,
+		AttendingGuests: []string{"Remind Ana", "Remind Bob"}, PlusOnes: []string{"Remind Sam"},
+		GuestMeals: []shared.GuestMeal{{GuestName: "Remind Bob", Main: dish.ID}}})
+	saveRSVP(shared.RSVPRequest{Name: "Remind Cat", Email: "remind-2@example.com", Verified: true})
+	saveRSVP(shared.RSVPRequest{Name: "Remind Dan", Email: "remind-3@example.com", IsAttending: true, AttendingGuests: []string{"Remind Dan"}})
+
+	w := serve(AdminMealReminders, http.MethodPost, "/api/admin-meal-reminders?dryRun=true", nil, adminToken(t, shared.RoleEditor))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var resp MealRemindersResponse
+	decode(t, w, &resp)
+	reminded := make(map[string]MealReminder)
+	for _, r := range resp.Reminders {
+		reminded[r.Email] = r
+	}
+	if r := reminded["remind-1@example.com"]; r.Sent || !slices.Equal(r.Guests, []string{"Remind Ana", "Remind Sam"}) {
+		t.Errorf("reminder = %+v, want unsent for Ana and Sam", r)
+	}
+	for _, email := range []string{"remind-2@example.com", "remind-3@example.com"} {
+		if _, ok := reminded[email]; ok {
+			t.Errorf("%s reminded, but isn't a verified guest who's coming", email)
+		}
+	}
+	if resp.Sent != 0 {
+		t.Errorf("dry run sent %d", resp.Sent)
+	}
+
+	if w := serve(AdminMealReminders, http.MethodPost, "/api/admin-meal-reminders", nil, adminToken(t, shared.RoleViewer)); w.Code != http.StatusForbidden {
+		t.Errorf("viewer status = %d, want forbidden", w.Code)
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"utils/shared"
+)
+
+// AddMenuOptionRequest is the request body for adding a dish to the menu
+type AddMenuOptionRequest struct {
+	Course      string `json:"course"` // "starter" | "main" | "dessert"
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// AdminMenuResponse is returned by every method on /api/admin-menu
+type AdminMenuResponse struct {
+	Success bool                `json:"success"`
+	Message string              `json:"message,omitempty"`
+	Menu    []shared.MenuOption `json:"menu"`
+}
+
+// AdminMenu lists the dishes guests choose their meals from (GET). Editors
+// can add a dish (POST) or take one off the menu (DELETE ?id=...).
+var AdminMenu = shared.RequireAdmin(shared.RoleViewer, adminMenu)
+
+func adminMenu(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodPost, http.MethodDelete:
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Method != http.MethodGet && !shared.AdminRoleFromContext(r.Context()).Allows(shared.RoleEditor) {
+		menuErr(w, http.StatusForbidden, fmt.Sprintf("This action requires the %s role", shared.RoleEditor))
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		menuErr(w, http.StatusInternalServerError, "Database not configured")
+		return
+	}
+
+	message := ""
+	switch r.Method {
+	case http.MethodPost:
+		var req AddMenuOptionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			menuErr(w, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+		course, err := shared.ParseCourse(req.Course)
+		if err != nil {
+			menuErr(w, http.StatusBadRequest, "Course must be starter, main or dessert")
+			return
+		}
+		option := shared.MenuOption{Course: course, Name: strings.TrimSpace(req.Name), Description: strings.TrimSpace(req.Description)}
+		if option.Name == "" {
+			menuErr(w, http.StatusBadRequest, "Name is required")
+			return
+		}
+
+		menu, err := store.ListMenuOptions()
+		if err != nil {
+			log.Printf("Error fetching menu: %v", err)
+			menuErr(w, http.StatusInternalServerError, "Failed to add dish")
+			return
+		}
+		for _, existing := range menu {
+			if existing.Course == course && shared.NormalizeString(existing.Name) == shared.NormalizeString(option.Name) {
+				menuErr(w, http.StatusConflict, fmt.Sprintf("%q is already on the menu", option.Name))
+				return
+			}
+		}
+
+		added, err := store.AddMenuOption(option)
+		if err != nil {
+			log.Printf("Error adding menu option: %v", err)
+			menuErr(w, http.StatusInternalServerError, "Failed to add dish")
+			return
+		}
+		log.Printf("✓ Admin %s added %s to the menu: %s", shared.AdminFromContext(r.Context()), added.Course, added.Name)
+		message = fmt.Sprintf("%s has been added to the menu", added.Name)
+
+	case http.MethodDelete:
+		id := strings.TrimSpace(r.URL.Query().Get("id"))
+		if id == "" {
+			menuErr(w, http.StatusBadRequest, "id is required")
+			return
+		}
+		// Guests who chose the dish are asked to choose again by the meal
+		// reminders, so their choices are left as they are
+		if err := store.DeleteMenuOption(id); err != nil {
+			log.Printf("Error deleting menu option %s: %v", id, err)
+			menuErr(w, http.StatusNotFound, "That dish is no longer on the menu")
+			return
+		}
+		log.Printf("✓ Admin %s took menu option %s off the menu", shared.AdminFromContext(r.Context()), id)
+		message = "The dish has been taken off the menu"
+	}
+
+	menu, err := store.ListMenuOptions()
+	if err != nil {
+		log.Printf("Error fetching menu: %v", err)
+		menuErr(w, http.StatusInternalServerError, "Failed to fetch menu")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminMenuResponse{Success: true, Message: message, Menu: menu})
+}
+
+func menuErr(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(AdminMenuResponse{Success: false, Message: message, Menu: []shared.MenuOption{}})
+}
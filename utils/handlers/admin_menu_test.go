@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"utils/shared"
+)
+
+// menuHas reports whether menu has a dish called name
+func menuHas(menu []shared.MenuOption, name string) bool {
+	for _, option := range menu {
+		if option.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAdminMenu(t *testing.T) {
+	editor, viewer := adminToken(t, shared.RoleEditor), adminToken(t, shared.RoleViewer)
+
+	w := serve(AdminMenu, http.MethodPost, "/api/admin-menu", AddMenuOptionRequest{Course: "Mains", Name: " Menu Test Risotto "}, editor)
+	var resp AdminMenuResponse
+	decode(t, w, &resp)
+	if w.Code != http.StatusOK || !menuHas(resp.Menu, "Menu Test Risotto") {
+		t.Fatalf("add = %d %+v", w.Code, resp)
+	}
+	var id string
+	for _, option := range resp.Menu {
+		if option.Name == "Menu Test Risotto" {
+			id = option.ID
+			if option.Course != shared.CourseMain {
+				t.Errorf("course = %q, want main", option.Course)
+			}
+		}
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       interface{}
+		token      string
+		wantStatus int
+	}{
+		{"viewer lists", http.MethodGet, "/api/admin-menu", nil, viewer, http.StatusOK},
+		{"viewer can't add", http.MethodPost, "/api/admin-menu", AddMenuOptionRequest{Course: "main", Name: "Menu Test Pie"}, viewer, http.StatusForbidden},
+		{"duplicate", http.MethodPost, "/api/admin-menu", AddMenuOptionRequest{Course: "main", Name: "menu test risotto"}, editor, http.StatusConflict},
+		{"unknown course", http.MethodPost, "/api/admin-menu", AddMenuOptionRequest{Course: "pudding", Name: "Menu Test Pie"}, editor, http.StatusBadRequest},
+		{"no name", http.MethodPost, "/api/admin-menu", AddMenuOptionRequest{Course: "main"}, editor, http.StatusBadRequest},
+		{"bad body", http.MethodPost, "/api/admin-menu", "{", editor, http.StatusBadRequest},
+		{"delete without id", http.MethodDelete, "/api/admin-menu", nil, editor, http.StatusBadRequest},
+		{"delete", http.MethodDelete, "/api/admin-menu?id=" + id, nil, editor, http.StatusOK},
+		{"delete again", http.MethodDelete, "/api/admin-menu?id=" + id, nil, editor, http.StatusNotFound},
+		{"PUT", http.MethodPut, "/api/admin-menu", nil, editor, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serve(AdminMenu, tt.method, tt.target, tt.body, tt.token); w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+
+	w = serve(AdminMenu, http.MethodGet, "/api/admin-menu", nil, viewer)
+	decode(t, w, &resp)
+	if menuHas(resp.Menu, "Menu Test Risotto") {
+		t.Errorf("deleted dish still on the menu: %+v", resp.Menu)
+	}
+}
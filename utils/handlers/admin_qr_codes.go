@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"utils/shared"
+)
+
+// AdminQRCodes renders QR codes for the stationery, as PNG or (with
+// ?format=svg) SVG:
+//   - ?household=<id>: the household's invite link (see shared.InviteURL)
+//   - ?guest=<id>: the guest's check-in pass (see shared.CheckInPassSigner)
+//   - neither: a zip of both for every household (see shared.WriteQRCodesZip)
+//
+// ?size sets the width of PNGs in pixels (default 512).
+var AdminQRCodes = shared.RequireAdmin(shared.RoleViewer, adminQRCodes)
+
+func adminQRCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	format, err := shared.ParseQRFormat(query.Get("format"))
+	if err != nil {
+		qrCodesErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	size := shared.DefaultQRSize
+	if s := strings.TrimSpace(query.Get("size")); s != "" {
+		size, err = strconv.Atoi(s)
+		if err != nil || size < 64 || size > shared.MaxQRSize {
+			qrCodesErr(w, http.StatusBadRequest, "size must be a number of pixels from 64 to "+strconv.Itoa(shared.MaxQRSize))
+			return
+		}
+	}
+	householdID := strings.TrimSpace(query.Get("household"))
+	guestID := strings.TrimSpace(query.Get("guest"))
+
+	signer, err := shared.NewCheckInPassSigner()
+	if err != nil && householdID == "" {
+		log.Printf("Check-in passes not configured: %v", err)
+		qrCodesErr(w, http.StatusInternalServerError, "Check-in passes not configured")
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		qrCodesErr(w, http.StatusInternalServerError, "Database not configured")
+		return
+	}
+	households, err := store.ListHouseholds()
+	if err != nil {
+		log.Printf("Error fetching households: %v", err)
+		qrCodesErr(w, http.StatusInternalServerError, "Failed to fetch households")
+		return
+	}
+
+	var content, filename string
+	switch {
+	case householdID != "":
+		var household *shared.Household
+		for i := range households {
+			if households[i].ID == householdID {
+				household = &households[i]
+			}
+		}
+		if household == nil {
+			qrCodesErr(w, http.StatusNotFound, "Household not found")
+			return
+		}
+		if content = shared.HouseholdInviteQRContent(*household); content == "" {
+			qrCodesErr(w, http.StatusConflict, household.Name+" has no invite code yet - generate codes with the invite-codes tool")
+			return
+		}
+		filename = shared.FileNameSlug(household.Name, "household") + "-invite"
+
+	case guestID != "":
+		guests, err := store.ListGuests()
+		if err != nil {
+			log.Printf("Error fetching guests: %v", err)
+			qrCodesErr(w, http.StatusInternalServerError, "Failed to fetch guest list")
+			return
+		}
+		for _, g := range guests {
+			if g.ID == guestID {
+				content = signer.URL(g.ID)
+				filename = shared.FileNameSlug(g.Name, "guest") + "-pass"
+			}
+		}
+		if content == "" {
+			qrCodesErr(w, http.StatusNotFound, "Guest not found")
+			return
+		}
+
+	default:
+		guests, err := store.ListGuests()
+		if err != nil {
+			log.Printf("Error fetching guests: %v", err)
+			qrCodesErr(w, http.StatusInternalServerError, "Failed to fetch guest list")
+			return
+		}
+		var buf bytes.Buffer
+		if err := shared.WriteQRCodesZip(&buf, guests, households, format, size); err != nil {
+			log.Printf("Error writing QR codes zip: %v", err)
+			qrCodesErr(w, http.StatusInternalServerError, "Failed to render QR codes")
+			return
+		}
+		log.Printf("QR codes export (%s) for %s: %d households, %d guests",
+			format, shared.AdminFromContext(r.Context()), len(households), len(guests))
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="qr_codes.zip"`)
+		w.Write(buf.Bytes())
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := shared.WriteQRCode(&buf, content, format, size); err != nil {
+		log.Printf("Error rendering QR code: %v", err)
+		qrCodesErr(w, http.StatusInternalServerError, "Failed to render QR code")
+		return
+	}
+	w.Header().Set("Content-Type", shared.QRContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+"."+format+`"`)
+	w.Write(buf.Bytes())
+}
+
+func qrCodesErr(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": message})
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"utils/shared"
+)
+
+func TestAdminQRCodes(t *testing.T) {
+	t.Setenv("CHECKIN_PASS_SECRET", "qr-test-secret")
+	addGuests(t,
+		shared.Guest{Name: "QR Ana", Address: "5 Quick Response Way"},
+		shared.Guest{Name: "QR Bob", Address: "6 Quick Response Way"},
+	)
+	ana, bob := findGuest(t, "QR Ana"), findGuest(t, "QR Bob")
+	setInviteCode(t, "QR Ana", "QRQR-5555")
+	token := adminToken(t, shared.RoleViewer)
+
+	tests := []struct {
+		name            string
+		target          string
+		wantStatus      int
+		wantType        string
+		wantDisposition string
+	}{
+		{"invite", "/api/admin-qr-codes?household=" + ana.HouseholdID, http.StatusOK, "image/png", `attachment; filename="5-quick-response-way-invite.png"`},
+		{"pass as svg", "/api/admin-qr-codes?format=svg&guest=" + ana.ID, http.StatusOK, "image/svg+xml", `attachment; filename="qr-ana-pass.svg"`},
+		{"zip", "/api/admin-qr-codes?size=128", http.StatusOK, "application/zip", `attachment; filename="qr_codes.zip"`},
+		{"household without a code", "/api/admin-qr-codes?household=" + bob.HouseholdID, http.StatusConflict, "application/json", ""},
+		{"unknown household", "/api/admin-qr-codes?household=missing", http.StatusNotFound, "application/json", ""},
+		{"unknown guest", "/api/admin-qr-codes?guest=missing", http.StatusNotFound, "application/json", ""},
+		{"bad format", "/api/admin-qr-codes?format=gif", http.StatusBadRequest, "application/json", ""},
+		{"too small", "/api/admin-qr-codes?size=10", http.StatusBadRequest, "application/json", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(AdminQRCodes, http.MethodGet, tt.target, nil, token)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if got := w.Header().Get("Content-Disposition"); got != tt.wantDisposition {
+				t.Errorf("Content-Disposition = %q, want %q", got, tt.wantDisposition)
+			}
+		})
+	}
+
+	if w := serve(AdminQRCodes, http.MethodGet, "/api/admin-qr-codes", nil, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("status without a token = %d, want 401", w.Code)
+	}
+
+	// ADMIN_TOKEN_SECRET is set, but passes are never signed with it
+	t.Setenv("CHECKIN_PASS_SECRET", "")
+	if w := serve(AdminQRCodes, http.MethodGet, "/api/admin-qr-codes?guest="+ana.ID, nil, token); w.Code != http.StatusInternalServerError {
+		t.Errorf("pass status without CHECKIN_PASS_SECRET = %d, want 500", w.Code)
+	}
+	if w := serve(AdminQRCodes, http.MethodGet, "/api/admin-qr-codes?household="+ana.HouseholdID, nil, token); w.Code != http.StatusOK {
+		t.Errorf("invite status without CHECKIN_PASS_SECRET = %d, want 200", w.Code)
+	}
+}
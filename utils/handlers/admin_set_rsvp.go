@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"utils/shared"
+)
+
+type SetRSVPRequest struct {
+	GuestName string `json:"guestName"`
+	Status    string `json:"status"` // "attending", "not_attending", "no_response"
+}
+
+type SetRSVPResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// AdminSetRSVP handles admin overrides of a single guest's RSVP status
+var AdminSetRSVP = shared.RequireAdmin(shared.RoleEditor, adminSetRSVP)
+
+func adminSetRSVP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SetRSVPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonErr(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	req.GuestName = strings.TrimSpace(req.GuestName)
+	if req.GuestName == "" {
+		jsonErr(w, http.StatusBadRequest, "guestName is required")
+		return
+	}
+	if req.Status != "attending" && req.Status != "not_attending" && req.Status != "no_response" {
+		jsonErr(w, http.StatusBadRequest, "invalid status value")
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		jsonErr(w, http.StatusInternalServerError, "Database not configured")
+		return
+	}
+
+	if req.Status == "no_response" {
+		if err := store.ClearOverride(req.GuestName); err != nil {
+			log.Printf("Error deleting override for %s: %v", req.GuestName, err)
+			jsonErr(w, http.StatusInternalServerError, "Failed to update RSVP status")
+			return
+		}
+		log.Printf("Admin reset RSVP to no_response for: %s", req.GuestName)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SetRSVPResponse{true,
+			fmt.Sprintf("%s has been reset to no response", req.GuestName)})
+		return
+	}
+
+	if err := store.SetOverride(req.GuestName, req.Status == "attending"); err != nil {
+		log.Printf("Error setting override for %s: %v", req.GuestName, err)
+		jsonErr(w, http.StatusInternalServerError, "Failed to update RSVP status")
+		return
+	}
+
+	statusMsg := "not attending"
+	if req.Status == "attending" {
+		statusMsg = "attending"
+	}
+	log.Printf("Admin set RSVP for %s → %s", req.GuestName, req.Status)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SetRSVPResponse{true,
+		fmt.Sprintf("%s has been marked as %s", req.GuestName, statusMsg)})
+}
+
+func jsonErr(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(SetRSVPResponse{false, message})
+}
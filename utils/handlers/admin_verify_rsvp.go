@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"utils/shared"
+)
+
+// AdminVerifyRequest is the payload for verifying or rejecting an
+// unverified RSVP from the admin panel. Late requests (RSVPs sent after the
+// deadline) go through the same actions: "verify" approves them, "reject"
+// with Late set declines them and emails the guest.
+//
+// On "verify", the admin may supply corrected canonical names (matched
+// against the guests table in the UI) so that the dashboard's
+// name-based correlation finds these guests from now on.
+type AdminVerifyRequest struct {
+	Action          string   `json:"action"` // "verify" | "reject"
+	Email           string   `json:"email"`
+	Name            string   `json:"name,omitempty"`            // corrected submitter name (optional)
+	AttendingGuests []string `json:"attendingGuests,omitempty"` // corrected attending guest names (optional)
+	SkipEmail       bool     `json:"skipEmail,omitempty"`       // true when fixing names on an already-verified RSVP
+	Late            bool     `json:"late,omitempty"`            // "reject" only: decline the pending late request rather than remove every RSVP for the email
+}
+
+type AdminVerifyResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func avrJSON(w http.ResponseWriter, code int, success bool, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(AdminVerifyResponse{success, message})
+}
+
+// AdminVerifyRSVP handles verifying or rejecting an unverified RSVP from the admin panel
+var AdminVerifyRSVP = shared.RequireAdmin(shared.RoleEditor, adminVerifyRSVP)
+
+func adminVerifyRSVP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AdminVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		avrJSON(w, http.StatusBadRequest, false, "Invalid request format")
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		avrJSON(w, http.StatusBadRequest, false, "email is required")
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		avrJSON(w, http.StatusInternalServerError, false, "Database not configured")
+		return
+	}
+
+	switch req.Action {
+	case "reject":
+		if req.Late {
+			pending, err := findPendingLateRSVP(store, req.Email)
+			if err != nil {
+				log.Printf("Error looking up RSVP %s: %v", req.Email, err)
+				avrJSON(w, http.StatusInternalServerError, false, "Failed to decline RSVP")
+				return
+			}
+			if pending == nil {
+				avrJSON(w, http.StatusNotFound, false, "No pending late RSVP request for that email - it may already have been approved or declined")
+				return
+			}
+			notified, err := declineLateRSVP(store, pending)
+			if err != nil {
+				log.Printf("Error declining late RSVP %s: %v", req.Email, err)
+				avrJSON(w, http.StatusInternalServerError, false, "Failed to decline RSVP")
+				return
+			}
+			if !notified {
+				avrJSON(w, http.StatusOK, true, "Late RSVP declined (email to guest failed to send)")
+				return
+			}
+			avrJSON(w, http.StatusOK, true, fmt.Sprintf("Late RSVP declined — %s has been notified", req.Email))
+			return
+		}
+
+		if err := store.DeleteRSVPs(req.Email); err != nil {
+			log.Printf("Error rejecting RSVP %s: %v", req.Email, err)
+			avrJSON(w, http.StatusInternalServerError, false, "Failed to reject RSVP")
+			return
+		}
+		log.Printf("Admin rejected unverified RSVP: %s", req.Email)
+		avrJSON(w, http.StatusOK, true, "RSVP rejected and removed")
+
+	case "verify":
+		before, err := store.LatestRSVP(req.Email)
+		if err != nil {
+			log.Printf("Error loading RSVP %s: %v", req.Email, err)
+			avrJSON(w, http.StatusInternalServerError, false, "Failed to verify RSVP")
+			return
+		}
+		rows, err := store.VerifyRSVPs(req.Email, shared.RSVPUpdate{Name: req.Name, AttendingGuests: req.AttendingGuests})
+		if err != nil {
+			log.Printf("Error verifying RSVP %s: %v", req.Email, err)
+			avrJSON(w, http.StatusInternalServerError, false, "Failed to verify RSVP")
+			return
+		}
+		if len(rows) == 0 || before == nil {
+			avrJSON(w, http.StatusNotFound, false, "No RSVP found for that email")
+			return
+		}
+		log.Printf("Admin verified RSVP for %s (guests: %v)", req.Email, rows[0].AttendingGuests)
+
+		// The confirmation is built from the row as stored, falling back to
+		// the one VerifyRSVPs returned if it can't be read back
+		confirmed := rows[0]
+		if after, err := store.LatestRSVP(req.Email); err != nil {
+			log.Printf("Error loading verified RSVP %s: %v", req.Email, err)
+		} else if after != nil {
+			if err := shared.SyncVerifiedDietary(store, *before, *after); err != nil {
+				log.Printf("Failed to save guest dietary requirements: %v", err)
+			}
+			confirmed = *after
+		}
+
+		// Already-verified RSVPs (name fixes) got their confirmation earlier
+		if req.SkipEmail {
+			avrJSON(w, http.StatusOK, true, "RSVP names updated")
+			return
+		}
+
+		// Best-effort confirmation email to the now-verified guest
+		if err := shared.SendConfirmationEmail(shared.ConfirmationRSVP(confirmed)); err != nil {
+			log.Printf("Failed to send confirmation email after admin verification: %v", err)
+			avrJSON(w, http.StatusOK, true, "RSVP verified (confirmation email failed to send)")
+			return
+		}
+		avrJSON(w, http.StatusOK, true, fmt.Sprintf("RSVP verified — confirmation sent to %s", confirmed.Email))
+
+	default:
+		avrJSON(w, http.StatusBadRequest, false, "action must be \"verify\" or \"reject\"")
+	}
+}
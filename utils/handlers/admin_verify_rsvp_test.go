@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"utils/shared"
+)
+
+func TestAdminVerifyRSVP(t *testing.T) {
+	store := testStore(t)
+	tests := []struct {
+		name         string
+		saved        shared.RSVPRequest
+		req          AdminVerifyRequest
+		wantStatus   int
+		wantKept     bool
+		wantVerified bool
+	}{
+		{
+			"verify with corrected names",
+			shared.RSVPRequest{Name: "Avr Ana", Email: "avr-verify@example.com", IsAttending: true, AttendingGuests: []string{"Ana"}},
+			AdminVerifyRequest{Action: "verify", Email: "avr-verify@example.com", Name: "Avr Ana", AttendingGuests: []string{"Avr Ana"}},
+			http.StatusOK, true, true,
+		},
+		{
+			"reject",
+			shared.RSVPRequest{Name: "Avr Bob", Email: "avr-reject@example.com"},
+			AdminVerifyRequest{Action: "reject", Email: "avr-reject@example.com"},
+			http.StatusOK, false, false,
+		},
+		{
+			"approve late request",
+			shared.RSVPRequest{Name: "Avr Cat", Email: "avr-late-approve@example.com", IsAttending: true, AttendingGuests: []string{"Avr Cat"}, LateRequest: true},
+			AdminVerifyRequest{Action: "verify", Email: "avr-late-approve@example.com"},
+			http.StatusOK, true, true,
+		},
+		{
+			"decline late request",
+			shared.RSVPRequest{Name: "Avr Dan", Email: "avr-late-decline@example.com", IsAttending: true, AttendingGuests: []string{"Avr Dan"}, LateRequest: true},
+			AdminVerifyRequest{Action: "reject", Email: "avr-late-decline@example.com", Late: true},
+			http.StatusOK, false, false,
+		},
+		{
+			"decline late request already handled",
+			shared.RSVPRequest{Name: "Avr Fay", Email: "avr-late-handled@example.com", Verified: true},
+			AdminVerifyRequest{Action: "reject", Email: "avr-late-handled@example.com", Late: true},
+			http.StatusNotFound, true, true,
+		},
+		{
+			"verify unknown email",
+			shared.RSVPRequest{},
+			AdminVerifyRequest{Action: "verify", Email: "avr-nobody@example.com"},
+			http.StatusNotFound, false, false,
+		},
+		{
+			"unknown action",
+			shared.RSVPRequest{Name: "Avr Eve", Email: "avr-action@example.com"},
+			AdminVerifyRequest{Action: "approve", Email: "avr-action@example.com"},
+			http.StatusBadRequest, true, false,
+		},
+		{
+			"no email",
+			shared.RSVPRequest{},
+			AdminVerifyRequest{Action: "verify", Email: " "},
+			http.StatusBadRequest, false, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.saved.Email != "" {
+				if err := store.SaveRSVP(tt.saved); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := serve(AdminVerifyRSVP, http.MethodPost, "/api/admin-verify-rsvp", tt.req, adminToken(t, shared.RoleEditor))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			rsvps := rsvpsFor(t, tt.req.Email)
+			if got := len(rsvps) > 0; got != tt.wantKept {
+				t.Fatalf("RSVP kept = %v, want %v", got, tt.wantKept)
+			}
+			if tt.wantKept && rsvps[0].Verified != tt.wantVerified {
+				t.Errorf("verified = %v, want %v", rsvps[0].Verified, tt.wantVerified)
+			}
+			if tt.wantVerified && tt.req.Name != "" && (rsvps[0].Name != tt.req.Name || rsvps[0].AttendingGuests[0] != tt.req.AttendingGuests[0]) {
+				t.Errorf("names not corrected: %+v", rsvps[0])
+			}
+		})
+	}
+}
+
+func TestAdminVerifyRSVPLateDecline(t *testing.T) {
+	addGuests(t, shared.Guest{Name: "Avrlate Ana"})
+	store := testStore(t)
+	if err := store.SaveRSVP(shared.RSVPRequest{Name: "Avrlate Ana", Email: "avrlate@example.com", Verified: true}); err != nil {
+		t.Fatal(err)
+	}
+	onTime := rsvpsFor(t, "avrlate@example.com")[0]
+	time.Sleep(time.Millisecond)
+	if err := store.SaveRSVP(shared.RSVPRequest{Name: "Avrlate Ana", Email: "avrlate@example.com", IsAttending: true,
+		AttendingGuests: []string{"Avrlate Ana"}, LateRequest: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := serve(AdminVerifyRSVP, http.MethodPost, "/api/admin-verify-rsvp",
+		AdminVerifyRequest{Action: "reject", Email: "avrlate@example.com", Late: true}, adminToken(t, shared.RoleEditor))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	rsvps := rsvpsFor(t, "avrlate@example.com")
+	if len(rsvps) != 1 || rsvps[0].ID != onTime.ID || rsvps[0].IsAttending || !rsvps[0].Verified {
+		t.Errorf("RSVPs after declining = %+v, want only the one sent before the deadline", rsvps)
+	}
+}
+
+func TestAdminVerifyRSVPDiets(t *testing.T) {
+	addGuests(t, shared.Guest{Name: "Averdiet Ana"})
+	store := testStore(t)
+	store.SaveRSVP(shared.RSVPRequest{Name: "Ana", Email: "averdiet@example.com", IsAttending: true, AttendingGuests: []string{"Ana A"},
+		GuestDiets: []shared.GuestDiet{{GuestName: "Ana A", Diet: "vegan"}}})
+
+	req := AdminVerifyRequest{Action: "verify", Email: "averdiet@example.com", AttendingGuests: []string{"Averdiet Ana"}}
+	if w := serve(AdminVerifyRSVP, http.MethodPost, "/api/admin-verify-rsvp", req, adminToken(t, shared.RoleEditor)); w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	// The diet follows the corrected name onto the invite list
+	want := []shared.GuestDiet{{GuestName: "Averdiet Ana", Diet: "vegan"}}
+	if rsvps := rsvpsFor(t, "averdiet@example.com"); !sameDiets(rsvps[0].GuestDiets, want) {
+		t.Errorf("RSVP diets = %+v, want %+v", rsvps[0].GuestDiets, want)
+	}
+	if got := findGuest(t, "Averdiet Ana").Dietary; got != "vegan" {
+		t.Errorf("guest dietary = %q, want vegan", got)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"utils/shared"
+)
+
+// ConfigResponse tells the frontend which phase the site is in
+type ConfigResponse struct {
+	Success      bool   `json:"success"`
+	Message      string `json:"message,omitempty"`
+	RSVPPhase    string `json:"rsvpPhase,omitempty"`
+	RSVPOpensAt  string `json:"rsvpOpensAt,omitempty"`
+	RSVPClosesAt string `json:"rsvpClosesAt,omitempty"`
+	ServerTime   string `json:"serverTime,omitempty"`
+}
+
+// Config handles requests for the public site configuration
+func Config(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	schedule, err := shared.LoadRSVPSchedule()
+	if err != nil {
+		log.Printf("RSVP schedule misconfigured: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ConfigResponse{
+			Success: false,
+			Message: "Server configuration error",
+		})
+		return
+	}
+
+	resp := ConfigResponse{
+		Success:    true,
+		RSVPPhase:  schedule.Phase(),
+		ServerTime: time.Now().UTC().Format(time.RFC3339),
+	}
+	if !schedule.OpensAt.IsZero() {
+		resp.RSVPOpensAt = schedule.OpensAt.Format(time.RFC3339)
+	}
+	if !schedule.ClosesAt.IsZero() {
+		resp.RSVPClosesAt = schedule.ClosesAt.Format(time.RFC3339)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"utils/shared"
+)
+
+// EmailWebhook receives Resend's webhook events, signed with
+// RESEND_WEBHOOK_SECRET, and marks outbox emails that bounced. Other
+// events are acknowledged and ignored.
+func EmailWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	secret := os.Getenv("RESEND_WEBHOOK_SECRET")
+	if secret == "" {
+		log.Printf("RESEND_WEBHOOK_SECRET not configured")
+		http.Error(w, "Server configuration error", http.StatusInternalServerError)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := shared.VerifyResendWebhook(secret, r.Header, body, time.Now()); err != nil {
+		log.Printf("⚠️  Rejected email webhook: %v", err)
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var event shared.ResendWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if event.Type != "email.bounced" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		http.Error(w, "Database not configured", http.StatusInternalServerError)
+		return
+	}
+	reason := event.Data.Bounce.Message
+	if reason == "" {
+		reason = "Bounced"
+	}
+	found, err := shared.MarkEmailBounced(store, event.Data.EmailID, reason)
+	if err != nil {
+		// Resend retries webhooks that fail, so this is worth a 500
+		log.Printf("Error marking email %s bounced: %v", event.Data.EmailID, err)
+		http.Error(w, "Failed to record bounce", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		log.Printf("⚠️  Bounce reported for email %s, which isn't in the outbox", event.Data.EmailID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
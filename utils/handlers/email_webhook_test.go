@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"utils/shared"
+)
+
+func TestEmailWebhook(t *testing.T) {
+	key := []byte("webhook-test-key")
+	t.Setenv("RESEND_WEBHOOK_SECRET", "whsec_"+base64.StdEncoding.EncodeToString(key))
+	email, err := testStore(t).EnqueueEmail(shared.OutboxEmail{
+		Kind: "rsvp-confirmation", To: "webhook-ana@example.com", Status: shared.EmailSent, ProviderID: "re_webhook_1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(body string, signKey []byte) *httptest.ResponseRecorder {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, signKey)
+		mac.Write([]byte("msg_1." + timestamp + "." + body))
+		r := httptest.NewRequest(http.MethodPost, "/api/email-webhook", bytes.NewBufferString(body))
+		r.Header.Set("svix-id", "msg_1")
+		r.Header.Set("svix-timestamp", timestamp)
+		r.Header.Set("svix-signature", "v1,"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		w := httptest.NewRecorder()
+		EmailWebhook(w, r)
+		return w
+	}
+
+	bounce := `{"type":"email.bounced","data":{"email_id":"re_webhook_1","bounce":{"message":"Mailbox does not exist"}}}`
+	if w := post(bounce, []byte("other-key")); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrongly signed: status = %d, want 401", w.Code)
+	}
+	if stored, _ := testStore(t).GetOutboxEmail(email.ID); stored.Status != shared.EmailSent {
+		t.Fatalf("a wrongly signed bounce marked the email %s", stored.Status)
+	}
+	if w := post(`{"type":"email.delivered","data":{"email_id":"re_webhook_1"}}`, key); w.Code != http.StatusNoContent {
+		t.Errorf("other event: status = %d, want 204", w.Code)
+	}
+	if w := post(bounce, key); w.Code != http.StatusNoContent {
+		t.Fatalf("bounce: status = %d, want 204: %s", w.Code, w.Body)
+	}
+	if stored, _ := testStore(t).GetOutboxEmail(email.ID); stored.Status != shared.EmailBounced || stored.LastError != "Mailbox does not exist" {
+		t.Errorf("bounced email = %+v", stored)
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"utils/shared"
+)
+
+// emailWorkerBatch is how many queued emails one run of the worker sends
+// by default, to finish well inside a serverless function's time limit
+const emailWorkerBatch = 25
+
+// EmailWorkerResponse is returned by /api/email-worker
+type EmailWorkerResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	shared.EmailOutboxRun
+}
+
+// EmailWorker sends queued emails that are due another attempt (see
+// shared.ProcessEmailOutbox). It is meant to be run on a schedule, such as
+// a Vercel cron job, and needs "Authorization: Bearer $CRON_SECRET".
+// ?limit sets how many emails to send at most (default 25).
+func EmailWorker(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	secret := os.Getenv("CRON_SECRET")
+	if secret == "" {
+		log.Printf("CRON_SECRET not configured")
+		emailWorkerErr(w, http.StatusInternalServerError, "Server configuration error")
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		emailWorkerErr(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	limit := emailWorkerBatch
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 100 {
+			emailWorkerErr(w, http.StatusBadRequest, "limit must be a number from 1 to 100")
+			return
+		}
+		limit = n
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		emailWorkerErr(w, http.StatusInternalServerError, "Database not configured")
+		return
+	}
+	run, err := shared.ProcessEmailOutbox(store, limit)
+	if err != nil {
+		log.Printf("Email worker failed: %v", err)
+		emailWorkerErr(w, http.StatusInternalServerError, "Failed to process the email outbox")
+		return
+	}
+	if run != (shared.EmailOutboxRun{}) {
+		log.Printf("✓ Email worker: %d sent, %d to retry, %d failed", run.Sent, run.Retrying, run.Failed)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(EmailWorkerResponse{Success: true, EmailOutboxRun: run})
+}
+
+func emailWorkerErr(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(EmailWorkerResponse{Success: false, Message: message})
+}
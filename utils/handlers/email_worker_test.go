@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"utils/shared"
+)
+
+func TestEmailWorker(t *testing.T) {
+	t.Setenv("CRON_SECRET", "cron-test-secret")
+	queued, err := testStore(t).EnqueueEmail(shared.OutboxEmail{
+		Kind: "meal-reminder", To: "worker-ana@example.com", Subject: "Meals", Text: "Choose",
+		Status: shared.EmailQueued, NextAttemptAt: time.Now().UTC().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		target string
+		token  string
+		want   int
+	}{
+		{"no token", "/api/email-worker", "", http.StatusUnauthorized},
+		{"wrong token", "/api/email-worker", "wrong", http.StatusUnauthorized},
+		{"bad limit", "/api/email-worker?limit=500", "cron-test-secret", http.StatusBadRequest},
+	} {
+		if w := serve(EmailWorker, http.MethodGet, tt.target, nil, tt.token); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+
+	w := serve(EmailWorker, http.MethodGet, "/api/email-worker", nil, "cron-test-secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var resp EmailWorkerResponse
+	decode(t, w, &resp)
+	if !resp.Success || resp.Sent < 1 {
+		t.Errorf("response = %+v, want the queued email sent", resp)
+	}
+	if email, _ := testStore(t).GetOutboxEmail(queued.ID); email.Status != shared.EmailSent {
+		t.Errorf("email is %s, want %s", email.Status, shared.EmailSent)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"utils/shared"
+)
+
+// GetAvatars handles getting avatar selections from all RSVPs
+func GetAvatars(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get store - without one, return an empty plaza rather than an error
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK) // Return empty array rather than error
+		json.NewEncoder(w).Encode(shared.GetAvatarsResponse{
+			Success: true,
+			Avatars: []shared.GuestAvatar{},
+		})
+		return
+	}
+
+	// Fetch avatar selections - only verified and attending guests
+	selections, err := store.ListAvatars()
+	if err != nil {
+		log.Printf("Error fetching avatars: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK) // Return empty array rather than error
+		json.NewEncoder(w).Encode(shared.GetAvatarsResponse{
+			Success: true,
+			Avatars: []shared.GuestAvatar{},
+		})
+		return
+	}
+
+	// Collect all avatars, deduplicating by guest name
+	seenGuests := make(map[string]bool)
+	avatars := []shared.GuestAvatar{}
+	for _, avatarSelection := range selections {
+		// Skip if we've already seen this guest (deduplicate by normalised full name)
+		key := shared.NormalizeString(avatarSelection.GuestName)
+		if seenGuests[key] {
+			continue
+		}
+		seenGuests[key] = true
+
+		// Extract first name (text before first space)
+		firstName := avatarSelection.GuestName
+		for i, char := range avatarSelection.GuestName {
+			if char == ' ' {
+				firstName = avatarSelection.GuestName[:i]
+				break
+			}
+		}
+
+		avatars = append(avatars, shared.GuestAvatar{
+			Name:    firstName,
+			Avatar:  avatarSelection.Avatar,
+			Message: avatarSelection.Message,
+		})
+	}
+
+	log.Printf("Returning %d unique avatars from RSVPs", len(avatars))
+
+	// Cache the response: 60s browser cache, 120s CDN cache, stale-while-revalidate 60s
+	w.Header().Set("Cache-Control", "public, max-age=60, s-maxage=120, stale-while-revalidate=60")
+
+	// Send success response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shared.GetAvatarsResponse{
+		Success: true,
+		Avatars: avatars,
+	})
+}
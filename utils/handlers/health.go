@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"utils/shared"
+)
+
+// Health handles health check requests
+func Health(w http.ResponseWriter, r *http.Request) {
+	// Load guest count for health check
+	guestCount := 0
+	if store, err := shared.NewStore(); err == nil {
+		if guests, err := shared.LoadGuests(store); err == nil {
+			guestCount = len(guests)
+		}
+	}
+
+	// Report the RSVP phase so a misconfigured schedule shows up here too
+	rsvpPhase := "misconfigured"
+	if schedule, err := shared.LoadRSVPSchedule(); err == nil {
+		rsvpPhase = schedule.Phase()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "healthy",
+		"timestamp": time.Now().Unix(),
+		"guests":    guestCount,
+		"rsvpPhase": rsvpPhase,
+	})
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"log"
+
+	"utils/shared"
+)
+
+// findPendingLateRSVP returns the late RSVP request for email that is still
+// waiting for admin approval, or nil
+func findPendingLateRSVP(store shared.Store, email string) (*shared.RSVPRecord, error) {
+	rsvps, err := store.ListRSVPs()
+	if err != nil {
+		return nil, err
+	}
+	for _, rsvp := range rsvps {
+		if rsvp.Email == email && rsvp.Late && !rsvp.Verified {
+			found := rsvp
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// declineLateRSVP removes a pending late request and emails the guest. Only
+// the late row is removed: an RSVP the guest sent before the deadline
+// stands. The returned bool reports whether the email was sent.
+func declineLateRSVP(store shared.Store, rsvp *shared.RSVPRecord) (bool, error) {
+	if err := store.DeleteRSVP(rsvp.ID); err != nil {
+		return false, err
+	}
+	log.Printf("Declined late RSVP request: %s (%s)", rsvp.Name, rsvp.Email)
+
+	if err := shared.SendLateRSVPDeclinedEmail(shared.RSVPRequest{Name: rsvp.Name, Email: rsvp.Email}); err != nil {
+		log.Printf("Failed to send late RSVP declined email to %s: %v", rsvp.Email, err)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+
+	"utils/shared"
+)
+
+// testTokenSecret signs the admin tokens used in handler tests
+const testTokenSecret = "handler-test-secret"
+
+// TestMain runs every handler test against the process-wide memory store,
+// so tests give their guests and emails names no other test uses. Every
+// test request comes from the same address, so the per-IP lookup limit is
+// off and misses never back off.
+func TestMain(m *testing.M) {
+	os.Setenv("STORE_BACKEND", "memory")
+	os.Setenv("ADMIN_TOKEN_SECRET", testTokenSecret)
+	os.Setenv("RSVP_CLOSES_AT", "none")
+	os.Setenv("VERIFY_NAME_IP_LIMIT", "0")
+	os.Setenv("VERIFY_NAME_FREE_MISSES", "1000")
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// testStore returns the store the handlers use
+func testStore(t *testing.T) shared.Store {
+	t.Helper()
+	store, err := shared.NewStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// addGuests puts guests on the invite list, grouping them into households
+// as an import would
+func addGuests(t *testing.T, guests ...shared.Guest) {
+	t.Helper()
+	store := testStore(t)
+	if err := shared.ResolveHouseholds(store, guests); err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range guests {
+		if err := store.AddGuest(g); err != nil {
+			t.Fatalf("AddGuest(%s) error = %v", g.Name, err)
+		}
+	}
+}
+
+// rsvpsFor returns the stored RSVPs for email
+func rsvpsFor(t *testing.T, email string) []shared.RSVPRecord {
+	t.Helper()
+	all, err := testStore(t).ListRSVPs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rsvps []shared.RSVPRecord
+	for _, rsvp := range all {
+		if rsvp.Email == email {
+			rsvps = append(rsvps, rsvp)
+		}
+	}
+	return rsvps
+}
+
+// findGuest returns the invite list entry for name
+func findGuest(t *testing.T, name string) shared.Guest {
+	t.Helper()
+	guest, err := testStore(t).FindGuestByName(name)
+	if err != nil || guest == nil {
+		t.Fatalf("FindGuestByName(%s) = %+v, %v", name, guest, err)
+	}
+	return *guest
+}
+
+// sameDiets reports whether a and b hold the same per-guest diets
+func sameDiets(a, b []shared.GuestDiet) bool {
+	return slices.EqualFunc(a, b, func(x, y shared.GuestDiet) bool {
+		return x.GuestName == y.GuestName && x.Diet == y.Diet && slices.Equal(x.Categories, y.Categories)
+	})
+}
+
+// adminToken returns a bearer token for an admin with role
+func adminToken(t *testing.T, role shared.AdminRole) string {
+	t.Helper()
+	auth, err := shared.NewAdminAuth()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth.IssueToken("test-"+string(role), role)
+}
+
+// serve calls handler with method, target and body (JSON-encoded unless
+// it is already a string), as the admin holding token if it isn't empty
+func serve(handler http.HandlerFunc, method, target string, body interface{}, token string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		data, _ := json.Marshal(b)
+		reader = bytes.NewBuffer(data)
+	}
+	r := httptest.NewRequest(method, target, reader)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+// decode parses a JSON response body into out
+func decode(t *testing.T, w *httptest.ResponseRecorder, out interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+		t.Fatalf("response %q isn't JSON: %v", w.Body.String(), err)
+	}
+}
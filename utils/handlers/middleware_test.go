@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithCORS(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		wantOrigin string
+		wantCalled bool
+	}{
+		{"preflight", http.MethodOptions, "http://localhost:5173", "http://localhost:5173", false},
+		{"request", http.MethodPost, "http://localhost:5173", "http://localhost:5173", true},
+		{"no origin", http.MethodGet, "", "*", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := WithCORS(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			r := httptest.NewRequest(tt.method, "/api/health", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if called != tt.wantCalled {
+				t.Errorf("handler called = %v, want %v", called, tt.wantCalled)
+			}
+			if !tt.wantCalled && w.Code != http.StatusOK {
+				t.Errorf("preflight status = %d, want 200", w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+		})
+	}
+}
+
+func TestWithRecover(t *testing.T) {
+	handler := WithRecover(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
+
+func TestWithLogging(t *testing.T) {
+	var recorded *statusWriter
+	handler := WithLogging(func(w http.ResponseWriter, r *http.Request) {
+		recorded = w.(*statusWriter)
+		http.Error(w, "nope", http.StatusNotFound)
+	})
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/api/missing", nil))
+	if w.Code != http.StatusNotFound || recorded.status != http.StatusNotFound {
+		t.Errorf("status = %d, recorded %d, want 404", w.Code, recorded.status)
+	}
+}
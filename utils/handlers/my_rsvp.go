@@ -0,0 +1,442 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"utils/shared"
+)
+
+// GuestRSVP is a guest's own view of their RSVP
+type GuestRSVP struct {
+	Name            string                   `json:"name"`
+	Email           string                   `json:"email"`
+	IsAttending     bool                     `json:"isAttending"`
+	AttendingGuests []string                 `json:"attendingGuests"`
+	PlusOnes        []string                 `json:"plusOnes"`
+	GuestDiets      []shared.GuestDiet       `json:"guestDiets"`
+	GuestMeals      []shared.GuestMeal       `json:"guestMeals"`
+	GuestEvents     []shared.EventAttendance `json:"guestEvents"` // events each person coming attends
+	Diet            string                   `json:"diet"`
+	Avatars         []shared.AvatarSelection `json:"avatars"`
+	Verified        bool                     `json:"verified"`
+	Late            bool                     `json:"late"`
+	FamilyMembers   []shared.FamilyMember    `json:"familyMembers"` // who may be marked as attending
+	PlusOneSlots    int                      `json:"plusOneSlots"`  // plus-ones the family members may bring between them
+	Editable        bool                     `json:"editable"`      // false once RSVPs have closed
+	Menu            []shared.MenuOption      `json:"menu"`          // dishes to choose meals from
+	Events          []shared.Event           `json:"events"`        // every event, for naming those in FamilyMembers
+}
+
+// MyRSVPResponse is returned by both GET and POST on /api/my-rsvp
+type MyRSVPResponse struct {
+	Success bool       `json:"success"`
+	Message string     `json:"message,omitempty"`
+	Code    string     `json:"code,omitempty"`
+	RSVP    *GuestRSVP `json:"rsvp,omitempty"`
+}
+
+// MyRSVPUpdateRequest holds a guest's changes. Omitted fields are left as
+// they are.
+type MyRSVPUpdateRequest struct {
+	IsAttending     *bool                    `json:"isAttending,omitempty"`
+	AttendingGuests []string                 `json:"attendingGuests,omitempty"`
+	PlusOnes        []string                 `json:"plusOnes,omitempty"`
+	GuestDiets      []shared.GuestDiet       `json:"guestDiets,omitempty"`
+	GuestMeals      []shared.GuestMeal       `json:"guestMeals,omitempty"`
+	GuestEvents     []shared.EventAttendance `json:"guestEvents,omitempty"`
+	Diet            *string                  `json:"diet,omitempty"`
+	Avatars         []shared.AvatarSelection `json:"avatars,omitempty"`
+}
+
+func myRSVPJSON(w http.ResponseWriter, code int, resp MyRSVPResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// MyRSVP lets a guest view (GET) and amend (POST) their RSVP using the
+// signed token from their confirmation email (?token=...)
+func MyRSVP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	signer, err := shared.NewRSVPLinkSigner()
+	if err != nil {
+		log.Printf("RSVP links not configured: %v", err)
+		myRSVPJSON(w, http.StatusInternalServerError, MyRSVPResponse{Message: "Server configuration error"})
+		return
+	}
+	email, ok := signer.Verify(r.URL.Query().Get("token"))
+	if !ok {
+		myRSVPJSON(w, http.StatusUnauthorized, MyRSVPResponse{
+			Code:    "invalid_link",
+			Message: "This link is invalid or has expired - please contact the couple",
+		})
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		myRSVPJSON(w, http.StatusInternalServerError, MyRSVPResponse{Message: "Server error - please try again"})
+		return
+	}
+
+	rsvp, err := store.LatestRSVP(email)
+	if err != nil {
+		log.Printf("Error loading RSVP for %s: %v", email, err)
+		myRSVPJSON(w, http.StatusInternalServerError, MyRSVPResponse{Message: "Server error - please try again"})
+		return
+	}
+	if rsvp == nil {
+		myRSVPJSON(w, http.StatusNotFound, MyRSVPResponse{Message: "We couldn't find your RSVP - please contact the couple"})
+		return
+	}
+
+	guestList, err := shared.LoadGuests(store)
+	if err != nil {
+		log.Printf("Error loading guests: %v", err)
+		myRSVPJSON(w, http.StatusInternalServerError, MyRSVPResponse{Message: "Server error - please try again"})
+		return
+	}
+	events, err := store.ListEvents()
+	if err != nil {
+		log.Printf("Error loading events: %v", err)
+		myRSVPJSON(w, http.StatusInternalServerError, MyRSVPResponse{Message: "Server error - please try again"})
+		return
+	}
+	family := rsvpFamilyMembers(*rsvp, guestList, events)
+	familyNames := make([]string, len(family))
+	for i, member := range family {
+		familyNames[i] = member.Name
+	}
+	plusOneSlots := shared.PlusOneAllowance(familyNames, guestList)
+
+	menu, err := store.ListMenuOptions()
+	if err != nil {
+		log.Printf("Error loading menu: %v", err)
+		myRSVPJSON(w, http.StatusInternalServerError, MyRSVPResponse{Message: "Server error - please try again"})
+		return
+	}
+
+	editable := true
+	if schedule, err := shared.LoadRSVPSchedule(); err != nil || schedule.Phase() != shared.RSVPPhaseOpen {
+		editable = false
+	}
+
+	if r.Method == http.MethodGet {
+		myRSVPJSON(w, http.StatusOK, MyRSVPResponse{Success: true, RSVP: toGuestRSVP(*rsvp, family, plusOneSlots, editable, menu, events)})
+		return
+	}
+
+	var req MyRSVPUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		myRSVPJSON(w, http.StatusBadRequest, MyRSVPResponse{Message: "Invalid request format"})
+		return
+	}
+
+	if _, ok := checkRSVPWindow(w, false, func(code, message string) interface{} {
+		return MyRSVPResponse{Success: false, Code: code, Message: message}
+	}); !ok {
+		return
+	}
+
+	changes, message := buildRSVPChanges(*rsvp, req, family, guestList, menu, events)
+	if message != "" {
+		myRSVPJSON(w, http.StatusBadRequest, MyRSVPResponse{Message: message})
+		return
+	}
+	if len(changes.GuestEvents) > 0 {
+		if err := checkEventCapacity(store, changes.GuestEvents, guestList, events); err != nil {
+			myRSVPJSON(w, http.StatusBadRequest, MyRSVPResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	updated, err := store.UpdateRSVP(rsvp.ID, changes)
+	if err != nil {
+		log.Printf("Error updating RSVP for %s: %v", email, err)
+		myRSVPJSON(w, http.StatusInternalServerError, MyRSVPResponse{Message: "Failed to save your changes - please try again"})
+		return
+	}
+
+	if !reflect.DeepEqual(toGuestRSVP(*rsvp, nil, 0, false, nil, nil), toGuestRSVP(*updated, nil, 0, false, nil, nil)) {
+		log.Printf("✏️  %s (%s) edited their RSVP", updated.Name, updated.Email)
+		// Not using goroutine to ensure it completes before serverless function terminates
+		shared.SendRSVPChangedNotification(*rsvp, *updated)
+	}
+
+	// Unverified RSVPs reach the guest records once an admin verifies them
+	if updated.Verified && (changes.GuestDiets != nil || changes.AttendingGuests != nil) {
+		if err := shared.SaveGuestDietary(store, updated.AttendingGuests, updated.GuestDiets, guestList); err != nil {
+			log.Printf("Failed to save guest dietary requirements for %s: %v", email, err)
+		}
+	}
+
+	myRSVPJSON(w, http.StatusOK, MyRSVPResponse{
+		Success: true,
+		Message: "Your RSVP has been updated",
+		RSVP:    toGuestRSVP(*updated, family, plusOneSlots, editable, menu, events),
+	})
+}
+
+// rsvpFamilyMembers returns the people an RSVP may list as attending: the
+// submitter's household from the invite list, plus anyone already on the
+// RSVP (so unlisted guests can still edit their own entry)
+func rsvpFamilyMembers(rsvp shared.RSVPRecord, guestList []shared.Guest, events []shared.Event) []shared.FamilyMember {
+	var family []shared.FamilyMember
+	for _, name := range append([]string{rsvp.Name}, rsvp.AttendingGuests...) {
+		if guest := shared.FindGuest(name, guestList); guest != nil {
+			family = shared.HouseholdMembers(*guest, guestList, events)
+			break
+		}
+	}
+
+	seen := make(map[string]bool, len(family))
+	for _, member := range family {
+		seen[shared.NormalizeString(member.Name)] = true
+	}
+	for _, name := range rsvp.AttendingGuests {
+		if key := shared.NormalizeString(name); !seen[key] {
+			seen[key] = true
+			family = append(family, shared.FamilyMember{Name: name, Events: shared.InvitedEvents(nil, events)})
+		}
+	}
+	if family == nil {
+		family = []shared.FamilyMember{{Name: rsvp.Name, Events: shared.InvitedEvents(nil, events)}}
+	}
+	return family
+}
+
+// buildRSVPChanges validates a guest's update against their RSVP, household,
+// plus-one allowance, the menu and the events they are invited to. It
+// returns a guest-facing message when the update is invalid.
+func buildRSVPChanges(rsvp shared.RSVPRecord, req MyRSVPUpdateRequest, family []shared.FamilyMember, guestList []shared.Guest, menu []shared.MenuOption, events []shared.Event) (shared.RSVPChanges, string) {
+	changes := shared.RSVPChanges{IsAttending: req.IsAttending, Diet: req.Diet}
+
+	isAttending := rsvp.IsAttending
+	if req.IsAttending != nil {
+		isAttending = *req.IsAttending
+	}
+	attending := rsvp.AttendingGuests
+
+	switch {
+	case !isAttending:
+		attending = []string{}
+		changes.AttendingGuests = attending
+		changes.PlusOnes = []string{}
+	case req.AttendingGuests != nil:
+		// Canonicalise names to the invite list spelling
+		canonical := make(map[string]string, len(family))
+		for _, member := range family {
+			canonical[shared.NormalizeString(member.Name)] = member.Name
+		}
+		attending = make([]string, 0, len(req.AttendingGuests))
+		for _, name := range req.AttendingGuests {
+			match, ok := canonical[shared.NormalizeString(name)]
+			if !ok {
+				return changes, "You can only RSVP for people in your party: " + strings.TrimSpace(name) + " isn't one of them"
+			}
+			attending = append(attending, match)
+		}
+		changes.AttendingGuests = attending
+	}
+	if isAttending && len(attending) == 0 {
+		return changes, "At least one guest must be specified when attending"
+	}
+
+	if isAttending && (req.PlusOnes != nil || changes.AttendingGuests != nil) {
+		plusOnes := rsvp.PlusOnes
+		if req.PlusOnes != nil {
+			plusOnes = req.PlusOnes
+		}
+		cleaned, ok := shared.CheckPlusOnes(plusOnes, attending, guestList)
+		if !ok {
+			allowance := shared.PlusOneAllowance(attending, guestList)
+			if allowance == 0 {
+				return changes, "Nobody attending was invited with a plus-one - please remove your plus-ones"
+			}
+			return changes, fmt.Sprintf("Your party can only bring %d plus-one(s) - please remove some", allowance)
+		}
+		changes.PlusOnes = cleaned
+	}
+
+	people := append([]string{}, attending...)
+	if changes.PlusOnes != nil {
+		people = append(people, changes.PlusOnes...)
+	} else {
+		people = append(people, rsvp.PlusOnes...)
+	}
+	coming := func(name string) bool {
+		for _, p := range people {
+			if shared.NormalizeString(p) == shared.NormalizeString(name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if req.GuestDiets != nil || changes.AttendingGuests != nil || changes.PlusOnes != nil {
+		if req.GuestDiets != nil {
+			if category := shared.UnknownDietCategory(req.GuestDiets); category != "" {
+				return changes, "Unknown dietary requirement: " + category
+			}
+			cleaned, unknown := shared.CleanGuestDiets(req.GuestDiets, people)
+			if unknown != "" {
+				return changes, "Dietary requirements can only be given for people coming: " + unknown + " isn't one of them"
+			}
+			changes.GuestDiets = cleaned
+		} else {
+			// Drop diets of anyone who is no longer coming
+			kept := []shared.GuestDiet{}
+			for _, d := range rsvp.GuestDiets {
+				if coming(d.GuestName) {
+					kept = append(kept, d)
+				}
+			}
+			changes.GuestDiets = kept
+		}
+	}
+
+	if isAttending && (req.GuestEvents != nil || changes.AttendingGuests != nil || changes.PlusOnes != nil) {
+		plusOnes := rsvp.PlusOnes
+		if changes.PlusOnes != nil {
+			plusOnes = changes.PlusOnes
+		}
+		if req.GuestEvents != nil {
+			resolved, err := shared.ResolveGuestEvents(req.GuestEvents, attending, plusOnes, guestList, events)
+			if err != nil {
+				return changes, err.Error()
+			}
+			changes.GuestEvents = resolved
+		} else {
+			// Keep the events of anyone still coming, unless the party
+			// changed so that they no longer fit (e.g. a plus-one at an
+			// event no guest on the RSVP is coming to)
+			kept := []shared.EventAttendance{}
+			for _, e := range rsvp.GuestEvents {
+				if coming(e.GuestName) {
+					kept = append(kept, e)
+				}
+			}
+			resolved, err := shared.ResolveGuestEvents(kept, attending, plusOnes, guestList, events)
+			if err != nil {
+				resolved, _ = shared.ResolveGuestEvents(nil, attending, plusOnes, guestList, events)
+			}
+			changes.GuestEvents = resolved
+		}
+	} else if !isAttending {
+		changes.GuestEvents = []shared.EventAttendance{}
+	}
+
+	if req.GuestMeals != nil {
+		cleaned, err := shared.CleanGuestMeals(req.GuestMeals, people, menu)
+		if err != nil {
+			return changes, err.Error()
+		}
+		changes.GuestMeals = cleaned
+	} else if changes.AttendingGuests != nil || changes.PlusOnes != nil {
+		// Drop meals of anyone who is no longer coming
+		kept := []shared.GuestMeal{}
+		for _, m := range rsvp.GuestMeals {
+			if coming(m.GuestName) {
+				kept = append(kept, m)
+			}
+		}
+		changes.GuestMeals = kept
+	}
+
+	if req.Avatars != nil {
+		attendingSet := make(map[string]bool, len(attending))
+		for _, name := range attending {
+			attendingSet[shared.NormalizeString(name)] = true
+		}
+		for _, avatar := range req.Avatars {
+			if !attendingSet[shared.NormalizeString(avatar.GuestName)] {
+				return changes, "Avatars can only be chosen for attending guests"
+			}
+			if avatar.Avatar == "" {
+				return changes, "Avatar is required for all guests"
+			}
+		}
+		changes.AvatarData = req.Avatars
+	} else if changes.AttendingGuests != nil {
+		// Drop avatars of guests who are no longer coming
+		kept := []shared.AvatarSelection{}
+		for _, avatar := range rsvp.AvatarData {
+			for _, name := range attending {
+				if shared.NormalizeString(avatar.GuestName) == shared.NormalizeString(name) {
+					kept = append(kept, avatar)
+					break
+				}
+			}
+		}
+		changes.AvatarData = kept
+	}
+
+	if changes.Diet != nil {
+		diet := strings.TrimSpace(*changes.Diet)
+		changes.Diet = &diet
+	}
+	return changes, ""
+}
+
+// toGuestRSVP converts a stored RSVP to the guest-facing shape
+func toGuestRSVP(rsvp shared.RSVPRecord, family []shared.FamilyMember, plusOneSlots int, editable bool, menu []shared.MenuOption, events []shared.Event) *GuestRSVP {
+	attending := rsvp.AttendingGuests
+	if attending == nil {
+		attending = []string{}
+	}
+	plusOnes := rsvp.PlusOnes
+	if plusOnes == nil {
+		plusOnes = []string{}
+	}
+	guestDiets := rsvp.GuestDiets
+	if guestDiets == nil {
+		guestDiets = []shared.GuestDiet{}
+	}
+	guestMeals := rsvp.GuestMeals
+	if guestMeals == nil {
+		guestMeals = []shared.GuestMeal{}
+	}
+	guestEvents := rsvp.GuestEvents
+	if guestEvents == nil {
+		guestEvents = []shared.EventAttendance{}
+	}
+	if menu == nil {
+		menu = []shared.MenuOption{}
+	}
+	if events == nil {
+		events = []shared.Event{}
+	}
+	avatars := rsvp.AvatarData
+	if avatars == nil {
+		avatars = []shared.AvatarSelection{}
+	}
+	return &GuestRSVP{
+		Name:            rsvp.Name,
+		Email:           rsvp.Email,
+		IsAttending:     rsvp.IsAttending,
+		AttendingGuests: attending,
+		PlusOnes:        plusOnes,
+		GuestDiets:      guestDiets,
+		GuestMeals:      guestMeals,
+		GuestEvents:     guestEvents,
+		Diet:            rsvp.Diet,
+		Avatars:         avatars,
+		Verified:        rsvp.Verified,
+		Late:            rsvp.Late,
+		FamilyMembers:   family,
+		PlusOneSlots:    plusOneSlots,
+		Editable:        editable,
+		Menu:            menu,
+		Events:          events,
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"utils/shared"
+)
+
+// myRSVPTarget returns the /api/my-rsvp URL with a signed token for email
+func myRSVPTarget(t *testing.T, email string) string {
+	t.Helper()
+	signer, err := shared.NewRSVPLinkSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "/api/my-rsvp?token=" + url.QueryEscape(signer.Sign(email))
+}
+
+func TestMyRSVP(t *testing.T) {
+	addGuests(t,
+		shared.Guest{Name: "Mine Ana", Address: "1 Mine Street", PlusOnes: 1},
+		shared.Guest{Name: "Mine Bob", Address: "1 Mine Street"},
+	)
+	store := testStore(t)
+	if err := store.SaveRSVP(shared.RSVPRequest{Name: "Mine Ana", Email: "mine@example.com", IsAttending: true, AttendingGuests: []string{"Mine Ana"}, Verified: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("view", func(t *testing.T) {
+		w := serve(MyRSVP, http.MethodGet, myRSVPTarget(t, "mine@example.com"), nil, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body)
+		}
+		var resp MyRSVPResponse
+		decode(t, w, &resp)
+		if resp.RSVP == nil || !resp.RSVP.Editable || len(resp.RSVP.FamilyMembers) != 2 || !slices.Equal(resp.RSVP.AttendingGuests, []string{"Mine Ana"}) {
+			t.Errorf("rsvp = %+v, want Mine Ana's editable RSVP with both household members", resp.RSVP)
+		}
+		if resp.RSVP != nil && resp.RSVP.PlusOneSlots != 1 {
+			t.Errorf("plus-one slots = %d, want 1", resp.RSVP.PlusOneSlots)
+		}
+	})
+
+	tests := []struct {
+		name          string
+		target        string
+		update        interface{}
+		closed        bool
+		wantStatus    int
+		wantAttending []string
+	}{
+		{"bad token", "/api/my-rsvp?token=nope", MyRSVPUpdateRequest{}, false, http.StatusUnauthorized, []string{"Mine Ana"}},
+		{"no RSVP for the email", myRSVPTarget(t, "mine-nobody@example.com"), MyRSVPUpdateRequest{}, false, http.StatusNotFound, []string{"Mine Ana"}},
+		{"someone outside the party", myRSVPTarget(t, "mine@example.com"), MyRSVPUpdateRequest{AttendingGuests: []string{"Mine Ana", "Mine Stranger"}}, false, http.StatusBadRequest, []string{"Mine Ana"}},
+		{"after RSVPs close", myRSVPTarget(t, "mine@example.com"), MyRSVPUpdateRequest{AttendingGuests: []string{"Mine Bob"}}, true, http.StatusForbidden, []string{"Mine Ana"}},
+		{"bad body", myRSVPTarget(t, "mine@example.com"), "{", false, http.StatusBadRequest, []string{"Mine Ana"}},
+		{"too many plus-ones", myRSVPTarget(t, "mine@example.com"), MyRSVPUpdateRequest{PlusOnes: []string{"Sam", "Kit"}}, false, http.StatusBadRequest, []string{"Mine Ana"}},
+		{"add a plus-one", myRSVPTarget(t, "mine@example.com"), MyRSVPUpdateRequest{PlusOnes: []string{"Sam"}}, false, http.StatusOK, []string{"Mine Ana"}},
+		{"plus-one's guest leaves", myRSVPTarget(t, "mine@example.com"), MyRSVPUpdateRequest{AttendingGuests: []string{"Mine Bob"}}, false, http.StatusBadRequest, []string{"Mine Ana"}},
+		{"add a household member", myRSVPTarget(t, "mine@example.com"), MyRSVPUpdateRequest{AttendingGuests: []string{"mine ana", "MINE BOB"}}, false, http.StatusOK, []string{"Mine Ana", "Mine Bob"}},
+		{"decline", myRSVPTarget(t, "mine@example.com"), map[string]bool{"isAttending": false}, false, http.StatusOK, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.closed {
+				t.Setenv("RSVP_CLOSES_AT", "2000-01-01T00:00:00Z")
+			}
+			w := serve(MyRSVP, http.MethodPost, tt.target, tt.update, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			rsvp, err := store.LatestRSVP("mine@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(rsvp.AttendingGuests, tt.wantAttending) || rsvp.IsAttending != (len(tt.wantAttending) > 0) {
+				t.Errorf("stored RSVP = %+v, want attending %v", rsvp, tt.wantAttending)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Package handlers holds the HTTP handlers behind every /api endpoint. The
+// files in api/ wrap them one per Vercel function; cmd/server mounts them
+// all on a single mux.
+package handlers
+
+import "net/http"
+
+// Route pairs an API path with its handler
+type Route struct {
+	Path    string
+	Handler http.HandlerFunc
+}
+
+// Routes lists every API endpoint. Keep in sync with the rewrites in
+// vercel.json.
+var Routes = []Route{
+	{"/api/health", Health},
+	{"/api/config", Config},
+	{"/api/verify-name", VerifyName},
+	{"/api/verify-code", VerifyCode},
+	{"/api/submit-rsvp", SubmitRSVP},
+	{"/api/get-avatars", GetAvatars},
+	{"/api/save-avatars", SaveAvatars},
+	{"/api/my-rsvp", MyRSVP},
+	{"/api/verify-rsvp", VerifyRSVP},
+	{"/api/email-worker", EmailWorker},
+	{"/api/email-webhook", EmailWebhook},
+	{"/api/admin-login", AdminLogin},
+	{"/api/admin-dashboard", AdminDashboard},
+	{"/api/admin-dashboard-export", AdminDashboardExport},
+	{"/api/admin-catering-report", AdminCateringReport},
+	{"/api/admin-menu", AdminMenu},
+	{"/api/admin-events", AdminEvents},
+	{"/api/admin-meal-reminders", AdminMealReminders},
+	{"/api/admin-add-guest", AdminAddGuest},
+	{"/api/admin-export-guests", AdminExportGuests},
+	{"/api/admin-qr-codes", AdminQRCodes},
+	{"/api/admin-set-rsvp", AdminSetRSVP},
+	{"/api/admin-verify-rsvp", AdminVerifyRSVP},
+	{"/api/admin-email-outbox", AdminEmailOutbox},
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRoutesMatchVercel checks that Routes and the Vercel deployment serve
+// the same endpoints
+func TestRoutesMatchVercel(t *testing.T) {
+	root := filepath.Join("..", "..")
+	data, err := os.ReadFile(filepath.Join(root, "vercel.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Rewrites []struct {
+			Source      string `json:"source"`
+			Destination string `json:"destination"`
+		} `json:"rewrites"`
+		Crons []struct {
+			Path string `json:"path"`
+		} `json:"crons"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	rewrites := map[string]string{}
+	for _, r := range config.Rewrites {
+		rewrites[r.Source] = r.Destination
+	}
+
+	routed := map[string]bool{}
+	for _, route := range Routes {
+		if routed[route.Path] {
+			t.Errorf("%s is routed twice", route.Path)
+		}
+		routed[route.Path] = true
+
+		destination, ok := rewrites[route.Path]
+		if !ok {
+			t.Errorf("%s has no rewrite in vercel.json", route.Path)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, destination)); err != nil {
+			t.Errorf("%s rewrites to %s: %v", route.Path, destination, err)
+		}
+	}
+
+	// Rewrites to a function that exists must be routed too
+	for source, destination := range rewrites {
+		if _, err := os.Stat(filepath.Join(root, destination)); err == nil && !routed[source] {
+			t.Errorf("%s is served on Vercel but missing from Routes", source)
+		}
+	}
+
+	// Cron jobs must call an endpoint that exists
+	for _, cron := range config.Crons {
+		if !routed[cron.Path] {
+			t.Errorf("cron job calls %s, which isn't routed", cron.Path)
+		}
+	}
+}
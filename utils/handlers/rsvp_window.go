@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"utils/shared"
+)
+
+// checkRSVPWindow checks the RSVP schedule and returns the current phase.
+// When guests can't RSVP right now it writes a refusal and returns false.
+// allowLate lets a request through after the deadline so it can be held as
+// a late request; it never opens the form early. respond builds the calling
+// handler's own error body from an error code and message.
+func checkRSVPWindow(w http.ResponseWriter, allowLate bool, respond func(code, message string) interface{}) (string, bool) {
+	schedule, err := shared.LoadRSVPSchedule()
+	if err != nil {
+		log.Printf("RSVP schedule misconfigured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(respond("", "Server configuration error"))
+		return "", false
+	}
+
+	// One reading of the clock, so the phase and the refusal can't disagree
+	// when a request lands just as RSVPs open or close
+	now := time.Now()
+	phase := schedule.PhaseAt(now)
+	code, message := schedule.WindowErrorAt(now)
+	if code == "" || (allowLate && phase == shared.RSVPPhaseClosed) {
+		return phase, true
+	}
+
+	log.Printf("Refusing guest request outside RSVP window: %s", code)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(respond(code, message))
+	return phase, false
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"utils/shared"
+)
+
+// SaveAvatars handles saving avatar selections for guests
+func SaveAvatars(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, ok := checkRSVPWindow(w, false, func(code, message string) interface{} {
+		return shared.SaveAvatarsResponse{Success: false, Code: code, Message: message}
+	}); !ok {
+		return
+	}
+
+	// Parse request body
+	var req shared.SaveAvatarsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error parsing request body: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(shared.SaveAvatarsResponse{
+			Success: false,
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	// Validate request
+	if req.Email == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(shared.SaveAvatarsResponse{
+			Success: false,
+			Message: "Email is required",
+		})
+		return
+	}
+
+	if len(req.Avatars) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(shared.SaveAvatarsResponse{
+			Success: false,
+			Message: "At least one avatar selection is required",
+		})
+		return
+	}
+
+	// Validate each avatar selection
+	for _, avatar := range req.Avatars {
+		if avatar.GuestName == "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(shared.SaveAvatarsResponse{
+				Success: false,
+				Message: "Guest name is required for all avatars",
+			})
+			return
+		}
+		if avatar.Avatar == "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(shared.SaveAvatarsResponse{
+				Success: false,
+				Message: "Avatar is required for all guests",
+			})
+			return
+		}
+	}
+
+	// Update the most recent RSVP for this email with the avatar data
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(shared.SaveAvatarsResponse{
+			Success: false,
+			Message: "Database connection failed",
+		})
+		return
+	}
+
+	if err := store.SaveAvatars(req.Email, req.Avatars); err != nil {
+		log.Printf("Error updating RSVP avatars for %s: %v", req.Email, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(shared.SaveAvatarsResponse{
+			Success: false,
+			Message: "Failed to save avatar selections",
+		})
+		return
+	}
+
+	log.Printf("Successfully saved avatar selections for %d guests (email: %s)", len(req.Avatars), req.Email)
+
+	// Send success response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shared.SaveAvatarsResponse{
+		Success: true,
+		Message: "Avatar selections saved successfully",
+	})
+}
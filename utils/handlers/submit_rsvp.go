@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"utils/shared"
+)
+
+// SubmitRSVP handles RSVP submission requests
+func SubmitRSVP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req shared.RSVPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(shared.RSVPResponse{
+			Success: false,
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	// After the deadline, submissions are only accepted as late requests
+	phase, ok := checkRSVPWindow(w, req.LateRequest, func(code, message string) interface{} {
+		return shared.RSVPResponse{Success: false, Code: code, Message: message}
+	})
+	if !ok {
+		return
+	}
+	req.LateRequest = phase == shared.RSVPPhaseClosed
+
+	// Validate email
+	if !shared.IsValidEmail(req.Email) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(shared.RSVPResponse{
+			Success: false,
+			Message: "Invalid email address",
+		})
+		return
+	}
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(shared.RSVPResponse{
+			Success: false,
+			Message: "Server error - please try again",
+		})
+		return
+	}
+
+	// An invite code identifies the household RSVPing, so its guests need
+	// no admin check. A code that matches no household is refused, and
+	// counted like a failed verify-code so codes can't be guessed here.
+	var household *shared.Household
+	if strings.TrimSpace(req.InviteCode) != "" {
+		limiter, err := shared.NewNameLookupLimiter()
+		if err != nil {
+			log.Printf("Name lookup misconfigured: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(shared.RSVPResponse{
+				Success: false,
+				Message: "Server configuration error",
+			})
+			return
+		}
+		ipAddress := clientIP(r)
+		if wait := limiter.AllowCode(ipAddress); wait > 0 {
+			log.Printf("⚠️  Rate limited invite code RSVP from %s for %s", ipAddress, wait.Round(time.Second))
+			writeRateLimited(w, wait)
+			return
+		}
+		households, err := store.ListHouseholds()
+		if err != nil {
+			log.Printf("Error loading households: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(shared.RSVPResponse{
+				Success: false,
+				Message: "Server error - please try again",
+			})
+			return
+		}
+		household = shared.FindHouseholdByInviteCode(req.InviteCode, households)
+		limiter.RecordResult(ipAddress, household != nil)
+		if household == nil {
+			log.Printf("⚠️  RSVP from %s with unknown invite code %q", req.Name, req.InviteCode)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(shared.RSVPResponse{
+				Success: false,
+				Code:    shared.CodeInvalidInviteCode,
+				Message: "That invite code isn't valid - please check your invitation",
+			})
+			return
+		}
+		req.InviteCode = household.InviteCode
+	}
+
+	// If attending, validate guest list
+	verified := true
+	var guestList []shared.Guest
+	log.Printf("Processing RSVP: Name=%s, Email=%s, IsAttending=%v, Guests=%v, PlusOnes=%v",
+		req.Name, req.Email, req.IsAttending, req.AttendingGuests, req.PlusOnes)
+	if !req.IsAttending {
+		req.PlusOnes = nil
+		req.GuestDiets = nil
+		req.GuestMeals = nil
+		req.GuestEvents = nil
+	} else {
+		// Validate that at least one guest is attending
+		if len(req.AttendingGuests) == 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(shared.RSVPResponse{
+				Success: false,
+				Message: "At least one guest must be specified when attending",
+			})
+			return
+		}
+
+		// Load guest list to validate attending guests, and the events
+		// they may come to
+		var events []shared.Event
+		guestList, err = shared.LoadGuests(store)
+		if err == nil {
+			events, err = store.ListEvents()
+		}
+		if err != nil {
+			log.Printf("Error loading guests: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(shared.RSVPResponse{
+				Success: false,
+				Message: "Server error - please try again",
+			})
+			return
+		}
+
+		// Check if all attending guests exist in the guest list, storing
+		// fuzzy matches under their guest-list spelling so the dashboard
+		// can correlate them. If any guest is not found, mark as
+		// unverified but still allow RSVP
+		for i, attendingGuest := range req.AttendingGuests {
+			guest := shared.FindGuest(attendingGuest, guestList)
+			if guest == nil {
+				verified = false
+				log.Printf("⚠️  Unverified guest attempting RSVP: %s (not found: %s)", req.Name, attendingGuest)
+				continue
+			}
+			if household != nil && guest.HouseholdID != household.ID {
+				verified = false
+				log.Printf("⚠️  %s RSVPed with the invite code for %s but %s is in another household",
+					req.Name, household.Name, guest.Name)
+			}
+			req.AttendingGuests[i] = guest.Name
+			for j := range req.GuestDiets {
+				if shared.NormalizeString(req.GuestDiets[j].GuestName) == shared.NormalizeString(attendingGuest) {
+					req.GuestDiets[j].GuestName = guest.Name
+				}
+			}
+			for j := range req.GuestMeals {
+				if shared.NormalizeString(req.GuestMeals[j].GuestName) == shared.NormalizeString(attendingGuest) {
+					req.GuestMeals[j].GuestName = guest.Name
+				}
+			}
+			for j := range req.GuestEvents {
+				if shared.NormalizeString(req.GuestEvents[j].GuestName) == shared.NormalizeString(attendingGuest) {
+					req.GuestEvents[j].GuestName = guest.Name
+				}
+			}
+		}
+
+		// Named plus-ones are verified as long as the attending guests'
+		// allowance covers them
+		plusOnes, withinAllowance := shared.CheckPlusOnes(req.PlusOnes, req.AttendingGuests, guestList)
+		req.PlusOnes = plusOnes
+		if !withinAllowance {
+			verified = false
+			log.Printf("⚠️  %s named %d plus-ones, more than their allowance of %d",
+				req.Name, len(plusOnes), shared.PlusOneAllowance(req.AttendingGuests, guestList))
+		}
+
+		// Guests may only come to the events they are invited to (and
+		// plus-ones to those their party comes to), while there's room
+		guestEvents, err := shared.ResolveGuestEvents(req.GuestEvents, req.AttendingGuests, req.PlusOnes, guestList, events)
+		if err == nil {
+			err = checkEventCapacity(store, guestEvents, guestList, events)
+		}
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(shared.RSVPResponse{Success: false, Message: err.Error()})
+			return
+		}
+		req.GuestEvents = guestEvents
+
+		if category := shared.UnknownDietCategory(req.GuestDiets); category != "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(shared.RSVPResponse{Success: false, Message: "Unknown dietary requirement: " + category})
+			return
+		}
+		guestDiets, unknown := shared.CleanGuestDiets(req.GuestDiets, append(append([]string{}, req.AttendingGuests...), req.PlusOnes...))
+		if unknown != "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(shared.RSVPResponse{
+				Success: false,
+				Message: "Dietary requirements can only be given for attending guests: " + unknown + " isn't one of them",
+			})
+			return
+		}
+		req.GuestDiets = guestDiets
+
+		menu, err := store.ListMenuOptions()
+		if err != nil {
+			log.Printf("Error loading menu: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(shared.RSVPResponse{
+				Success: false,
+				Message: "Server error - please try again",
+			})
+			return
+		}
+		guestMeals, err := shared.CleanGuestMeals(req.GuestMeals, append(append([]string{}, req.AttendingGuests...), req.PlusOnes...), menu)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(shared.RSVPResponse{Success: false, Message: err.Error()})
+			return
+		}
+		req.GuestMeals = guestMeals
+	}
+
+	if household != nil && verified {
+		log.Printf("✓ RSVP from %s verified by the invite code for %s", req.Name, household.Name)
+	}
+
+	// Set verified status - late requests stay pending until an admin
+	// approves them, even for guests on the list
+	req.Verified = verified && !req.LateRequest
+
+	// Save RSVP to database
+	if err := store.SaveRSVP(req); err != nil {
+		log.Printf("Failed to save RSVP to database: %v", err)
+		// Continue even if database save fails
+	}
+
+	// Unverified RSVPs only reach the guest records once an admin verifies them
+	if req.Verified && req.IsAttending {
+		if err := shared.SaveGuestDietary(store, req.AttendingGuests, req.GuestDiets, guestList); err != nil {
+			log.Printf("Failed to save guest dietary requirements: %v", err)
+		}
+	}
+
+	if req.LateRequest {
+		log.Printf("⏰ Late RSVP request from %s (%s) - held for admin approval", req.Name, req.Email)
+		// Not using goroutine to ensure it completes before serverless function terminates
+		shared.SendLateRSVPNotification(req)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(shared.RSVPResponse{
+			Success: true,
+			Code:    shared.CodeLateRSVPPending,
+			Message: "Thanks! RSVPs have closed, so we've passed your request on to the couple - you'll hear back by email",
+		})
+		return
+	}
+
+	// Send confirmation email only for verified users
+	log.Printf("Verified status: %v", verified)
+	if verified {
+		log.Printf("Sending confirmation email to verified user: %s", req.Email)
+		if err := shared.SendConfirmationEmail(req); err != nil {
+			log.Printf("Failed to send confirmation email: %v", err)
+			// Don't fail the request if email fails - just log it
+		}
+	} else {
+		log.Printf("⚠️  Skipping confirmation email for unverified user: %s (%s)", req.Name, req.Email)
+		log.Printf("⚠️  Sending admin notification for unverified RSVP")
+		// Send admin notification for unverified RSVP with verification button
+		// Note: Not using goroutine to ensure it completes before serverless function terminates
+		shared.SendUnverifiedRSVPNotification(req)
+		log.Printf("⚠️  Admin notification sent")
+	}
+
+	if req.IsAttending {
+		log.Printf("✓ RSVP completed (ATTENDING): %s (%s) - Guests: %v - Plus-ones: %v - Events: %v - Diets: %v - Meals: %v - Diet: %s",
+			req.Name, req.Email, req.AttendingGuests, req.PlusOnes, req.GuestEvents, req.GuestDiets, req.GuestMeals, req.Diet)
+	} else {
+		log.Printf("✓ RSVP completed (NOT ATTENDING): %s (%s)", req.Name, req.Email)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shared.RSVPResponse{
+		Success: true,
+		Message: "RSVP submitted successfully",
+	})
+}
+
+// checkEventCapacity returns a guest-facing error when attendance would take
+// an event past its capacity (see shared.CheckEventCapacity). RSVPs are only
+// fetched when some event has a capacity. A store error is logged and lets
+// the RSVP through rather than turning guests away.
+func checkEventCapacity(store shared.Store, attendance []shared.EventAttendance, guestList []shared.Guest, events []shared.Event) error {
+	limited := false
+	for _, e := range events {
+		limited = limited || e.Capacity > 0
+	}
+	if !limited {
+		return nil
+	}
+	rsvps, err := store.ListRSVPs()
+	if err != nil {
+		log.Printf("Error loading RSVPs for capacity check: %v", err)
+		return nil
+	}
+	return shared.CheckEventCapacity(attendance, rsvps, guestList, events)
+}
@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"utils/shared"
+)
+
+func TestSubmitRSVP(t *testing.T) {
+	addGuests(t, shared.Guest{Name: "Submit Ana"}, shared.Guest{Name: "Submit Bob"})
+
+	tests := []struct {
+		name         string
+		req          shared.RSVPRequest
+		wantStatus   int
+		wantSaved    bool
+		wantVerified bool
+	}{
+		{"household attending", shared.RSVPRequest{Name: "Submit Ana", Email: "submit-ana@example.com", IsAttending: true, AttendingGuests: []string{"Submit Ana", "Submit Bob"}}, http.StatusOK, true, true},
+		{"declining", shared.RSVPRequest{Name: "Submit Bob", Email: "submit-bob@example.com"}, http.StatusOK, true, true},
+		{"names stored as invited", shared.RSVPRequest{Name: "Submit Ana", Email: "submit-typo@example.com", IsAttending: true, AttendingGuests: []string{"submit ana", "Sbumit Bob"}}, http.StatusOK, true, true},
+		{"guest not on the list", shared.RSVPRequest{Name: "Submit Ana", Email: "submit-stranger@example.com", IsAttending: true, AttendingGuests: []string{"Submit Ana", "Submit Stranger"}}, http.StatusOK, true, false},
+		{"attending with nobody", shared.RSVPRequest{Name: "Submit Ana", Email: "submit-nobody@example.com", IsAttending: true}, http.StatusBadRequest, false, false},
+		{"bad email", shared.RSVPRequest{Name: "Submit Ana", Email: "submit-ana"}, http.StatusBadRequest, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(SubmitRSVP, http.MethodPost, "/api/submit-rsvp", tt.req, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			rsvps := rsvpsFor(t, tt.req.Email)
+			if got := len(rsvps) > 0; got != tt.wantSaved {
+				t.Fatalf("saved = %v, want %v", got, tt.wantSaved)
+			}
+			if tt.wantSaved && rsvps[0].Verified != tt.wantVerified {
+				t.Errorf("verified = %v, want %v", rsvps[0].Verified, tt.wantVerified)
+			}
+			if tt.wantVerified && tt.req.IsAttending && !slices.Equal(rsvps[0].AttendingGuests, []string{"Submit Ana", "Submit Bob"}) {
+				t.Errorf("attending = %v, want the invite list spelling", rsvps[0].AttendingGuests)
+			}
+		})
+	}
+}
+
+func TestSubmitRSVPPlusOnes(t *testing.T) {
+	addGuests(t,
+		shared.Guest{Name: "Plus Ana", Address: "1 Plus Street", PlusOnes: 1},
+		shared.Guest{Name: "Plus Bob", Address: "1 Plus Street"},
+	)
+
+	tests := []struct {
+		name         string
+		req          shared.RSVPRequest
+		wantPlusOnes []string
+		wantVerified bool
+	}{
+		{"within allowance", shared.RSVPRequest{Email: "plus-1@example.com", IsAttending: true, AttendingGuests: []string{"Plus Ana", "Plus Bob"}, PlusOnes: []string{" Sam ", ""}}, []string{"Sam"}, true},
+		{"over allowance", shared.RSVPRequest{Email: "plus-2@example.com", IsAttending: true, AttendingGuests: []string{"Plus Ana"}, PlusOnes: []string{"Sam", "Kit"}}, []string{"Sam", "Kit"}, false},
+		{"allowance holder not coming", shared.RSVPRequest{Email: "plus-3@example.com", IsAttending: true, AttendingGuests: []string{"Plus Bob"}, PlusOnes: []string{"Sam"}}, []string{"Sam"}, false},
+		{"declining drops plus-ones", shared.RSVPRequest{Email: "plus-4@example.com", PlusOnes: []string{"Sam"}}, []string{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Name = "Plus Ana"
+			if w := serve(SubmitRSVP, http.MethodPost, "/api/submit-rsvp", tt.req, ""); w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+			rsvps := rsvpsFor(t, tt.req.Email)
+			if len(rsvps) != 1 {
+				t.Fatalf("saved %d RSVPs, want 1", len(rsvps))
+			}
+			if !slices.Equal(rsvps[0].PlusOnes, tt.wantPlusOnes) || rsvps[0].Verified != tt.wantVerified {
+				t.Errorf("saved plus-ones %q verified %v, want %q verified %v",
+					rsvps[0].PlusOnes, rsvps[0].Verified, tt.wantPlusOnes, tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestSubmitRSVPMeals(t *testing.T) {
+	addGuests(t, shared.Guest{Name: "Meal Ana", Address: "1 Meal Street", PlusOnes: 1}, shared.Guest{Name: "Meal Bob", Address: "1 Meal Street"})
+	store := testStore(t)
+	starter, _ := store.AddMenuOption(shared.MenuOption{Course: shared.CourseStarter, Name: "Meal Test Soup"})
+	main, _ := store.AddMenuOption(shared.MenuOption{Course: shared.CourseMain, Name: "Meal Test Pie"})
+
+	tests := []struct {
+		name       string
+		req        shared.RSVPRequest
+		wantStatus int
+		wantMeals  []shared.GuestMeal
+	}{
+		{
+			"stored in the RSVP's spelling",
+			shared.RSVPRequest{Name: "Meal Ana", Email: "meal-1@example.com", IsAttending: true, AttendingGuests: []string{"Meal Ana"}, PlusOnes: []string{"Sam"},
+				GuestMeals: []shared.GuestMeal{{GuestName: "meal ana", Starter: starter.ID, Main: main.ID}, {GuestName: "Sam", Main: main.ID}}},
+			http.StatusOK,
+			[]shared.GuestMeal{{GuestName: "Meal Ana", Starter: starter.ID, Main: main.ID}, {GuestName: "Sam", Main: main.ID}},
+		},
+		{
+			"dish from the wrong course",
+			shared.RSVPRequest{Name: "Meal Bob", Email: "meal-2@example.com", IsAttending: true, AttendingGuests: []string{"Meal Bob"},
+				GuestMeals: []shared.GuestMeal{{GuestName: "Meal Bob", Starter: main.ID}}},
+			http.StatusBadRequest, nil,
+		},
+		{
+			"dish not on the menu",
+			shared.RSVPRequest{Name: "Meal Bob", Email: "meal-3@example.com", IsAttending: true, AttendingGuests: []string{"Meal Bob"},
+				GuestMeals: []shared.GuestMeal{{GuestName: "Meal Bob", Main: "no-such-dish"}}},
+			http.StatusBadRequest, nil,
+		},
+		{
+			"someone not coming",
+			shared.RSVPRequest{Name: "Meal Bob", Email: "meal-4@example.com", IsAttending: true, AttendingGuests: []string{"Meal Bob"},
+				GuestMeals: []shared.GuestMeal{{GuestName: "Meal Ana", Main: main.ID}}},
+			http.StatusBadRequest, nil,
+		},
+		{
+			"declining drops meals",
+			shared.RSVPRequest{Name: "Meal Bob", Email: "meal-5@example.com", GuestMeals: []shared.GuestMeal{{GuestName: "Meal Bob", Main: main.ID}}},
+			http.StatusOK, []shared.GuestMeal{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(SubmitRSVP, http.MethodPost, "/api/submit-rsvp", tt.req, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			rsvps := rsvpsFor(t, tt.req.Email)
+			if tt.wantMeals == nil {
+				if len(rsvps) != 0 {
+					t.Errorf("saved %+v", rsvps)
+				}
+				return
+			}
+			if len(rsvps) != 1 || !slices.Equal(rsvps[0].GuestMeals, tt.wantMeals) {
+				t.Errorf("saved = %+v, want meals %+v", rsvps, tt.wantMeals)
+			}
+		})
+	}
+}
+
+func TestSubmitRSVPEvents(t *testing.T) {
+	addGuests(t,
+		shared.Guest{Name: "Event Ana", Address: "1 Event Street", Ceremony: true, PlusOnes: 1},
+		shared.Guest{Name: "Event Bob", Address: "2 Event Street", PlusOnes: 1},
+	)
+	both := []string{shared.EventCeremony, shared.EventReception}
+
+	tests := []struct {
+		name       string
+		req        shared.RSVPRequest
+		wantStatus int
+		wantEvents map[string][]string
+	}{
+		{
+			"defaults to the invitation",
+			shared.RSVPRequest{Name: "Event Ana", Email: "event-1@example.com", IsAttending: true, AttendingGuests: []string{"Event Ana"}, PlusOnes: []string{"Sam"}},
+			http.StatusOK, map[string][]string{"Event Ana": both, "Sam": both},
+		},
+		{
+			"reception only",
+			shared.RSVPRequest{Name: "Event Ana", Email: "event-2@example.com", IsAttending: true, AttendingGuests: []string{"Event Ana"},
+				GuestEvents: []shared.EventAttendance{{GuestName: "event ana", Events: []string{"Reception"}}}},
+			http.StatusOK, map[string][]string{"Event Ana": {shared.EventReception}},
+		},
+		{
+			"reception guest at the ceremony",
+			shared.RSVPRequest{Name: "Event Bob", Email: "event-3@example.com", IsAttending: true, AttendingGuests: []string{"Event Bob"},
+				GuestEvents: []shared.EventAttendance{{GuestName: "Event Bob", Events: both}}},
+			http.StatusBadRequest, nil,
+		},
+		{
+			"their plus-one at the ceremony",
+			shared.RSVPRequest{Name: "Event Bob", Email: "event-4@example.com", IsAttending: true, AttendingGuests: []string{"Event Bob"}, PlusOnes: []string{"Kit"},
+				GuestEvents: []shared.EventAttendance{{GuestName: "Kit", Events: both}}},
+			http.StatusBadRequest, nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(SubmitRSVP, http.MethodPost, "/api/submit-rsvp", tt.req, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			rsvps := rsvpsFor(t, tt.req.Email)
+			if tt.wantEvents == nil {
+				if len(rsvps) != 0 {
+					t.Errorf("saved %+v", rsvps)
+				}
+				return
+			}
+			got := make(map[string][]string)
+			for _, e := range rsvps[0].GuestEvents {
+				got[e.GuestName] = e.Events
+			}
+			if len(got) != len(tt.wantEvents) {
+				t.Fatalf("events = %v, want %v", got, tt.wantEvents)
+			}
+			for name, want := range tt.wantEvents {
+				if !slices.Equal(got[name], want) {
+					t.Errorf("%s events = %v, want %v", name, got[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSubmitRSVPCapacity(t *testing.T) {
+	dinner := shared.Event{ID: "capacity-test-dinner", Name: "Capacity Test Dinner", Capacity: 2}
+	if err := testStore(t).SaveEvent(dinner); err != nil {
+		t.Fatal(err)
+	}
+	addGuests(t,
+		shared.Guest{Name: "Cap Ana", Events: []string{dinner.ID}, PlusOnes: 1},
+		shared.Guest{Name: "Cap Bob", Events: []string{dinner.ID}},
+		shared.Guest{Name: "Cap Cat", Events: []string{dinner.ID}},
+	)
+	both := []string{dinner.ID, shared.EventReception}
+
+	tests := []struct {
+		name       string
+		req        shared.RSVPRequest
+		wantStatus int
+	}{
+		{"room for two", shared.RSVPRequest{Name: "Cap Ana", Email: "cap-1@example.com", IsAttending: true, AttendingGuests: []string{"Cap Ana"}, PlusOnes: []string{"Sam"}}, http.StatusOK},
+		{"full", shared.RSVPRequest{Name: "Cap Bob", Email: "cap-2@example.com", IsAttending: true, AttendingGuests: []string{"Cap Bob"}}, http.StatusBadRequest},
+		{"reception only", shared.RSVPRequest{Name: "Cap Bob", Email: "cap-3@example.com", IsAttending: true, AttendingGuests: []string{"Cap Bob"},
+			GuestEvents: []shared.EventAttendance{{GuestName: "Cap Bob", Events: []string{shared.EventReception}}}}, http.StatusOK},
+		{"changing their own RSVP", shared.RSVPRequest{Name: "Cap Ana", Email: "cap-4@example.com", IsAttending: true, AttendingGuests: []string{"Cap Ana"}, PlusOnes: []string{"Sam"},
+			GuestEvents: []shared.EventAttendance{{GuestName: "Cap Ana", Events: both}, {GuestName: "Sam", Events: []string{shared.EventReception}}}}, http.StatusOK},
+		{"a place freed", shared.RSVPRequest{Name: "Cap Cat", Email: "cap-5@example.com", IsAttending: true, AttendingGuests: []string{"Cap Cat"}}, http.StatusOK},
+		{"full again", shared.RSVPRequest{Name: "Cap Bob", Email: "cap-6@example.com", IsAttending: true, AttendingGuests: []string{"Cap Bob"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(SubmitRSVP, http.MethodPost, "/api/submit-rsvp", tt.req, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if saved := len(rsvpsFor(t, tt.req.Email)) == 1; saved != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("saved = %v", saved)
+			}
+		})
+	}
+}
+
+func TestSubmitRSVPDiets(t *testing.T) {
+	addGuests(t,
+		shared.Guest{Name: "Diet Ana", Address: "1 Diet Street", PlusOnes: 1},
+		shared.Guest{Name: "Diet Bob", Address: "1 Diet Street"},
+		shared.Guest{Name: "Diet Cat"},
+	)
+
+	tests := []struct {
+		name        string
+		req         shared.RSVPRequest
+		wantStatus  int
+		wantDiets   []shared.GuestDiet
+		wantDietary map[string]string // stored against invite list entries
+	}{
+		{
+			"stored against guests",
+			shared.RSVPRequest{Name: "Diet Ana", Email: "diet-1@example.com", IsAttending: true, AttendingGuests: []string{"Diet Ana", "Diet Bob"}, PlusOnes: []string{"Sam"},
+				GuestDiets: []shared.GuestDiet{{GuestName: "diet ana", Categories: []string{"Peanuts", "vegan"}, Diet: " no soy "}, {GuestName: "Sam", Diet: "halal"}}},
+			http.StatusOK,
+			[]shared.GuestDiet{{GuestName: "Diet Ana", Categories: []string{"vegan", "peanuts"}, Diet: "no soy"}, {GuestName: "Sam", Diet: "halal"}},
+			map[string]string{"Diet Ana": "no soy", "Diet Bob": ""},
+		},
+		{
+			"unknown category",
+			shared.RSVPRequest{Name: "Diet Cat", Email: "diet-5@example.com", IsAttending: true, AttendingGuests: []string{"Diet Cat"},
+				GuestDiets: []shared.GuestDiet{{GuestName: "Diet Cat", Categories: []string{"keto"}}}},
+			http.StatusBadRequest, nil, map[string]string{"Diet Cat": ""},
+		},
+		{
+			"someone not coming",
+			shared.RSVPRequest{Name: "Diet Cat", Email: "diet-2@example.com", IsAttending: true, AttendingGuests: []string{"Diet Cat"},
+				GuestDiets: []shared.GuestDiet{{GuestName: "Diet Bob", Diet: "vegan"}}},
+			http.StatusBadRequest, nil, map[string]string{"Diet Cat": ""},
+		},
+		{
+			"unverified RSVPs wait for an admin",
+			shared.RSVPRequest{Name: "Diet Cat", Email: "diet-3@example.com", IsAttending: true, AttendingGuests: []string{"Diet Cat", "Diet Stranger"},
+				GuestDiets: []shared.GuestDiet{{GuestName: "Diet Cat", Diet: "vegan"}}},
+			http.StatusOK,
+			[]shared.GuestDiet{{GuestName: "Diet Cat", Diet: "vegan"}},
+			map[string]string{"Diet Cat": ""},
+		},
+		{
+			"declining drops diets",
+			shared.RSVPRequest{Name: "Diet Cat", Email: "diet-4@example.com", GuestDiets: []shared.GuestDiet{{GuestName: "Diet Cat", Diet: "vegan"}}},
+			http.StatusOK, []shared.GuestDiet{}, map[string]string{"Diet Cat": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(SubmitRSVP, http.MethodPost, "/api/submit-rsvp", tt.req, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if rsvps := rsvpsFor(t, tt.req.Email); tt.wantDiets != nil && (len(rsvps) != 1 || !sameDiets(rsvps[0].GuestDiets, tt.wantDiets)) {
+				t.Errorf("saved = %+v, want diets %+v", rsvps, tt.wantDiets)
+			}
+			for name, want := range tt.wantDietary {
+				if got := findGuest(t, name).Dietary; got != want {
+					t.Errorf("%s dietary = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRSVPWindow(t *testing.T) {
+	addGuests(t, shared.Guest{Name: "Window Ana"})
+	guestHandlers := []struct {
+		name    string
+		handler http.HandlerFunc
+		body    interface{}
+	}{
+		{"verify-name", VerifyName, shared.RSVPRequest{Name: "Window Ana"}},
+		{"submit-rsvp", SubmitRSVP, shared.RSVPRequest{Name: "Window Ana", Email: "window-ana@example.com"}},
+		{"save-avatars", SaveAvatars, shared.SaveAvatarsRequest{Email: "window-ana@example.com"}},
+	}
+	windows := []struct {
+		name       string
+		opensAt    string
+		closesAt   string
+		wantStatus int
+		wantCode   string
+	}{
+		{"not open yet", "2999-01-01T00:00:00Z", "none", http.StatusForbidden, shared.ErrCodeRSVPNotOpen},
+		{"closed", "", "2000-01-01T00:00:00Z", http.StatusForbidden, shared.ErrCodeRSVPClosed},
+		{"misconfigured", "", "August", http.StatusInternalServerError, ""},
+	}
+	for _, window := range windows {
+		for _, h := range guestHandlers {
+			t.Run(window.name+"/"+h.name, func(t *testing.T) {
+				t.Setenv("RSVP_OPENS_AT", window.opensAt)
+				t.Setenv("RSVP_CLOSES_AT", window.closesAt)
+
+				w := serve(h.handler, http.MethodPost, "/api/"+h.name, h.body, "")
+				if w.Code != window.wantStatus {
+					t.Fatalf("status = %d, want %d: %s", w.Code, window.wantStatus, w.Body)
+				}
+				var resp shared.RSVPResponse
+				decode(t, w, &resp)
+				if resp.Success || resp.Code != window.wantCode {
+					t.Errorf("response = %+v, want code %q", resp, window.wantCode)
+				}
+			})
+		}
+	}
+	if rsvps := rsvpsFor(t, "window-ana@example.com"); len(rsvps) != 0 {
+		t.Errorf("RSVP saved outside the window: %+v", rsvps)
+	}
+}
+
+func TestSubmitLateRSVP(t *testing.T) {
+	addGuests(t, shared.Guest{Name: "Late Ana"})
+	t.Setenv("RSVP_CLOSES_AT", "2000-01-01T00:00:00Z")
+
+	tests := []struct {
+		name       string
+		email      string
+		late       bool
+		wantStatus int
+		wantCode   string
+	}{
+		{"late request", "late-ana@example.com", true, http.StatusOK, shared.CodeLateRSVPPending},
+		{"ordinary submission", "late-ana-2@example.com", false, http.StatusForbidden, shared.ErrCodeRSVPClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := shared.RSVPRequest{Name: "Late Ana", Email: tt.email, IsAttending: true, AttendingGuests: []string{"Late Ana"}, LateRequest: tt.late}
+			w := serve(SubmitRSVP, http.MethodPost, "/api/submit-rsvp", req, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var resp shared.RSVPResponse
+			decode(t, w, &resp)
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", resp.Code, tt.wantCode)
+			}
+
+			rsvps := rsvpsFor(t, tt.email)
+			if !tt.late {
+				if len(rsvps) != 0 {
+					t.Errorf("refused RSVP was saved: %+v", rsvps)
+				}
+				return
+			}
+			// Guests on the list still wait for an admin after the deadline
+			if len(rsvps) != 1 || !rsvps[0].Late || rsvps[0].Verified {
+				t.Errorf("saved = %+v, want one pending late RSVP", rsvps)
+			}
+		})
+	}
+
+	t.Run("late flag ignored while open", func(t *testing.T) {
+		t.Setenv("RSVP_CLOSES_AT", "none")
+		req := shared.RSVPRequest{Name: "Late Ana", Email: "late-ana-3@example.com", LateRequest: true}
+		if w := serve(SubmitRSVP, http.MethodPost, "/api/submit-rsvp", req, ""); w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body)
+		}
+		if rsvps := rsvpsFor(t, req.Email); len(rsvps) != 1 || rsvps[0].Late || !rsvps[0].Verified {
+			t.Errorf("saved = %+v, want one verified on-time RSVP", rsvps)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"utils/shared"
+)
+
+// VerifyCode looks up a household by the invite code printed on its
+// invitation (or in its invite link), answering like a successful
+// verify-name: the household's members, the events each is invited to and
+// the menu. A valid code passes any household challenge.
+func VerifyCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req shared.VerifyCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success: false,
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	// After the deadline, codes can only be looked up for a late request
+	if _, ok := checkRSVPWindow(w, req.LateRequest, func(code, message string) interface{} {
+		return shared.VerifyNameResponse{Success: false, Code: code, Message: message}
+	}); !ok {
+		return
+	}
+
+	if strings.TrimSpace(req.Code) == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success: false,
+			Message: "Code cannot be empty",
+		})
+		return
+	}
+
+	// Code lookups share the per-IP limit and backoff with name lookups, so
+	// codes can't be guessed any faster than names
+	limiter, err := shared.NewNameLookupLimiter()
+	if err != nil {
+		log.Printf("Name lookup misconfigured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success: false,
+			Message: "Server configuration error",
+		})
+		return
+	}
+	ipAddress := clientIP(r)
+	if wait := limiter.AllowCode(ipAddress); wait > 0 {
+		log.Printf("⚠️  Rate limited invite code lookup from %s for %s", ipAddress, wait.Round(time.Second))
+		writeRateLimited(w, wait)
+		return
+	}
+
+	var households []shared.Household
+	var guestList []shared.Guest
+	var events []shared.Event
+	store, err := shared.NewStore()
+	if err == nil {
+		households, err = store.ListHouseholds()
+	}
+	if err == nil {
+		guestList, err = shared.LoadGuests(store)
+	}
+	if err == nil {
+		events, err = store.ListEvents()
+	}
+	if err != nil {
+		log.Printf("Error loading households: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success: false,
+			Message: "Server error - please try again",
+		})
+		return
+	}
+
+	var firstGuest *shared.Guest
+	household := shared.FindHouseholdByInviteCode(req.Code, households)
+	if household != nil {
+		for i := range guestList {
+			if guestList[i].HouseholdID == household.ID {
+				firstGuest = &guestList[i]
+				break
+			}
+		}
+	}
+	limiter.RecordResult(ipAddress, firstGuest != nil)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if firstGuest == nil {
+		log.Printf("⚠️  Unknown invite code %q from %s", req.Code, ipAddress)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success: false,
+			Code:    shared.CodeInvalidInviteCode,
+			Message: "We couldn't find that code - please check your invitation, or enter your name instead",
+		})
+		return
+	}
+
+	log.Printf("Invite code matched household %s (ID: %s)", household.Name, household.ID)
+
+	// An unreadable menu only means guests can't choose their meals yet
+	menu, err := store.ListMenuOptions()
+	if err != nil {
+		log.Printf("Error loading menu: %v", err)
+	}
+
+	resp := householdResponse(*firstGuest, guestList, events, menu)
+	resp.Household = household.Name
+	json.NewEncoder(w).Encode(resp)
+}
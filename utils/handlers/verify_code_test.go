@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"utils/shared"
+)
+
+// setInviteCode gives the household of the guest named name code
+func setInviteCode(t *testing.T, name, code string) {
+	t.Helper()
+	if err := testStore(t).SetHouseholdInviteCode(findGuest(t, name).HouseholdID, code); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyCode(t *testing.T) {
+	addGuests(t,
+		shared.Guest{Name: "Code Ana", Address: "7 Code Close", PlusOnes: 1},
+		shared.Guest{Name: "Code Bob", Address: "7 Code Close"},
+		shared.Guest{Name: "Code Cat", Address: "8 Code Close"},
+	)
+	setInviteCode(t, "Code Ana", "CDCD-7777")
+
+	tests := []struct {
+		name       string
+		code       string
+		wantStatus int
+		wantCode   string
+		wantFamily []string
+	}{
+		{"unknown code", "CDCD-7778", http.StatusNotFound, shared.CodeInvalidInviteCode, nil},
+		{"empty", " ", http.StatusBadRequest, "", nil},
+		{"code as printed", "CDCD-7777", http.StatusOK, "", []string{"Code Ana", "Code Bob"}},
+		{"code as typed", " cdcd7777 ", http.StatusOK, "", []string{"Code Ana", "Code Bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(VerifyCode, http.MethodPost, "/api/verify-code", shared.VerifyCodeRequest{Code: tt.code}, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var resp shared.VerifyNameResponse
+			decode(t, w, &resp)
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", resp.Code, tt.wantCode)
+			}
+			var family []string
+			for _, m := range resp.FamilyMembers {
+				family = append(family, m.Name)
+			}
+			if !slices.Equal(family, tt.wantFamily) {
+				t.Errorf("family = %v, want %v", family, tt.wantFamily)
+			}
+			if tt.wantFamily != nil && (resp.Household != "7 Code Close" || resp.PlusOneSlots != 1) {
+				t.Errorf("household %q with %d plus-ones, want 7 Code Close with 1", resp.Household, resp.PlusOneSlots)
+			}
+		})
+	}
+}
+
+func TestSubmitRSVPInviteCode(t *testing.T) {
+	addGuests(t,
+		shared.Guest{Name: "Invite Ana", Address: "3 Invite Row"},
+		shared.Guest{Name: "Invite Bob", Address: "3 Invite Row"},
+		shared.Guest{Name: "Invite Cat", Address: "4 Invite Row"},
+	)
+	setInviteCode(t, "Invite Ana", "NVNV-3333")
+
+	tests := []struct {
+		name         string
+		req          shared.RSVPRequest
+		wantStatus   int
+		wantVerified bool
+	}{
+		{"unknown code", shared.RSVPRequest{Name: "Invite Ana", Email: "invite-unknown@example.com", InviteCode: "NVNV-3334", IsAttending: true, AttendingGuests: []string{"Invite Ana"}}, http.StatusBadRequest, false},
+		{"guest from another household", shared.RSVPRequest{Name: "Invite Ana", Email: "invite-other@example.com", InviteCode: "NVNV-3333", IsAttending: true, AttendingGuests: []string{"Invite Ana", "Invite Cat"}}, http.StatusOK, false},
+		{"household", shared.RSVPRequest{Name: "Invite Ana", Email: "invite-ana@example.com", InviteCode: "nvnv 3333", IsAttending: true, AttendingGuests: []string{"Invite Ana", "Invite Bob"}}, http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(SubmitRSVP, http.MethodPost, "/api/submit-rsvp", tt.req, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			rsvps := rsvpsFor(t, tt.req.Email)
+			if tt.wantStatus != http.StatusOK {
+				var resp shared.RSVPResponse
+				decode(t, w, &resp)
+				if len(rsvps) != 0 || resp.Code != shared.CodeInvalidInviteCode {
+					t.Errorf("code %q with %d RSVPs saved, want %q and none", resp.Code, len(rsvps), shared.CodeInvalidInviteCode)
+				}
+				return
+			}
+			if len(rsvps) != 1 || rsvps[0].Verified != tt.wantVerified {
+				t.Fatalf("RSVPs = %+v, want one with verified %v", rsvps, tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestVerifyCodeAfterReimport(t *testing.T) {
+	// A guest with no household column or address to group by is only
+	// given a household by the invite-codes tool
+	addGuests(t, shared.Guest{Name: "Reimport Rex", Address: "n/a"})
+	store := testStore(t)
+	if _, _, err := shared.HouseholdsForUnhousedGuests(store); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shared.GenerateInviteCodes(store, false); err != nil {
+		t.Fatal(err)
+	}
+	rex := findGuest(t, "Reimport Rex")
+	households, err := store.ListHouseholds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var code string
+	for _, h := range households {
+		if h.ID == rex.HouseholdID {
+			code = h.InviteCode
+		}
+	}
+	if code == "" {
+		t.Fatalf("Reimport Rex has no invite code")
+	}
+
+	// Re-importing the invite list, still without a household column,
+	// updates their table but leaves them in the household the code is for
+	if _, err := shared.ImportGuests(store, []shared.Guest{{Name: "Reimport Rex", Address: "n/a", Table: "5"}}, shared.ImportOptions{Update: true}); err != nil {
+		t.Fatal(err)
+	}
+	if after := findGuest(t, "Reimport Rex"); after.HouseholdID != rex.HouseholdID || after.Table != "5" {
+		t.Fatalf("after re-import Reimport Rex = %+v, want table 5 in household %q", after, rex.HouseholdID)
+	}
+
+	w := serve(VerifyCode, http.MethodPost, "/api/verify-code", shared.VerifyCodeRequest{Code: code}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want the code to still work: %s", w.Code, w.Body)
+	}
+	var resp shared.VerifyNameResponse
+	decode(t, w, &resp)
+	if len(resp.FamilyMembers) != 1 || resp.FamilyMembers[0].Name != "Reimport Rex" {
+		t.Errorf("family = %+v, want Reimport Rex", resp.FamilyMembers)
+	}
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"utils/shared"
+)
+
+// VerifyName handles name verification requests
+func VerifyName(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req shared.RSVPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success: false,
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	// After the deadline, names can only be looked up for a late request
+	if _, ok := checkRSVPWindow(w, req.LateRequest, func(code, message string) interface{} {
+		return shared.VerifyNameResponse{Success: false, Code: code, Message: message}
+	}); !ok {
+		return
+	}
+
+	// Validate name is not empty
+	if strings.TrimSpace(req.Name) == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success: false,
+			Message: "Name cannot be empty",
+		})
+		return
+	}
+
+	// Limit lookups per IP address and per name from each address, so the
+	// guest list can't be scraped by guessing names
+	limiter, err := shared.NewNameLookupLimiter()
+	var challenge string
+	if err == nil {
+		challenge, err = shared.LoadHouseholdChallenge()
+	}
+	if err != nil {
+		log.Printf("Name lookup misconfigured: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success: false,
+			Message: "Server configuration error",
+		})
+		return
+	}
+	ipAddress := clientIP(r)
+	if wait := limiter.Allow(ipAddress, req.Name); wait > 0 {
+		log.Printf("⚠️  Rate limited name lookup of %q from %s for %s", req.Name, ipAddress, wait.Round(time.Second))
+		writeRateLimited(w, wait)
+		return
+	}
+
+	// Load guest list and the events family members may be invited to
+	var guestList []shared.Guest
+	var events []shared.Event
+	store, err := shared.NewStore()
+	if err == nil {
+		guestList, err = shared.LoadGuests(store)
+	}
+	if err == nil {
+		events, err = store.ListEvents()
+	}
+	if err != nil {
+		log.Printf("Error loading guests: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success: false,
+			Message: "Server error - please try again",
+		})
+		return
+	}
+
+	// Search for the guest: exact or high-confidence fuzzy matches are
+	// accepted, medium-confidence ones are offered as suggestions
+	foundGuest := shared.FindGuest(req.Name, guestList)
+	var suggested []shared.Guest
+	if foundGuest == nil && !req.SkipSuggestions {
+		suggested = shared.Suggestions(req.Name, shared.MatchGuests(req.Name, guestList))
+	}
+
+	// With a household challenge set, a matched guest must also give their
+	// postcode or invite code before their household is listed, and a
+	// suggestion is only offered to someone who gives its answer
+	if challenge != shared.ChallengeNone && (foundGuest != nil || len(suggested) > 0) {
+		var households []shared.Household
+		if challenge == shared.ChallengeCode {
+			if households, err = store.ListHouseholds(); err != nil {
+				log.Printf("Error loading households: %v", err)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+					Success: false,
+					Message: "Server error - please try again",
+				})
+				return
+			}
+		}
+		answered := strings.TrimSpace(req.ChallengeAnswer) != ""
+		resp := shared.VerifyNameResponse{Success: false, ChallengeType: challenge}
+		if foundGuest != nil {
+			if expected := shared.ChallengeAnswer(challenge, *foundGuest, households); expected != "" {
+				switch {
+				case !answered:
+					limiter.RecordResult(ipAddress, true)
+					resp.Code, resp.Message = shared.CodeChallengeRequired, shared.ChallengePrompt(challenge)
+				case !shared.CheckChallengeAnswer(expected, req.ChallengeAnswer):
+					log.Printf("⚠️  Wrong %s given for %s from %s", challenge, foundGuest.Name, ipAddress)
+					limiter.RecordResult(ipAddress, false)
+					resp.Code = shared.CodeChallengeFailed
+				}
+			}
+		} else {
+			// Guests with nothing to ask for are never suggested, as that
+			// would name them without a challenge
+			var askable, passed []shared.Guest
+			for _, g := range suggested {
+				if expected := shared.ChallengeAnswer(challenge, g, households); expected != "" {
+					askable = append(askable, g)
+					if answered && shared.CheckChallengeAnswer(expected, req.ChallengeAnswer) {
+						passed = append(passed, g)
+					}
+				}
+			}
+			suggested = passed
+			switch {
+			case len(askable) == 0:
+			case !answered:
+				limiter.RecordResult(ipAddress, false)
+				resp.Code, resp.Message = shared.CodeChallengeRequired, shared.ChallengePrompt(challenge)
+			case len(passed) == 0:
+				log.Printf("⚠️  Wrong %s given for suggestions for %q from %s", challenge, req.Name, ipAddress)
+				limiter.RecordResult(ipAddress, false)
+				resp.Code = shared.CodeChallengeFailed
+			}
+		}
+		if resp.Code == shared.CodeChallengeFailed {
+			resp.Message = "That doesn't match our records - please check your invitation and try again"
+		}
+		if resp.Code != "" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+	}
+	suggestions := make([]string, len(suggested))
+	for i, g := range suggested {
+		suggestions[i] = g.Name
+	}
+
+	// Guests choose their meals on the form; an unreadable menu only means
+	// they can't choose yet
+	var menu []shared.MenuOption
+	if foundGuest != nil || len(suggestions) == 0 {
+		if menu, err = store.ListMenuOptions(); err != nil {
+			log.Printf("Error loading menu: %v", err)
+		}
+	}
+
+	// Only a confident match counts as a hit: suggestions reveal names too
+	limiter.RecordResult(ipAddress, foundGuest != nil)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if foundGuest != nil {
+		log.Printf("Guest found: %s (ID: %s, Household: %s)", foundGuest.Name, foundGuest.ID, foundGuest.HouseholdID)
+		if shared.NormalizeString(foundGuest.Name) != shared.NormalizeString(req.Name) {
+			log.Printf("Fuzzy matched %q to %s", req.Name, foundGuest.Name)
+		}
+
+		resp := householdResponse(*foundGuest, guestList, events, menu)
+		resp.MatchedName = foundGuest.Name
+		json.NewEncoder(w).Encode(resp)
+	} else if len(suggestions) > 0 {
+		log.Printf("No confident match for %q - suggesting %v", req.Name, suggestions)
+
+		// No admin alert yet: the guest will most likely pick a suggestion
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success:     false,
+			Code:        shared.CodeDidYouMean,
+			Message:     "We couldn't find that exact name - did you mean one of these?",
+			Suggestions: suggestions,
+		})
+	} else {
+		log.Printf("Guest not found (allowing as unverified): %s", req.Name)
+
+		// Send notification to admin about unlisted guest, unless so many
+		// have been sent lately that this is more likely a bot
+		if limiter.AllowAlert() {
+			// Not using goroutine, so the alert is in the outbox before the
+			// serverless function terminates
+			shared.SendUnlistedGuestNotification(req, ipAddress, r.Header.Get("User-Agent"))
+		} else {
+			log.Printf("⚠️  Unlisted guest alert limit reached - not emailing about %q from %s", req.Name, ipAddress)
+		}
+
+		// Allow the user to proceed but with empty family members
+		// They will be marked as unverified when they submit RSVP
+		json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+			Success:       true,
+			Message:       "Please proceed with your RSVP",
+			FamilyMembers: []shared.FamilyMember{},
+			Menu:          menu,
+			Events:        events,
+		})
+	}
+}
+
+// householdResponse is verify-name's (and verify-code's) success response
+// for guest: everyone in their household, with the events each is invited
+// to, and how many plus-ones they may bring
+func householdResponse(guest shared.Guest, guestList []shared.Guest, events []shared.Event, menu []shared.MenuOption) shared.VerifyNameResponse {
+	familyMembers := shared.HouseholdMembers(guest, guestList, events)
+	plusOneSlots := shared.HouseholdPlusOnes(guest, guestList)
+
+	log.Printf("Found %d family members and %d plus-one slots in household %q for %s",
+		len(familyMembers), plusOneSlots, guest.HouseholdID, guest.Name)
+
+	return shared.VerifyNameResponse{
+		Success:       true,
+		Message:       "Guest found",
+		FamilyMembers: familyMembers,
+		PlusOneSlots:  plusOneSlots,
+		Menu:          menu,
+		Events:        events,
+	}
+}
+
+// writeRateLimited refuses a lookup made too soon, saying when to try again
+func writeRateLimited(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(shared.VerifyNameResponse{
+		Success: false,
+		Code:    shared.CodeRateLimited,
+		Message: fmt.Sprintf("Too many attempts - please wait %s and try again", waitDescription(wait)),
+	})
+}
+
+// clientIP returns the address the request came from. X-Forwarded-For
+// can be set by anyone, so it is only read when TRUSTED_PROXY_HOPS says
+// how many proxies in front of the server append to it (1 by default on
+// Vercel, which appends the address it saw): the client is that many
+// entries from the end. Otherwise it is the remote host.
+func clientIP(r *http.Request) string {
+	if hops := trustedProxyHops(); hops > 0 {
+		var entries []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			entries = append(entries, strings.Split(header, ",")...)
+		}
+		if len(entries) >= hops {
+			if ip := strings.TrimSpace(entries[len(entries)-hops]); ip != "" {
+				return ip
+			}
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// trustedProxyHops reads TRUSTED_PROXY_HOPS, defaulting to 1 on Vercel
+// and 0 elsewhere. An invalid value trusts no proxy.
+func trustedProxyHops() int {
+	v := strings.TrimSpace(os.Getenv("TRUSTED_PROXY_HOPS"))
+	if v == "" {
+		if os.Getenv("VERCEL") != "" {
+			return 1
+		}
+		return 0
+	}
+	hops, err := strconv.Atoi(v)
+	if err != nil || hops < 0 {
+		log.Printf("⚠️  Invalid TRUSTED_PROXY_HOPS %q, ignoring X-Forwarded-For", v)
+		return 0
+	}
+	return hops
+}
+
+// waitDescription says how long wait is in words, rounded up to a second
+// or a minute
+func waitDescription(wait time.Duration) string {
+	if wait < time.Minute {
+		seconds := int((wait + time.Second - 1) / time.Second)
+		if seconds == 1 {
+			return "a second"
+		}
+		return fmt.Sprintf("%d seconds", seconds)
+	}
+	minutes := int((wait + time.Minute - 1) / time.Minute)
+	if minutes == 1 {
+		return "a minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"utils/shared"
+)
+
+func TestVerifyName(t *testing.T) {
+	addGuests(t,
+		shared.Guest{Name: "Zebulon Quackenbush", Address: "1 Quack Lane"},
+		shared.Guest{Name: "Zebulan Quackenbush"},
+		shared.Guest{Name: "Persephone Quackenbush", Address: "1 Quack Lane"},
+	)
+
+	tests := []struct {
+		name            string
+		req             shared.RSVPRequest
+		wantStatus      int
+		wantMatched     string
+		wantFamily      []string
+		wantSuggestions []string
+	}{
+		{"exact", shared.RSVPRequest{Name: "persephone quackenbush"}, http.StatusOK, "Persephone Quackenbush", []string{"Persephone Quackenbush", "Zebulon Quackenbush"}, nil},
+		{"typo", shared.RSVPRequest{Name: "Persephnoe Quackenbush"}, http.StatusOK, "Persephone Quackenbush", []string{"Persephone Quackenbush", "Zebulon Quackenbush"}, nil},
+		{"did you mean", shared.RSVPRequest{Name: "Zebulin Quackenbush"}, http.StatusOK, "", nil, []string{"Zebulan Quackenbush", "Zebulon Quackenbush"}},
+		{"suggestions rejected", shared.RSVPRequest{Name: "Zebulin Quackenbush", SkipSuggestions: true}, http.StatusOK, "", nil, nil},
+		{"stranger", shared.RSVPRequest{Name: "Ignatius Fenwick-Blythe"}, http.StatusOK, "", nil, nil},
+		{"empty", shared.RSVPRequest{Name: " "}, http.StatusBadRequest, "", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(VerifyName, http.MethodPost, "/api/verify-name", tt.req, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var resp shared.VerifyNameResponse
+			decode(t, w, &resp)
+
+			var family []string
+			for _, m := range resp.FamilyMembers {
+				family = append(family, m.Name)
+			}
+			if resp.MatchedName != tt.wantMatched || !slices.Equal(family, tt.wantFamily) {
+				t.Errorf("matched %q with family %v, want %q with %v", resp.MatchedName, family, tt.wantMatched, tt.wantFamily)
+			}
+			if !slices.Equal(resp.Suggestions, tt.wantSuggestions) {
+				t.Errorf("suggestions = %v, want %v", resp.Suggestions, tt.wantSuggestions)
+			}
+			if got := resp.Code == shared.CodeDidYouMean; got != (tt.wantSuggestions != nil) {
+				t.Errorf("code = %q with suggestions %v", resp.Code, resp.Suggestions)
+			}
+		})
+	}
+}
+
+func TestVerifyNameRateLimited(t *testing.T) {
+	t.Setenv("VERIFY_NAME_NAME_LIMIT", "2")
+	t.Setenv("TRUSTED_PROXY_HOPS", "1")
+	addGuests(t, shared.Guest{Name: "Cornelius Throgmorton"})
+
+	for i, step := range []struct {
+		ip   string
+		want int
+	}{
+		{"198.51.100.1", http.StatusOK},
+		{"198.51.100.1", http.StatusOK},
+		{"198.51.100.1", http.StatusTooManyRequests},
+		// The limit on a name is per address
+		{"198.51.100.2", http.StatusOK},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/api/verify-name", bytes.NewBufferString(`{"name":"Cornelius Throgmorton"}`))
+		r.Header.Set("X-Forwarded-For", step.ip)
+		w := httptest.NewRecorder()
+		VerifyName(w, r)
+
+		if w.Code != step.want {
+			t.Fatalf("lookup %d from %s: status = %d, want %d: %s", i, step.ip, w.Code, step.want, w.Body)
+		}
+		if step.want != http.StatusTooManyRequests {
+			continue
+		}
+		var resp shared.VerifyNameResponse
+		decode(t, w, &resp)
+		if resp.Code != shared.CodeRateLimited || w.Header().Get("Retry-After") == "" {
+			t.Errorf("code = %q, Retry-After %q, want %q with a Retry-After", resp.Code, w.Header().Get("Retry-After"), shared.CodeRateLimited)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		hops      string
+		vercel    string
+		forwarded []string
+		want      string
+	}{
+		{"no proxy", "", "", []string{"203.0.113.9"}, "192.0.2.1"},
+		{"vercel", "", "1", []string{"203.0.113.9, 198.51.100.7"}, "198.51.100.7"},
+		{"two proxies", "2", "", []string{"203.0.113.9, 198.51.100.7", "10.0.0.1"}, "198.51.100.7"},
+		{"fewer entries than hops", "3", "", []string{"198.51.100.7"}, "192.0.2.1"},
+		{"invalid hops", "many", "1", []string{"198.51.100.7"}, "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUSTED_PROXY_HOPS", tt.hops)
+			t.Setenv("VERCEL", tt.vercel)
+			r := httptest.NewRequest(http.MethodPost, "/api/verify-name", nil)
+			for _, f := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", f)
+			}
+			if got := clientIP(r); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitDescription(t *testing.T) {
+	for wait, want := range map[time.Duration]string{
+		time.Second:               "a second",
+		1500 * time.Millisecond:   "2 seconds",
+		time.Minute:               "a minute",
+		time.Minute + time.Second: "2 minutes",
+		15 * time.Minute:          "15 minutes",
+	} {
+		if got := waitDescription(wait); got != want {
+			t.Errorf("waitDescription(%s) = %q, want %q", wait, got, want)
+		}
+	}
+}
+
+func TestVerifyNameChallenge(t *testing.T) {
+	t.Setenv("HOUSEHOLD_CHALLENGE", shared.ChallengePostcode)
+	addGuests(t,
+		shared.Guest{Name: "Eudora Pemberton", Address: "4 Rook Lane, London SW18 2PU"},
+		shared.Guest{Name: "Horace Pemberton", Address: "4 Rook Lane, London SW18 2PU"},
+		shared.Guest{Name: "Wilhelmina Ashdown", Address: "n/a"},
+	)
+
+	tests := []struct {
+		name            string
+		req             shared.RSVPRequest
+		wantCode        string
+		wantFamily      int
+		wantSuggestions []string
+	}{
+		{"asked for the postcode", shared.RSVPRequest{Name: "Eudora Pemberton"}, shared.CodeChallengeRequired, 0, nil},
+		{"wrong postcode", shared.RSVPRequest{Name: "Eudora Pemberton", ChallengeAnswer: "SW18 2PV"}, shared.CodeChallengeFailed, 0, nil},
+		{"right postcode", shared.RSVPRequest{Name: "Eudora Pemberton", ChallengeAnswer: "sw182pu"}, "", 2, nil},
+		{"no address to challenge", shared.RSVPRequest{Name: "Wilhelmina Ashdown"}, "", 1, nil},
+		{"suggestion asks for the postcode", shared.RSVPRequest{Name: "Horatio Pemberton"}, shared.CodeChallengeRequired, 0, nil},
+		{"suggestion with the wrong postcode", shared.RSVPRequest{Name: "Horatio Pemberton", ChallengeAnswer: "N1 1AA"}, shared.CodeChallengeFailed, 0, nil},
+		{"suggestion with the right postcode", shared.RSVPRequest{Name: "Horatio Pemberton", ChallengeAnswer: "SW18 2PU"}, shared.CodeDidYouMean, 0, []string{"Horace Pemberton"}},
+		{"no suggestion without an address", shared.RSVPRequest{Name: "Wilma Ashdown"}, "", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(VerifyName, http.MethodPost, "/api/verify-name", tt.req, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+			}
+			var resp shared.VerifyNameResponse
+			decode(t, w, &resp)
+			if resp.Code != tt.wantCode || len(resp.FamilyMembers) != tt.wantFamily {
+				t.Errorf("code %q with %d family members, want %q with %d", resp.Code, len(resp.FamilyMembers), tt.wantCode, tt.wantFamily)
+			}
+			if !slices.Equal(resp.Suggestions, tt.wantSuggestions) {
+				t.Errorf("suggestions = %v, want %v", resp.Suggestions, tt.wantSuggestions)
+			}
+			if (tt.wantCode == shared.CodeChallengeRequired || tt.wantCode == shared.CodeChallengeFailed) && resp.ChallengeType != shared.ChallengePostcode {
+				t.Errorf("challengeType = %q, want %q", resp.ChallengeType, shared.ChallengePostcode)
+			}
+		})
+	}
+}
@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"utils/shared"
+)
+
+// VerifyRSVPRequest represents the verification request
+type VerifyRSVPRequest struct {
+	Email  string `json:"email"`
+	APIKey string `json:"apiKey"`
+	Action string `json:"action,omitempty"` // "verify" (default) | "decline" for late requests
+}
+
+// VerifyRSVP handles RSVP verification requests (admin only). The links in
+// admin emails are GETs, which only show a page asking to confirm: link
+// scanners and previews open them too, so nothing changes until the form
+// on that page is posted. JSON POSTs from the API act straight away.
+func VerifyRSVP(w http.ResponseWriter, r *http.Request) {
+	var email, apiKeyParam, action string
+
+	if r.Method == http.MethodGet {
+		// Parse from query parameters (email link)
+		email = r.URL.Query().Get("email")
+		apiKeyParam = r.URL.Query().Get("apiKey")
+		action = r.URL.Query().Get("action")
+	} else if isFormPost(r) {
+		// Parse from the confirmation page's form
+		if err := r.ParseForm(); err != nil {
+			showErrorPage(w, r, "Invalid request format")
+			return
+		}
+		email = r.PostForm.Get("email")
+		apiKeyParam = r.PostForm.Get("apiKey")
+		action = r.PostForm.Get("action")
+	} else if r.Method == http.MethodPost {
+		// Parse from JSON body (API)
+		var req VerifyRSVPRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"message": "Invalid request format",
+			})
+			return
+		}
+		email = req.Email
+		apiKeyParam = req.APIKey
+		action = req.Action
+	} else {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Verify API key
+	adminAPIKey := os.Getenv("ADMIN_API_KEY")
+	if adminAPIKey == "" {
+		log.Printf("ADMIN_API_KEY not configured")
+		showErrorPage(w, r, "Server configuration error")
+		return
+	}
+
+	if apiKeyParam != adminAPIKey {
+		log.Printf("Invalid API key provided for verification attempt")
+		showErrorPage(w, r, "Invalid API key")
+		return
+	}
+
+	// Validate email
+	if email == "" {
+		showErrorPage(w, r, "Email is required")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		showConfirmPage(w, r, email, apiKeyParam, action)
+		return
+	}
+
+	// Update RSVP in database to set verified = true
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Printf("Store not configured: %v", err)
+		showErrorPage(w, r, "Database not configured")
+		return
+	}
+
+	if action == "decline" {
+		declineLateRSVPRequest(w, r, store, email)
+		return
+	}
+
+	rsvps, err := store.VerifyRSVPs(email, shared.RSVPUpdate{})
+	if err != nil {
+		log.Printf("Error verifying RSVP: %v", err)
+		showErrorPage(w, r, "Failed to update RSVP")
+		return
+	}
+
+	log.Printf("✓ RSVP verified for email: %s", email)
+
+	// The confirmation is built from the row as stored, falling back to the
+	// one VerifyRSVPs returned if it can't be read back
+	var confirmed *shared.RSVPRecord
+	if len(rsvps) > 0 {
+		confirmed = &rsvps[0]
+	}
+	if latest, err := store.LatestRSVP(email); err != nil {
+		log.Printf("Error loading verified RSVP for %s: %v", email, err)
+	} else if latest != nil {
+		if err := shared.SyncVerifiedDietary(store, *latest, *latest); err != nil {
+			log.Printf("Failed to save guest dietary requirements: %v", err)
+		}
+		confirmed = latest
+	}
+
+	// Send confirmation email to the now-verified guest
+	if confirmed != nil {
+		if err := shared.SendConfirmationEmail(shared.ConfirmationRSVP(*confirmed)); err != nil {
+			log.Printf("Failed to send confirmation email after verification: %v", err)
+		} else {
+			log.Printf("✓ Sent confirmation email to verified guest: %s", email)
+		}
+	}
+
+	// Return appropriate response based on request method
+	if isFormPost(r) {
+		showSuccessPage(w, r, email, "verify")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": "RSVP verified successfully",
+		})
+	}
+}
+
+// declineLateRSVPRequest handles the "decline" link in a late RSVP
+// notification: the request is removed and the guest emailed
+func declineLateRSVPRequest(w http.ResponseWriter, r *http.Request, store shared.Store, email string) {
+	pending, err := findPendingLateRSVP(store, email)
+	if err != nil {
+		log.Printf("Error looking up late RSVP: %v", err)
+		showErrorPage(w, r, "Failed to look up RSVP")
+		return
+	}
+	if pending == nil {
+		showErrorPage(w, r, "No pending late RSVP request for that email - it may already have been approved or declined")
+		return
+	}
+
+	if _, err := declineLateRSVP(store, pending); err != nil {
+		log.Printf("Error declining late RSVP: %v", err)
+		showErrorPage(w, r, "Failed to decline RSVP")
+		return
+	}
+
+	if isFormPost(r) {
+		showSuccessPage(w, r, email, "decline")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": "Late RSVP declined",
+		})
+	}
+}
+
+// isFormPost reports whether r was posted by the confirmation page's form,
+// and so should be answered with HTML rather than JSON
+func isFormPost(r *http.Request) bool {
+	return r.Method == http.MethodPost &&
+		strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded")
+}
+
+// showConfirmPage shows the page an email link opens, with a button that
+// posts the verify or decline action back to this endpoint
+func showConfirmPage(w http.ResponseWriter, r *http.Request, email, apiKey, action string) {
+	heading, question, button, color := "Verify RSVP?", "Verify the RSVP for", "Verify RSVP", "#28a745"
+	if action == "decline" {
+		heading, question, button, color = "Decline Late RSVP?", "Decline and remove the late RSVP request from", "Decline RSVP", "#dc3545"
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	page := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <title>%s</title>
+    <meta name="robots" content="noindex">
+    <style>
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif;
+            display: flex;
+            align-items: center;
+            justify-content: center;
+            min-height: 100vh;
+            margin: 0;
+            background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
+        }
+        .container {
+            background: white;
+            border-radius: 10px;
+            padding: 40px;
+            max-width: 500px;
+            text-align: center;
+            box-shadow: 0 10px 40px rgba(0,0,0,0.2);
+        }
+        h1 {
+            color: #333;
+            margin: 0 0 10px 0;
+        }
+        p {
+            color: #666;
+            line-height: 1.6;
+            margin: 20px 0;
+        }
+        .email {
+            background: #f8f9fa;
+            padding: 10px;
+            border-radius: 5px;
+            font-family: monospace;
+            color: #495057;
+        }
+        button {
+            background: %s;
+            color: white;
+            border: none;
+            border-radius: 5px;
+            padding: 12px 30px;
+            font-size: 16px;
+            cursor: pointer;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>%s</h1>
+        <p>%s <strong class="email">%s</strong>?</p>
+        <form method="POST" action="%s">
+            <input type="hidden" name="email" value="%s">
+            <input type="hidden" name="apiKey" value="%s">
+            <input type="hidden" name="action" value="%s">
+            <button type="submit">%s</button>
+        </form>
+    </div>
+</body>
+</html>
+`, heading, color, heading, question, html.EscapeString(email), html.EscapeString(r.URL.Path),
+		html.EscapeString(email), html.EscapeString(apiKey), html.EscapeString(action), button)
+	w.Write([]byte(page))
+}
+
+// showSuccessPage shows an HTML success page for a verified or declined RSVP
+func showSuccessPage(w http.ResponseWriter, r *http.Request, email, action string) {
+	heading, outcome, note := "RSVP Verified!", "has been successfully verified", "A confirmation email has been sent to the guest."
+	if action == "decline" {
+		heading, outcome, note = "Late RSVP Declined", "has been declined and removed", "The guest has been emailed to let them know."
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <title>RSVP Verified</title>
+    <style>
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif;
+            display: flex;
+            align-items: center;
+            justify-content: center;
+            min-height: 100vh;
+            margin: 0;
+            background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
+        }
+        .container {
+            background: white;
+            border-radius: 10px;
+            padding: 40px;
+            max-width: 500px;
+            text-align: center;
+            box-shadow: 0 10px 40px rgba(0,0,0,0.2);
+        }
+        .success-icon {
+            font-size: 64px;
+            color: #28a745;
+            margin-bottom: 20px;
+        }
+        h1 {
+            color: #333;
+            margin: 0 0 10px 0;
+        }
+        p {
+            color: #666;
+            line-height: 1.6;
+            margin: 20px 0;
+        }
+        .email {
+            background: #f8f9fa;
+            padding: 10px;
+            border-radius: 5px;
+            font-family: monospace;
+            color: #495057;
+            margin: 20px 0;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="success-icon">✓</div>
+        <h1>%s</h1>
+        <p>The RSVP for <strong class="email">%s</strong> %s.</p>
+        <p>%s</p>
+        <p>You can close this window now.</p>
+    </div>
+</body>
+</html>
+`, heading, html.EscapeString(email), outcome, note)
+	w.Write([]byte(page))
+}
+
+// showErrorPage shows an HTML error page
+func showErrorPage(w http.ResponseWriter, r *http.Request, message string) {
+	// If this is an API request (JSON POST), return JSON
+	if r.Method == http.MethodPost && !isFormPost(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"message": message,
+		})
+		return
+	}
+
+	// Otherwise return HTML page
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	html := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Verification Error</title>
+    <style>
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif;
+            display: flex;
+            align-items: center;
+            justify-content: center;
+            min-height: 100vh;
+            margin: 0;
+            background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
+        }
+        .container {
+            background: white;
+            border-radius: 10px;
+            padding: 40px;
+            max-width: 500px;
+            text-align: center;
+            box-shadow: 0 10px 40px rgba(0,0,0,0.2);
+        }
+        .error-icon {
+            font-size: 64px;
+            color: #dc3545;
+            margin-bottom: 20px;
+        }
+        h1 {
+            color: #333;
+            margin: 0 0 10px 0;
+        }
+        p {
+            color: #666;
+            line-height: 1.6;
+            margin: 20px 0;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="error-icon">✗</div>
+        <h1>Verification Failed</h1>
+        <p>%s</p>
+        <p>Please contact the administrator if you believe this is an error.</p>
+    </div>
+</body>
+</html>
+`, message)
+	w.Write([]byte(html))
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"utils/shared"
+)
+
+func TestVerifyRSVP(t *testing.T) {
+	t.Setenv("ADMIN_API_KEY", "verify-rsvp-key")
+	addGuests(t, shared.Guest{Name: "Vrl Ana"})
+	if err := testStore(t).SaveRSVP(shared.RSVPRequest{Name: "Vrl Ana", Email: "vrl-ana@example.com", IsAttending: true, AttendingGuests: []string{"Vrl Ana"}}); err != nil {
+		t.Fatal(err)
+	}
+	form := url.Values{"email": {"vrl-ana@example.com"}, "apiKey": {"verify-rsvp-key"}, "action": {"verify"}}
+
+	// Opening the email link only asks to confirm
+	w := serve(VerifyRSVP, http.MethodGet, "/api/verify-rsvp?"+form.Encode(), nil, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<form method="POST"`) {
+		t.Fatalf("GET status = %d, want a confirmation form: %s", w.Code, w.Body)
+	}
+	if rsvpsFor(t, "vrl-ana@example.com")[0].Verified {
+		t.Fatalf("opening the link verified the RSVP")
+	}
+
+	for _, tt := range []struct {
+		name   string
+		apiKey string
+	}{
+		{"wrong key", "wrong"},
+		{"confirmed", "verify-rsvp-key"},
+	} {
+		form.Set("apiKey", tt.apiKey)
+		r := httptest.NewRequest(http.MethodPost, "/api/verify-rsvp", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		VerifyRSVP(w, r)
+		if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+			t.Errorf("%s: Content-Type = %q, want a page", tt.name, got)
+		}
+		if verified := rsvpsFor(t, "vrl-ana@example.com")[0].Verified; verified != (tt.name == "confirmed") {
+			t.Errorf("%s: verified = %v", tt.name, verified)
+		}
+	}
+}
+
+func TestVerifyRSVPEscapesEmail(t *testing.T) {
+	t.Setenv("ADMIN_API_KEY", "verify-rsvp-key")
+	email := `<img src=x onerror=alert(1)>@example.com`
+	form := url.Values{"email": {email}, "apiKey": {"verify-rsvp-key"}, "action": {"verify"}}
+
+	r := httptest.NewRequest(http.MethodPost, "/api/verify-rsvp", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	VerifyRSVP(w, r)
+	if strings.Contains(w.Body.String(), "<img") || !strings.Contains(w.Body.String(), html.EscapeString(email)) {
+		t.Errorf("page doesn't show the email escaped: %s", w.Body)
+	}
+}
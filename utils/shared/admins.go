@@ -0,0 +1,131 @@
+package shared
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LegacyAdminUsername is the single hardcoded admin from before named
+// accounts existed. It can still log in with ADMIN_PASSWORD, as an owner,
+// until the first account is created in the admins table.
+const LegacyAdminUsername = "jemarko"
+
+// MinAdminPasswordLength is the shortest password HashPassword accepts
+const MinAdminPasswordLength = 10
+
+// AdminRole controls which admin endpoints an account may use
+type AdminRole string
+
+const (
+	// RoleViewer can load the dashboard but not change anything
+	RoleViewer AdminRole = "viewer"
+	// RoleEditor can also add guests and set or verify RSVPs
+	RoleEditor AdminRole = "editor"
+	// RoleOwner can do everything, including managing other admins
+	RoleOwner AdminRole = "owner"
+)
+
+// rank orders roles so a higher role includes everything a lower one can do
+func (r AdminRole) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleOwner:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Allows reports whether r grants at least the permissions of min
+func (r AdminRole) Allows(min AdminRole) bool {
+	return r.rank() > 0 && r.rank() >= min.rank()
+}
+
+// ParseAdminRole validates a role name
+func ParseAdminRole(s string) (AdminRole, error) {
+	role := AdminRole(strings.ToLower(strings.TrimSpace(s)))
+	if role.rank() == 0 {
+		return "", fmt.Errorf("unknown role %q (expected owner, editor or viewer)", s)
+	}
+	return role, nil
+}
+
+// AdminAccount is a row in the admins table
+type AdminAccount struct {
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"password_hash"`
+	Role         AdminRole `json:"role"`
+	CreatedAt    string    `json:"created_at,omitempty"`
+	UpdatedAt    string    `json:"updated_at,omitempty"`
+}
+
+// NormalizeUsername lowercases and trims a username so logins are
+// case-insensitive
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// HashPassword returns the bcrypt hash stored for an admin password
+func HashPassword(password string) (string, error) {
+	if len(password) < MinAdminPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", MinAdminPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %v", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches the account's hash
+func (a *AdminAccount) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(a.PasswordHash), []byte(password)) == nil
+}
+
+// AuthenticateAdmin checks username and password against the admins table
+// and returns the matching account, or nil if the credentials are wrong.
+//
+// While the table is empty, LegacyAdminUsername with ADMIN_PASSWORD is
+// accepted as an owner so existing deployments keep working until the
+// first account is created with the manage-admins tool.
+func AuthenticateAdmin(store Store, username, password string) (*AdminAccount, error) {
+	username = NormalizeUsername(username)
+
+	account, err := store.GetAdmin(username)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil {
+		if !account.CheckPassword(password) {
+			return nil, nil
+		}
+		return account, nil
+	}
+
+	admins, err := store.ListAdmins()
+	if err != nil {
+		return nil, err
+	}
+	if username != LegacyAdminUsername {
+		return nil, nil
+	}
+	if len(admins) > 0 {
+		log.Printf("⚠️  Refused legacy %s login: ADMIN_PASSWORD stops working once an admin account exists (%d configured)", LegacyAdminUsername, len(admins))
+		return nil, nil
+	}
+
+	legacyPassword := os.Getenv("ADMIN_PASSWORD")
+	if legacyPassword == "" || subtle.ConstantTimeCompare([]byte(password), []byte(legacyPassword)) != 1 {
+		return nil, nil
+	}
+	log.Println("⚠️  No admin accounts configured - accepted legacy ADMIN_PASSWORD login")
+	return &AdminAccount{Username: LegacyAdminUsername, Role: RoleOwner}, nil
+}
@@ -0,0 +1,111 @@
+package shared
+
+import "testing"
+
+func TestAdminRoleAllows(t *testing.T) {
+	tests := []struct {
+		role AdminRole
+		min  AdminRole
+		want bool
+	}{
+		{RoleViewer, RoleViewer, true},
+		{RoleViewer, RoleEditor, false},
+		{RoleEditor, RoleViewer, true},
+		{RoleEditor, RoleOwner, false},
+		{RoleOwner, RoleEditor, true},
+		{"", RoleViewer, false},
+		{"root", "root", false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.Allows(tt.min); got != tt.want {
+			t.Errorf("%q.Allows(%q) = %v, want %v", tt.role, tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestParseAdminRole(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    AdminRole
+		wantErr bool
+	}{
+		{"owner", RoleOwner, false},
+		{" Editor ", RoleEditor, false},
+		{"VIEWER", RoleViewer, false},
+		{"admin", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAdminRole(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseAdminRole(%q) = %q, %v, want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	if _, err := HashPassword("too short"); err == nil {
+		t.Errorf("HashPassword accepted a %d character password", len("too short"))
+	}
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	account := AdminAccount{PasswordHash: hash}
+	if !account.CheckPassword("correct horse") {
+		t.Errorf("CheckPassword rejected the right password")
+	}
+	if account.CheckPassword("correct horse ") || account.CheckPassword("") {
+		t.Errorf("CheckPassword accepted a wrong password")
+	}
+}
+
+func TestAuthenticateAdmin(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withAccount := NewMemoryStore(nil)
+	withAccount.SaveAdmin(AdminAccount{Username: "Ana", PasswordHash: hash, Role: RoleEditor})
+
+	tests := []struct {
+		name     string
+		store    Store
+		username string
+		password string
+		want     string // username of the account returned, "" for none
+		wantRole AdminRole
+	}{
+		{"account", withAccount, "ana", "correct horse", "ana", RoleEditor},
+		{"username any case", withAccount, " ANA ", "correct horse", "ana", RoleEditor},
+		{"wrong password", withAccount, "ana", "wrong horse", "", ""},
+		{"unknown username", withAccount, "bob", "correct horse", "", ""},
+		{"legacy login once accounts exist", withAccount, LegacyAdminUsername, "legacy password", "", ""},
+		{"legacy login before any account", NewMemoryStore(nil), LegacyAdminUsername, "legacy password", LegacyAdminUsername, RoleOwner},
+		{"legacy login, wrong password", NewMemoryStore(nil), LegacyAdminUsername, "wrong", "", ""},
+		{"other username before any account", NewMemoryStore(nil), "ana", "legacy password", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ADMIN_PASSWORD", "legacy password")
+			got, err := AuthenticateAdmin(tt.store, tt.username, tt.password)
+			if err != nil {
+				t.Fatalf("AuthenticateAdmin() error = %v", err)
+			}
+			gotName, gotRole := "", AdminRole("")
+			if got != nil {
+				gotName, gotRole = got.Username, got.Role
+			}
+			if gotName != tt.want || gotRole != tt.wantRole {
+				t.Errorf("AuthenticateAdmin(%q) = %q (%s), want %q (%s)", tt.username, gotName, gotRole, tt.want, tt.wantRole)
+			}
+		})
+	}
+
+	t.Run("legacy login without ADMIN_PASSWORD", func(t *testing.T) {
+		t.Setenv("ADMIN_PASSWORD", "")
+		if got, _ := AuthenticateAdmin(NewMemoryStore(nil), LegacyAdminUsername, ""); got != nil {
+			t.Errorf("AuthenticateAdmin accepted an empty legacy password")
+		}
+	})
+}
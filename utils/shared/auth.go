@@ -0,0 +1,171 @@
+package shared
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AdminTokenValidity is how long an admin token stays valid after login
+const AdminTokenValidity = 8 * time.Hour
+
+// AdminAuth issues and checks admin bearer tokens.
+//
+// Token format: "<username>:<role>:<unixTimestamp>.<hmac-sha256-hex>"
+//
+// The role is fixed when the token is issued, so a role change or password
+// reset takes effect at the account's next login.
+//
+// Tokens are signed with the current secret. During a key rotation the
+// previous secret is still accepted until previousUntil, so admins who
+// logged in before the rotation aren't signed out mid-session.
+type AdminAuth struct {
+	secret        []byte
+	previous      []byte
+	previousUntil time.Time
+	validity      time.Duration
+}
+
+// AdminSession identifies the admin a valid token was issued to
+type AdminSession struct {
+	Username string
+	Role     AdminRole
+}
+
+// adminContextKey is the request context key holding the AdminSession
+type adminContextKey struct{}
+
+// NewAdminAuth builds an AdminAuth from the environment:
+//   - ADMIN_TOKEN_SECRET: current signing secret. There is no fallback:
+//     admin tokens are never signed with a password.
+//   - ADMIN_TOKEN_SECRET_PREVIOUS: secret being rotated out
+//   - ADMIN_TOKEN_SECRET_PREVIOUS_UNTIL: RFC 3339 time after which the
+//     previous secret is rejected; without it the previous secret is ignored
+func NewAdminAuth() (*AdminAuth, error) {
+	secret := os.Getenv("ADMIN_TOKEN_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("ADMIN_TOKEN_SECRET not configured")
+	}
+
+	auth := &AdminAuth{secret: []byte(secret), validity: AdminTokenValidity}
+
+	if previous := os.Getenv("ADMIN_TOKEN_SECRET_PREVIOUS"); previous != "" {
+		until, err := time.Parse(time.RFC3339, os.Getenv("ADMIN_TOKEN_SECRET_PREVIOUS_UNTIL"))
+		if err != nil {
+			log.Printf("⚠️  ADMIN_TOKEN_SECRET_PREVIOUS_UNTIL missing or invalid (%v) - ignoring previous secret", err)
+		} else {
+			auth.previous = []byte(previous)
+			auth.previousUntil = until
+		}
+	}
+
+	return auth, nil
+}
+
+// IssueToken returns a new signed token for username acting as role
+func (a *AdminAuth) IssueToken(username string, role AdminRole) string {
+	payload := fmt.Sprintf("%s:%s:%d", username, role, time.Now().Unix())
+	return payload + "." + hmacSign(a.secret, payload)
+}
+
+// ValidateToken checks a token's signature and expiry and returns the
+// session it was issued for
+func (a *AdminAuth) ValidateToken(token string) (AdminSession, bool) {
+	dot := strings.LastIndex(token, ".")
+	if dot < 0 {
+		return AdminSession{}, false
+	}
+	payload, sig := token[:dot], token[dot+1:]
+
+	valid := hmac.Equal([]byte(sig), []byte(hmacSign(a.secret, payload)))
+	if !valid && a.previous != nil && time.Now().Before(a.previousUntil) {
+		valid = hmac.Equal([]byte(sig), []byte(hmacSign(a.previous, payload)))
+	}
+	if !valid {
+		return AdminSession{}, false
+	}
+
+	// Check expiry — payload is "username:role:timestamp"
+	parts := strings.Split(payload, ":")
+	if len(parts) != 3 {
+		return AdminSession{}, false
+	}
+	ts, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return AdminSession{}, false
+	}
+	if time.Since(time.Unix(ts, 0)) > a.validity {
+		return AdminSession{}, false
+	}
+	role, err := ParseAdminRole(parts[1])
+	if err != nil {
+		return AdminSession{}, false
+	}
+	return AdminSession{Username: parts[0], Role: role}, true
+}
+
+// hmacSign returns the hex HMAC-SHA256 of payload under secret
+func hmacSign(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RequireAdmin wraps an admin handler so it only runs for requests carrying
+// a valid "Authorization: Bearer <token>" header for an account with at
+// least role min. The authenticated admin is available to the handler via
+// AdminFromContext and AdminRoleFromContext.
+func RequireAdmin(min AdminRole, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, err := NewAdminAuth()
+		if err != nil {
+			log.Printf("Admin auth not configured: %v", err)
+			writeAuthError(w, http.StatusInternalServerError, "Server configuration error")
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		session, ok := auth.ValidateToken(token)
+		if token == "" || !ok {
+			log.Printf("Unauthorized admin request to %s from %s", r.URL.Path, r.RemoteAddr)
+			writeAuthError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		if !session.Role.Allows(min) {
+			log.Printf("Admin %s (%s) denied access to %s (needs %s)", session.Username, session.Role, r.URL.Path, min)
+			writeAuthError(w, http.StatusForbidden, fmt.Sprintf("This action requires the %s role", min))
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), adminContextKey{}, session)))
+	}
+}
+
+// AdminFromContext returns the admin username set by RequireAdmin
+func AdminFromContext(ctx context.Context) string {
+	session, _ := ctx.Value(adminContextKey{}).(AdminSession)
+	return session.Username
+}
+
+// AdminRoleFromContext returns the admin role set by RequireAdmin
+func AdminRoleFromContext(ctx context.Context) AdminRole {
+	session, _ := ctx.Value(adminContextKey{}).(AdminSession)
+	return session.Role
+}
+
+// writeAuthError writes the {"success": false, "message": ...} body every
+// admin endpoint uses for errors
+func writeAuthError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": message})
+}
@@ -0,0 +1,172 @@
+package shared
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACSign(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		payload string
+		want    string
+	}{
+		// RFC 4231 test case 2
+		{"rfc 4231", "Jefe", "what do ya want for nothing?", "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{"empty payload", "key", "", "5d5d139563c95b5967b9bd9a8c9b233a9dedb45072794cd232dc1b74832607d0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hmacSign([]byte(tt.secret), tt.payload); got != tt.want {
+				t.Errorf("hmacSign(%q, %q) = %s, want %s", tt.secret, tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAdminAuth(t *testing.T) {
+	tests := []struct {
+		name         string
+		secret       string
+		password     string
+		previous     string
+		until        string
+		wantSecret   string
+		wantErr      bool
+		wantPrevious bool
+	}{
+		{name: "nothing configured", wantErr: true},
+		{name: "secret", secret: "s", password: "pw", wantSecret: "s"},
+		{name: "admin password is never used", password: "pw", wantErr: true},
+		{name: "previous with until", secret: "s", previous: "p", until: "2030-01-01T00:00:00Z", wantSecret: "s", wantPrevious: true},
+		{name: "previous without until", secret: "s", previous: "p", wantSecret: "s"},
+		{name: "previous with bad until", secret: "s", previous: "p", until: "tomorrow", wantSecret: "s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ADMIN_TOKEN_SECRET", tt.secret)
+			t.Setenv("ADMIN_TOKEN_SECRET_PREVIOUS", tt.previous)
+			t.Setenv("ADMIN_TOKEN_SECRET_PREVIOUS_UNTIL", tt.until)
+			t.Setenv("ADMIN_PASSWORD", tt.password)
+
+			auth, err := NewAdminAuth()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAdminAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if string(auth.secret) != tt.wantSecret {
+				t.Errorf("secret = %q, want %q", auth.secret, tt.wantSecret)
+			}
+			if got := auth.previous != nil; got != tt.wantPrevious {
+				t.Errorf("previous secret kept = %v, want %v", got, tt.wantPrevious)
+			}
+		})
+	}
+}
+
+// signAdminToken builds a token as IssueToken would, issued at issued
+func signAdminToken(secret, username, role string, issued time.Time) string {
+	payload := fmt.Sprintf("%s:%s:%d", username, role, issued.Unix())
+	return payload + "." + hmacSign([]byte(secret), payload)
+}
+
+func TestAdminAuthValidateToken(t *testing.T) {
+	now := time.Now()
+	auth := &AdminAuth{
+		secret:        []byte("current"),
+		previous:      []byte("previous"),
+		previousUntil: now.Add(time.Hour),
+		validity:      AdminTokenValidity,
+	}
+	rotated := &AdminAuth{
+		secret:        []byte("current"),
+		previous:      []byte("previous"),
+		previousUntil: now.Add(-time.Minute),
+		validity:      AdminTokenValidity,
+	}
+
+	tests := []struct {
+		name  string
+		auth  *AdminAuth
+		token string
+		want  AdminSession
+		ok    bool
+	}{
+		{"issued", auth, auth.IssueToken("ana", RoleEditor), AdminSession{"ana", RoleEditor}, true},
+		{"current secret", auth, signAdminToken("current", "ana", "owner", now), AdminSession{"ana", RoleOwner}, true},
+		{"previous secret during rotation", auth, signAdminToken("previous", "ana", "viewer", now), AdminSession{"ana", RoleViewer}, true},
+		{"previous secret after rotation", rotated, signAdminToken("previous", "ana", "viewer", now), AdminSession{}, false},
+		{"unknown secret", auth, signAdminToken("other", "ana", "viewer", now), AdminSession{}, false},
+		{"expired", auth, signAdminToken("current", "ana", "viewer", now.Add(-AdminTokenValidity-time.Minute)), AdminSession{}, false},
+		{"nearly expired", auth, signAdminToken("current", "ana", "viewer", now.Add(-AdminTokenValidity+time.Minute)), AdminSession{"ana", RoleViewer}, true},
+		{"unknown role", auth, signAdminToken("current", "ana", "root", now), AdminSession{}, false},
+		{"bad timestamp", auth, "ana:viewer:soon." + hmacSign([]byte("current"), "ana:viewer:soon"), AdminSession{}, false},
+		{"no role", auth, "ana:1." + hmacSign([]byte("current"), "ana:1"), AdminSession{}, false},
+		{"extra field", auth, "a:b:viewer:1." + hmacSign([]byte("current"), "a:b:viewer:1"), AdminSession{}, false},
+		{"no signature", auth, "ana:viewer:1", AdminSession{}, false},
+		{"empty", auth, "", AdminSession{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.auth.ValidateToken(tt.token)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("ValidateToken(%q) = %+v, %v, want %+v, %v", tt.token, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		token := auth.IssueToken("ana", RoleViewer)
+		tampered := strings.Replace(token, ":viewer:", ":owner:", 1)
+		if _, ok := auth.ValidateToken(tampered); ok {
+			t.Errorf("ValidateToken accepted a token with its role changed")
+		}
+	})
+}
+
+func TestRequireAdmin(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN_SECRET", "current")
+	t.Setenv("ADMIN_TOKEN_SECRET_PREVIOUS", "")
+	auth := &AdminAuth{secret: []byte("current"), validity: AdminTokenValidity}
+
+	tests := []struct {
+		name       string
+		header     string
+		min        AdminRole
+		wantStatus int
+		want       AdminSession
+	}{
+		{"viewer may view", "Bearer " + auth.IssueToken("ana", RoleViewer), RoleViewer, http.StatusOK, AdminSession{"ana", RoleViewer}},
+		{"owner may edit", "Bearer " + auth.IssueToken("ana", RoleOwner), RoleEditor, http.StatusOK, AdminSession{"ana", RoleOwner}},
+		{"viewer may not edit", "Bearer " + auth.IssueToken("ana", RoleViewer), RoleEditor, http.StatusForbidden, AdminSession{}},
+		{"editor may not manage", "Bearer " + auth.IssueToken("ana", RoleEditor), RoleOwner, http.StatusForbidden, AdminSession{}},
+		{"no header", "", RoleViewer, http.StatusUnauthorized, AdminSession{}},
+		{"not bearer", auth.IssueToken("ana", RoleOwner) + "x", RoleViewer, http.StatusUnauthorized, AdminSession{}},
+		{"other secret", "Bearer " + signAdminToken("other", "ana", "owner", time.Now()), RoleViewer, http.StatusUnauthorized, AdminSession{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got AdminSession
+			handler := RequireAdmin(tt.min, func(w http.ResponseWriter, r *http.Request) {
+				got = AdminSession{AdminFromContext(r.Context()), AdminRoleFromContext(r.Context())}
+			})
+			r := httptest.NewRequest(http.MethodGet, "/api/admin-dashboard", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.wantStatus || got != tt.want {
+				t.Errorf("status = %d, admin = %+v, want %d, %+v", w.Code, got, tt.wantStatus, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package shared
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// checkInPassPurpose is mixed into every signature so a check-in pass can
+// never be passed off as an RSVP link or admin token
+const checkInPassPurpose = "check-in"
+
+// checkInPassSigLength is how many hex digits of the signature a pass
+// keeps: 128 bits is plenty against forgery, and a shorter pass makes for a
+// QR code that still scans when printed small
+const checkInPassSigLength = 32
+
+// CheckInPassSigner issues and checks the signed tokens on guests' check-in
+// passes. Passes are printed ahead of the day, so they don't expire.
+//
+// Token format: "<guestID>.<hmac-sha256-hex, truncated>"
+type CheckInPassSigner struct {
+	secret []byte
+}
+
+// NewCheckInPassSigner builds a signer from CHECKIN_PASS_SECRET. It has no
+// fallback: passes are printed and can't be recalled, so they mustn't
+// depend on a secret rotated for another purpose.
+func NewCheckInPassSigner() (*CheckInPassSigner, error) {
+	secret := os.Getenv("CHECKIN_PASS_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("CHECKIN_PASS_SECRET not configured")
+	}
+	return &CheckInPassSigner{secret: []byte(secret)}, nil
+}
+
+// Sign returns the check-in pass token for the guest with guestID
+func (s *CheckInPassSigner) Sign(guestID string) string {
+	return guestID + "." + s.signature(guestID)
+}
+
+// Verify checks a pass token's signature and returns the guest ID it was
+// issued for
+func (s *CheckInPassSigner) Verify(token string) (string, bool) {
+	dot := strings.LastIndex(token, ".")
+	if dot <= 0 {
+		return "", false
+	}
+	guestID, sig := token[:dot], token[dot+1:]
+	if !hmac.Equal([]byte(sig), []byte(s.signature(guestID))) {
+		return "", false
+	}
+	return guestID, true
+}
+
+func (s *CheckInPassSigner) signature(guestID string) string {
+	return hmacSign(s.secret, checkInPassPurpose+":"+guestID)[:checkInPassSigLength]
+}
+
+// URL returns the link on the guest's check-in pass, which carries their
+// signed pass token for the door to scan
+func (s *CheckInPassSigner) URL(guestID string) string {
+	return siteBaseURL() + "/check-in?pass=" + url.QueryEscape(s.Sign(guestID))
+}
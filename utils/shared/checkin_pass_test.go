@@ -0,0 +1,86 @@
+package shared
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCheckInPassSignerVerify(t *testing.T) {
+	signer := &CheckInPassSigner{secret: []byte("secret")}
+	other := &CheckInPassSigner{secret: []byte("other")}
+	rsvpLink := (&RSVPLinkSigner{secret: []byte("secret"), validity: RSVPEditLinkValidity}).Sign("jane@example.com")
+	token := signer.Sign("guest-1")
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+		ok    bool
+	}{
+		{"signed", token, "guest-1", true},
+		{"uuid", signer.Sign("3f2b9c1e-8d4a-4e7f-9b6c-2a1d5e8f0c3b"), "3f2b9c1e-8d4a-4e7f-9b6c-2a1d5e8f0c3b", true},
+		{"dots in id", signer.Sign("guest.1.a"), "guest.1.a", true},
+		{"other secret", other.Sign("guest-1"), "", false},
+		{"id swapped", "guest-2" + token[len("guest-1"):], "", false},
+		{"signature cut short", token[:len(token)-1], "", false},
+		{"signature changed case", "guest-1." + strings.ToUpper(token[len("guest-1."):]), "", false},
+		{"rsvp link with the same secret", rsvpLink, "", false},
+		{"no id", token[len("guest-1"):], "", false},
+		{"no signature", "guest-1.", "", false},
+		{"no dot", "guest-1", "", false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := signer.Verify(tt.token)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Verify(%q) = %q, %v, want %q, %v", tt.token, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCheckInPassSignerURL(t *testing.T) {
+	t.Setenv("BASE_URL", "wedding.example.com/")
+	signer := &CheckInPassSigner{secret: []byte("secret")}
+
+	link, err := url.Parse(signer.URL("guest 1"))
+	if err != nil {
+		t.Fatalf("URL() is not a valid URL: %v", err)
+	}
+	if got := link.Scheme + "://" + link.Host + link.Path; got != "https://wedding.example.com/check-in" {
+		t.Errorf("URL() points at %q", got)
+	}
+	if id, ok := signer.Verify(link.Query().Get("pass")); !ok || id != "guest 1" {
+		t.Errorf("URL() pass verifies as %q, %v, want %q, true", id, ok, "guest 1")
+	}
+}
+
+func TestNewCheckInPassSigner(t *testing.T) {
+	tests := []struct {
+		name        string
+		passSecret  string
+		adminSecret string
+		wantSecret  string
+		wantErr     bool
+	}{
+		{"pass secret", "pass", "admin", "pass", false},
+		{"admin secret is never used", "", "admin", "", true},
+		{"neither", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CHECKIN_PASS_SECRET", tt.passSecret)
+			t.Setenv("ADMIN_TOKEN_SECRET", tt.adminSecret)
+
+			signer, err := NewCheckInPassSigner()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewCheckInPassSigner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && string(signer.secret) != tt.wantSecret {
+				t.Errorf("secret = %q, want %q", signer.secret, tt.wantSecret)
+			}
+		})
+	}
+}
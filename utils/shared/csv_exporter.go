@@ -0,0 +1,66 @@
+package shared
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// GuestCSVHeader returns the columns WriteGuestsCSV writes: those
+// LoadGuestsFromCSV reads, then one per invitation-only event other than
+// the ceremony (which has its own column), named after the event
+func GuestCSVHeader(events []Event) []string {
+	header := []string{"Name", "Address", "Household", "Ceremony", "Plus Ones", "Table"}
+	for _, e := range csvEventColumns(events) {
+		header = append(header, e.Name)
+	}
+	return header
+}
+
+// csvEventColumns returns the events that get a column of their own
+func csvEventColumns(events []Event) []Event {
+	var columns []Event
+	for _, e := range events {
+		if !e.AllGuests && e.ID != EventCeremony {
+			columns = append(columns, e)
+		}
+	}
+	return columns
+}
+
+// WriteGuestsCSV writes the invite list in the format LoadGuestsFromCSV
+// reads, so it can go back into the spreadsheet and be imported again
+// without changes. Guests are listed by household. Households named after
+// their address are left blank, as guests are grouped by address anyway.
+func WriteGuestsCSV(w io.Writer, guests []Guest, households []Household, events []Event) error {
+	householdNames := make(map[string]string, len(households))
+	for _, h := range households {
+		householdNames[h.ID] = h.Name
+	}
+	eventColumns := csvEventColumns(events)
+	yes := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+
+	out := csv.NewWriter(w)
+	out.Write(GuestCSVHeader(events))
+	for _, members := range GroupGuestsByHousehold(guests) {
+		for _, g := range members {
+			syncCeremony(&g)
+			household := householdNames[g.HouseholdID]
+			if NormalizeString(household) == NormalizeString(HouseholdNameForAddress(g.Address)) {
+				household = ""
+			}
+			row := []string{g.Name, g.Address, household, yes(g.Ceremony), strconv.Itoa(g.PlusOnes), g.Table}
+			for _, e := range eventColumns {
+				row = append(row, yes(HasEvent(g.Events, e.ID)))
+			}
+			out.Write(row)
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,47 @@
+package shared
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteGuestsCSVRoundTrip(t *testing.T) {
+	store := NewMemoryStore([]Guest{
+		{Name: "Jane Smith", Address: "1 High St", Household: "The Smiths", Ceremony: true, PlusOnes: 1, Table: "4"},
+		{Name: "John Smith", Address: "1 High St", Household: "The Smiths"},
+		{Name: "Bob Jones", Address: "9 Low Rd", Events: []string{"brunch"}},
+		{Name: "Đorđe Petrović, Jr"},
+	})
+	store.SaveEvent(Event{ID: "brunch", Name: "Sunday Brunch"})
+	bob, _ := store.FindGuestByName("Bob Jones")
+	bob.Events = []string{"brunch"}
+	store.UpdateGuest(*bob)
+
+	guests, _ := store.ListGuests()
+	households, _ := store.ListHouseholds()
+	events, _ := store.ListEvents()
+	var buf bytes.Buffer
+	if err := WriteGuestsCSV(&buf, guests, households, events); err != nil {
+		t.Fatalf("WriteGuestsCSV() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "Name,Address,Household,Ceremony,Plus Ones,Table,Sunday Brunch" {
+		t.Errorf("header = %s", lines[0])
+	}
+	if !strings.Contains(buf.String(), "Bob Jones,9 Low Rd,,no,0,,yes") {
+		t.Errorf("CSV = %s, want Bob with no household name (his address) and invited to brunch", buf.String())
+	}
+
+	loaded, err := LoadGuestsFromCSV(writeTestFile(t, "export.csv", buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := ImportGuests(store, loaded, ImportOptions{DryRun: true, Update: true, Prune: true})
+	if err != nil {
+		t.Fatalf("ImportGuests() error = %v", err)
+	}
+	if len(diff.Added)+len(diff.Changed)+len(diff.Removed) != 0 {
+		t.Errorf("re-importing the export changes %+v", diff)
+	}
+}
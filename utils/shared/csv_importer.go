@@ -97,23 +97,28 @@ func (db *Database) ImportGuestsToDatabase(guests []Guest) error {
 	}
 
 	// Filter out guests that already exist
-	var newGuests []GuestRecord
+	var toImport []Guest
 	skippedCount := 0
 	for _, guest := range guests {
 		if existingNames[guest.Name] {
 			skippedCount++
 			continue
 		}
-		// Always include all fields to ensure consistency
-		address := guest.Address
-		if address == "" {
-			address = "" // Ensure empty string, not null
-		}
-		newGuests = append(newGuests, GuestRecord{
-			Name:     guest.Name,
-			Address:  address,
-			Ceremony: guest.Ceremony,
-		})
+		toImport = append(toImport, guest)
+	}
+
+	// Group by the household column (or address), creating households as needed
+	if err := ResolveHouseholds(db, toImport); err != nil {
+		return err
+	}
+	// Event columns become invitations to the events of the same name
+	if err := ResolveEventInvitations(db, toImport); err != nil {
+		return err
+	}
+
+	newGuests := make([]GuestRecord, 0, len(toImport))
+	for _, guest := range toImport {
+		newGuests = append(newGuests, newGuestRecord(guest))
 	}
 
 	log.Printf("Found %d existing guests, %d new guests to import", len(existingGuests), len(newGuests))
@@ -198,18 +203,22 @@ func (db *Database) ImportGuestsToDatabase(guests []Guest) error {
 	return nil
 }
 
-// ImportCSVToDatabase loads a CSV file and imports it to the database
-func ImportCSVToDatabase(csvFilePath string, db *Database) error {
-	// Load guests from CSV
-	guests, err := LoadGuestsFromCSV(csvFilePath)
+// ImportFileToDatabase loads an invite list (CSV, XLSX, vCard or JSON; see
+// LoadGuestsFromFile) and imports it to store as opts allows (see
+// ImportGuests), logging what was added, changed and removed. If the import
+// fails part way, the log shows what was written before it stopped and the
+// error says where.
+func ImportFileToDatabase(filePath string, store Store, opts ImportOptions) error {
+	guests, err := LoadGuestsFromFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to load CSV: %v", err)
+		return fmt.Errorf("failed to load %s: %v", filePath, err)
 	}
 
-	log.Printf("Loaded %d guests from %s", len(guests), csvFilePath)
-
-	// Import to database
-	if err := db.ImportGuestsToDatabase(guests); err != nil {
+	diff, err := ImportGuests(store, guests, opts)
+	if diff != nil {
+		LogGuestDiff(diff, opts)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to import to database: %v", err)
 	}
 
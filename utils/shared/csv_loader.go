@@ -1,7 +1,6 @@
 package shared
 
 import (
-	"encoding/csv"
 	"log"
 	"os"
 	"strconv"
@@ -32,10 +31,28 @@ func parseBool(s string) bool {
 	}
 }
 
-// LoadGuestsFromCSV loads guests from a CSV file. Columns are located by
-// header name (case-insensitive, trims whitespace/trailing "?"), so the
-// CSV can have columns in any order as long as headers include at least
-// "Name". "Address" and "Ceremony" are optional.
+// plusOneHeaders are the accepted spellings of the plus-one allowance column
+var plusOneHeaders = []string{"plus ones", "plus one", "plus_ones", "plus-ones", "plusones", "+1"}
+
+// tableHeaders are the accepted spellings of the reception table column
+var tableHeaders = []string{"table", "table number", "table name", "table_name", "table no", "table no."}
+
+// parsePlusOnes reads a plus-one allowance: a count, or a truthy value
+// (see parseBool) for a single plus-one. Anything else means none.
+func parsePlusOnes(s string) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+		if n < 0 {
+			return 0
+		}
+		return n
+	}
+	if parseBool(s) {
+		return 1
+	}
+	return 0
+}
+
+// LoadGuestsFromCSV loads guests from a CSV file (see CSVImporter)
 func LoadGuestsFromCSV(filename string) ([]Guest, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -43,16 +60,26 @@ func LoadGuestsFromCSV(filename string) ([]Guest, error) {
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	// Allow rows with a variable number of fields (defensive against trailing commas)
-	reader.FieldsPerRecord = -1
-	records, err := reader.ReadAll()
+	guests, err := CSVImporter{}.ReadGuests(file)
 	if err != nil {
 		return nil, err
 	}
 
+	log.Printf("✓ Loaded %d guests from CSV file: %s", len(guests), filename)
+	return guests, nil
+}
+
+// guestsFromRows reads guests from a table whose first row is the header.
+// Columns are located by header name (case-insensitive, trims
+// whitespace/trailing "?"), so they can be in any order as long as headers
+// include at least "Name". "Address", "Household", "Ceremony", "Plus Ones"
+// and "Table" are optional; guests without a household are grouped by
+// address. Any other column is taken to be an event, named by its header:
+// a truthy value (see parseBool) invites the guest to it. Guest.Events
+// holds those headers until ResolveEventInvitations matches them to events.
+func guestsFromRows(records [][]string) []Guest {
 	if len(records) < 2 {
-		return []Guest{}, nil
+		return []Guest{}
 	}
 
 	// Build a header → column index map
@@ -64,11 +91,41 @@ func LoadGuestsFromCSV(filename string) ([]Guest, error) {
 
 	nameIdx, hasName := colIndex["name"]
 	if !hasName {
-		log.Printf("⚠️  CSV missing required 'Name' column header: %v", header)
-		return []Guest{}, nil
+		log.Printf("⚠️  Guest list missing required 'Name' column header: %v", header)
+		return []Guest{}
 	}
 	addressIdx, hasAddress := colIndex["address"]
+	householdIdx, hasHousehold := colIndex["household"]
 	ceremonyIdx, hasCeremony := colIndex["ceremony"]
+	plusOnesIdx, hasPlusOnes := -1, false
+	for _, h := range plusOneHeaders {
+		if plusOnesIdx, hasPlusOnes = colIndex[h]; hasPlusOnes {
+			break
+		}
+	}
+	tableIdx, hasTable := -1, false
+	for _, h := range tableHeaders {
+		if tableIdx, hasTable = colIndex[h]; hasTable {
+			break
+		}
+	}
+
+	// Every column not read above may invite guests to an event
+	known := map[int]bool{nameIdx: true}
+	for _, col := range []struct {
+		idx int
+		ok  bool
+	}{{addressIdx, hasAddress}, {householdIdx, hasHousehold}, {ceremonyIdx, hasCeremony}, {plusOnesIdx, hasPlusOnes}, {tableIdx, hasTable}} {
+		if col.ok {
+			known[col.idx] = true
+		}
+	}
+	var eventCols []int
+	for i, col := range header {
+		if !known[i] && normaliseHeader(col) != "" {
+			eventCols = append(eventCols, i)
+		}
+	}
 
 	getField := func(record []string, idx int) string {
 		if idx < 0 || idx >= len(record) {
@@ -95,20 +152,45 @@ func LoadGuestsFromCSV(filename string) ([]Guest, error) {
 			address = getField(record, addressIdx)
 		}
 
+		household := ""
+		if hasHousehold {
+			household = getField(record, householdIdx)
+		}
+
 		ceremony := false
 		if hasCeremony {
 			ceremony = parseBool(getField(record, ceremonyIdx))
 		}
 
+		plusOnes := 0
+		if hasPlusOnes {
+			plusOnes = parsePlusOnes(getField(record, plusOnesIdx))
+		}
+
+		table := ""
+		if hasTable {
+			table = getField(record, tableIdx)
+		}
+
+		var events []string
+		for _, idx := range eventCols {
+			if parseBool(getField(record, idx)) {
+				events = append(events, strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(header[idx]), "?")))
+			}
+		}
+
 		guests = append(guests, Guest{
-			ID:       strconv.Itoa(idCounter),
-			Name:     name,
-			Address:  address,
-			Ceremony: ceremony,
+			ID:        strconv.Itoa(idCounter),
+			Name:      name,
+			Address:   address,
+			Household: household,
+			Ceremony:  ceremony,
+			Events:    events,
+			PlusOnes:  plusOnes,
+			Table:     table,
 		})
 		idCounter++
 	}
 
-	log.Printf("✓ Loaded %d guests from CSV file: %s", len(guests), filename)
-	return guests, nil
+	return guests
 }
@@ -0,0 +1,93 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTestFile writes content to name in a temporary directory and
+// returns its path
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadGuestsFromCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []Guest
+		wantErr bool
+	}{
+		{
+			"columns in any order",
+			"Ceremony?, Plus Ones ,Address,Name,Household\nyes,2,1 High St,Jane Smith,The Smiths\nno,,n/a,Bob Jones,\n",
+			[]Guest{
+				{ID: "1", Name: "Jane Smith", Address: "1 High St", Household: "The Smiths", Ceremony: true, PlusOnes: 2},
+				{ID: "2", Name: "Bob Jones", Address: "n/a"},
+			},
+			false,
+		},
+		{
+			"name only, trailing commas and blank names",
+			"Name\nJane Smith,,\n ,\nBob Jones\n",
+			[]Guest{{ID: "1", Name: "Jane Smith"}, {ID: "2", Name: "Bob Jones"}},
+			false,
+		},
+		{"+1 header", "name,+1\nJane,yes\n", []Guest{{ID: "1", Name: "Jane", PlusOnes: 1}}, false},
+		{"table column", "Name,Table No.\nJane, 4 \nBob,\n", []Guest{{ID: "1", Name: "Jane", Table: "4"}, {ID: "2", Name: "Bob"}}, false},
+		{"event columns", "Name,Address,Welcome Drinks?,Brunch\nJane,1 High St,yes,no\nBob,,,x\n",
+			[]Guest{{ID: "1", Name: "Jane", Address: "1 High St", Events: []string{"Welcome Drinks"}}, {ID: "2", Name: "Bob"}}, false},
+		{"no name column", "Guest,Address\nJane,1 High St\n", []Guest{}, false},
+		{"header only", "Name\n", []Guest{}, false},
+		{"bad quoting", "Name\n\"Jane\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadGuestsFromCSV(writeTestFile(t, "guests.csv", tt.csv))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadGuestsFromCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("LoadGuestsFromCSV() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
+					t.Errorf("guest %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := LoadGuestsFromCSV(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Errorf("LoadGuestsFromCSV(missing) succeeded")
+	}
+}
+
+func TestParsePlusOnes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"2", 2},
+		{" 1 ", 1},
+		{"0", 0},
+		{"-1", 0},
+		{"yes", 1},
+		{"Y", 1},
+		{"no", 0},
+		{"", 0},
+		{"maybe", 0},
+	}
+	for _, tt := range tests {
+		if got := parsePlusOnes(tt.in); got != tt.want {
+			t.Errorf("parsePlusOnes(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
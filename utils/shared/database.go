@@ -20,30 +20,40 @@ type Database struct {
 
 // GuestRecord represents a guest in the database
 type GuestRecord struct {
-	ID        string `json:"id,omitempty"`
-	Name      string `json:"name"`
-	Address   string `json:"address"` // Removed omitempty - always include
-	Ceremony  bool   `json:"ceremony"`
-	CreatedAt string `json:"created_at,omitempty"`
-	Dietary   string `json:"dietary,omitempty"`
+	ID             string   `json:"id,omitempty"`
+	Name           string   `json:"name"`
+	Address        string   `json:"address"`      // Removed omitempty - always include
+	HouseholdID    *string  `json:"household_id"` // null for guests without a household
+	Ceremony       bool     `json:"ceremony"`
+	PlusOnes       int      `json:"plus_ones"`
+	TableName      string   `json:"table_name"`
+	CreatedAt      string   `json:"created_at,omitempty"`
+	Dietary        string   `json:"dietary,omitempty"`
+	DietCategories []string `json:"diet_categories,omitempty"`
+	Events         []string `json:"events"`
 }
 
 // RSVPRecord represents an RSVP submission in the database
 type RSVPRecord struct {
-	ID              string   `json:"id,omitempty"`
-	Name            string   `json:"name"`
-	Email           string   `json:"email"`
-	IsAttending     bool     `json:"is_attending"`
-	AttendingGuests []string `json:"attending_guests,omitempty"`
-	Diet            string   `json:"diet,omitempty"`
-	SubmittedAt     string   `json:"submitted_at,omitempty"`
-	Verified        bool     `json:"verified"`
+	ID              string            `json:"id,omitempty"`
+	Name            string            `json:"name"`
+	Email           string            `json:"email"`
+	IsAttending     bool              `json:"is_attending"`
+	AttendingGuests []string          `json:"attending_guests,omitempty"`
+	PlusOnes        []string          `json:"plus_ones,omitempty"`
+	GuestDiets      []GuestDiet       `json:"guest_diets,omitempty"`
+	GuestMeals      []GuestMeal       `json:"guest_meals,omitempty"`
+	GuestEvents     []EventAttendance `json:"guest_events,omitempty"`
+	Diet            string            `json:"diet,omitempty"`
+	SubmittedAt     string            `json:"submitted_at,omitempty"`
+	Verified        bool              `json:"verified"`
+	Late            bool              `json:"late"` // submitted after the RSVP deadline
+	AvatarData      []AvatarSelection `json:"avatar_data,omitempty"`
 }
 
 var (
 	guestListCache []Guest
 	guestListMutex sync.RWMutex
-	guestListOnce  sync.Once
 )
 
 // NewDatabase creates a new database connection
@@ -65,64 +75,7 @@ func (db *Database) IsConfigured() bool {
 
 // LoadGuests loads the guest list from Supabase with caching
 func (db *Database) LoadGuests() ([]Guest, error) {
-	// Try to use cache first
-	guestListMutex.RLock()
-	if len(guestListCache) > 0 {
-		guests := guestListCache
-		guestListMutex.RUnlock()
-		return guests, nil
-	}
-	guestListMutex.RUnlock()
-
-	// Load from database
-	if !db.IsConfigured() {
-		return nil, fmt.Errorf("Supabase not configured - please check SUPABASE_URL and SUPABASE_API_KEY in api/.env")
-	}
-
-	url := fmt.Sprintf("%s/rest/v1/guests?select=*", db.url)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("apikey", db.apiKey)
-	req.Header.Set("Authorization", "Bearer "+db.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := db.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch guests: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Supabase returned status %d", resp.StatusCode)
-	}
-
-	var records []GuestRecord
-	if err := json.NewDecoder(resp.Body).Decode(&records); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
-	}
-
-	// Convert to Guest format
-	guests := make([]Guest, len(records))
-	for i, record := range records {
-		guests[i] = Guest{
-			ID:       record.ID,
-			Name:     record.Name,
-			Address:  record.Address,
-			Ceremony: record.Ceremony,
-		}
-	}
-
-	// Cache the result
-	guestListMutex.Lock()
-	guestListCache = guests
-	guestListMutex.Unlock()
-
-	log.Printf("✓ Loaded %d guests from Supabase", len(guests))
-	return guests, nil
+	return LoadGuests(db)
 }
 
 // SaveRSVP saves an RSVP submission to Supabase
@@ -138,9 +91,14 @@ func (db *Database) SaveRSVP(rsvp RSVPRequest) error {
 		Email:           rsvp.Email,
 		IsAttending:     rsvp.IsAttending,
 		AttendingGuests: rsvp.AttendingGuests,
+		PlusOnes:        rsvp.PlusOnes,
+		GuestDiets:      rsvp.GuestDiets,
+		GuestMeals:      rsvp.GuestMeals,
+		GuestEvents:     rsvp.GuestEvents,
 		Diet:            rsvp.Diet,
 		SubmittedAt:     time.Now().UTC().Format(time.RFC3339),
 		Verified:        rsvp.Verified,
+		Late:            rsvp.LateRequest,
 	}
 
 	jsonData, err := json.Marshal(record)
@@ -176,9 +134,7 @@ func (db *Database) SaveRSVP(rsvp RSVPRequest) error {
 
 // ClearCache clears the guest list cache
 func (db *Database) ClearCache() {
-	guestListMutex.Lock()
-	guestListCache = []Guest{}
-	guestListMutex.Unlock()
+	ClearGuestCache()
 }
 
 // getDefaultGuestList returns a default guest list for development
@@ -187,7 +143,7 @@ func getDefaultGuestList() []Guest {
 		{ID: "1", Name: "John Smith", Address: "123 Main St, London"},
 		{ID: "2", Name: "Jane Smith", Address: "123 Main St, London"},
 		{ID: "3", Name: "Bob Johnson", Address: "456 Oak Ave, Manchester"},
-		{ID: "4", Name: "Alice Williams", Address: "789 Elm Road, Birmingham"},
+		{ID: "4", Name: "Alice Williams", Address: "789 Elm Road, Birmingham", PlusOnes: 1},
 		{ID: "5", Name: "Tom Williams", Address: "789 Elm Road, Birmingham"},
 	}
 }
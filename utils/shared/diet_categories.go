@@ -0,0 +1,84 @@
+package shared
+
+import "strings"
+
+// DietCategory is one entry in the fixed dietary taxonomy guests choose
+// from. Allergens are the 14 the UK requires caterers to declare.
+type DietCategory struct {
+	ID       string `json:"id"`
+	Label    string `json:"label"`
+	Allergen bool   `json:"allergen,omitempty"`
+}
+
+// DietCategories is the taxonomy, in the order reports list it
+var DietCategories = []DietCategory{
+	{ID: "vegetarian", Label: "Vegetarian"},
+	{ID: "vegan", Label: "Vegan"},
+	{ID: "pescatarian", Label: "Pescatarian"},
+	{ID: "halal", Label: "Halal"},
+	{ID: "kosher", Label: "Kosher"},
+	{ID: "celery", Label: "Celery", Allergen: true},
+	{ID: "gluten", Label: "Gluten", Allergen: true},
+	{ID: "crustaceans", Label: "Crustaceans", Allergen: true},
+	{ID: "eggs", Label: "Eggs", Allergen: true},
+	{ID: "fish", Label: "Fish", Allergen: true},
+	{ID: "lupin", Label: "Lupin", Allergen: true},
+	{ID: "milk", Label: "Milk", Allergen: true},
+	{ID: "molluscs", Label: "Molluscs", Allergen: true},
+	{ID: "mustard", Label: "Mustard", Allergen: true},
+	{ID: "tree_nuts", Label: "Tree nuts", Allergen: true},
+	{ID: "peanuts", Label: "Peanuts", Allergen: true},
+	{ID: "sesame", Label: "Sesame", Allergen: true},
+	{ID: "soya", Label: "Soya", Allergen: true},
+	{ID: "sulphites", Label: "Sulphites", Allergen: true},
+}
+
+// dietCategoryIndex returns the position of the category with id or label
+// s in DietCategories (case-insensitive, spaces and hyphens read as "_"),
+// or -1
+func dietCategoryIndex(s string) int {
+	key := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(s)))
+	for i, c := range DietCategories {
+		if key == c.ID || key == strings.ReplaceAll(strings.ToLower(c.Label), " ", "_") {
+			return i
+		}
+	}
+	return -1
+}
+
+// CleanDietCategories converts categories to their IDs, dropping blanks and
+// duplicates and putting them in taxonomy order. If one isn't in the
+// taxonomy it is returned as unknown.
+func CleanDietCategories(categories []string) (cleaned []string, unknown string) {
+	chosen := make([]bool, len(DietCategories))
+	for _, c := range categories {
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+		i := dietCategoryIndex(c)
+		if i < 0 {
+			return nil, strings.TrimSpace(c)
+		}
+		chosen[i] = true
+	}
+	cleaned = []string{}
+	for i, c := range DietCategories {
+		if chosen[i] {
+			cleaned = append(cleaned, c.ID)
+		}
+	}
+	return cleaned, ""
+}
+
+// DietCategoryLabels returns the labels of category IDs, e.g. "Vegan"
+func DietCategoryLabels(ids []string) []string {
+	labels := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if i := dietCategoryIndex(id); i >= 0 {
+			labels = append(labels, DietCategories[i].Label)
+		} else {
+			labels = append(labels, id)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,36 @@
+package shared
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCleanDietCategories(t *testing.T) {
+	tests := []struct {
+		name        string
+		categories  []string
+		want        []string
+		wantUnknown string
+	}{
+		{"taxonomy order", []string{"peanuts", "vegan"}, []string{"vegan", "peanuts"}, ""},
+		{"labels and spellings", []string{"Tree Nuts", "tree-nuts", " MILK "}, []string{"milk", "tree_nuts"}, ""},
+		{"blanks dropped", []string{"", " ", "halal"}, []string{"halal"}, ""},
+		{"unknown", []string{"vegan", " keto "}, nil, "keto"},
+		{"none", nil, []string{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unknown := CleanDietCategories(tt.categories)
+			if !slices.Equal(got, tt.want) || (got == nil) != (tt.want == nil) || unknown != tt.wantUnknown {
+				t.Errorf("CleanDietCategories(%q) = %q, %q, want %q, %q", tt.categories, got, unknown, tt.want, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestDietCategoryLabels(t *testing.T) {
+	got := DietCategoryLabels([]string{"vegan", "tree_nuts", "mystery"})
+	if want := []string{"Vegan", "Tree nuts", "mystery"}; !slices.Equal(got, want) {
+		t.Errorf("DietCategoryLabels() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,170 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnknownDietCategory returns the first category in diets that isn't in the
+// taxonomy (see DietCategories), or ""
+func UnknownDietCategory(diets []GuestDiet) string {
+	for _, d := range diets {
+		if _, unknown := CleanDietCategories(d.Categories); unknown != "" {
+			return unknown
+		}
+	}
+	return ""
+}
+
+// CleanGuestDiets checks per-guest dietary requirements against the people
+// on an RSVP (its attending guests and plus-ones). It returns them with
+// names in the RSVP's spelling, categories as IDs, notes trimmed and empty
+// entries dropped; a later entry for the same person replaces an earlier
+// one. If an entry names someone who isn't on the RSVP, that name is
+// returned as unknown. Categories should already have been checked with
+// UnknownDietCategory.
+func CleanGuestDiets(diets []GuestDiet, people []string) (cleaned []GuestDiet, unknown string) {
+	canonical := make(map[string]string, len(people))
+	for _, name := range people {
+		canonical[NormalizeString(name)] = strings.TrimSpace(name)
+	}
+
+	cleaned = []GuestDiet{}
+	index := make(map[string]int)
+	for _, d := range diets {
+		key := NormalizeString(d.GuestName)
+		name, ok := canonical[key]
+		if !ok {
+			return nil, strings.TrimSpace(d.GuestName)
+		}
+		categories, _ := CleanDietCategories(d.Categories)
+		diet := GuestDiet{GuestName: name, Categories: categories, Diet: strings.TrimSpace(d.Diet)}
+		if i, seen := index[key]; seen {
+			cleaned[i] = diet
+			continue
+		}
+		index[key] = len(cleaned)
+		cleaned = append(cleaned, diet)
+	}
+
+	kept := cleaned[:0]
+	for _, d := range cleaned {
+		if d.Diet != "" || len(d.Categories) > 0 {
+			kept = append(kept, d)
+		}
+	}
+	return kept, ""
+}
+
+// SaveGuestDietary stores the dietary requirements from a verified RSVP
+// against each attending guest's invite-list entry. Attending guests with no
+// entry in diets have theirs cleared; plus-ones and unlisted names are
+// skipped, as they have no entry to store against.
+func SaveGuestDietary(store Store, attendingGuests []string, diets []GuestDiet, guestList []Guest) error {
+	byName := make(map[string]GuestDiet, len(diets))
+	for _, d := range diets {
+		byName[NormalizeString(d.GuestName)] = d
+	}
+	for _, name := range attendingGuests {
+		guest := FindGuest(name, guestList)
+		if guest == nil {
+			continue
+		}
+		diet := byName[NormalizeString(name)]
+		if diet.Diet == guest.Dietary && strings.Join(diet.Categories, ",") == strings.Join(guest.DietCategories, ",") {
+			continue
+		}
+		if err := store.SetGuestDietary(guest.ID, diet.Diet, diet.Categories); err != nil {
+			return fmt.Errorf("failed to save dietary requirements for %s: %v", guest.Name, err)
+		}
+	}
+	return nil
+}
+
+// DescribeDiet summarises one person's dietary requirements, e.g.
+// "Vegan, Peanuts (no mushrooms)"
+func DescribeDiet(categories []string, notes string) string {
+	labels := strings.Join(DietCategoryLabels(categories), ", ")
+	notes = strings.TrimSpace(notes)
+	switch {
+	case labels == "":
+		return notes
+	case notes == "":
+		return labels
+	}
+	return labels + " (" + notes + ")"
+}
+
+// describeDiets summarises an RSVP's dietary requirements on one line, e.g.
+// "Jane Smith: Vegan; Party: no mushrooms", or "" if there are none
+func describeDiets(diets []GuestDiet, partyNote string) string {
+	parts := make([]string, 0, len(diets)+1)
+	for _, d := range diets {
+		parts = append(parts, d.GuestName+": "+DescribeDiet(d.Categories, d.Diet))
+	}
+	if note := strings.TrimSpace(partyNote); note != "" {
+		parts = append(parts, "Party: "+note)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SyncVerifiedDietary runs after an admin verifies an RSVP. before is the
+// RSVP as submitted and after as verified: when the admin corrected guest
+// names, per-guest diets and meal choices follow them to the corrected
+// names, and their events are reset to everything the corrected guest is
+// invited to (see AttendsEvent), since an unlisted name could only choose
+// the reception. The diets are then stored against the attending guests
+// (see SaveGuestDietary).
+func SyncVerifiedDietary(store Store, before, after RSVPRecord) error {
+	diets := after.GuestDiets
+	if len(before.AttendingGuests) == len(after.AttendingGuests) {
+		renamedDiets := make([]GuestDiet, len(diets))
+		copy(renamedDiets, diets)
+		renamedMeals := make([]GuestMeal, len(after.GuestMeals))
+		copy(renamedMeals, after.GuestMeals)
+		events := make([]EventAttendance, 0, len(after.GuestEvents))
+		renamedGuests := make(map[string]bool)
+		changed := false
+		for i, old := range before.AttendingGuests {
+			if old == after.AttendingGuests[i] {
+				continue
+			}
+			renamedGuests[NormalizeString(old)] = true
+			for j := range renamedDiets {
+				if NormalizeString(renamedDiets[j].GuestName) == NormalizeString(old) {
+					renamedDiets[j].GuestName = after.AttendingGuests[i]
+					changed = true
+				}
+			}
+			for j := range renamedMeals {
+				if NormalizeString(renamedMeals[j].GuestName) == NormalizeString(old) {
+					renamedMeals[j].GuestName = after.AttendingGuests[i]
+					changed = true
+				}
+			}
+		}
+		for _, e := range after.GuestEvents {
+			if renamedGuests[NormalizeString(e.GuestName)] {
+				changed = true
+				continue
+			}
+			events = append(events, e)
+		}
+		if changed {
+			changes := RSVPChanges{GuestDiets: renamedDiets, GuestMeals: renamedMeals, GuestEvents: events}
+			if _, err := store.UpdateRSVP(after.ID, changes); err != nil {
+				return fmt.Errorf("failed to update renamed guests: %v", err)
+			}
+			diets = renamedDiets
+		}
+	}
+
+	if !after.IsAttending {
+		return nil
+	}
+	guestList, err := store.ListGuests()
+	if err != nil {
+		return err
+	}
+	return SaveGuestDietary(store, after.AttendingGuests, diets, guestList)
+}
@@ -0,0 +1,157 @@
+package shared
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCleanGuestDiets(t *testing.T) {
+	people := []string{"Jane Smith", "Đorđe Petrović", " Sam "}
+	tests := []struct {
+		name        string
+		diets       []GuestDiet
+		want        []GuestDiet
+		wantUnknown string
+	}{
+		{"canonical names", []GuestDiet{{GuestName: "jane smith", Diet: " vegan "}, {GuestName: "Djordje Petrovic", Diet: "no nuts"}},
+			[]GuestDiet{{GuestName: "Jane Smith", Diet: "vegan"}, {GuestName: "Đorđe Petrović", Diet: "no nuts"}}, ""},
+		{"plus-one", []GuestDiet{{GuestName: "Sam", Diet: "halal"}}, []GuestDiet{{GuestName: "Sam", Diet: "halal"}}, ""},
+		{"later entry wins", []GuestDiet{{GuestName: "Jane Smith", Diet: "vegan"}, {GuestName: "JANE SMITH", Diet: "vegetarian"}}, []GuestDiet{{GuestName: "Jane Smith", Diet: "vegetarian"}}, ""},
+		{"blank dropped", []GuestDiet{{GuestName: "Jane Smith", Diet: " "}, {GuestName: "Sam", Diet: "halal"}}, []GuestDiet{{GuestName: "Sam", Diet: "halal"}}, ""},
+		{"blanked by a later entry", []GuestDiet{{GuestName: "Jane Smith", Diet: "vegan"}, {GuestName: "Jane Smith", Diet: ""}}, []GuestDiet{}, ""},
+		{"someone not coming", []GuestDiet{{GuestName: "Jane Smith", Diet: "vegan"}, {GuestName: " Eve ", Diet: "vegan"}}, nil, "Eve"},
+		{"categories as IDs", []GuestDiet{{GuestName: "Sam", Categories: []string{"Peanuts", "vegan", "peanuts", " "}}},
+			[]GuestDiet{{GuestName: "Sam", Categories: []string{"vegan", "peanuts"}}}, ""},
+		{"categories without notes kept", []GuestDiet{{GuestName: "Jane Smith", Categories: []string{"Tree nuts"}, Diet: " "}},
+			[]GuestDiet{{GuestName: "Jane Smith", Categories: []string{"tree_nuts"}}}, ""},
+		{"none", nil, []GuestDiet{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unknown := CleanGuestDiets(tt.diets, people)
+			if !equalDiets(got, tt.want) || unknown != tt.wantUnknown {
+				t.Errorf("CleanGuestDiets() = %+v, %q, want %+v, %q", got, unknown, tt.want, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+// equalDiets reports whether a and b hold the same diets, treating nil and
+// empty categories alike
+func equalDiets(a, b []GuestDiet) bool {
+	return (a == nil) == (b == nil) && slices.EqualFunc(a, b, func(x, y GuestDiet) bool {
+		return x.GuestName == y.GuestName && x.Diet == y.Diet && slices.Equal(x.Categories, y.Categories)
+	})
+}
+
+func TestUnknownDietCategory(t *testing.T) {
+	diets := []GuestDiet{{GuestName: "Jane", Categories: []string{"vegan"}}, {GuestName: "Sam", Categories: []string{"halal", " keto "}}}
+	if got := UnknownDietCategory(diets); got != "keto" {
+		t.Errorf("UnknownDietCategory() = %q, want keto", got)
+	}
+	if got := UnknownDietCategory(diets[:1]); got != "" {
+		t.Errorf("UnknownDietCategory(known) = %q, want none", got)
+	}
+}
+
+func TestDescribeDiet(t *testing.T) {
+	tests := []struct {
+		categories []string
+		notes      string
+		want       string
+	}{
+		{[]string{"vegan", "peanuts"}, " no mushrooms ", "Vegan, Peanuts (no mushrooms)"},
+		{[]string{"tree_nuts"}, "", "Tree nuts"},
+		{nil, "no mushrooms", "no mushrooms"},
+		{nil, "", ""},
+	}
+	for _, tt := range tests {
+		if got := DescribeDiet(tt.categories, tt.notes); got != tt.want {
+			t.Errorf("DescribeDiet(%v, %q) = %q, want %q", tt.categories, tt.notes, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeDiets(t *testing.T) {
+	tests := []struct {
+		diets []GuestDiet
+		party string
+		want  string
+	}{
+		{[]GuestDiet{{GuestName: "Jane Smith", Diet: "vegan"}, {GuestName: "Sam", Diet: "halal"}}, " no mushrooms ", "Jane Smith: vegan; Sam: halal; Party: no mushrooms"},
+		{[]GuestDiet{{GuestName: "Jane Smith", Categories: []string{"vegan"}, Diet: "no soy"}}, "", "Jane Smith: Vegan (no soy)"},
+		{nil, "", ""},
+	}
+	for _, tt := range tests {
+		if got := describeDiets(tt.diets, tt.party); got != tt.want {
+			t.Errorf("describeDiets(%+v, %q) = %q, want %q", tt.diets, tt.party, got, tt.want)
+		}
+	}
+}
+
+// guestDietary returns the dietary requirements stored against each guest
+func guestDietary(t *testing.T, store Store) map[string]string {
+	t.Helper()
+	guests, err := store.ListGuests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dietary := make(map[string]string, len(guests))
+	for _, g := range guests {
+		dietary[g.Name] = g.Dietary
+	}
+	return dietary
+}
+
+func TestSaveGuestDietary(t *testing.T) {
+	store := NewMemoryStore([]Guest{
+		{ID: "1", Name: "Jane Smith", Dietary: "vegetarian"},
+		{ID: "2", Name: "John Smith", Dietary: "no nuts"},
+		{ID: "3", Name: "Anna Smith", Dietary: "vegan"},
+	})
+	guests, _ := store.ListGuests()
+
+	diets := []GuestDiet{{GuestName: "Jane Smith", Categories: []string{"vegan"}, Diet: "vegan"}, {GuestName: "Sam", Diet: "halal"}}
+	if err := SaveGuestDietary(store, []string{"Jane Smith", "John Smith", "Unlisted"}, diets, guests); err != nil {
+		t.Fatalf("SaveGuestDietary() error = %v", err)
+	}
+	if jane, _ := store.FindGuestByName("Jane Smith"); !slices.Equal(jane.DietCategories, []string{"vegan"}) {
+		t.Errorf("Jane Smith categories = %v, want [vegan]", jane.DietCategories)
+	}
+	want := map[string]string{"Jane Smith": "vegan", "John Smith": "", "Anna Smith": "vegan"}
+	for name, dietary := range guestDietary(t, store) {
+		if dietary != want[name] {
+			t.Errorf("%s dietary = %q, want %q", name, dietary, want[name])
+		}
+	}
+}
+
+func TestSyncVerifiedDietary(t *testing.T) {
+	store := NewMemoryStore([]Guest{{ID: "1", Name: "Jane Smith"}, {ID: "2", Name: "John Smith"}})
+	store.SaveRSVP(RSVPRequest{Name: "Jane", Email: "jane@example.com", IsAttending: true,
+		AttendingGuests: []string{"Janey", "John Smith"}, GuestDiets: []GuestDiet{{GuestName: "Janey", Diet: "vegan"}, {GuestName: "John Smith", Diet: "no nuts"}},
+		GuestMeals:  []GuestMeal{{GuestName: "Janey", Main: "m1"}},
+		GuestEvents: []EventAttendance{{GuestName: "Janey", Events: []string{EventReception}}, {GuestName: "John Smith", Events: []string{EventReception}}}})
+	before, _ := store.LatestRSVP("jane@example.com")
+	store.VerifyRSVPs("jane@example.com", RSVPUpdate{AttendingGuests: []string{"Jane Smith", "John Smith"}})
+	after, _ := store.LatestRSVP("jane@example.com")
+
+	if err := SyncVerifiedDietary(store, *before, *after); err != nil {
+		t.Fatalf("SyncVerifiedDietary() error = %v", err)
+	}
+	want := []GuestDiet{{GuestName: "Jane Smith", Diet: "vegan"}, {GuestName: "John Smith", Diet: "no nuts"}}
+	synced, _ := store.LatestRSVP("jane@example.com")
+	if !equalDiets(synced.GuestDiets, want) {
+		t.Errorf("RSVP diets = %+v, want %+v", synced.GuestDiets, want)
+	}
+	if len(synced.GuestMeals) != 1 || synced.GuestMeals[0].GuestName != "Jane Smith" {
+		t.Errorf("RSVP meals = %+v, want Jane Smith's", synced.GuestMeals)
+	}
+	// Jane Smith's events go back to her invitation; John Smith keeps his
+	if len(synced.GuestEvents) != 1 || synced.GuestEvents[0].GuestName != "John Smith" {
+		t.Errorf("RSVP events = %+v, want John Smith's only", synced.GuestEvents)
+	}
+	if dietary := guestDietary(t, store); dietary["Jane Smith"] != "vegan" || dietary["John Smith"] != "no nuts" {
+		t.Errorf("guest dietary = %v", dietary)
+	}
+}
@@ -1,8 +1,11 @@
 package shared
 
 import (
+	"context"
 	"fmt"
+	"html"
 	"log"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -47,68 +50,63 @@ func NewEmailService() *EmailService {
 	}
 }
 
-// SendEmail sends an email using Resend SDK
-func (es *EmailService) SendEmail(to, subject, body string) error {
-	// If no API key is configured, log to console instead
-	if !es.isEnabled {
-		log.Println("⚠️  RESEND_API_KEY not configured - logging email to console instead")
-		return es.logEmailToConsole(to, subject, body)
-	}
-
-	// Construct the from field
-	from := fmt.Sprintf("%s <%s>", es.fromName, es.fromEmail)
-
-	// Create HTML version of the email
-	htmlBody := strings.ReplaceAll(body, "\n", "<br>")
-
-	// Prepare email params using Resend SDK
-	params := &resend.SendEmailRequest{
-		From:    from,
-		To:      []string{to},
-		Subject: subject,
-		Text:    body,
-		Html:    fmt.Sprintf("<div style='font-family: sans-serif;'>%s</div>", htmlBody),
-	}
+// SendEmail queues a plain text email (see queue). kind says what the
+// email is for in the outbox, e.g. "meal-reminder".
+func (es *EmailService) SendEmail(kind, to, subject, body string) error {
+	return es.queue(OutboxEmail{Kind: kind, To: to, Subject: subject, Text: body})
+}
 
-	// Send email using Resend SDK
-	sent, err := es.client.Emails.Send(params)
-	if err != nil {
-		return fmt.Errorf("failed to send email via Resend: %v", err)
-	}
+// SendHTMLEmail queues an email with separate plain text and HTML bodies
+func (es *EmailService) SendHTMLEmail(kind, to, subject, textBody, htmlBody string) error {
+	return es.queue(OutboxEmail{Kind: kind, To: to, Subject: subject, Text: textBody, HTML: htmlBody})
+}
 
-	log.Printf("✓ Email sent successfully to %s (Resend ID: %s)", to, sent.Id)
-	return nil
+// SendTemplateEmail queues an email using a Resend template
+func (es *EmailService) SendTemplateEmail(kind, to, templateName string, templateData map[string]interface{}) error {
+	return es.queue(OutboxEmail{Kind: kind, To: to, Template: templateName, TemplateData: templateData})
 }
 
-// SendTemplateEmail sends an email using a Resend template
-func (es *EmailService) SendTemplateEmail(to, templateName string, templateData map[string]interface{}) error {
+// deliver sends an email through Resend and returns Resend's ID for it.
+// Outbox emails are sent with an idempotency key, so a retry after an
+// attempt that reached Resend without our hearing back isn't sent twice.
+func (es *EmailService) deliver(email OutboxEmail) (string, error) {
 	// If no API key is configured, log to console instead
 	if !es.isEnabled {
 		log.Println("⚠️  RESEND_API_KEY not configured - logging email to console instead")
-		return es.logTemplateEmailToConsole(to, templateName, templateData)
+		if email.Template != "" {
+			return "", es.logTemplateEmailToConsole(email.To, email.Template, email.TemplateData)
+		}
+		return "", es.logEmailToConsole(email.To, email.Subject, email.Text)
 	}
 
 	// Construct the from field
-	from := fmt.Sprintf("%s <%s>", es.fromName, es.fromEmail)
-
-	// Prepare email params using Resend SDK with template
 	params := &resend.SendEmailRequest{
-		From: from,
-		To:   []string{to},
-		Template: &resend.EmailTemplate{
-			Id:        templateName,
-			Variables: templateData,
-		},
+		From: fmt.Sprintf("%s <%s>", es.fromName, es.fromEmail),
+		To:   []string{email.To},
+	}
+	switch {
+	case email.Template != "":
+		params.Template = &resend.EmailTemplate{Id: email.Template, Variables: email.TemplateData}
+	case email.HTML != "":
+		params.Subject, params.Text, params.Html = email.Subject, email.Text, email.HTML
+	default:
+		// Create HTML version of the email
+		htmlBody := strings.ReplaceAll(email.Text, "\n", "<br>")
+		params.Subject, params.Text = email.Subject, email.Text
+		params.Html = fmt.Sprintf("<div style='font-family: sans-serif;'>%s</div>", htmlBody)
 	}
 
-	// Send email using Resend SDK
-	sent, err := es.client.Emails.Send(params)
+	options := &resend.SendEmailOptions{}
+	if email.ID != "" {
+		options.IdempotencyKey = fmt.Sprintf("outbox-%s-%d", email.ID, email.Resends)
+	}
+	sent, err := es.client.Emails.SendWithOptions(context.Background(), params, options)
 	if err != nil {
-		return fmt.Errorf("failed to send template email via Resend: %v", err)
+		return "", fmt.Errorf("failed to send email via Resend: %v", err)
 	}
 
-	log.Printf("✓ Template email sent successfully to %s (Template: %s, Resend ID: %s)", to, templateName, sent.Id)
-	return nil
+	log.Printf("✓ Email sent successfully to %s (Resend ID: %s)", email.To, sent.Id)
+	return sent.Id, nil
 }
 
 // logEmailToConsole logs the email to console (for development/testing)
@@ -142,12 +140,52 @@ func (es *EmailService) logTemplateEmailToConsole(to, templateName string, templ
 	return nil
 }
 
-// SendConfirmationEmail sends a confirmation email to the guest using Resend template
+// siteBaseURL returns BASE_URL with an https:// prefix, for links in emails
+func siteBaseURL() string {
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://your-domain.vercel.app" // fallback
+	}
+	// Ensure BASE_URL has https:// prefix
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		baseURL = "https://" + baseURL
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+// ConfirmationRSVP returns the RSVP a confirmation email is sent for, as
+// stored once rsvp was verified: every guest's diets, meals and events
+// included, not just who is coming
+func ConfirmationRSVP(rsvp RSVPRecord) RSVPRequest {
+	return RSVPRequest{
+		Name:            rsvp.Name,
+		Email:           rsvp.Email,
+		IsAttending:     rsvp.IsAttending,
+		AttendingGuests: rsvp.AttendingGuests,
+		PlusOnes:        rsvp.PlusOnes,
+		GuestDiets:      rsvp.GuestDiets,
+		GuestMeals:      rsvp.GuestMeals,
+		GuestEvents:     rsvp.GuestEvents,
+		Diet:            rsvp.Diet,
+		Verified:        true,
+	}
+}
+
+// SendConfirmationEmail sends a confirmation email to the guest using Resend
+// template. The template's EDIT_RSVP_URL variable gets a signed link the
+// guest can use to change their RSVP later.
 func SendConfirmationEmail(req RSVPRequest) error {
 	emailService := NewEmailService()
 
+	templateData := map[string]interface{}{}
+	if editURL, err := RSVPEditURL(req.Email); err != nil {
+		log.Printf("⚠️  Can't sign edit link for confirmation email: %v", err)
+	} else {
+		templateData["EDIT_RSVP_URL"] = editURL
+	}
+
 	// Send email using the rsvp-confirm template
-	return emailService.SendTemplateEmail(req.Email, "rsvp-confirm", map[string]interface{}{})
+	return emailService.SendTemplateEmail("rsvp-confirmation", req.Email, "rsvp-confirm", templateData)
 }
 
 // SendUnlistedGuestNotification sends an email to admin when unlisted guest tries to RSVP
@@ -183,7 +221,7 @@ This person was not found in your guest list. You may want to:
 This is an automated notification from your wedding RSVP system.
 `, req.Name, timestamp, ipAddress, userAgent)
 
-	if err := emailService.SendEmail(adminEmail, subject, body); err != nil {
+	if err := emailService.SendEmail("unlisted-guest", adminEmail, subject, body); err != nil {
 		log.Printf("Failed to send unlisted guest notification: %v", err)
 	} else {
 		log.Printf("✓ Sent unlisted guest notification to admin for: %s", req.Name)
@@ -204,15 +242,7 @@ func SendUnverifiedRSVPNotification(req RSVPRequest) {
 		return
 	}
 
-	baseURL := os.Getenv("BASE_URL")
-	if baseURL == "" {
-		baseURL = "https://your-domain.vercel.app" // fallback
-	}
-	// Ensure BASE_URL has https:// prefix
-	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
-		baseURL = "https://" + baseURL
-	}
-
+	baseURL := siteBaseURL()
 	emailService := NewEmailService()
 	timestamp := time.Now().Format(time.RFC1123)
 
@@ -226,6 +256,9 @@ func SendUnverifiedRSVPNotification(req RSVPRequest) {
 		for i, guest := range req.AttendingGuests {
 			guestList += fmt.Sprintf("%d. %s\n", i+1, guest)
 		}
+		for i, guest := range req.PlusOnes {
+			guestList += fmt.Sprintf("%d. %s (plus-one)\n", len(req.AttendingGuests)+i+1, guest)
+		}
 	}
 
 	htmlBody := fmt.Sprintf(`
@@ -301,10 +334,10 @@ func SendUnverifiedRSVPNotification(req RSVPRequest) {
 			return ""
 		}(),
 		func() string {
-			if req.Diet != "" {
+			if diets := describeDiets(req.GuestDiets, req.Diet); diets != "" {
 				return fmt.Sprintf(`<div class="detail-row">
                 <span class="label">Dietary Requirements:</span> %s
-            </div>`, req.Diet)
+            </div>`, html.EscapeString(diets))
 			}
 			return ""
 		}(),
@@ -340,42 +373,272 @@ This is an automated notification from your wedding RSVP system.
 			return ""
 		}(),
 		func() string {
-			if req.Diet != "" {
-				return fmt.Sprintf("Dietary Requirements: %s\n", req.Diet)
+			if diets := describeDiets(req.GuestDiets, req.Diet); diets != "" {
+				return fmt.Sprintf("Dietary Requirements: %s\n", diets)
 			}
 			return ""
 		}(),
 		baseURL, req.Email, adminAPIKey)
 
-	// Send using the raw email method with HTML
-	from := fmt.Sprintf("%s <%s>", emailService.fromName, emailService.fromEmail)
+	if err := emailService.SendHTMLEmail("unverified-rsvp", adminEmail, subject, textBody, htmlBody); err != nil {
+		log.Printf("Failed to send unverified RSVP notification: %v", err)
+	} else {
+		log.Printf("✓ Sent unverified RSVP notification to admin for: %s", req.Name)
+	}
+}
 
-	if !emailService.isEnabled {
-		log.Println("⚠️  RESEND_API_KEY not configured - logging email to console instead")
-		log.Println("=" + strings.Repeat("=", 70))
-		log.Println("📧 UNVERIFIED RSVP NOTIFICATION (Console Mode)")
-		log.Println("=" + strings.Repeat("=", 70))
-		log.Printf("From: %s", from)
-		log.Printf("To: %s", adminEmail)
-		log.Printf("Subject: %s", subject)
-		log.Println(strings.Repeat("-", 72))
-		log.Println(textBody)
-		log.Println("=" + strings.Repeat("=", 70))
+// SendLateRSVPNotification sends an email to admin when a guest asks to RSVP
+// after the deadline, with buttons to approve or decline the request
+func SendLateRSVPNotification(req RSVPRequest) {
+	adminEmail := os.Getenv("ADMIN_EMAIL")
+	if adminEmail == "" {
+		log.Println("⚠️  ADMIN_EMAIL not configured - skipping late RSVP notification")
 		return
 	}
 
-	params := &resend.SendEmailRequest{
-		From:    from,
-		To:      []string{adminEmail},
-		Subject: subject,
-		Text:    textBody,
-		Html:    htmlBody,
+	adminAPIKey := os.Getenv("ADMIN_API_KEY")
+	if adminAPIKey == "" {
+		log.Println("⚠️  ADMIN_API_KEY not configured - skipping late RSVP notification")
+		return
+	}
+
+	baseURL := siteBaseURL()
+	emailService := NewEmailService()
+	timestamp := time.Now().Format(time.RFC1123)
+
+	approveURL := fmt.Sprintf("%s/api/verify-rsvp?email=%s&apiKey=%s", baseURL, url.QueryEscape(req.Email), url.QueryEscape(adminAPIKey))
+	declineURL := approveURL + "&action=decline"
+
+	subject := fmt.Sprintf("⏰ Late RSVP Request: %s", req.Name)
+
+	attendingStatus := "NOT ATTENDING"
+	guestList := ""
+	if req.IsAttending {
+		attendingStatus = "ATTENDING"
+		for i, guest := range req.AttendingGuests {
+			guestList += fmt.Sprintf("%d. %s\n", i+1, guest)
+		}
+		for i, guest := range req.PlusOnes {
+			guestList += fmt.Sprintf("%d. %s (plus-one)\n", len(req.AttendingGuests)+i+1, guest)
+		}
+	}
+
+	guestsHTML := ""
+	if req.IsAttending {
+		guestsHTML = fmt.Sprintf(`<div class="detail-row">
+                <span class="label">Guests:</span><br/>
+                <pre style="margin: 5px 0; padding: 10px; background-color: #f8f9fa; border-radius: 3px;">%s</pre>
+            </div>`, html.EscapeString(guestList))
+	}
+	dietHTML := ""
+	if diets := describeDiets(req.GuestDiets, req.Diet); diets != "" {
+		dietHTML = fmt.Sprintf(`<div class="detail-row">
+                <span class="label">Dietary Requirements:</span> %s
+            </div>`, html.EscapeString(diets))
 	}
 
-	sent, err := emailService.client.Emails.Send(params)
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #f8f9fa; padding: 20px; border-radius: 5px; margin-bottom: 20px; }
+        .details { background-color: #fff; padding: 20px; border: 1px solid #dee2e6; border-radius: 5px; margin-bottom: 20px; }
+        .detail-row { margin-bottom: 10px; }
+        .label { font-weight: bold; color: #495057; }
+        .button { display: inline-block; padding: 12px 24px; color: white; text-decoration: none; border-radius: 5px; font-weight: bold; margin: 20px 8px; }
+        .approve { background-color: #28a745; }
+        .decline { background-color: #dc3545; }
+        .footer { color: #6c757d; font-size: 0.9em; margin-top: 30px; padding-top: 20px; border-top: 1px solid #dee2e6; }
+        .warning { background-color: #fff3cd; padding: 15px; border-left: 4px solid #ffc107; margin-bottom: 20px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h2>⏰ Late RSVP Request Received</h2>
+        </div>
+
+        <div class="warning">
+            <strong>Action Required:</strong> This guest RSVPd after the deadline. Their RSVP won't count until you approve it.
+        </div>
+
+        <div class="details">
+            <div class="detail-row">
+                <span class="label">Name:</span> %s
+            </div>
+            <div class="detail-row">
+                <span class="label">Email:</span> %s
+            </div>
+            <div class="detail-row">
+                <span class="label">Status:</span> %s
+            </div>
+            <div class="detail-row">
+                <span class="label">Submitted:</span> %s
+            </div>
+            %s
+            %s
+        </div>
+
+        <div style="text-align: center;">
+            <a href="%s" class="button approve" style="color: white;">✓ Approve</a>
+            <a href="%s" class="button decline" style="color: white;">✗ Decline</a>
+        </div>
+
+        <div class="footer">
+            <p><strong>Approving</strong> adds the RSVP to your numbers and sends the guest the usual confirmation email.</p>
+            <p><strong>Declining</strong> removes the request and lets the guest know by email.</p>
+            <p>This is an automated notification from your wedding RSVP system.</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(req.Name), html.EscapeString(req.Email), attendingStatus, timestamp,
+		guestsHTML, dietHTML, approveURL, declineURL)
+
+	textBody := fmt.Sprintf(`
+Late RSVP Request Received
+
+ACTION REQUIRED: This guest RSVPd after the deadline. Their RSVP won't count until you approve it.
+
+Name: %s
+Email: %s
+Status: %s
+Submitted: %s
+%s%s
+
+To approve, open:
+%s
+
+To decline (the guest will be emailed), open:
+%s
+
+---
+This is an automated notification from your wedding RSVP system.
+`, req.Name, req.Email, attendingStatus, timestamp,
+		func() string {
+			if req.IsAttending {
+				return fmt.Sprintf("\nGuests:\n%s\n", guestList)
+			}
+			return ""
+		}(),
+		func() string {
+			if diets := describeDiets(req.GuestDiets, req.Diet); diets != "" {
+				return fmt.Sprintf("Dietary Requirements: %s\n", diets)
+			}
+			return ""
+		}(),
+		approveURL, declineURL)
+
+	if err := emailService.SendHTMLEmail("late-rsvp", adminEmail, subject, textBody, htmlBody); err != nil {
+		log.Printf("Failed to send late RSVP notification: %v", err)
+	} else {
+		log.Printf("✓ Sent late RSVP notification to admin for: %s", req.Name)
+	}
+}
+
+// SendLateRSVPDeclinedEmail lets a guest know their late RSVP request was declined
+func SendLateRSVPDeclinedEmail(req RSVPRequest) error {
+	emailService := NewEmailService()
+
+	subject := "About your RSVP"
+	body := fmt.Sprintf(`
+Hi %s,
+
+Thank you for getting in touch after the RSVP deadline. Unfortunately we
+aren't able to add you to our final numbers at this stage.
+
+We're sorry we can't accommodate you this time, and we hope to celebrate
+with you soon.
+
+With love,
+%s
+`, req.Name, emailService.fromName)
+
+	return emailService.SendEmail("late-rsvp-declined", req.Email, subject, body)
+}
+
+// SendMealReminderEmail asks a guest to choose meals for the people on
+// their RSVP who haven't chosen every course yet, with their signed link to
+// do so
+func SendMealReminderEmail(rsvp RSVPRecord, missing []string) error {
+	editURL, err := RSVPEditURL(rsvp.Email)
 	if err != nil {
-		log.Printf("Failed to send unverified RSVP notification: %v", err)
+		return fmt.Errorf("can't sign edit link: %v", err)
+	}
+	emailService := NewEmailService()
+
+	subject := "Choose your meals for the wedding"
+	body := fmt.Sprintf(`
+Hi %s,
+
+We're so glad you can make it! We're finalising the menu with the venue and
+still need meal choices for:
+
+%s
+You can choose them here:
+%s
+
+With love,
+%s
+`, rsvp.Name, "- "+strings.Join(missing, "\n- ")+"\n", editURL, emailService.fromName)
+
+	return emailService.SendEmail("meal-reminder", rsvp.Email, subject, body)
+}
+
+// SendRSVPChangedNotification tells the admin a guest edited their RSVP via
+// their magic link
+func SendRSVPChangedNotification(before, after RSVPRecord) {
+	adminEmail := os.Getenv("ADMIN_EMAIL")
+	if adminEmail == "" {
+		log.Println("⚠️  ADMIN_EMAIL not configured - skipping RSVP change notification")
+		return
+	}
+
+	emailService := NewEmailService()
+	timestamp := time.Now().Format(time.RFC1123)
+
+	describe := func(r RSVPRecord) string {
+		status := "NOT ATTENDING"
+		if r.IsAttending {
+			status = "ATTENDING: " + strings.Join(r.AttendingGuests, ", ")
+			if len(r.PlusOnes) > 0 {
+				status += " + plus-ones: " + strings.Join(r.PlusOnes, ", ")
+			}
+		}
+		diet := describeDiets(r.GuestDiets, r.Diet)
+		if diet == "" {
+			diet = "(none)"
+		}
+		avatars := make([]string, 0, len(r.AvatarData))
+		for _, a := range r.AvatarData {
+			avatars = append(avatars, fmt.Sprintf("%s: %s %q", a.GuestName, a.Avatar, a.Message))
+		}
+		return fmt.Sprintf("Status: %s\nDietary Requirements: %s\nAvatars: %s",
+			status, diet, strings.Join(avatars, "; "))
+	}
+
+	subject := fmt.Sprintf("✏️  RSVP Updated: %s", after.Name)
+	body := fmt.Sprintf(`
+Hello,
+
+%s (%s) changed their RSVP at %s.
+
+Before:
+%s
+
+After:
+%s
+
+---
+This is an automated notification from your wedding RSVP system.
+`, after.Name, after.Email, timestamp, describe(before), describe(after))
+
+	if err := emailService.SendEmail("rsvp-changed", adminEmail, subject, body); err != nil {
+		log.Printf("Failed to send RSVP change notification: %v", err)
 	} else {
-		log.Printf("✓ Sent unverified RSVP notification to admin for: %s (Resend ID: %s)", req.Name, sent.Id)
+		log.Printf("✓ Sent RSVP change notification to admin for: %s", after.Name)
 	}
 }
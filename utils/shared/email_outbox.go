@@ -0,0 +1,189 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Outbox email statuses
+const (
+	EmailQueued  = "queued"  // waiting for its first or next send attempt
+	EmailSending = "sending" // claimed by a sender until NextAttemptAt (its lease)
+	EmailSent    = "sent"    // accepted by Resend
+	EmailFailed  = "failed"  // gave up after EmailMaxAttempts
+	EmailBounced = "bounced" // sent, but the recipient's server rejected it
+)
+
+// EmailMaxAttempts is how many times an email is tried before it is marked
+// failed and left for an admin to resend
+const EmailMaxAttempts = 8
+
+// emailRetryBase and emailRetryMax bound the wait between attempts, which
+// doubles each time: 1m, 2m, 4m... so the last attempt is about 2 hours
+// after the first
+const (
+	emailRetryBase = time.Minute
+	emailRetryMax  = 6 * time.Hour
+)
+
+// emailSendLease is how long a sender's claim on an email lasts (see
+// Store.ClaimOutboxEmail). Should the sender die mid-send, ProcessEmailOutbox
+// picks the email up again once the lease runs out.
+const emailSendLease = 2 * time.Minute
+
+// OutboxEmail is an email in the outbox: everything needed to send it, and
+// how sending it has gone. An email has either a Resend Template (with
+// TemplateData) or a Subject with a Text body and optional HTML body.
+type OutboxEmail struct {
+	ID            string                 `json:"id"`
+	Kind          string                 `json:"kind"` // what the email is for, e.g. "rsvp-confirmation"
+	To            string                 `json:"recipient"`
+	Subject       string                 `json:"subject,omitempty"`
+	Text          string                 `json:"text_body,omitempty"`
+	HTML          string                 `json:"html_body,omitempty"`
+	Template      string                 `json:"template,omitempty"`
+	TemplateData  map[string]interface{} `json:"template_data,omitempty"`
+	Status        string                 `json:"status"`
+	Attempts      int                    `json:"attempts"`
+	Resends       int                    `json:"resends"` // times an admin has sent it again
+	LastError     string                 `json:"last_error,omitempty"`
+	NextAttemptAt time.Time              `json:"next_attempt_at,omitzero"`
+	ProviderID    string                 `json:"provider_id,omitempty"` // Resend's ID for the email once sent
+	CreatedAt     time.Time              `json:"created_at,omitzero"`
+	SentAt        time.Time              `json:"sent_at,omitzero"`
+}
+
+// EmailRetryDelay is how long to wait after the attempts-th failed attempt
+// before trying again
+func EmailRetryDelay(attempts int) time.Duration {
+	delay := emailRetryBase
+	for i := 1; i < attempts && delay < emailRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, emailRetryMax)
+}
+
+// queue puts email in the outbox, already claimed for sending, and tries
+// to send it straight away. If that fails it is queued for
+// ProcessEmailOutbox to retry, so the error is only logged. An email is
+// only sent without the outbox when the outbox can't be written, in which
+// case a failure to send is returned.
+func (es *EmailService) queue(email OutboxEmail) error {
+	store, err := NewStore()
+	var queued *OutboxEmail
+	if err == nil {
+		email.Status = EmailSending
+		email.NextAttemptAt = time.Now().UTC().Add(emailSendLease)
+		queued, err = store.EnqueueEmail(email)
+	}
+	if err != nil {
+		log.Printf("⚠️  Email outbox unavailable, sending %s email to %s directly: %v", email.Kind, email.To, err)
+		_, err := es.deliver(email)
+		return err
+	}
+
+	if err := es.attempt(store, queued); err != nil {
+		log.Printf("⚠️  Failed to send %s email to %s, will retry: %v", queued.Kind, queued.To, err)
+	}
+	return nil
+}
+
+// attempt sends an outbox email the caller has claimed and records how it
+// went: sent, queued for another try after EmailRetryDelay, or failed
+// after EmailMaxAttempts
+func (es *EmailService) attempt(store Store, email *OutboxEmail) error {
+	email.Attempts++
+	providerID, err := es.deliver(*email)
+	now := time.Now().UTC()
+	if err == nil {
+		email.Status, email.ProviderID, email.SentAt, email.LastError = EmailSent, providerID, now, ""
+	} else {
+		email.LastError = err.Error()
+		if email.Attempts >= EmailMaxAttempts {
+			email.Status = EmailFailed
+			log.Printf("❌ Giving up on %s email %s to %s after %d attempts: %v", email.Kind, email.ID, email.To, email.Attempts, err)
+		} else {
+			email.Status, email.NextAttemptAt = EmailQueued, now.Add(EmailRetryDelay(email.Attempts))
+		}
+	}
+	if updateErr := store.UpdateOutboxEmail(*email); updateErr != nil {
+		log.Printf("⚠️  Failed to update outbox email %s: %v", email.ID, updateErr)
+	}
+	return err
+}
+
+// EmailOutboxRun counts what one ProcessEmailOutbox run did
+type EmailOutboxRun struct {
+	Sent     int `json:"sent"`
+	Retrying int `json:"retrying"` // failed this time, to be tried again
+	Failed   int `json:"failed"`   // failed for the last time
+}
+
+// ProcessEmailOutbox tries to send up to limit queued emails that are due
+// another attempt, oldest first. Each is claimed before it is sent, so an
+// email another run or an admin resend claimed meanwhile is skipped. It is
+// run on a schedule by the email-worker endpoint (and cmd/server).
+func ProcessEmailOutbox(store Store, limit int) (EmailOutboxRun, error) {
+	var run EmailOutboxRun
+	due, err := store.DueOutboxEmails(time.Now().UTC(), limit)
+	if err != nil {
+		return run, fmt.Errorf("failed to load queued emails: %v", err)
+	}
+
+	emailService := NewEmailService()
+	for _, d := range due {
+		now := time.Now().UTC()
+		email, err := store.ClaimOutboxEmail(d.ID, []string{EmailQueued, EmailSending}, now, now.Add(emailSendLease))
+		if err != nil {
+			log.Printf("⚠️  Failed to claim outbox email %s: %v", d.ID, err)
+			continue
+		}
+		if email == nil {
+			continue
+		}
+		switch err := emailService.attempt(store, email); {
+		case err == nil:
+			run.Sent++
+		case email.Status == EmailFailed:
+			run.Failed++
+		default:
+			run.Retrying++
+			log.Printf("⚠️  Attempt %d at %s email %s to %s failed, retrying in %s: %v",
+				email.Attempts, email.Kind, email.ID, email.To, EmailRetryDelay(email.Attempts), err)
+		}
+	}
+	return run, nil
+}
+
+// ResendOutboxEmail sends the sent, failed or bounced email with id again
+// straight away, with a fresh set of attempts, and returns it as updated.
+// It returns nil if the email is queued or being sent, which leaves it to
+// whoever claimed it. If sending fails the email is returned along with
+// the error, queued for the worker to retry.
+func ResendOutboxEmail(store Store, id string) (*OutboxEmail, error) {
+	now := time.Now().UTC()
+	email, err := store.ClaimOutboxEmail(id, []string{EmailSent, EmailFailed, EmailBounced}, now, now.Add(emailSendLease))
+	if err != nil || email == nil {
+		return nil, err
+	}
+	email.Attempts, email.LastError, email.ProviderID = 0, "", ""
+	email.SentAt = time.Time{}
+	email.Resends++
+	return email, NewEmailService().attempt(store, email)
+}
+
+// MarkEmailBounced marks the email Resend knows as providerID bounced,
+// noting why. It returns false if no outbox email has that ID.
+func MarkEmailBounced(store Store, providerID, reason string) (bool, error) {
+	email, err := store.FindOutboxEmailByProviderID(providerID)
+	if err != nil || email == nil {
+		return false, err
+	}
+	email.Status, email.LastError = EmailBounced, reason
+	if err := store.UpdateOutboxEmail(*email); err != nil {
+		return false, err
+	}
+	log.Printf("⚠️  %s email %s to %s bounced: %s", email.Kind, email.ID, email.To, reason)
+	return true, nil
+}
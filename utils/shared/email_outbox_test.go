@@ -0,0 +1,203 @@
+package shared
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEmailRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{EmailMaxAttempts, 128 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, 6 * time.Hour},
+		{1000, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := EmailRetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("EmailRetryDelay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestStoreOutbox(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+		var ids []string
+		for _, e := range []OutboxEmail{
+			{Kind: "rsvp-confirmation", To: "jane@example.com", Template: "rsvp-confirm", TemplateData: map[string]interface{}{"name": "Jane"}, Status: EmailQueued, NextAttemptAt: now},
+			{Kind: "meal-reminder", To: "bob@example.com", Subject: "Meals", Text: "Choose", Status: EmailQueued, NextAttemptAt: now.Add(time.Minute)},
+			{Kind: "late-rsvp", To: "admin@example.com", Subject: "Late", Text: "Late", HTML: "<p>Late</p>", Status: EmailQueued, NextAttemptAt: now.Add(-time.Minute)},
+		} {
+			queued, err := store.EnqueueEmail(e)
+			if err != nil {
+				t.Fatalf("EnqueueEmail(%s) error = %v", e.Kind, err)
+			}
+			if queued.ID == "" || queued.CreatedAt.IsZero() {
+				t.Fatalf("EnqueueEmail() = %+v, want an ID and creation time", queued)
+			}
+			ids = append(ids, queued.ID)
+			time.Sleep(time.Millisecond)
+		}
+
+		due, err := store.DueOutboxEmails(now, 10)
+		if err != nil {
+			t.Fatalf("DueOutboxEmails() error = %v", err)
+		}
+		if len(due) != 2 || due[0].ID != ids[0] || due[1].ID != ids[2] {
+			t.Fatalf("DueOutboxEmails() = %+v, want the first and last, oldest first", due)
+		}
+		if due[0].TemplateData["name"] != "Jane" || due[1].HTML != "<p>Late</p>" {
+			t.Errorf("DueOutboxEmails() lost the email contents: %+v", due)
+		}
+		if due, _ := store.DueOutboxEmails(now, 1); len(due) != 1 {
+			t.Errorf("DueOutboxEmails(limit 1) returned %d", len(due))
+		}
+
+		sent := due[0]
+		sent.Status, sent.Attempts, sent.ProviderID, sent.SentAt = EmailSent, 1, "re_123", now
+		if err := store.UpdateOutboxEmail(sent); err != nil {
+			t.Fatalf("UpdateOutboxEmail() error = %v", err)
+		}
+		got, err := store.GetOutboxEmail(ids[0])
+		if err != nil || got == nil || got.Status != EmailSent || got.Attempts != 1 || !got.SentAt.Equal(now) {
+			t.Errorf("GetOutboxEmail() = %+v, %v, want it sent", got, err)
+		}
+		if found, _ := store.FindOutboxEmailByProviderID("re_123"); found == nil || found.ID != ids[0] {
+			t.Errorf("FindOutboxEmailByProviderID() = %+v", found)
+		}
+		if missing, _ := store.FindOutboxEmailByProviderID("re_missing"); missing != nil {
+			t.Errorf("FindOutboxEmailByProviderID(missing) = %+v, want nil", missing)
+		}
+		if missing, _ := store.GetOutboxEmail("missing"); missing != nil {
+			t.Errorf("GetOutboxEmail(missing) = %+v, want nil", missing)
+		}
+
+		all, err := store.ListOutboxEmails()
+		if err != nil || len(all) != 3 || all[0].ID != ids[2] {
+			t.Errorf("ListOutboxEmails() = %+v, %v, want all three, newest first", all, err)
+		}
+		queued, _ := store.ListOutboxEmails(EmailQueued, EmailFailed)
+		var kinds []string
+		for _, e := range queued {
+			kinds = append(kinds, e.Kind)
+		}
+		if want := []string{"late-rsvp", "meal-reminder"}; !slices.Equal(kinds, want) {
+			t.Errorf("ListOutboxEmails(queued, failed) = %v, want %v", kinds, want)
+		}
+	})
+}
+
+func TestStoreClaimOutboxEmail(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	lease := now.Add(emailSendLease)
+	retryable := []string{EmailQueued, EmailSending}
+	finished := []string{EmailSent, EmailFailed, EmailBounced}
+
+	tests := []struct {
+		name      string
+		status    string
+		nextAt    time.Time
+		from      []string
+		wantClaim bool
+	}{
+		{"queued and due", EmailQueued, now, retryable, true},
+		{"queued for later", EmailQueued, now.Add(time.Second), retryable, false},
+		{"lease ran out", EmailSending, now.Add(-time.Second), retryable, true},
+		{"still leased", EmailSending, lease, retryable, false},
+		{"sent", EmailSent, time.Time{}, retryable, false},
+		{"resend sent", EmailSent, time.Time{}, finished, true},
+		{"resend failed", EmailFailed, now.Add(time.Hour), finished, true},
+		{"resend while queued", EmailQueued, now, finished, false},
+	}
+	forEachStore(t, func(t *testing.T, store Store) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				email, err := store.EnqueueEmail(OutboxEmail{To: "jane@example.com", Status: tt.status, NextAttemptAt: tt.nextAt})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				claimed, err := store.ClaimOutboxEmail(email.ID, tt.from, now, lease)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if (claimed != nil) != tt.wantClaim {
+					t.Fatalf("ClaimOutboxEmail() = %+v, want claimed %v", claimed, tt.wantClaim)
+				}
+				stored, _ := store.GetOutboxEmail(email.ID)
+				if !tt.wantClaim {
+					if stored.Status != tt.status || !stored.NextAttemptAt.Equal(tt.nextAt) {
+						t.Errorf("unclaimed email changed to %s until %s", stored.Status, stored.NextAttemptAt)
+					}
+					return
+				}
+				if stored.Status != EmailSending || !stored.NextAttemptAt.Equal(lease) {
+					t.Errorf("claimed email is %s until %s, want %s until %s", stored.Status, stored.NextAttemptAt, EmailSending, lease)
+				}
+				// Whoever claims second must find it taken
+				if again, _ := store.ClaimOutboxEmail(email.ID, tt.from, now, lease); again != nil {
+					t.Errorf("email was claimed twice")
+				}
+			})
+		}
+
+		t.Run("unknown id", func(t *testing.T) {
+			claimed, err := store.ClaimOutboxEmail("missing", retryable, now, lease)
+			if claimed != nil || err != nil {
+				t.Errorf("ClaimOutboxEmail() = %+v, %v, want nil, nil", claimed, err)
+			}
+		})
+	})
+}
+
+func TestProcessEmailOutbox(t *testing.T) {
+	t.Setenv("RESEND_API_KEY", "")
+	store := NewMemoryStore(nil)
+	now := time.Now().UTC()
+	for _, e := range []OutboxEmail{
+		{Kind: "meal-reminder", To: "jane@example.com", Subject: "Meals", Text: "Choose", Status: EmailQueued, NextAttemptAt: now.Add(-time.Minute)},
+		{Kind: "meal-reminder", To: "bob@example.com", Subject: "Meals", Text: "Choose", Status: EmailQueued, NextAttemptAt: now.Add(time.Hour)},
+	} {
+		if _, err := store.EnqueueEmail(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run, err := ProcessEmailOutbox(store, 10)
+	if err != nil {
+		t.Fatalf("ProcessEmailOutbox() error = %v", err)
+	}
+	if run != (EmailOutboxRun{Sent: 1}) {
+		t.Errorf("ProcessEmailOutbox() = %+v, want one sent", run)
+	}
+	sent, _ := store.ListOutboxEmails(EmailSent)
+	if len(sent) != 1 || sent[0].To != "jane@example.com" || sent[0].Attempts != 1 {
+		t.Errorf("sent emails = %+v, want Jane's after one attempt", sent)
+	}
+}
+
+func TestMarkEmailBounced(t *testing.T) {
+	store := NewMemoryStore(nil)
+	email, err := store.EnqueueEmail(OutboxEmail{Kind: "rsvp-confirmation", To: "jane@example.com", Status: EmailSent, ProviderID: "re_123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found, err := MarkEmailBounced(store, "re_123", "Mailbox full"); !found || err != nil {
+		t.Fatalf("MarkEmailBounced() = %v, %v, want true", found, err)
+	}
+	if got, _ := store.GetOutboxEmail(email.ID); got.Status != EmailBounced || got.LastError != "Mailbox full" {
+		t.Errorf("bounced email = %+v", got)
+	}
+	if found, err := MarkEmailBounced(store, "re_missing", "Bounced"); found || err != nil {
+		t.Errorf("MarkEmailBounced(missing) = %v, %v, want false", found, err)
+	}
+}
@@ -0,0 +1,38 @@
+package shared
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfirmationRSVP(t *testing.T) {
+	rsvp := RSVPRecord{
+		ID:              "r1",
+		Name:            "Jane Smith",
+		Email:           "jane@example.com",
+		IsAttending:     true,
+		AttendingGuests: []string{"Jane Smith", "John Smith"},
+		PlusOnes:        []string{"Sam"},
+		GuestDiets:      []GuestDiet{{GuestName: "John Smith", Categories: []string{"vegan"}, Diet: "no soy"}},
+		GuestMeals:      []GuestMeal{{GuestName: "Jane Smith", Main: "fish"}},
+		GuestEvents:     []EventAttendance{{GuestName: "Sam", Events: []string{EventReception}}},
+		Diet:            "high chair please",
+		SubmittedAt:     "2026-06-01T12:00:00Z",
+		Late:            true,
+	}
+	want := RSVPRequest{
+		Name:            "Jane Smith",
+		Email:           "jane@example.com",
+		IsAttending:     true,
+		AttendingGuests: []string{"Jane Smith", "John Smith"},
+		PlusOnes:        []string{"Sam"},
+		GuestDiets:      []GuestDiet{{GuestName: "John Smith", Categories: []string{"vegan"}, Diet: "no soy"}},
+		GuestMeals:      []GuestMeal{{GuestName: "Jane Smith", Main: "fish"}},
+		GuestEvents:     []EventAttendance{{GuestName: "Sam", Events: []string{EventReception}}},
+		Diet:            "high chair please",
+		Verified:        true,
+	}
+	if got := ConfirmationRSVP(rsvp); !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfirmationRSVP() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,368 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IDs of the events every store starts with. Everyone invited comes to the
+// reception; the ceremony is by invitation (Guest.Ceremony).
+const (
+	EventCeremony  = "ceremony"
+	EventReception = "reception"
+)
+
+// Event is one part of the wedding guests RSVP to
+type Event struct {
+	ID        string `json:"id"`   // short slug, e.g. "welcome-drinks"
+	Name      string `json:"name"` // display name, e.g. "Welcome Drinks"
+	Venue     string `json:"venue"`
+	StartsAt  string `json:"starts_at,omitempty"` // RFC 3339; events are listed in this order
+	Capacity  int    `json:"capacity"`            // most people who may come, 0 for no limit
+	AllGuests bool   `json:"all_guests"`          // everyone on the invite list is invited
+}
+
+// DefaultEvents are the events a new store is seeded with, matching the
+// Supabase migration that created the events table
+var DefaultEvents = []Event{
+	{ID: EventCeremony, Name: "Ceremony", Venue: "Wandsworth Town Hall", StartsAt: "2026-08-01T16:00:00+01:00"},
+	{ID: EventReception, Name: "Reception", Venue: "Sands End", StartsAt: "2026-08-01T18:00:00+01:00", AllGuests: true},
+}
+
+// EventAttendance is the events one attending guest or plus-one is coming to
+type EventAttendance struct {
+	GuestName string   `json:"guestName"`
+	Events    []string `json:"events"` // Event IDs
+}
+
+// EventSlug turns an event name into an ID: lowercase ASCII letters and
+// digits separated by single dashes
+func EventSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range NormalizeString(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// sortEvents orders events by start time, those without one last
+func sortEvents(events []Event) {
+	startsAt := func(e Event) time.Time {
+		t, _ := time.Parse(time.RFC3339, e.StartsAt)
+		return t
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := startsAt(events[i]), startsAt(events[j])
+		if a.IsZero() != b.IsZero() {
+			return b.IsZero()
+		}
+		return a.Before(b)
+	})
+}
+
+// eventIndex returns the position in events of the event with ID or name s
+// (compared after NormalizeString, or as a slug), or -1
+func eventIndex(s string, events []Event) int {
+	key, slug := NormalizeString(s), EventSlug(s)
+	for i, e := range events {
+		if slug == e.ID || key == NormalizeString(e.Name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindEvent returns the event with ID or name s, or nil
+func FindEvent(s string, events []Event) *Event {
+	if i := eventIndex(s, events); i >= 0 {
+		return &events[i]
+	}
+	return nil
+}
+
+// EventName returns the display name of the event with id, e.g. "Ceremony"
+func EventName(id string, events []Event) string {
+	if e := FindEvent(id, events); e != nil {
+		return e.Name
+	}
+	return id
+}
+
+// InvitedEvents returns the IDs of the events guest is invited to, in
+// running order: those open to all guests and those on their invitation.
+// Guests not on the list (nil) are only invited to the former.
+func InvitedEvents(guest *Guest, events []Event) []string {
+	invited := []string{}
+	for _, e := range events {
+		if e.AllGuests || (guest != nil && HasEvent(guest.Events, e.ID)) {
+			invited = append(invited, e.ID)
+		}
+	}
+	return invited
+}
+
+// HasEvent reports whether events includes id
+func HasEvent(events []string, id string) bool {
+	for _, e := range events {
+		if e == id {
+			return true
+		}
+	}
+	return false
+}
+
+// syncCeremony keeps the guest's Ceremony flag and their invitation to the
+// ceremony event in step, whichever of the two was set
+func syncCeremony(g *Guest) {
+	if g.Ceremony && !HasEvent(g.Events, EventCeremony) {
+		g.Events = append(g.Events, EventCeremony)
+	}
+	g.Ceremony = HasEvent(g.Events, EventCeremony)
+}
+
+// ResolveEventInvitations replaces the event names on each guest's
+// invitation (as read from an import file's columns) with event IDs from
+// store. Names that aren't events are dropped with a warning.
+func ResolveEventInvitations(store Store, guests []Guest) error {
+	events, err := store.ListEvents()
+	if err != nil {
+		return fmt.Errorf("failed to load events: %v", err)
+	}
+	warned := make(map[string]bool)
+	for i := range guests {
+		ids := []string{}
+		for _, name := range guests[i].Events {
+			e := FindEvent(name, events)
+			if e == nil {
+				if !warned[name] {
+					warned[name] = true
+					log.Printf("⚠️  %q isn't an event - ignoring invitations to it", name)
+				}
+				continue
+			}
+			if !HasEvent(ids, e.ID) {
+				ids = append(ids, e.ID)
+			}
+		}
+		guests[i].Events = ids
+		syncCeremony(&guests[i])
+	}
+	return nil
+}
+
+// cleanEvents converts requested to event IDs in running order, dropping
+// duplicates. If one isn't an event it is returned as unknown.
+func cleanEvents(requested []string, events []Event) (cleaned []string, unknown string) {
+	chosen := make([]bool, len(events))
+	for _, r := range requested {
+		i := eventIndex(r, events)
+		if i < 0 {
+			return nil, strings.TrimSpace(r)
+		}
+		chosen[i] = true
+	}
+	cleaned = []string{}
+	for i, e := range events {
+		if chosen[i] {
+			cleaned = append(cleaned, e.ID)
+		}
+	}
+	return cleaned, ""
+}
+
+// ResolveGuestEvents works out which events everyone coming on an RSVP
+// attends. requested may give events for any of the attending guests and
+// plus-ones; anyone left out comes to everything they are invited to.
+// Attending guests may only come to the events they are invited to (see
+// InvitedEvents), and plus-ones only to events an attending guest is coming
+// to. The result lists everyone coming, with names in the RSVP's spelling;
+// the error is guest-facing.
+func ResolveGuestEvents(requested []EventAttendance, attending, plusOnes []string, guestList []Guest, events []Event) ([]EventAttendance, error) {
+	canonical := make(map[string]string, len(attending)+len(plusOnes))
+	for _, name := range append(append([]string{}, attending...), plusOnes...) {
+		canonical[NormalizeString(name)] = strings.TrimSpace(name)
+	}
+	chosen := make(map[string][]string, len(requested))
+	for _, r := range requested {
+		key := NormalizeString(r.GuestName)
+		if _, ok := canonical[key]; !ok {
+			return nil, fmt.Errorf("Events can only be chosen for people coming: %s isn't one of them", strings.TrimSpace(r.GuestName))
+		}
+		cleaned, unknown := cleanEvents(r.Events, events)
+		if unknown != "" {
+			return nil, fmt.Errorf("Unknown event: %s", unknown)
+		}
+		chosen[key] = cleaned
+	}
+
+	resolve := func(name string, invited []string) ([]string, error) {
+		cleaned, ok := chosen[NormalizeString(name)]
+		if !ok {
+			return invited, nil
+		}
+		if len(cleaned) == 0 {
+			return nil, fmt.Errorf("Please choose at least one event for %s", name)
+		}
+		for _, e := range cleaned {
+			if !HasEvent(invited, e) {
+				return nil, fmt.Errorf("%s isn't invited to the %s", name, strings.ToLower(EventName(e, events)))
+			}
+		}
+		return cleaned, nil
+	}
+
+	resolved := make([]EventAttendance, 0, len(attending)+len(plusOnes))
+	partyEvents := make(map[string]bool)
+	for _, name := range attending {
+		attended, err := resolve(strings.TrimSpace(name), InvitedEvents(FindGuest(name, guestList), events))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range attended {
+			partyEvents[e] = true
+		}
+		resolved = append(resolved, EventAttendance{GuestName: strings.TrimSpace(name), Events: attended})
+	}
+	plusOneInvited := eventIDsIn(partyEvents, events)
+	for _, name := range plusOnes {
+		attended, err := resolve(strings.TrimSpace(name), plusOneInvited)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, EventAttendance{GuestName: strings.TrimSpace(name), Events: attended})
+	}
+	return resolved, nil
+}
+
+// eventIDsIn returns the IDs in set, in running order
+func eventIDsIn(set map[string]bool, events []Event) []string {
+	ids := []string{}
+	for _, e := range events {
+		if set[e.ID] {
+			ids = append(ids, e.ID)
+		}
+	}
+	return ids
+}
+
+// AttendsEvent reports whether name, coming on rsvp, attends event. RSVPs
+// from before events were chosen per person count everyone as coming to
+// everything they were invited to (invited).
+func AttendsEvent(rsvp RSVPRecord, name, event string, invited []string) bool {
+	key := NormalizeString(name)
+	for _, a := range rsvp.GuestEvents {
+		if NormalizeString(a.GuestName) == key {
+			return HasEvent(a.Events, event)
+		}
+	}
+	return HasEvent(invited, event)
+}
+
+// attendedEvents returns the IDs of the events name, coming on rsvp,
+// attends out of those they are invited to
+func attendedEvents(rsvp RSVPRecord, name string, invited []string) []string {
+	attended := []string{}
+	for _, e := range invited {
+		if AttendsEvent(rsvp, name, e, invited) {
+			attended = append(attended, e)
+		}
+	}
+	return attended
+}
+
+// RSVPGuestEvents returns the events everyone coming on rsvp attends, as in
+// ResolveGuestEvents: only events they are invited to count, whatever the
+// RSVP says, and plus-ones come to events an attending guest on it comes to.
+func RSVPGuestEvents(rsvp RSVPRecord, guestList []Guest, events []Event) []EventAttendance {
+	if !rsvp.IsAttending {
+		return []EventAttendance{}
+	}
+	resolved := make([]EventAttendance, 0, len(rsvp.AttendingGuests)+len(rsvp.PlusOnes))
+	partyEvents := make(map[string]bool)
+	for _, name := range rsvp.AttendingGuests {
+		attended := attendedEvents(rsvp, name, InvitedEvents(findGuestByNormalizedName(name, guestList), events))
+		for _, e := range attended {
+			partyEvents[e] = true
+		}
+		resolved = append(resolved, EventAttendance{GuestName: name, Events: attended})
+	}
+	plusOneInvited := eventIDsIn(partyEvents, events)
+	for _, name := range rsvp.PlusOnes {
+		resolved = append(resolved, EventAttendance{GuestName: name, Events: attendedEvents(rsvp, name, plusOneInvited)})
+	}
+	return resolved
+}
+
+// EventHeadcounts returns how many people are coming to each event, by
+// event ID. rsvps are newest first, so each person's newest verified RSVP
+// decides; people named in exclude (e.g. those on an RSVP being submitted)
+// aren't counted.
+func EventHeadcounts(rsvps []RSVPRecord, guestList []Guest, events []Event, exclude []string) map[string]int {
+	decided := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		decided[NormalizeString(name)] = true
+	}
+	counts := make(map[string]int, len(events))
+	for _, rsvp := range rsvps {
+		if !rsvp.Verified {
+			continue
+		}
+		if !rsvp.IsAttending {
+			decided[NormalizeString(rsvp.Name)] = true
+			continue
+		}
+		for _, a := range RSVPGuestEvents(rsvp, guestList, events) {
+			key := NormalizeString(a.GuestName)
+			if decided[key] {
+				continue
+			}
+			decided[key] = true
+			for _, e := range a.Events {
+				counts[e]++
+			}
+		}
+	}
+	return counts
+}
+
+// CheckEventCapacity returns a guest-facing error when the people in
+// attendance, coming on one RSVP, would take an event past its capacity.
+// Everyone else is counted as in EventHeadcounts.
+func CheckEventCapacity(attendance []EventAttendance, rsvps []RSVPRecord, guestList []Guest, events []Event) error {
+	names := make([]string, len(attendance))
+	for i, a := range attendance {
+		names[i] = a.GuestName
+	}
+	counts := EventHeadcounts(rsvps, guestList, events, names)
+	for _, e := range events {
+		if e.Capacity <= 0 {
+			continue
+		}
+		coming := 0
+		for _, a := range attendance {
+			if HasEvent(a.Events, e.ID) {
+				coming++
+			}
+		}
+		if coming == 0 || counts[e.ID]+coming <= e.Capacity {
+			continue
+		}
+		if left := e.Capacity - counts[e.ID]; left > 0 {
+			return fmt.Errorf("Sorry, there are only %d places left at the %s", left, strings.ToLower(e.Name))
+		}
+		return fmt.Errorf("Sorry, the %s is full", strings.ToLower(e.Name))
+	}
+	return nil
+}
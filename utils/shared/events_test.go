@@ -0,0 +1,180 @@
+package shared
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestResolveGuestEvents(t *testing.T) {
+	guestList := []Guest{
+		{ID: "1", Name: "Jane Smith", Events: []string{EventCeremony}},
+		{ID: "2", Name: "John Smith", Events: []string{EventCeremony}},
+		{ID: "3", Name: "Bob Jones"},
+	}
+	both := []string{EventCeremony, EventReception}
+	reception := []string{EventReception}
+
+	tests := []struct {
+		name      string
+		requested []EventAttendance
+		attending []string
+		plusOnes  []string
+		want      []EventAttendance
+		wantErr   bool
+	}{
+		{
+			"defaults to everything invited to",
+			nil, []string{"Jane Smith", "Bob Jones"}, []string{"Sam"},
+			[]EventAttendance{{"Jane Smith", both}, {"Bob Jones", reception}, {"Sam", both}}, false,
+		},
+		{
+			"names and IDs, any order",
+			[]EventAttendance{{"jane smith", []string{"Reception", "ceremony", "reception"}}}, []string{"Jane Smith"}, nil,
+			[]EventAttendance{{"Jane Smith", both}}, false,
+		},
+		{
+			"reception only",
+			[]EventAttendance{{"Jane Smith", reception}}, []string{"Jane Smith", "John Smith"}, nil,
+			[]EventAttendance{{"Jane Smith", reception}, {"John Smith", both}}, false,
+		},
+		{
+			"plus-ones follow the party",
+			[]EventAttendance{{"Jane Smith", reception}}, []string{"Jane Smith", "Bob Jones"}, []string{"Sam"},
+			[]EventAttendance{{"Jane Smith", reception}, {"Bob Jones", reception}, {"Sam", reception}}, false,
+		},
+		{"not invited to the ceremony", []EventAttendance{{"Bob Jones", both}}, []string{"Bob Jones"}, nil, nil, true},
+		{"unlisted guest at the ceremony", []EventAttendance{{"Stranger", both}}, []string{"Stranger"}, nil, nil, true},
+		{"plus-one without a ceremony guest", []EventAttendance{{"Sam", both}}, []string{"Bob Jones"}, []string{"Sam"}, nil, true},
+		{"no events", []EventAttendance{{"Jane Smith", nil}}, []string{"Jane Smith"}, nil, nil, true},
+		{"unknown event", []EventAttendance{{"Jane Smith", []string{"after party"}}}, []string{"Jane Smith"}, nil, nil, true},
+		{"someone not coming", []EventAttendance{{"John Smith", both}}, []string{"Jane Smith"}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveGuestEvents(tt.requested, tt.attending, tt.plusOnes, guestList, DefaultEvents)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveGuestEvents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.EqualFunc(got, tt.want, func(a, b EventAttendance) bool {
+				return a.GuestName == b.GuestName && slices.Equal(a.Events, b.Events)
+			}) {
+				t.Errorf("ResolveGuestEvents() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttendsEvent(t *testing.T) {
+	rsvp := RSVPRecord{GuestEvents: []EventAttendance{{"Jane Smith", []string{EventReception}}}}
+	invited := []string{EventCeremony, EventReception}
+	if AttendsEvent(rsvp, "jane smith", EventCeremony, invited) {
+		t.Errorf("Jane Smith attends the ceremony she turned down")
+	}
+	if !AttendsEvent(rsvp, "John Smith", EventCeremony, invited) {
+		t.Errorf("John Smith, with no events chosen, doesn't attend the ceremony he's invited to")
+	}
+	if got := EventName(EventCeremony, DefaultEvents) + "," + EventName("party", DefaultEvents); got != "Ceremony,party" {
+		t.Errorf("EventName() = %s", got)
+	}
+}
+
+func TestEventSlug(t *testing.T) {
+	tests := map[string]string{
+		"Welcome Drinks":       "welcome-drinks",
+		"  Sunday -- Brunch! ": "sunday-brunch",
+		"Đorđe's Party 2":      "dorde-s-party-2",
+		"!!!":                  "",
+	}
+	for name, want := range tests {
+		if got := EventSlug(name); got != want {
+			t.Errorf("EventSlug(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestInvitedEvents(t *testing.T) {
+	events := append([]Event{{ID: "brunch", Name: "Brunch"}}, DefaultEvents...)
+	tests := []struct {
+		name  string
+		guest *Guest
+		want  []string
+	}{
+		{"open to all", &Guest{Name: "Bob Jones"}, []string{EventReception}},
+		{"on their invitation", &Guest{Name: "Jane Smith", Events: []string{EventCeremony, "brunch"}}, []string{"brunch", EventCeremony, EventReception}},
+		{"unlisted", nil, []string{EventReception}},
+	}
+	for _, tt := range tests {
+		if got := InvitedEvents(tt.guest, events); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: InvitedEvents() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveEventInvitations(t *testing.T) {
+	store := NewMemoryStore(nil)
+	guests := []Guest{
+		{Name: "Jane Smith", Events: []string{"Ceremony", "after party", "ceremony"}},
+		{Name: "Bob Jones", Ceremony: true},
+		{Name: "Sam"},
+	}
+	if err := ResolveEventInvitations(store, guests); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range [][]string{{EventCeremony}, {EventCeremony}, {}} {
+		if !slices.Equal(guests[i].Events, want) || guests[i].Ceremony != (len(want) > 0) {
+			t.Errorf("%s events = %v (ceremony %v), want %v", guests[i].Name, guests[i].Events, guests[i].Ceremony, want)
+		}
+	}
+}
+
+func TestCheckEventCapacity(t *testing.T) {
+	events := []Event{
+		{ID: EventCeremony, Name: "Ceremony", Capacity: 3},
+		{ID: EventReception, Name: "Reception", AllGuests: true},
+	}
+	guestList := []Guest{
+		{Name: "Jane Smith", Events: []string{EventCeremony}},
+		{Name: "John Smith", Events: []string{EventCeremony}},
+		{Name: "Eve Brown", Events: []string{EventCeremony}},
+	}
+	// Newest first: Eve's decline outweighs her acceptance, and Jane's
+	// unverified RSVP doesn't count
+	rsvps := []RSVPRecord{
+		{Name: "Eve Brown", Verified: true, AttendingGuests: []string{"Eve Brown"}},
+		{Name: "Jane Smith", IsAttending: true, AttendingGuests: []string{"Jane Smith"}},
+		{Name: "John Smith", Verified: true, IsAttending: true, AttendingGuests: []string{"John Smith"}, PlusOnes: []string{"Sam"}},
+		{Name: "Eve Brown", Verified: true, IsAttending: true, AttendingGuests: []string{"Eve Brown"}},
+	}
+	if got := EventHeadcounts(rsvps, guestList, events, nil); got[EventCeremony] != 2 || got[EventReception] != 2 {
+		t.Errorf("EventHeadcounts() = %v, want 2 at each", got)
+	}
+	if got := EventHeadcounts(rsvps, guestList, events, []string{"john smith"}); got[EventCeremony] != 1 {
+		t.Errorf("EventHeadcounts(excluding John) = %v, want 1 at the ceremony", got)
+	}
+
+	tests := []struct {
+		name       string
+		attendance []EventAttendance
+		wantErr    string
+	}{
+		{"fits", []EventAttendance{{"Jane Smith", []string{EventCeremony, EventReception}}}, ""},
+		{"too many", []EventAttendance{{"Jane Smith", []string{EventCeremony}}, {"Kit", []string{EventCeremony}}},
+			"Sorry, there are only 1 places left at the ceremony"},
+		{"replacing their own RSVP", []EventAttendance{{"John Smith", []string{EventCeremony}}, {"Sam", []string{EventCeremony}}}, ""},
+		{"no limit", []EventAttendance{{"Jane Smith", []string{EventReception}}, {"Kit", []string{EventReception}}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckEventCapacity(tt.attendance, rsvps, guestList, events)
+			if got := fmt.Sprint(err); (err == nil) != (tt.wantErr == "") || (err != nil && got != tt.wantErr) {
+				t.Errorf("CheckEventCapacity() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	events[0].Capacity = 2
+	if err := CheckEventCapacity([]EventAttendance{{"Jane Smith", []string{EventCeremony}}}, rsvps, guestList, events); fmt.Sprint(err) != "Sorry, the ceremony is full" {
+		t.Errorf("CheckEventCapacity(full) = %v", err)
+	}
+}
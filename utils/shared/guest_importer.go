@@ -0,0 +1,169 @@
+package shared
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// GuestImporter reads an invite list in one file format. Guests come back
+// as LoadGuestsFromCSV returns them: numbered from 1, with Household and
+// Events holding names until ResolveHouseholds and ResolveEventInvitations
+// match them to the store.
+type GuestImporter interface {
+	ReadGuests(r io.Reader) ([]Guest, error)
+}
+
+// guestImporters maps file extensions to the importer for that format
+var guestImporters = map[string]GuestImporter{
+	".csv":   CSVImporter{},
+	".xlsx":  XLSXImporter{},
+	".xlsm":  XLSXImporter{},
+	".vcf":   VCardImporter{},
+	".vcard": VCardImporter{},
+	".json":  JSONImporter{},
+}
+
+// GuestImporterFor picks the importer for filename by its extension
+func GuestImporterFor(filename string) (GuestImporter, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if importer, ok := guestImporters[ext]; ok {
+		return importer, nil
+	}
+	exts := make([]string, 0, len(guestImporters))
+	for e := range guestImporters {
+		exts = append(exts, e)
+	}
+	sort.Strings(exts)
+	return nil, fmt.Errorf("can't import %q files - use one of %s", ext, strings.Join(exts, ", "))
+}
+
+// LoadGuestsFromFile loads guests from an invite list in any format
+// GuestImporterFor knows, chosen by the file's extension
+func LoadGuestsFromFile(filename string) ([]Guest, error) {
+	importer, err := GuestImporterFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	guests, err := importer.ReadGuests(file)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✓ Loaded %d guests from %s", len(guests), filename)
+	return guests, nil
+}
+
+// CSVImporter reads a CSV file with a header row (see guestsFromRows)
+type CSVImporter struct{}
+
+func (CSVImporter) ReadGuests(r io.Reader) ([]Guest, error) {
+	reader := csv.NewReader(r)
+	// Allow rows with a variable number of fields (defensive against trailing commas)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return guestsFromRows(records), nil
+}
+
+// XLSXImporter reads the first sheet of an Excel workbook, laid out like
+// the CSV file (see guestsFromRows). Cells are read as Excel displays them.
+type XLSXImporter struct{}
+
+func (XLSXImporter) ReadGuests(r io.Reader) ([]Guest, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workbook: %v", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return []Guest{}, nil
+	}
+	rows, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sheet %q: %v", sheets[0], err)
+	}
+	return guestsFromRows(rows), nil
+}
+
+// JSONImporter reads a JSON array of guests, or an object with one under
+// "guests", using the field names of the Guest API type:
+//
+//	[{"name": "John Smith", "address": "1 Main St", "household": "Smiths",
+//	  "ceremony": true, "events": ["Brunch"], "plusOnes": 1, "table": 4}]
+//
+// Only name is required. Other fields, such as id or dietary, are ignored.
+type JSONImporter struct{}
+
+// jsonGuest is a guest in a JSON import. Table may be a number or a string.
+type jsonGuest struct {
+	Name      string      `json:"name"`
+	Address   string      `json:"address"`
+	Household string      `json:"household"`
+	Ceremony  bool        `json:"ceremony"`
+	Events    []string    `json:"events"`
+	PlusOnes  int         `json:"plusOnes"`
+	Table     interface{} `json:"table"`
+}
+
+func (JSONImporter) ReadGuests(r io.Reader) ([]Guest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var list []jsonGuest
+	if err := json.Unmarshal(data, &list); err != nil {
+		var wrapped struct {
+			Guests []jsonGuest `json:"guests"`
+		}
+		if json.Unmarshal(data, &wrapped) != nil {
+			return nil, fmt.Errorf("expected a list of guests: %v", err)
+		}
+		list = wrapped.Guests
+	}
+
+	guests := []Guest{}
+	for _, g := range list {
+		name := strings.TrimSpace(g.Name)
+		if name == "" {
+			continue
+		}
+		table := ""
+		switch t := g.Table.(type) {
+		case string:
+			table = strings.TrimSpace(t)
+		case float64:
+			table = strconv.FormatFloat(t, 'f', -1, 64)
+		}
+		guests = append(guests, Guest{
+			ID:        strconv.Itoa(len(guests) + 1),
+			Name:      name,
+			Address:   strings.TrimSpace(g.Address),
+			Household: strings.TrimSpace(g.Household),
+			Ceremony:  g.Ceremony,
+			Events:    g.Events,
+			PlusOnes:  max(g.PlusOnes, 0),
+			Table:     table,
+		})
+	}
+	return guests, nil
+}
@@ -0,0 +1,102 @@
+package shared
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGuestImporterFor(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     GuestImporter
+	}{
+		{"guests.csv", CSVImporter{}},
+		{"Guests.XLSX", XLSXImporter{}},
+		{"contacts.vcf", VCardImporter{}},
+		{"export.json", JSONImporter{}},
+		{"guests.numbers", nil},
+		{"guests", nil},
+	}
+	for _, tt := range tests {
+		got, err := GuestImporterFor(tt.filename)
+		if got != tt.want || (err != nil) != (tt.want == nil) {
+			t.Errorf("GuestImporterFor(%q) = %T, %v, want %T", tt.filename, got, err, tt.want)
+		}
+	}
+}
+
+func TestXLSXImporter(t *testing.T) {
+	f := excelize.NewFile()
+	for i, row := range [][]interface{}{
+		{"Name", "Address", "Plus Ones", "Table", "Ceremony?", "Brunch"},
+		{"Jane Smith", "1 High St", 2, 4, "yes", "Y"},
+		{" ", "blank name"},
+		{"Bob Jones"},
+	} {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		f.SetSheetRow("Sheet1", cell, &row)
+	}
+	f.NewSheet("Ignored")
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := XLSXImporter{}.ReadGuests(&buf)
+	if err != nil {
+		t.Fatalf("ReadGuests() error = %v", err)
+	}
+	want := []Guest{
+		{ID: "1", Name: "Jane Smith", Address: "1 High St", Ceremony: true, Events: []string{"Brunch"}, PlusOnes: 2, Table: "4"},
+		{ID: "2", Name: "Bob Jones"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadGuests() = %+v, want %+v", got, want)
+	}
+
+	if _, err := (XLSXImporter{}).ReadGuests(strings.NewReader("not a workbook")); err == nil {
+		t.Errorf("ReadGuests(not a workbook) succeeded")
+	}
+}
+
+func TestJSONImporter(t *testing.T) {
+	want := []Guest{
+		{ID: "1", Name: "Jane Smith", Address: "1 High St", Household: "Smiths", Ceremony: true, Events: []string{"Brunch"}, PlusOnes: 1, Table: "4"},
+		{ID: "2", Name: "Bob Jones", Table: "Top"},
+	}
+	list := `[{"name": " Jane Smith ", "address": "1 High St", "household": "Smiths", "ceremony": true, "events": ["Brunch"], "plusOnes": 1, "table": 4},
+		{"name": ""}, {"name": "Bob Jones", "plusOnes": -2, "table": "Top", "dietary": "ignored"}]`
+	tests := []struct {
+		name    string
+		json    string
+		want    []Guest
+		wantErr bool
+	}{
+		{"list", list, want, false},
+		{"wrapped", `{"guests": ` + list + `}`, want, false},
+		{"empty", `[]`, []Guest{}, false},
+		{"not a list", `"guests"`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONImporter{}.ReadGuests(strings.NewReader(tt.json))
+			if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadGuests() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadGuestsFromFile(t *testing.T) {
+	got, err := LoadGuestsFromFile(writeTestFile(t, "guests.json", `[{"name": "Jane Smith"}]`))
+	if err != nil || len(got) != 1 || got[0].Name != "Jane Smith" {
+		t.Errorf("LoadGuestsFromFile(json) = %+v, %v", got, err)
+	}
+	if _, err := LoadGuestsFromFile(writeTestFile(t, "guests.txt", "Jane Smith")); err == nil {
+		t.Errorf("LoadGuestsFromFile(txt) succeeded")
+	}
+}
@@ -0,0 +1,318 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ImportOptions controls what ImportGuests changes. By default only guests
+// not yet on the invite list are added; changes and removals are reported.
+type ImportOptions struct {
+	DryRun bool // report what would change without writing anything
+	Update bool // update guests whose details differ from the import
+	Prune  bool // remove guests missing from the import
+	Force  bool // with Prune, also remove guests named on an RSVP
+}
+
+// GuestChange is a guest whose invite list details differ in an import
+type GuestChange struct {
+	Before Guest
+	After  Guest    // the imported details, with Before's ID
+	Fields []string // what differs, e.g. "address", "events"
+}
+
+// Differs reports whether field is one of those that differ
+func (c GuestChange) Differs(field string) bool {
+	for _, f := range c.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// GuestDiff compares the invite list with an import, matching guests by
+// name after NormalizeString
+type GuestDiff struct {
+	Added   []Guest
+	Changed []GuestChange
+	Removed []Guest
+	// Removed guests named on an RSVP, by name, who are only removed
+	// with ImportOptions.Force
+	WithRSVPs []string
+	// Imported rows repeating an earlier row's name, which are ignored
+	Duplicates []Guest
+	// How many of Added, Changed and Removed ImportGuests wrote, each from
+	// the start of the list: all it was allowed to unless it failed part
+	// way, and none on a dry run
+	Written GuestDiffCounts
+}
+
+// GuestDiffCounts counts guests in each part of a GuestDiff
+type GuestDiffCounts struct {
+	Added, Changed, Removed int
+}
+
+// DiffGuests compares the existing invite list with imported guests.
+// Imported guests' events must already be resolved to IDs (see
+// ResolveEventInvitations); households are compared by name, as the
+// import hasn't created them yet.
+func DiffGuests(existing, imported []Guest, households []Household) GuestDiff {
+	householdNames := make(map[string]string, len(households))
+	for _, h := range households {
+		householdNames[h.ID] = h.Name
+	}
+	byName := make(map[string]Guest, len(existing))
+	for _, g := range existing {
+		byName[NormalizeString(g.Name)] = g
+	}
+
+	var diff GuestDiff
+	seen := make(map[string]bool, len(imported))
+	for _, g := range imported {
+		key := NormalizeString(g.Name)
+		if seen[key] {
+			diff.Duplicates = append(diff.Duplicates, g)
+			continue
+		}
+		seen[key] = true
+		before, ok := byName[key]
+		if !ok {
+			diff.Added = append(diff.Added, g)
+			continue
+		}
+		if fields := changedFields(before, g, householdNames[before.HouseholdID]); len(fields) > 0 {
+			g.ID = before.ID
+			diff.Changed = append(diff.Changed, GuestChange{Before: before, After: g, Fields: fields})
+		}
+	}
+	for _, g := range existing {
+		if !seen[NormalizeString(g.Name)] {
+			diff.Removed = append(diff.Removed, g)
+		}
+	}
+	return diff
+}
+
+// changedFields lists the invite list details that differ between a guest
+// (in household household) and their imported record. A record with no
+// household name, neither a household nor an address to group by, keeps
+// the guest's household: it may have been made for them by the
+// invite-codes tool, and leaving it would stop their invite code working.
+func changedFields(before, after Guest, household string) []string {
+	var fields []string
+	if before.Name != strings.TrimSpace(after.Name) {
+		fields = append(fields, "name")
+	}
+	if strings.TrimSpace(before.Address) != strings.TrimSpace(after.Address) {
+		fields = append(fields, "address")
+	}
+	if name := HouseholdName(after); name != "" && NormalizeString(household) != NormalizeString(name) {
+		fields = append(fields, "household")
+	}
+	syncCeremony(&before)
+	syncCeremony(&after)
+	if !sameEvents(before.Events, after.Events) {
+		fields = append(fields, "events")
+	}
+	if before.PlusOnes != after.PlusOnes {
+		fields = append(fields, "plus ones")
+	}
+	if strings.TrimSpace(before.Table) != strings.TrimSpace(after.Table) {
+		fields = append(fields, "table")
+	}
+	return fields
+}
+
+// sameEvents reports whether a and b hold the same event IDs, in any order
+func sameEvents(a, b []string) bool {
+	for _, id := range a {
+		if !HasEvent(b, id) {
+			return false
+		}
+	}
+	for _, id := range b {
+		if !HasEvent(a, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// ImportGuests brings the invite list in store in line with imported
+// guests, as far as opts allows, and returns what differed. When pruning
+// would remove guests named on an RSVP without opts.Force, nothing is
+// written and the diff is returned with an error. If a write fails, the
+// diff is returned with the error, and its Written counts say how far the
+// import got.
+func ImportGuests(store Store, imported []Guest, opts ImportOptions) (*GuestDiff, error) {
+	guests := make([]Guest, len(imported))
+	copy(guests, imported)
+	for i := range guests {
+		guests[i].Name = strings.TrimSpace(guests[i].Name)
+	}
+	// Event columns become invitations to the events of the same name
+	if err := ResolveEventInvitations(store, guests); err != nil {
+		return nil, err
+	}
+
+	existing, err := store.ListGuests()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing guests: %v", err)
+	}
+	households, err := store.ListHouseholds()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load households: %v", err)
+	}
+	diff := DiffGuests(existing, guests, households)
+
+	if len(diff.Removed) > 0 {
+		rsvps, err := store.ListRSVPs()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load RSVPs: %v", err)
+		}
+		diff.WithRSVPs = namesWithRSVPs(diff.Removed, rsvps)
+	}
+	if opts.Prune && !opts.Force && len(diff.WithRSVPs) > 0 {
+		return &diff, fmt.Errorf("refusing to remove guests named on an RSVP (%s) - use force to remove them anyway",
+			strings.Join(diff.WithRSVPs, ", "))
+	}
+	if opts.DryRun {
+		return &diff, nil
+	}
+
+	// Group by the household column (or address), creating households as needed
+	if err := ResolveHouseholds(store, diff.Added); err != nil {
+		return &diff, err
+	}
+	for _, g := range diff.Added {
+		if err := store.AddGuest(g); err != nil {
+			return &diff, fmt.Errorf("failed to add %s: %v", g.Name, err)
+		}
+		diff.Written.Added++
+	}
+
+	if opts.Update {
+		for _, c := range diff.Changed {
+			after := c.After
+			after.HouseholdID = c.Before.HouseholdID
+			if c.Differs("household") {
+				resolved := []Guest{after}
+				resolved[0].HouseholdID = ""
+				if err := ResolveHouseholds(store, resolved); err != nil {
+					return &diff, err
+				}
+				after = resolved[0]
+			}
+			if err := store.UpdateGuest(after); err != nil {
+				return &diff, fmt.Errorf("failed to update %s: %v", c.Before.Name, err)
+			}
+			diff.Written.Changed++
+		}
+	}
+
+	if opts.Prune {
+		for _, g := range diff.Removed {
+			if err := store.DeleteGuest(g.ID); err != nil {
+				return &diff, fmt.Errorf("failed to remove %s: %v", g.Name, err)
+			}
+			diff.Written.Removed++
+		}
+	}
+	return &diff, nil
+}
+
+// namesWithRSVPs returns the names of the guests who submitted, or are
+// named as attending on, any of rsvps
+func namesWithRSVPs(guests []Guest, rsvps []RSVPRecord) []string {
+	named := make(map[string]bool)
+	for _, rsvp := range rsvps {
+		named[NormalizeString(rsvp.Name)] = true
+		for _, name := range rsvp.AttendingGuests {
+			named[NormalizeString(name)] = true
+		}
+	}
+	var names []string
+	for _, g := range guests {
+		if named[NormalizeString(g.Name)] {
+			names = append(names, g.Name)
+		}
+	}
+	return names
+}
+
+// LogGuestDiff prints what an import added, changed and removed, or would
+// have with opts.DryRun. If it failed part way, the guests it didn't get
+// to are marked as such (see GuestDiff.Written).
+func LogGuestDiff(diff *GuestDiff, opts ImportOptions) {
+	verb := func(done, would string, written, total int) string {
+		switch {
+		case opts.DryRun:
+			return fmt.Sprintf("%s %d guests", would, total)
+		case written < total:
+			return fmt.Sprintf("%s %d of %d guests before the import stopped", done, written, total)
+		default:
+			return fmt.Sprintf("%s %d guests", done, total)
+		}
+	}
+	// unwritten marks the guest at index i of a list if the import stopped
+	// before writing it
+	unwritten := func(i, written int) string {
+		if opts.DryRun || i < written {
+			return ""
+		}
+		return " - not written"
+	}
+
+	if len(diff.Duplicates) > 0 {
+		log.Printf("⚠️  %d rows repeat a name already in the import - only the first is used", len(diff.Duplicates))
+		for _, g := range diff.Duplicates {
+			log.Printf("  = %s", g.Name)
+		}
+	}
+
+	log.Print(verb("Added", "Would add", diff.Written.Added, len(diff.Added)))
+	for i, g := range diff.Added {
+		log.Printf("  + %s%s", g.Name, unwritten(i, diff.Written.Added))
+	}
+
+	switch {
+	case len(diff.Changed) == 0:
+		log.Printf("No guests changed")
+	case opts.Update:
+		log.Print(verb("Updated", "Would update", diff.Written.Changed, len(diff.Changed)))
+	default:
+		log.Printf("⚠️  %d guests differ from the import - not updated (use --update)", len(diff.Changed))
+	}
+	for i, c := range diff.Changed {
+		note := ""
+		if opts.Update {
+			note = unwritten(i, diff.Written.Changed)
+		}
+		log.Printf("  ~ %s (%s)%s", c.Before.Name, strings.Join(c.Fields, ", "), note)
+	}
+
+	switch {
+	case len(diff.Removed) == 0:
+		log.Printf("No guests missing from the import")
+	case opts.Prune:
+		log.Print(verb("Removed", "Would remove", diff.Written.Removed, len(diff.Removed)))
+	default:
+		log.Printf("⚠️  %d guests are missing from the import - not removed (use --prune)", len(diff.Removed))
+	}
+	withRSVPs := make(map[string]bool, len(diff.WithRSVPs))
+	for _, name := range diff.WithRSVPs {
+		withRSVPs[name] = true
+	}
+	for i, g := range diff.Removed {
+		note := ""
+		if withRSVPs[g.Name] {
+			note = " (has an RSVP)"
+		}
+		if opts.Prune {
+			note += unwritten(i, diff.Written.Removed)
+		}
+		log.Printf("  - %s%s", g.Name, note)
+	}
+}
@@ -0,0 +1,175 @@
+package shared
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// guestNames returns the names of guests
+func guestNames(guests []Guest) []string {
+	names := []string{}
+	for _, g := range guests {
+		names = append(names, g.Name)
+	}
+	return names
+}
+
+func TestDiffGuests(t *testing.T) {
+	households := []Household{{ID: "h1", Name: "The Smiths"}, {ID: "h2", Name: "9 Low Rd"}}
+	existing := []Guest{
+		{ID: "1", Name: "Jane Smith", Address: "1 High St", HouseholdID: "h1", Events: []string{EventCeremony}},
+		{ID: "2", Name: "John Smith", Address: "1 High St", HouseholdID: "h1"},
+		{ID: "3", Name: "Bob Jones", Address: "9 Low Rd", HouseholdID: "h2", PlusOnes: 1},
+		{ID: "4", Name: "Eve Brown"},
+	}
+	imported := []Guest{
+		{Name: "jane smith", Address: "1 High St", Household: "the smiths", Ceremony: true},
+		{Name: "John Smith", Address: "2 High St", Household: "The Smiths", Table: "4"},
+		{Name: "Bob Jones", Address: "9 Low Rd", Events: []string{"brunch"}},
+		{Name: "Sam Green"},
+		{Name: "SAM GREEN", Address: "duplicate"},
+	}
+	diff := DiffGuests(existing, imported, households)
+
+	if got := guestNames(diff.Added); !slices.Equal(got, []string{"Sam Green"}) {
+		t.Errorf("added = %v, want Sam Green", got)
+	}
+	if got := guestNames(diff.Duplicates); !slices.Equal(got, []string{"SAM GREEN"}) {
+		t.Errorf("duplicates = %v, want the second Sam Green row", got)
+	}
+	if got := guestNames(diff.Removed); !slices.Equal(got, []string{"Eve Brown"}) {
+		t.Errorf("removed = %v, want Eve Brown", got)
+	}
+	want := map[string][]string{
+		"Jane Smith": {"name"},
+		"John Smith": {"address", "table"},
+		"Bob Jones":  {"events", "plus ones"},
+	}
+	if len(diff.Changed) != len(want) {
+		t.Fatalf("changed = %+v, want %v", diff.Changed, want)
+	}
+	for _, c := range diff.Changed {
+		if !slices.Equal(c.Fields, want[c.Before.Name]) || c.After.ID != c.Before.ID {
+			t.Errorf("%s changed %v (ID %q), want %v", c.Before.Name, c.Fields, c.After.ID, want[c.Before.Name])
+		}
+	}
+	if !diff.Changed[1].Differs("table") || diff.Changed[1].Differs("household") {
+		t.Errorf("Differs() disagrees with %v", diff.Changed[1].Fields)
+	}
+}
+
+func TestImportGuests(t *testing.T) {
+	newStore := func(t *testing.T) Store {
+		store := NewMemoryStore([]Guest{
+			{Name: "Jane Smith", Address: "1 High St"},
+			{Name: "Bob Jones", Address: "9 Low Rd"},
+			{Name: "Eve Brown"},
+		})
+		store.SaveRSVP(RSVPRequest{Name: "Eve Brown", Email: "eve@example.com"})
+		return store
+	}
+	imported := []Guest{
+		{Name: " Jane Smith ", Address: "2 High St", Events: []string{"Ceremony"}},
+		{Name: "Sam Green", Address: "2 High St"},
+	}
+
+	tests := []struct {
+		name        string
+		opts        ImportOptions
+		wantErr     bool
+		wantNames   []string
+		wantJane    string // Jane Smith's address afterwards
+		wantWritten GuestDiffCounts
+	}{
+		{"adds only", ImportOptions{}, false, []string{"Jane Smith", "Bob Jones", "Eve Brown", "Sam Green"}, "1 High St", GuestDiffCounts{Added: 1}},
+		{"dry run", ImportOptions{DryRun: true, Update: true, Prune: true, Force: true}, false, []string{"Jane Smith", "Bob Jones", "Eve Brown"}, "1 High St", GuestDiffCounts{}},
+		{"update", ImportOptions{Update: true}, false, []string{"Jane Smith", "Bob Jones", "Eve Brown", "Sam Green"}, "2 High St", GuestDiffCounts{Added: 1, Changed: 1}},
+		{"prune keeps guests with RSVPs", ImportOptions{Prune: true}, true, []string{"Jane Smith", "Bob Jones", "Eve Brown"}, "1 High St", GuestDiffCounts{}},
+		{"forced prune", ImportOptions{Prune: true, Force: true}, false, []string{"Jane Smith", "Sam Green"}, "1 High St", GuestDiffCounts{Added: 1, Removed: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStore(t)
+			diff, err := ImportGuests(store, imported, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ImportGuests() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff == nil || len(diff.Added) != 1 || len(diff.Changed) != 1 || len(diff.Removed) != 2 || !slices.Equal(diff.WithRSVPs, []string{"Eve Brown"}) {
+				t.Errorf("diff = %+v", diff)
+			} else if diff.Written != tt.wantWritten {
+				t.Errorf("Written = %+v, want %+v", diff.Written, tt.wantWritten)
+			}
+			guests, _ := store.ListGuests()
+			names := guestNames(guests)
+			slices.Sort(names)
+			slices.Sort(tt.wantNames)
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("guests = %v, want %v", names, tt.wantNames)
+			}
+			jane, _ := store.FindGuestByName("Jane Smith")
+			if jane.Address != tt.wantJane || jane.Ceremony != (tt.wantJane == "2 High St") {
+				t.Errorf("Jane Smith = %+v, want at %s", jane, tt.wantJane)
+			}
+		})
+	}
+}
+
+// failingDeletes is a store that can't remove one guest
+type failingDeletes struct {
+	Store
+	id string
+}
+
+func (s failingDeletes) DeleteGuest(id string) error {
+	if id == s.id {
+		return fmt.Errorf("connection reset")
+	}
+	return s.Store.DeleteGuest(id)
+}
+
+func TestImportGuestsFailsPartWay(t *testing.T) {
+	memory := NewMemoryStore([]Guest{{Name: "Jane Smith", Address: "1 High St"}, {Name: "Bob Jones"}, {Name: "Eve Brown"}})
+	eve, _ := memory.FindGuestByName("Eve Brown")
+	store := failingDeletes{Store: memory, id: eve.ID}
+	opts := ImportOptions{Update: true, Prune: true}
+
+	diff, err := ImportGuests(store, []Guest{{Name: "Jane Smith", Address: "2 High St"}, {Name: "Sam Green"}}, opts)
+	if err == nil || !strings.Contains(err.Error(), "Eve Brown") {
+		t.Fatalf("ImportGuests() error = %v, want it to name Eve Brown", err)
+	}
+	if want := (GuestDiffCounts{Added: 1, Changed: 1, Removed: 1}); diff == nil || diff.Written != want {
+		t.Fatalf("diff = %+v, want %+v written", diff, want)
+	}
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	LogGuestDiff(diff, opts)
+	for _, want := range []string{"Added 1 guests", "Updated 1 guests", "Removed 1 of 2 guests before the import stopped", "  - Bob Jones\n", "  - Eve Brown - not written"} {
+		if !strings.Contains(logged.String(), want) {
+			t.Errorf("log doesn't say %q:\n%s", want, logged.String())
+		}
+	}
+	if strings.Contains(logged.String(), "Would") {
+		t.Errorf("log reports a failed import as a dry run:\n%s", logged.String())
+	}
+}
+
+func TestImportGuestsMovesHousehold(t *testing.T) {
+	store := NewMemoryStore([]Guest{{Name: "Jane Smith", Household: "The Smiths"}, {Name: "Anna Smith", Household: "The Smiths"}})
+	before, _ := store.FindGuestByName("Anna Smith")
+	if _, err := ImportGuests(store, []Guest{{Name: "Jane Smith", Household: "The Browns"}, {Name: "Anna Smith", Household: "the smiths"}},
+		ImportOptions{Update: true}); err != nil {
+		t.Fatal(err)
+	}
+	jane, _ := store.FindGuestByName("Jane Smith")
+	anna, _ := store.FindGuestByName("Anna Smith")
+	if anna.HouseholdID != before.HouseholdID || jane.HouseholdID == "" || jane.HouseholdID == anna.HouseholdID {
+		t.Errorf("households: Jane %q, Anna %q (was %q), want Jane moved out", jane.HouseholdID, anna.HouseholdID, before.HouseholdID)
+	}
+}
@@ -0,0 +1,100 @@
+package shared
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Household challenges verify-name can set before listing a household's
+// members, chosen by the HOUSEHOLD_CHALLENGE env var
+const (
+	ChallengeNone     = "none"     // the default: a name match is enough
+	ChallengePostcode = "postcode" // the postcode from the guest's address
+	ChallengeCode     = "code"     // the household's invite code, printed on the invitation
+)
+
+// Codes returned by verify-name when a name matched but the household
+// challenge hasn't been passed
+const (
+	CodeChallengeRequired = "challenge_required" // ask the guest for the answer
+	CodeChallengeFailed   = "challenge_failed"   // the answer given was wrong
+)
+
+// LoadHouseholdChallenge reads the challenge type from HOUSEHOLD_CHALLENGE
+// (ChallengeNone when unset)
+func LoadHouseholdChallenge() (string, error) {
+	challenge := strings.ToLower(strings.TrimSpace(os.Getenv("HOUSEHOLD_CHALLENGE")))
+	switch challenge {
+	case "", ChallengeNone:
+		return ChallengeNone, nil
+	case ChallengePostcode, ChallengeCode:
+		return challenge, nil
+	}
+	return "", fmt.Errorf("unknown HOUSEHOLD_CHALLENGE %q (expected none, postcode or code)", challenge)
+}
+
+// ukPostcode matches a UK postcode such as "SW18 2PU" or "n11aa"
+var ukPostcode = regexp.MustCompile(`(?i)\b([A-Z]{1,2}[0-9][A-Z0-9]?)\s*([0-9][A-Z]{2})\b`)
+
+// numericPostcode matches postal codes elsewhere, such as "11000" or "2000"
+var numericPostcode = regexp.MustCompile(`\b[0-9]{4,6}\b`)
+
+// PostcodeFromAddress finds the postcode in a free-text address: a UK
+// postcode if there is one, otherwise the last 4-6 digit number that
+// doesn't start the address (which would be a house number). It returns
+// "" if there is neither.
+func PostcodeFromAddress(address string) string {
+	if m := ukPostcode.FindAllStringSubmatch(address, -1); len(m) > 0 {
+		last := m[len(m)-1]
+		return strings.ToUpper(last[1] + " " + last[2])
+	}
+	address = strings.TrimSpace(address)
+	if m := numericPostcode.FindAllStringIndex(address, -1); len(m) > 0 && m[len(m)-1][0] > 0 {
+		last := m[len(m)-1]
+		return address[last[0]:last[1]]
+	}
+	return ""
+}
+
+// ChallengeAnswer returns what guest must give to pass challenge, or "" if
+// they aren't challenged: when the challenge is off, the guest has no
+// address, or there is nothing to ask for (no postcode in the address, or
+// no invite code for the household)
+func ChallengeAnswer(challenge string, guest Guest, households []Household) string {
+	if HouseholdNameForAddress(guest.Address) == "" {
+		return ""
+	}
+	switch challenge {
+	case ChallengePostcode:
+		return PostcodeFromAddress(guest.Address)
+	case ChallengeCode:
+		for _, h := range households {
+			if h.ID == guest.HouseholdID && guest.HouseholdID != "" {
+				return strings.TrimSpace(h.InviteCode)
+			}
+		}
+	}
+	return ""
+}
+
+// CheckChallengeAnswer reports whether given matches expected, ignoring
+// case, spaces and dashes
+func CheckChallengeAnswer(expected, given string) bool {
+	clean := func(s string) string {
+		s = strings.ToUpper(s)
+		return strings.NewReplacer(" ", "", "-", "", "\t", "").Replace(s)
+	}
+	e, g := clean(expected), clean(given)
+	return e != "" && subtle.ConstantTimeCompare([]byte(e), []byte(g)) == 1
+}
+
+// ChallengePrompt is what verify-name asks the guest for challenge
+func ChallengePrompt(challenge string) string {
+	if challenge == ChallengeCode {
+		return "Please enter the code printed on your invitation"
+	}
+	return "Please enter the postcode the invitation was sent to"
+}
@@ -0,0 +1,109 @@
+package shared
+
+import "testing"
+
+func TestLoadHouseholdChallenge(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{"", ChallengeNone, false},
+		{"none", ChallengeNone, false},
+		{" Postcode ", ChallengePostcode, false},
+		{"CODE", ChallengeCode, false},
+		{"password", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("HOUSEHOLD_CHALLENGE", tt.env)
+			got, err := LoadHouseholdChallenge()
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("LoadHouseholdChallenge() = %q, %v, want %q (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPostcodeFromAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"123 Main St, London SW18 2PU", "SW18 2PU"},
+		{"Flat 2, 10 High St, London n11aa", "N1 1AA"},
+		{"1 Park Road, Manchester M1 1AE, UK", "M1 1AE"},
+		{"10 Downing Street, London SW1A 2AA", "SW1A 2AA"},
+		{"Knez Mihailova 5, 11000 Beograd", "11000"},
+		{"Bulevar Oslobođenja 12, 21000 Novi Sad, Serbia", "21000"},
+		{"2000 Main Street", ""},
+		{"12 Baker Street, London", ""},
+		{"n/a", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := PostcodeFromAddress(tt.address); got != tt.want {
+				t.Errorf("PostcodeFromAddress(%q) = %q, want %q", tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChallengeAnswer(t *testing.T) {
+	households := []Household{
+		{ID: "h1", Name: "The Smiths", InviteCode: " K7QD-M3XR "},
+		{ID: "h2", Name: "The Joneses"},
+	}
+	smith := Guest{Name: "John Smith", Address: "123 Main St, London SW18 2PU", HouseholdID: "h1"}
+	jones := Guest{Name: "Bob Jones", Address: "12 Baker Street, London", HouseholdID: "h2"}
+	solo := Guest{Name: "Sam Solo", Address: "n/a"}
+
+	tests := []struct {
+		name      string
+		challenge string
+		guest     Guest
+		want      string
+	}{
+		{"off", ChallengeNone, smith, ""},
+		{"postcode", ChallengePostcode, smith, "SW18 2PU"},
+		{"code", ChallengeCode, smith, "K7QD-M3XR"},
+		{"no postcode in address", ChallengePostcode, jones, ""},
+		{"household without a code", ChallengeCode, jones, ""},
+		{"no address", ChallengePostcode, solo, ""},
+		{"no household", ChallengeCode, Guest{Name: "Ann", Address: "1 Main St, London N1 1AA"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChallengeAnswer(tt.challenge, tt.guest, households); got != tt.want {
+				t.Errorf("ChallengeAnswer(%q, %s) = %q, want %q", tt.challenge, tt.guest.Name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckChallengeAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		given    string
+		want     bool
+	}{
+		{"exact", "SW18 2PU", "SW18 2PU", true},
+		{"case and spacing", "SW18 2PU", "sw182pu", true},
+		{"dashes", "K7QD-M3XR", "k7qd m3xr", true},
+		{"tabs", "11000", "\t11000", true},
+		{"wrong", "SW18 2PU", "SW18 2PV", false},
+		{"prefix", "SW18 2PU", "SW18", false},
+		{"empty answer", "SW18 2PU", "", false},
+		{"nothing expected", "", "", false},
+		{"nothing expected but spaces given", " - ", " ", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckChallengeAnswer(tt.expected, tt.given); got != tt.want {
+				t.Errorf("CheckChallengeAnswer(%q, %q) = %v, want %v", tt.expected, tt.given, got, tt.want)
+			}
+		})
+	}
+}
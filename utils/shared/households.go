@@ -0,0 +1,96 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Household groups guests who RSVP together. Guests without a household
+// RSVP on their own.
+type Household struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name"`
+	InviteCode string `json:"invite_code,omitempty"` // printed on the invitation; empty until generated
+	CreatedAt  string `json:"created_at,omitempty"`
+}
+
+// HouseholdNameForAddress returns the household name to use for guests
+// grouped by address, or "" for blank addresses and "n/a" style
+// placeholders, which never group anyone
+func HouseholdNameForAddress(address string) string {
+	address = strings.TrimSpace(address)
+	switch strings.ToLower(address) {
+	case "", "n/a", "na", "n.a.", "n.a":
+		return ""
+	}
+	return address
+}
+
+// HouseholdName returns the household a guest should belong to when
+// imported or added: their explicit Household, falling back to their address
+func HouseholdName(g Guest) string {
+	if name := strings.TrimSpace(g.Household); name != "" {
+		return name
+	}
+	return HouseholdNameForAddress(g.Address)
+}
+
+// ResolveHouseholds sets HouseholdID on every guest that lacks one but has a
+// household name (see HouseholdName), creating households in store as needed
+func ResolveHouseholds(store Store, guests []Guest) error {
+	ids := make(map[string]string)
+	for i := range guests {
+		if guests[i].HouseholdID != "" {
+			continue
+		}
+		name := HouseholdName(guests[i])
+		if name == "" {
+			continue
+		}
+		key := NormalizeString(name)
+		if _, ok := ids[key]; !ok {
+			household, err := store.FindOrCreateHousehold(name)
+			if err != nil {
+				return fmt.Errorf("failed to resolve household %q: %v", name, err)
+			}
+			ids[key] = household.ID
+		}
+		guests[i].HouseholdID = ids[key]
+	}
+	return nil
+}
+
+// GroupGuestsByHousehold splits guests into households, in order of each
+// household's first member. Guests without a household form a group of one.
+func GroupGuestsByHousehold(guests []Guest) [][]Guest {
+	var groups [][]Guest
+	index := make(map[string]int)
+	for _, g := range guests {
+		if g.HouseholdID == "" {
+			groups = append(groups, []Guest{g})
+			continue
+		}
+		if i, ok := index[g.HouseholdID]; ok {
+			groups[i] = append(groups[i], g)
+			continue
+		}
+		index[g.HouseholdID] = len(groups)
+		groups = append(groups, []Guest{g})
+	}
+	return groups
+}
+
+// HouseholdMembers returns everyone on the invite list in guest's household,
+// including guest
+func HouseholdMembers(guest Guest, guestList []Guest, events []Event) []FamilyMember {
+	if guest.HouseholdID == "" {
+		return []FamilyMember{{ID: guest.ID, Name: guest.Name, Events: InvitedEvents(&guest, events)}}
+	}
+	members := []FamilyMember{}
+	for _, g := range guestList {
+		if g.HouseholdID == guest.HouseholdID {
+			members = append(members, FamilyMember{ID: g.ID, Name: g.Name, Events: InvitedEvents(&g, events)})
+		}
+	}
+	return members
+}
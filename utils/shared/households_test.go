@@ -0,0 +1,103 @@
+package shared
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHouseholdName(t *testing.T) {
+	tests := []struct {
+		guest Guest
+		want  string
+	}{
+		{Guest{Household: " The Smiths ", Address: "1 High St"}, "The Smiths"},
+		{Guest{Address: " 1 High St "}, "1 High St"},
+		{Guest{Address: "N/A"}, ""},
+		{Guest{Address: "n.a."}, ""},
+		{Guest{Address: "na"}, ""},
+		{Guest{Household: " ", Address: ""}, ""},
+	}
+	for _, tt := range tests {
+		if got := HouseholdName(tt.guest); got != tt.want {
+			t.Errorf("HouseholdName(%+v) = %q, want %q", tt.guest, got, tt.want)
+		}
+	}
+}
+
+func TestResolveHouseholds(t *testing.T) {
+	store := NewMemoryStore(nil)
+	existing, _ := store.FindOrCreateHousehold("The Smiths")
+	guests := []Guest{
+		{Name: "Jane", Household: "the smiths"},
+		{Name: "Anna", Address: "The Smiths"},
+		{Name: "Bob", Address: "2 Low Rd"},
+		{Name: "Eve", Address: "n/a"},
+		{Name: "Dan", HouseholdID: "kept", Address: "2 Low Rd"},
+	}
+	if err := ResolveHouseholds(store, guests); err != nil {
+		t.Fatalf("ResolveHouseholds() error = %v", err)
+	}
+	if guests[0].HouseholdID != existing.ID || guests[1].HouseholdID != existing.ID {
+		t.Errorf("Jane and Anna in %q and %q, want the existing %q", guests[0].HouseholdID, guests[1].HouseholdID, existing.ID)
+	}
+	if guests[2].HouseholdID == "" || guests[2].HouseholdID == existing.ID {
+		t.Errorf("Bob in %q, want a new household", guests[2].HouseholdID)
+	}
+	if guests[3].HouseholdID != "" {
+		t.Errorf("Eve in %q, want none", guests[3].HouseholdID)
+	}
+	if guests[4].HouseholdID != "kept" {
+		t.Errorf("Dan moved to %q", guests[4].HouseholdID)
+	}
+	if households, _ := store.ListHouseholds(); len(households) != 2 {
+		t.Errorf("households = %+v, want the Smiths and 2 Low Rd", households)
+	}
+}
+
+func TestGroupGuestsByHousehold(t *testing.T) {
+	guests := []Guest{
+		{Name: "Jane", HouseholdID: "a"},
+		{Name: "Eve"},
+		{Name: "Bob", HouseholdID: "b"},
+		{Name: "Anna", HouseholdID: "a"},
+		{Name: "Dan"},
+	}
+	var got [][]string
+	for _, group := range GroupGuestsByHousehold(guests) {
+		var names []string
+		for _, g := range group {
+			names = append(names, g.Name)
+		}
+		got = append(got, names)
+	}
+	want := [][]string{{"Jane", "Anna"}, {"Eve"}, {"Bob"}, {"Dan"}}
+	if !slices.EqualFunc(got, want, slices.Equal[[]string]) {
+		t.Errorf("GroupGuestsByHousehold() = %v, want %v", got, want)
+	}
+}
+
+func TestHouseholdMembers(t *testing.T) {
+	guests := []Guest{
+		{ID: "1", Name: "Jane", HouseholdID: "a"},
+		{ID: "2", Name: "Eve"},
+		{ID: "3", Name: "Anna", HouseholdID: "a"},
+		{ID: "4", Name: "Dan"},
+	}
+	tests := []struct {
+		guest Guest
+		want  []string
+	}{
+		{guests[0], []string{"Jane", "Anna"}},
+		{guests[2], []string{"Jane", "Anna"}},
+		{guests[1], []string{"Eve"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range HouseholdMembers(tt.guest, guests, DefaultEvents) {
+			got = append(got, m.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("HouseholdMembers(%s) = %v, want %v", tt.guest.Name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package shared
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+)
+
+// CodeInvalidInviteCode is returned by verify-code and submit-rsvp when no
+// household has the invite code given
+const CodeInvalidInviteCode = "invalid_invite_code"
+
+// inviteCodeAlphabet leaves out letters and digits that are easily misread
+// on a printed card: 0/O, 1/I/L
+const inviteCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// inviteCodeLength is the number of characters in a code, printed in two
+// halves as "ABCD-EFGH". 31^8 codes make guessing one impractical, all the
+// more so with verify-code's rate limits.
+const inviteCodeLength = 8
+
+// NewInviteCode returns a random invite code such as "K7QD-M3XR"
+func NewInviteCode() (string, error) {
+	code := make([]byte, inviteCodeLength)
+	max := big.NewInt(int64(len(inviteCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate invite code: %v", err)
+		}
+		code[i] = inviteCodeAlphabet[n.Int64()]
+	}
+	return formatInviteCode(string(code)), nil
+}
+
+// NormalizeInviteCode puts a code as typed by a guest (any case, with or
+// without spaces and dashes) in the form NewInviteCode returns
+func NormalizeInviteCode(code string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(code) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return formatInviteCode(b.String())
+}
+
+func formatInviteCode(code string) string {
+	if len(code) != inviteCodeLength {
+		return code
+	}
+	return code[:inviteCodeLength/2] + "-" + code[inviteCodeLength/2:]
+}
+
+// FindHouseholdByInviteCode returns the household whose invite code is
+// code (see NormalizeInviteCode), or nil
+func FindHouseholdByInviteCode(code string, households []Household) *Household {
+	code = NormalizeInviteCode(code)
+	if code == "" {
+		return nil
+	}
+	for i := range households {
+		if households[i].InviteCode != "" && NormalizeInviteCode(households[i].InviteCode) == code {
+			return &households[i]
+		}
+	}
+	return nil
+}
+
+// HouseholdsForUnhousedGuests puts each guest outside a household (a
+// blank or n/a address and no household column) in a household of their
+// own, named after them, so they can be sent an invite code too. Guests
+// who share their name with an existing household are left out rather
+// than joined to it, and returned as skipped.
+func HouseholdsForUnhousedGuests(store Store) (housed, skipped []Guest, err error) {
+	guests, err := store.ListGuests()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load guests: %v", err)
+	}
+	households, err := store.ListHouseholds()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load households: %v", err)
+	}
+	taken := make(map[string]bool, len(households))
+	for _, h := range households {
+		taken[NormalizeString(h.Name)] = true
+	}
+
+	for _, g := range guests {
+		if g.HouseholdID != "" {
+			continue
+		}
+		key := NormalizeString(g.Name)
+		if taken[key] {
+			skipped = append(skipped, g)
+			continue
+		}
+		household, err := store.FindOrCreateHousehold(g.Name)
+		if err != nil {
+			return housed, skipped, fmt.Errorf("failed to create a household for %s: %v", g.Name, err)
+		}
+		taken[key] = true
+		g.HouseholdID = household.ID
+		if err := store.UpdateGuest(g); err != nil {
+			return housed, skipped, fmt.Errorf("failed to add %s to their household: %v", g.Name, err)
+		}
+		housed = append(housed, g)
+	}
+	return housed, skipped, nil
+}
+
+// GenerateInviteCodes gives every household without an invite code a new
+// one, or every household when regenerate is set (which stops the old
+// codes working), and returns how many were set. Codes are unique.
+func GenerateInviteCodes(store Store, regenerate bool) (int, error) {
+	households, err := store.ListHouseholds()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load households: %v", err)
+	}
+	used := make(map[string]bool, len(households))
+	if !regenerate {
+		for _, h := range households {
+			if h.InviteCode != "" {
+				used[NormalizeInviteCode(h.InviteCode)] = true
+			}
+		}
+	}
+
+	generated := 0
+	for _, h := range households {
+		if h.InviteCode != "" && !regenerate {
+			continue
+		}
+		code, err := NewInviteCode()
+		for err == nil && used[code] {
+			code, err = NewInviteCode()
+		}
+		if err != nil {
+			return generated, err
+		}
+		if err := store.SetHouseholdInviteCode(h.ID, code); err != nil {
+			return generated, fmt.Errorf("failed to set invite code for %s: %v", h.Name, err)
+		}
+		used[code] = true
+		generated++
+	}
+	return generated, nil
+}
+
+// InviteURL returns the personalised invitation link for code, which
+// opens the RSVP form for the household without a name lookup
+func InviteURL(code string) string {
+	return siteBaseURL() + "/?code=" + url.QueryEscape(code)
+}
+
+// WriteInviteCodesCSV writes one row per household, for printing on the
+// invitations: its name, invite code and link, the guests invited and
+// their address. Households without a code have the code and link blank.
+func WriteInviteCodesCSV(w io.Writer, guests []Guest, households []Household) error {
+	members := make(map[string][]Guest)
+	for _, g := range guests {
+		if g.HouseholdID != "" {
+			members[g.HouseholdID] = append(members[g.HouseholdID], g)
+		}
+	}
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"Household", "Code", "Invite URL", "Guests", "Address"})
+	for _, h := range households {
+		if len(members[h.ID]) == 0 {
+			continue
+		}
+		names := make([]string, len(members[h.ID]))
+		for i, g := range members[h.ID] {
+			names[i] = g.Name
+		}
+		link := ""
+		if h.InviteCode != "" {
+			link = InviteURL(h.InviteCode)
+		}
+		out.Write([]string{h.Name, h.InviteCode, link, strings.Join(names, ", "), members[h.ID][0].Address})
+	}
+	out.Flush()
+	return out.Error()
+}
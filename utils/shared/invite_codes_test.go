@@ -0,0 +1,172 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestNewInviteCode(t *testing.T) {
+	format := regexp.MustCompile(`^[` + inviteCodeAlphabet + `]{4}-[` + inviteCodeAlphabet + `]{4}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := NewInviteCode()
+		if err != nil {
+			t.Fatalf("NewInviteCode() error = %v", err)
+		}
+		if !format.MatchString(code) {
+			t.Fatalf("NewInviteCode() = %q, want ABCD-EFGH", code)
+		}
+		if seen[code] {
+			t.Fatalf("NewInviteCode() repeated %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestNormalizeInviteCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"K7QD-M3XR", "K7QD-M3XR"},
+		{" k7qd m3xr ", "K7QD-M3XR"},
+		{"k7qdm3xr", "K7QD-M3XR"},
+		{"K7QD", "K7QD"},
+		{"--", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeInviteCode(tt.code); got != tt.want {
+			t.Errorf("NormalizeInviteCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestFindHouseholdByInviteCode(t *testing.T) {
+	households := []Household{
+		{ID: "h1", Name: "The Smiths", InviteCode: "K7QD-M3XR"},
+		{ID: "h2", Name: "The Joneses"},
+	}
+	if h := FindHouseholdByInviteCode("k7qd m3xr", households); h == nil || h.ID != "h1" {
+		t.Errorf("FindHouseholdByInviteCode() = %+v, want the Smiths", h)
+	}
+	for _, code := range []string{"K7QD-M3XS", "", " - "} {
+		if h := FindHouseholdByInviteCode(code, households); h != nil {
+			t.Errorf("FindHouseholdByInviteCode(%q) = %+v, want nil", code, h)
+		}
+	}
+}
+
+func TestGenerateInviteCodes(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		smiths, _ := store.FindOrCreateHousehold("The Smiths")
+		if _, err := store.FindOrCreateHousehold("The Joneses"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetHouseholdInviteCode(smiths.ID, "K7QD-M3XR"); err != nil {
+			t.Fatalf("SetHouseholdInviteCode() error = %v", err)
+		}
+
+		codes := func() map[string]string {
+			households, err := store.ListHouseholds()
+			if err != nil {
+				t.Fatal(err)
+			}
+			codes := make(map[string]string)
+			for _, h := range households {
+				codes[h.Name] = h.InviteCode
+			}
+			return codes
+		}
+
+		if n, err := GenerateInviteCodes(store, false); n != 1 || err != nil {
+			t.Fatalf("GenerateInviteCodes() = %d, %v, want 1", n, err)
+		}
+		got := codes()
+		if got["The Smiths"] != "K7QD-M3XR" || got["The Joneses"] == "" {
+			t.Errorf("codes after generating = %v, want the Smiths' kept and one for the Joneses", got)
+		}
+
+		if n, err := GenerateInviteCodes(store, true); n != 2 || err != nil {
+			t.Fatalf("GenerateInviteCodes(regenerate) = %d, %v, want 2", n, err)
+		}
+		regenerated := codes()
+		if regenerated["The Smiths"] == "K7QD-M3XR" || regenerated["The Smiths"] == regenerated["The Joneses"] {
+			t.Errorf("codes after regenerating = %v, want two new ones", regenerated)
+		}
+
+		if err := store.SetHouseholdInviteCode("missing", "K7QD-M3XR"); err == nil {
+			t.Errorf("SetHouseholdInviteCode(missing) succeeded")
+		}
+	})
+}
+
+func TestWriteInviteCodesCSV(t *testing.T) {
+	t.Setenv("BASE_URL", "wedding.example.com/")
+	households := []Household{
+		{ID: "h1", Name: "The Smiths", InviteCode: "K7QD-M3XR"},
+		{ID: "h2", Name: "The Joneses"},
+		{ID: "h3", Name: "Nobody Yet", InviteCode: "AAAA-BBBB"},
+	}
+	guests := []Guest{
+		{Name: "Jane Smith", Address: "1 High St", HouseholdID: "h1"},
+		{Name: "Bob Jones", Address: "9 Low Rd", HouseholdID: "h2"},
+		{Name: "John Smith", Address: "1 High St", HouseholdID: "h1"},
+		{Name: "Sam Solo"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteInviteCodesCSV(&buf, guests, households); err != nil {
+		t.Fatalf("WriteInviteCodesCSV() error = %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Household", "Code", "Invite URL", "Guests", "Address"},
+		{"The Smiths", "K7QD-M3XR", "https://wedding.example.com/?code=K7QD-M3XR", "Jane Smith, John Smith", "1 High St"},
+		{"The Joneses", "", "", "Bob Jones", "9 Low Rd"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("WriteInviteCodesCSV() = %q, want %q", rows, want)
+	}
+}
+
+func TestHouseholdsForUnhousedGuests(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		smiths, _ := store.FindOrCreateHousehold("The Smiths")
+		for _, g := range []Guest{
+			{Name: "Jane Smith", HouseholdID: smiths.ID},
+			{Name: "Sam Solo"},
+			{Name: "the smiths"},
+		} {
+			if err := store.AddGuest(g); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		housed, skipped, err := HouseholdsForUnhousedGuests(store)
+		if err != nil {
+			t.Fatalf("HouseholdsForUnhousedGuests() error = %v", err)
+		}
+		if got := guestNames(housed); !slices.Equal(got, []string{"Sam Solo"}) {
+			t.Errorf("housed = %v, want Sam Solo", got)
+		}
+		if got := guestNames(skipped); !slices.Equal(got, []string{"the smiths"}) {
+			t.Errorf("skipped = %v, want the guest sharing a household's name", got)
+		}
+		sam, _ := store.FindGuestByName("Sam Solo")
+		households, _ := store.ListHouseholds()
+		if len(households) != 2 || sam.HouseholdID == "" || sam.HouseholdID == smiths.ID {
+			t.Errorf("Sam Solo is in household %q of %+v, want one of their own", sam.HouseholdID, households)
+		}
+
+		if housed, _, _ := HouseholdsForUnhousedGuests(store); len(housed) != 0 {
+			t.Errorf("second run housed %v again", guestNames(housed))
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package shared
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Courses of the reception's set menu, in serving order
+const (
+	CourseStarter = "starter"
+	CourseMain    = "main"
+	CourseDessert = "dessert"
+)
+
+// MenuCourses lists every course, in serving order
+var MenuCourses = []string{CourseStarter, CourseMain, CourseDessert}
+
+// MenuOption is one dish guests may choose for a course
+type MenuOption struct {
+	ID          string `json:"id,omitempty"`
+	Course      string `json:"course"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	CreatedAt   string `json:"created_at,omitempty"`
+}
+
+// GuestMeal is one attending person's choice for each course, as MenuOption
+// IDs. A course left blank hasn't been chosen yet.
+type GuestMeal struct {
+	GuestName string `json:"guestName"`
+	Starter   string `json:"starter,omitempty"`
+	Main      string `json:"main,omitempty"`
+	Dessert   string `json:"dessert,omitempty"`
+}
+
+// Choice returns the option ID chosen for course, or ""
+func (m GuestMeal) Choice(course string) string {
+	switch course {
+	case CourseStarter:
+		return m.Starter
+	case CourseMain:
+		return m.Main
+	case CourseDessert:
+		return m.Dessert
+	}
+	return ""
+}
+
+// setChoice sets the option ID chosen for course
+func (m *GuestMeal) setChoice(course, id string) {
+	switch course {
+	case CourseStarter:
+		m.Starter = id
+	case CourseMain:
+		m.Main = id
+	case CourseDessert:
+		m.Dessert = id
+	}
+}
+
+// courseIndex returns the position of course in MenuCourses, or -1
+func courseIndex(course string) int {
+	for i, c := range MenuCourses {
+		if c == course {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParseCourse returns the course named by s (case-insensitive; "mains" and
+// "desserts" are accepted), or an error
+func ParseCourse(s string) (string, error) {
+	course := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "s")
+	if courseIndex(course) < 0 {
+		return "", fmt.Errorf("unknown course %q (want one of %s)", s, strings.Join(MenuCourses, ", "))
+	}
+	return course, nil
+}
+
+// sortMenu orders options by course, keeping their order within a course
+func sortMenu(menu []MenuOption) {
+	sort.SliceStable(menu, func(i, j int) bool {
+		return courseIndex(menu[i].Course) < courseIndex(menu[j].Course)
+	})
+}
+
+// findMenuOption returns the option with id, or nil
+func findMenuOption(id string, menu []MenuOption) *MenuOption {
+	for i := range menu {
+		if menu[i].ID == id {
+			return &menu[i]
+		}
+	}
+	return nil
+}
+
+// MenuOptionName returns the name of the option with id, or id itself if it
+// is no longer on the menu
+func MenuOptionName(id string, menu []MenuOption) string {
+	if option := findMenuOption(id, menu); option != nil {
+		return option.Name
+	}
+	return id
+}
+
+// CleanGuestMeals checks meal choices against the people on an RSVP (its
+// attending guests and plus-ones) and the menu. It returns them with names
+// in the RSVP's spelling and entries with no choices dropped; a later entry
+// for the same person replaces an earlier one. The error is guest-facing.
+func CleanGuestMeals(meals []GuestMeal, people []string, menu []MenuOption) ([]GuestMeal, error) {
+	canonical := make(map[string]string, len(people))
+	for _, name := range people {
+		canonical[NormalizeString(name)] = strings.TrimSpace(name)
+	}
+
+	cleaned := []GuestMeal{}
+	index := make(map[string]int)
+	for _, m := range meals {
+		key := NormalizeString(m.GuestName)
+		name, ok := canonical[key]
+		if !ok {
+			return nil, fmt.Errorf("Meal choices can only be given for people coming: %s isn't one of them", strings.TrimSpace(m.GuestName))
+		}
+		meal := GuestMeal{GuestName: name}
+		for _, course := range MenuCourses {
+			id := strings.TrimSpace(m.Choice(course))
+			if id == "" {
+				continue
+			}
+			if option := findMenuOption(id, menu); option == nil || option.Course != course {
+				return nil, fmt.Errorf("That %s isn't on the menu - please choose again for %s", course, name)
+			}
+			meal.setChoice(course, id)
+		}
+		if i, seen := index[key]; seen {
+			cleaned[i] = meal
+			continue
+		}
+		index[key] = len(cleaned)
+		cleaned = append(cleaned, meal)
+	}
+
+	kept := cleaned[:0]
+	for _, m := range cleaned {
+		if m.Starter != "" || m.Main != "" || m.Dessert != "" {
+			kept = append(kept, m)
+		}
+	}
+	return kept, nil
+}
+
+// MissingCourses returns the courses on the menu that meal has no valid
+// choice for, e.g. because the chosen dish was taken off the menu
+func MissingCourses(meal GuestMeal, menu []MenuOption) []string {
+	var missing []string
+	for _, course := range MenuCourses {
+		offered := false
+		for _, option := range menu {
+			if option.Course == course {
+				offered = true
+				break
+			}
+		}
+		if !offered {
+			continue
+		}
+		if option := findMenuOption(meal.Choice(course), menu); option == nil || option.Course != course {
+			missing = append(missing, course)
+		}
+	}
+	return missing
+}
+
+// PeopleWithoutMeals returns everyone coming on rsvp (attending guests and
+// plus-ones) who hasn't chosen every course on the menu
+func PeopleWithoutMeals(rsvp RSVPRecord, menu []MenuOption) []string {
+	meals := make(map[string]GuestMeal, len(rsvp.GuestMeals))
+	for _, m := range rsvp.GuestMeals {
+		meals[NormalizeString(m.GuestName)] = m
+	}
+	var missing []string
+	for _, name := range append(append([]string{}, rsvp.AttendingGuests...), rsvp.PlusOnes...) {
+		meal := meals[NormalizeString(name)]
+		if len(MissingCourses(meal, menu)) > 0 {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,97 @@
+package shared
+
+import (
+	"slices"
+	"testing"
+)
+
+// testMenu is a menu with two starters, a main and no desserts
+var testMenu = []MenuOption{
+	{ID: "s1", Course: CourseStarter, Name: "Soup"},
+	{ID: "s2", Course: CourseStarter, Name: "Salad"},
+	{ID: "m1", Course: CourseMain, Name: "Risotto"},
+}
+
+func TestParseCourse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"starter", CourseStarter, false},
+		{" Mains ", CourseMain, false},
+		{"DESSERTS", CourseDessert, false},
+		{"pudding", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCourse(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseCourse(%q) = %q, %v, want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCleanGuestMeals(t *testing.T) {
+	people := []string{"Jane Smith", "Sam"}
+	tests := []struct {
+		name    string
+		meals   []GuestMeal
+		want    []GuestMeal
+		wantErr bool
+	}{
+		{"canonical names", []GuestMeal{{GuestName: "jane smith", Starter: " s1 ", Main: "m1"}},
+			[]GuestMeal{{GuestName: "Jane Smith", Starter: "s1", Main: "m1"}}, false},
+		{"later entry wins", []GuestMeal{{GuestName: "Sam", Starter: "s1"}, {GuestName: "SAM", Starter: "s2"}},
+			[]GuestMeal{{GuestName: "Sam", Starter: "s2"}}, false},
+		{"no choices dropped", []GuestMeal{{GuestName: "Sam"}, {GuestName: "Jane Smith", Main: "m1"}},
+			[]GuestMeal{{GuestName: "Jane Smith", Main: "m1"}}, false},
+		{"someone not coming", []GuestMeal{{GuestName: "Eve", Main: "m1"}}, nil, true},
+		{"not on the menu", []GuestMeal{{GuestName: "Sam", Main: "m9"}}, nil, true},
+		{"wrong course", []GuestMeal{{GuestName: "Sam", Main: "s1"}}, nil, true},
+		{"none", nil, []GuestMeal{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CleanGuestMeals(tt.meals, people, testMenu)
+			if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+				t.Errorf("CleanGuestMeals() = %+v, %v, want %+v (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMissingCourses(t *testing.T) {
+	tests := []struct {
+		name string
+		meal GuestMeal
+		want []string
+	}{
+		{"all chosen", GuestMeal{Starter: "s2", Main: "m1"}, nil},
+		{"nothing chosen", GuestMeal{}, []string{CourseStarter, CourseMain}},
+		{"dish taken off the menu", GuestMeal{Starter: "s9", Main: "m1"}, []string{CourseStarter}},
+		{"dessert isn't offered", GuestMeal{Starter: "s1", Main: "m1", Dessert: "d1"}, nil},
+	}
+	for _, tt := range tests {
+		if got := MissingCourses(tt.meal, testMenu); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: MissingCourses() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPeopleWithoutMeals(t *testing.T) {
+	rsvp := RSVPRecord{
+		AttendingGuests: []string{"Jane Smith", "John Smith"},
+		PlusOnes:        []string{"Sam"},
+		GuestMeals:      []GuestMeal{{GuestName: "Jane Smith", Starter: "s1", Main: "m1"}, {GuestName: "Sam", Main: "m1"}},
+	}
+	if got, want := PeopleWithoutMeals(rsvp, testMenu), []string{"John Smith", "Sam"}; !slices.Equal(got, want) {
+		t.Errorf("PeopleWithoutMeals() = %v, want %v", got, want)
+	}
+	if got := PeopleWithoutMeals(rsvp, nil); got != nil {
+		t.Errorf("PeopleWithoutMeals(no menu) = %v, want none", got)
+	}
+	if got := MenuOptionName("m1", testMenu) + "," + MenuOptionName("gone", testMenu); got != "Risotto,gone" {
+		t.Errorf("MenuOptionName() = %s", got)
+	}
+}
@@ -0,0 +1,594 @@
+package shared
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process Store for offline development and demos.
+// Data lives only as long as the process.
+type MemoryStore struct {
+	mu         sync.Mutex
+	guests     []Guest
+	households []Household
+	menu       []MenuOption
+	events     []Event
+	rsvps      []RSVPRecord
+	admins     []AdminAccount
+	outbox     []OutboxEmail
+}
+
+var (
+	memoryStore     *MemoryStore
+	memoryStoreOnce sync.Once
+)
+
+// NewMemoryStore creates an in-memory store seeded with DefaultEvents and
+// guests, grouped into households and invited to events the same way as an
+// import
+func NewMemoryStore(guests []Guest) *MemoryStore {
+	ms := &MemoryStore{events: append([]Event{}, DefaultEvents...)}
+	seeded := make([]Guest, len(guests))
+	copy(seeded, guests)
+	ResolveHouseholds(ms, seeded)
+	ResolveEventInvitations(ms, seeded)
+	for _, g := range seeded {
+		if g.ID == "" {
+			g.ID = newID()
+		}
+		ms.guests = append(ms.guests, g)
+	}
+	return ms
+}
+
+// defaultMemoryStore returns the process-wide memory store, seeded with the
+// development guest list on first use
+func defaultMemoryStore() *MemoryStore {
+	memoryStoreOnce.Do(func() {
+		memoryStore = NewMemoryStore(getDefaultGuestList())
+	})
+	return memoryStore
+}
+
+// ListGuests returns a copy of the invite list, ordered by address then name
+func (ms *MemoryStore) ListGuests() ([]Guest, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	guests := make([]Guest, len(ms.guests))
+	copy(guests, ms.guests)
+	sort.SliceStable(guests, func(i, j int) bool {
+		if guests[i].Address != guests[j].Address {
+			return guests[i].Address < guests[j].Address
+		}
+		return guests[i].Name < guests[j].Name
+	})
+	return guests, nil
+}
+
+// FindGuestByName looks up a guest by normalised name (see NormalizeString)
+func (ms *MemoryStore) FindGuestByName(name string) (*Guest, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if g := findGuestByNormalizedName(name, ms.guests); g != nil {
+		found := *g
+		return &found, nil
+	}
+	return nil, nil
+}
+
+// AddGuest appends a guest to the invite list
+func (ms *MemoryStore) AddGuest(guest Guest) error {
+	ms.mu.Lock()
+	guest.ID = newID()
+	syncCeremony(&guest)
+	ms.guests = append(ms.guests, guest)
+	ms.mu.Unlock()
+
+	ClearGuestCache()
+	return nil
+}
+
+// SetGuestDietary replaces the dietary requirements of the guest with id
+func (ms *MemoryStore) SetGuestDietary(id, dietary string, categories []string) error {
+	ms.mu.Lock()
+	found := false
+	for i := range ms.guests {
+		if ms.guests[i].ID == id {
+			ms.guests[i].Dietary = dietary
+			ms.guests[i].DietCategories = categories
+			found = true
+		}
+	}
+	ms.mu.Unlock()
+
+	if !found {
+		return fmt.Errorf("guest %s not found", id)
+	}
+	ClearGuestCache()
+	return nil
+}
+
+// UpdateGuest replaces the invite list details of the guest with guest.ID
+func (ms *MemoryStore) UpdateGuest(guest Guest) error {
+	syncCeremony(&guest)
+	ms.mu.Lock()
+	found := false
+	for i := range ms.guests {
+		if g := &ms.guests[i]; g.ID == guest.ID {
+			g.Name, g.Address, g.HouseholdID = guest.Name, guest.Address, guest.HouseholdID
+			g.Ceremony, g.Events, g.PlusOnes, g.Table = guest.Ceremony, guest.Events, guest.PlusOnes, guest.Table
+			found = true
+		}
+	}
+	ms.mu.Unlock()
+
+	if !found {
+		return fmt.Errorf("guest %s not found", guest.ID)
+	}
+	ClearGuestCache()
+	return nil
+}
+
+// DeleteGuest removes the guest with id from the invite list
+func (ms *MemoryStore) DeleteGuest(id string) error {
+	ms.mu.Lock()
+	found := false
+	for i := range ms.guests {
+		if ms.guests[i].ID == id {
+			ms.guests = append(ms.guests[:i], ms.guests[i+1:]...)
+			found = true
+			break
+		}
+	}
+	ms.mu.Unlock()
+
+	if !found {
+		return fmt.Errorf("guest %s not found", id)
+	}
+	ClearGuestCache()
+	return nil
+}
+
+// ListHouseholds returns a copy of every household, ordered by name
+func (ms *MemoryStore) ListHouseholds() ([]Household, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	households := make([]Household, len(ms.households))
+	copy(households, ms.households)
+	sort.Slice(households, func(i, j int) bool { return households[i].Name < households[j].Name })
+	return households, nil
+}
+
+// FindOrCreateHousehold looks up a household by normalised name, adding it
+// if it doesn't exist
+func (ms *MemoryStore) FindOrCreateHousehold(name string) (*Household, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	name = strings.TrimSpace(name)
+	if h := findHouseholdByNormalizedName(name, ms.households); h != nil {
+		found := *h
+		return &found, nil
+	}
+	h := Household{ID: newID(), Name: name, CreatedAt: time.Now().UTC().Format(time.RFC3339)}
+	ms.households = append(ms.households, h)
+	return &h, nil
+}
+
+// SetHouseholdInviteCode replaces the invite code of the household with id
+func (ms *MemoryStore) SetHouseholdInviteCode(id, code string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range ms.households {
+		if ms.households[i].ID == id {
+			ms.households[i].InviteCode = code
+			return nil
+		}
+	}
+	return fmt.Errorf("household %s not found", id)
+}
+
+// ListMenuOptions returns a copy of the menu, ordered by course
+func (ms *MemoryStore) ListMenuOptions() ([]MenuOption, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	menu := make([]MenuOption, len(ms.menu))
+	copy(menu, ms.menu)
+	sortMenu(menu)
+	return menu, nil
+}
+
+// AddMenuOption appends a dish to the menu
+func (ms *MemoryStore) AddMenuOption(option MenuOption) (*MenuOption, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	option.ID = newID()
+	option.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	ms.menu = append(ms.menu, option)
+	return &option, nil
+}
+
+// DeleteMenuOption removes the dish with id from the menu
+func (ms *MemoryStore) DeleteMenuOption(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range ms.menu {
+		if ms.menu[i].ID == id {
+			ms.menu = append(ms.menu[:i], ms.menu[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("menu option %s not found", id)
+}
+
+// ListEvents returns a copy of every event, in running order
+func (ms *MemoryStore) ListEvents() ([]Event, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	events := make([]Event, len(ms.events))
+	copy(events, ms.events)
+	sortEvents(events)
+	return events, nil
+}
+
+// SaveEvent adds the event, or replaces the one with the same ID
+func (ms *MemoryStore) SaveEvent(event Event) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range ms.events {
+		if ms.events[i].ID == event.ID {
+			ms.events[i] = event
+			return nil
+		}
+	}
+	ms.events = append(ms.events, event)
+	return nil
+}
+
+// ListRSVPs returns a copy of every RSVP, newest first
+func (ms *MemoryStore) ListRSVPs() ([]RSVPRecord, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	rsvps := make([]RSVPRecord, 0, len(ms.rsvps))
+	for i := len(ms.rsvps) - 1; i >= 0; i-- {
+		rsvps = append(rsvps, ms.rsvps[i])
+	}
+	return rsvps, nil
+}
+
+// SaveRSVP appends an RSVP submission
+func (ms *MemoryStore) SaveRSVP(rsvp RSVPRequest) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.rsvps = append(ms.rsvps, RSVPRecord{
+		ID:              newID(),
+		Name:            rsvp.Name,
+		Email:           rsvp.Email,
+		IsAttending:     rsvp.IsAttending,
+		AttendingGuests: nonNilStrings(rsvp.AttendingGuests),
+		PlusOnes:        nonNilStrings(rsvp.PlusOnes),
+		GuestDiets:      rsvp.GuestDiets,
+		GuestMeals:      rsvp.GuestMeals,
+		GuestEvents:     rsvp.GuestEvents,
+		Diet:            rsvp.Diet,
+		SubmittedAt:     time.Now().UTC().Format(time.RFC3339Nano),
+		Verified:        rsvp.Verified,
+		Late:            rsvp.LateRequest,
+	})
+	return nil
+}
+
+// VerifyRSVPs marks every RSVP for email as verified and applies update
+func (ms *MemoryStore) VerifyRSVPs(email string, update RSVPUpdate) ([]RSVPRecord, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	var updated []RSVPRecord
+	for i := range ms.rsvps {
+		if ms.rsvps[i].Email != email {
+			continue
+		}
+		ms.rsvps[i].Verified = true
+		if name := strings.TrimSpace(update.Name); name != "" {
+			ms.rsvps[i].Name = name
+		}
+		if update.AttendingGuests != nil {
+			ms.rsvps[i].AttendingGuests = cleanGuestNames(update.AttendingGuests)
+		}
+		updated = append(updated, ms.rsvps[i])
+	}
+	return updated, nil
+}
+
+// DeleteRSVPs removes every RSVP for email
+func (ms *MemoryStore) DeleteRSVPs(email string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	kept := ms.rsvps[:0]
+	for _, rsvp := range ms.rsvps {
+		if rsvp.Email != email {
+			kept = append(kept, rsvp)
+		}
+	}
+	ms.rsvps = kept
+	return nil
+}
+
+// DeleteRSVP removes the RSVP with id
+func (ms *MemoryStore) DeleteRSVP(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	kept := ms.rsvps[:0]
+	for _, rsvp := range ms.rsvps {
+		if rsvp.ID != id {
+			kept = append(kept, rsvp)
+		}
+	}
+	ms.rsvps = kept
+	return nil
+}
+
+// LatestRSVP returns a copy of the most recent RSVP for email
+func (ms *MemoryStore) LatestRSVP(email string) (*RSVPRecord, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := len(ms.rsvps) - 1; i >= 0; i-- {
+		if ms.rsvps[i].Email == email {
+			found := ms.rsvps[i]
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateRSVP applies changes to the RSVP with id
+func (ms *MemoryStore) UpdateRSVP(id string, changes RSVPChanges) (*RSVPRecord, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range ms.rsvps {
+		if ms.rsvps[i].ID != id {
+			continue
+		}
+		if changes.IsAttending != nil {
+			ms.rsvps[i].IsAttending = *changes.IsAttending
+		}
+		if changes.AttendingGuests != nil {
+			ms.rsvps[i].AttendingGuests = cleanGuestNames(changes.AttendingGuests)
+		}
+		if changes.PlusOnes != nil {
+			ms.rsvps[i].PlusOnes = cleanGuestNames(changes.PlusOnes)
+		}
+		if changes.GuestDiets != nil {
+			ms.rsvps[i].GuestDiets = changes.GuestDiets
+		}
+		if changes.GuestMeals != nil {
+			ms.rsvps[i].GuestMeals = changes.GuestMeals
+		}
+		if changes.GuestEvents != nil {
+			ms.rsvps[i].GuestEvents = changes.GuestEvents
+		}
+		if changes.Diet != nil {
+			ms.rsvps[i].Diet = *changes.Diet
+		}
+		if changes.AvatarData != nil {
+			ms.rsvps[i].AvatarData = changes.AvatarData
+		}
+		updated := ms.rsvps[i]
+		return &updated, nil
+	}
+	return nil, fmt.Errorf("RSVP %s not found", id)
+}
+
+// SaveAvatars stores avatars on the most recent RSVP for email
+func (ms *MemoryStore) SaveAvatars(email string, avatars []AvatarSelection) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := len(ms.rsvps) - 1; i >= 0; i-- {
+		if ms.rsvps[i].Email == email {
+			ms.rsvps[i].AvatarData = avatars
+			return nil
+		}
+	}
+	return nil
+}
+
+// ListAvatars returns the avatar selections of verified, attending RSVPs
+func (ms *MemoryStore) ListAvatars() ([]AvatarSelection, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	avatars := []AvatarSelection{}
+	for _, rsvp := range ms.rsvps {
+		if rsvp.IsAttending && rsvp.Verified {
+			avatars = append(avatars, rsvp.AvatarData...)
+		}
+	}
+	return avatars, nil
+}
+
+// SetOverride replaces any admin override for guestName with a new one
+func (ms *MemoryStore) SetOverride(guestName string, isAttending bool) error {
+	if err := ms.ClearOverride(guestName); err != nil {
+		return err
+	}
+	return ms.SaveRSVP(overrideRecord(guestName, isAttending))
+}
+
+// ClearOverride removes any admin override for guestName
+func (ms *MemoryStore) ClearOverride(guestName string) error {
+	return ms.DeleteRSVPs(OverrideEmail(guestName))
+}
+
+// ListAdmins returns a copy of every admin account, ordered by username
+func (ms *MemoryStore) ListAdmins() ([]AdminAccount, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	admins := make([]AdminAccount, len(ms.admins))
+	copy(admins, ms.admins)
+	sort.Slice(admins, func(i, j int) bool { return admins[i].Username < admins[j].Username })
+	return admins, nil
+}
+
+// GetAdmin looks up an admin account by username
+func (ms *MemoryStore) GetAdmin(username string) (*AdminAccount, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	username = NormalizeUsername(username)
+	for _, a := range ms.admins {
+		if a.Username == username {
+			found := a
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// SaveAdmin creates or updates an admin account
+func (ms *MemoryStore) SaveAdmin(account AdminAccount) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	account.Username = NormalizeUsername(account.Username)
+	account.UpdatedAt = now
+	for i := range ms.admins {
+		if ms.admins[i].Username == account.Username {
+			account.CreatedAt = ms.admins[i].CreatedAt
+			ms.admins[i] = account
+			return nil
+		}
+	}
+	account.CreatedAt = now
+	ms.admins = append(ms.admins, account)
+	return nil
+}
+
+// EnqueueEmail adds email to the outbox
+func (ms *MemoryStore) EnqueueEmail(email OutboxEmail) (*OutboxEmail, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	email.ID = newID()
+	email.CreatedAt = time.Now().UTC()
+	ms.outbox = append(ms.outbox, email)
+	return &email, nil
+}
+
+// UpdateOutboxEmail saves the delivery state of the outbox email with email.ID
+func (ms *MemoryStore) UpdateOutboxEmail(email OutboxEmail) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range ms.outbox {
+		if ms.outbox[i].ID == email.ID {
+			o := &ms.outbox[i]
+			o.Status, o.Attempts, o.Resends, o.LastError = email.Status, email.Attempts, email.Resends, email.LastError
+			o.NextAttemptAt, o.ProviderID, o.SentAt = email.NextAttemptAt, email.ProviderID, email.SentAt
+			return nil
+		}
+	}
+	return fmt.Errorf("outbox email %s not found", email.ID)
+}
+
+// GetOutboxEmail returns the outbox email with id, or nil
+func (ms *MemoryStore) GetOutboxEmail(id string) (*OutboxEmail, error) {
+	return ms.findOutboxEmail(func(e OutboxEmail) bool { return e.ID == id }), nil
+}
+
+// FindOutboxEmailByProviderID returns the outbox email Resend knows as providerID, or nil
+func (ms *MemoryStore) FindOutboxEmailByProviderID(providerID string) (*OutboxEmail, error) {
+	return ms.findOutboxEmail(func(e OutboxEmail) bool { return e.ProviderID != "" && e.ProviderID == providerID }), nil
+}
+
+func (ms *MemoryStore) findOutboxEmail(match func(OutboxEmail) bool) *OutboxEmail {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, e := range ms.outbox {
+		if match(e) {
+			return &e
+		}
+	}
+	return nil
+}
+
+// ListOutboxEmails returns the outbox emails with any of statuses, newest first
+func (ms *MemoryStore) ListOutboxEmails(statuses ...string) ([]OutboxEmail, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	emails := []OutboxEmail{}
+	for i := len(ms.outbox) - 1; i >= 0; i-- {
+		if len(statuses) == 0 || slices.Contains(statuses, ms.outbox[i].Status) {
+			emails = append(emails, ms.outbox[i])
+		}
+	}
+	return emails, nil
+}
+
+// DueOutboxEmails returns up to limit queued emails due by now, and sending
+// ones whose lease ran out, oldest first
+func (ms *MemoryStore) DueOutboxEmails(now time.Time, limit int) ([]OutboxEmail, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	emails := []OutboxEmail{}
+	for _, e := range ms.outbox {
+		if len(emails) == limit {
+			break
+		}
+		if outboxEmailDue(e, now) {
+			emails = append(emails, e)
+		}
+	}
+	return emails, nil
+}
+
+// ClaimOutboxEmail marks the outbox email with id as sending until
+// leaseUntil, if it is one of from and due by now
+func (ms *MemoryStore) ClaimOutboxEmail(id string, from []string, now, leaseUntil time.Time) (*OutboxEmail, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range ms.outbox {
+		o := &ms.outbox[i]
+		if o.ID != id {
+			continue
+		}
+		if !slices.Contains(from, o.Status) || ((o.Status == EmailQueued || o.Status == EmailSending) && !outboxEmailDue(*o, now)) {
+			return nil, nil
+		}
+		o.Status, o.NextAttemptAt = EmailSending, leaseUntil
+		claimed := *o
+		return &claimed, nil
+	}
+	return nil, nil
+}
+
+// outboxEmailDue reports whether e is queued and due another attempt by
+// now, or was claimed for sending under a lease that ran out by now
+func outboxEmailDue(e OutboxEmail, now time.Time) bool {
+	return (e.Status == EmailQueued || e.Status == EmailSending) && !e.NextAttemptAt.After(now)
+}
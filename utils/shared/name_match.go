@@ -0,0 +1,294 @@
+package shared
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// MatchConfidence says how far a fuzzy name match can be trusted
+type MatchConfidence int
+
+const (
+	// MatchNone means the candidate is not a plausible match
+	MatchNone MatchConfidence = iota
+	// MatchMedium is worth offering as a "did you mean" suggestion
+	MatchMedium
+	// MatchHigh is close enough to accept without asking the guest
+	MatchHigh
+)
+
+// Score thresholds for each confidence level. A high score that is nearly
+// tied with another candidate is downgraded to medium, since picking one
+// would be a guess.
+const (
+	highMatchScore   = 0.85
+	mediumMatchScore = 0.6
+	ambiguousMargin  = 0.05
+)
+
+// CodeDidYouMean marks a verify-name response carrying suggestions instead
+// of a match
+const CodeDidYouMean = "did_you_mean"
+
+// maxSuggestions caps how many "did you mean" names verify-name returns
+const maxSuggestions = 3
+
+// GuestMatch is a scored candidate from the invite list
+type GuestMatch struct {
+	Guest      Guest
+	Score      float64 // 0-1, 1 for an exact match
+	Confidence MatchConfidence
+}
+
+// defaultNicknames are groups of given names treated as the same person.
+// Extend them with NICKNAMES_FILE: one group per line, names separated by
+// commas, "#" starts a comment.
+var defaultNicknames = [][]string{
+	{"jonathan", "jon", "john", "johnny", "jonny", "jack"},
+	{"william", "will", "bill", "billy", "liam"},
+	{"robert", "rob", "bob", "bobby", "robbie", "bert"},
+	{"richard", "rich", "rick", "dick"},
+	{"elizabeth", "liz", "lizzie", "beth", "betty", "eliza"},
+	{"katherine", "catherine", "kate", "katie", "cathy", "kathy", "kat"},
+	{"margaret", "maggie", "meg", "peggy"},
+	{"michael", "mike", "mick", "mikey"},
+	{"thomas", "tom", "tommy"},
+	{"james", "jim", "jimmy", "jamie"},
+	{"alexander", "alex", "sasha", "xander"},
+	{"alexandra", "alex", "sasha", "lexi"},
+	{"christopher", "chris", "kit"},
+	{"christine", "christina", "chris", "tina"},
+	{"daniel", "dan", "danny"},
+	{"david", "dave", "davey"},
+	{"edward", "ed", "eddie", "ted", "ned"},
+	{"jemima", "jem", "mima"},
+	{"joseph", "joe", "joey"},
+	{"matthew", "matt"},
+	{"nicholas", "nick", "nicky"},
+	{"samuel", "sam", "sammy"},
+	{"samantha", "sam", "sammy"},
+	{"stephen", "steven", "steve"},
+	{"rebecca", "becky", "becca"},
+	{"jennifer", "jen", "jenny"},
+	{"victoria", "vicky", "tori"},
+	{"benjamin", "ben", "benny"},
+	{"anthony", "tony"},
+	{"andrew", "andy", "drew"},
+	{"marko", "marco", "mark"},
+	{"aleksandar", "aleksandra", "aca", "saša"},
+	{"đorđe", "george"},
+	{"nikola", "nik", "nikolas"},
+	{"jovana", "jova"},
+	{"milica", "mica"},
+}
+
+var (
+	nicknameIndex     map[string]map[string]bool
+	nicknameIndexOnce sync.Once
+)
+
+// nicknames returns, for each known given name, the set of names it is
+// interchangeable with
+func nicknames() map[string]map[string]bool {
+	nicknameIndexOnce.Do(func() {
+		groups := defaultNicknames
+		if path := os.Getenv("NICKNAMES_FILE"); path != "" {
+			extra, err := loadNicknameFile(path)
+			if err != nil {
+				log.Printf("⚠️  Could not load NICKNAMES_FILE %s: %v", path, err)
+			}
+			groups = append(append([][]string{}, groups...), extra...)
+		}
+
+		nicknameIndex = make(map[string]map[string]bool)
+		for _, group := range groups {
+			for _, a := range group {
+				a = foldName(a)
+				if nicknameIndex[a] == nil {
+					nicknameIndex[a] = make(map[string]bool)
+				}
+				for _, b := range group {
+					nicknameIndex[a][foldName(b)] = true
+				}
+			}
+		}
+	})
+	return nicknameIndex
+}
+
+// loadNicknameFile reads nickname groups, one comma-separated group per line
+func loadNicknameFile(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var groups [][]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		var group []string
+		for _, name := range strings.Split(line, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				group = append(group, name)
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return groups, scanner.Err()
+}
+
+// foldName normalises s (see NormalizeString) and reduces punctuation to
+// spaces, so "Zoë O'Brien-Smith" becomes "zoe obrien smith"
+func foldName(s string) string {
+	var b strings.Builder
+	for _, r := range NormalizeString(s) {
+		switch {
+		case r == '\'' || r == '’' || r == '.':
+			// "O'Brien" and "St. John" read as one word
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// tokenSimilarity scores how alike two folded name tokens are, from 0 to 1
+func tokenSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if nicknames()[a][b] {
+		return 0.95
+	}
+	// An initial ("J") matches any name starting with that letter
+	if len([]rune(a)) == 1 || len([]rune(b)) == 1 {
+		if []rune(a)[0] == []rune(b)[0] {
+			return 0.7
+		}
+		return 0
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	sim := 1 - float64(editDistance(ra, rb))/float64(longest)
+	if sim < 0.5 {
+		return 0
+	}
+	return sim
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions and adjacent transpositions each cost 1
+func editDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// nameScore compares a typed name with a guest-list name, ignoring token
+// order. Every typed token counts fully; guest-list tokens the guest left
+// out (usually a middle name) only cost a quarter each.
+func nameScore(typed, listed string) float64 {
+	q, c := strings.Fields(foldName(typed)), strings.Fields(foldName(listed))
+	if len(q) == 0 || len(c) == 0 {
+		return 0
+	}
+	if strings.Join(q, " ") == strings.Join(c, " ") {
+		return 1
+	}
+
+	// Greedily pair the most similar tokens
+	usedQ, usedC := make([]bool, len(q)), make([]bool, len(c))
+	total, unmatchedC := 0.0, len(c)
+	for {
+		best, bi, bj := 0.0, -1, -1
+		for i := range q {
+			for j := range c {
+				if !usedQ[i] && !usedC[j] {
+					if s := tokenSimilarity(q[i], c[j]); s > best {
+						best, bi, bj = s, i, j
+					}
+				}
+			}
+		}
+		if bi < 0 {
+			break
+		}
+		usedQ[bi], usedC[bj] = true, true
+		total += best
+		unmatchedC--
+	}
+	return total / (float64(len(q)) + 0.25*float64(unmatchedC))
+}
+
+// MatchGuests scores every guest against name and returns the plausible
+// matches, best first. Only the top match can be MatchHigh, and only when
+// no other candidate comes close.
+func MatchGuests(name string, guestList []Guest) []GuestMatch {
+	var matches []GuestMatch
+	for _, g := range guestList {
+		if score := nameScore(name, g.Name); score >= mediumMatchScore {
+			matches = append(matches, GuestMatch{Guest: g, Score: score, Confidence: MatchMedium})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+
+	if len(matches) > 0 && matches[0].Score >= highMatchScore {
+		if matches[0].Score == 1 || len(matches) == 1 || matches[0].Score-matches[1].Score > ambiguousMargin {
+			matches[0].Confidence = MatchHigh
+		}
+	}
+	return matches
+}
+
+// Suggestions returns up to three guests worth offering as "did you mean"
+// for name, from its matches, when there is no confident match. A name of
+// one word gets none: a surname alone would list everyone who shares it.
+func Suggestions(name string, matches []GuestMatch) []Guest {
+	if len(strings.Fields(foldName(name))) < 2 {
+		return nil
+	}
+	var guests []Guest
+	for _, m := range matches {
+		if m.Confidence == MatchNone || len(guests) == maxSuggestions {
+			break
+		}
+		guests = append(guests, m.Guest)
+	}
+	return guests
+}
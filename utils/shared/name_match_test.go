@@ -0,0 +1,143 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var matchTestGuests = []Guest{
+	{ID: "1", Name: "John Smith"},
+	{ID: "2", Name: "Jane Smith"},
+	{ID: "3", Name: "Đorđe Petrović"},
+	{ID: "4", Name: "Mary Anne Williams"},
+	{ID: "5", Name: "Zoë O'Brien-Smith"},
+	{ID: "6", Name: "Milena Petrović"},
+	{ID: "7", Name: "Milan Petrović"},
+}
+
+func TestFindGuest(t *testing.T) {
+	tests := []struct {
+		name   string
+		typed  string
+		wantID string // "" for no match
+	}{
+		{"exact", "John Smith", "1"},
+		{"case and spacing", "  john   SMITH ", "1"},
+		{"nickname", "Jon Smith", "1"},
+		{"token order", "Smith John", "1"},
+		{"typo", "Jane Smiht", "2"},
+		{"transliterated", "Djordje Petrovic", "3"},
+		{"cyrillic", "Ђорђе Петровић", "3"},
+		{"dj typed as d", "Dorde Petrovic", "3"},
+		{"middle name left out", "Mary Williams", "4"},
+		{"punctuation", "Zoe OBrien Smith", "5"},
+		{"ambiguous", "Milen Petrovic", ""},
+		{"surname only", "Smith", ""},
+		{"stranger", "Bob Jones", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindGuest(tt.typed, matchTestGuests)
+			gotID := ""
+			if got != nil {
+				gotID = got.ID
+			}
+			if gotID != tt.wantID {
+				t.Errorf("FindGuest(%q) = guest %q, want %q", tt.typed, gotID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestMatchGuests(t *testing.T) {
+	tests := []struct {
+		name     string
+		typed    string
+		wantTop  string // ID of the best match, "" for none
+		wantConf MatchConfidence
+	}{
+		{"exact is high", "Jane Smith", "2", MatchHigh},
+		{"close typo is high", "Jane Smiht", "2", MatchHigh},
+		{"near tie is medium", "Milen Petrovic", "6", MatchMedium},
+		{"distant is medium", "Mile Petrovic", "6", MatchMedium},
+		{"unrelated is none", "Bob Jones", "", MatchNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := MatchGuests(tt.typed, matchTestGuests)
+			if tt.wantTop == "" {
+				if len(matches) > 0 {
+					t.Errorf("MatchGuests(%q) = %+v, want none", tt.typed, matches)
+				}
+				return
+			}
+			if len(matches) == 0 {
+				t.Fatalf("MatchGuests(%q) found nothing, want %q", tt.typed, tt.wantTop)
+			}
+			if matches[0].Guest.ID != tt.wantTop || matches[0].Confidence != tt.wantConf {
+				t.Errorf("MatchGuests(%q) top = %q (%v), want %q (%v)",
+					tt.typed, matches[0].Guest.ID, matches[0].Confidence, tt.wantTop, tt.wantConf)
+			}
+			for i := 1; i < len(matches); i++ {
+				if matches[i].Confidence == MatchHigh {
+					t.Errorf("MatchGuests(%q)[%d] is high; only the top match may be", tt.typed, i)
+				}
+				if matches[i].Score > matches[i-1].Score {
+					t.Errorf("MatchGuests(%q) not sorted best first at %d", tt.typed, i)
+				}
+			}
+		})
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	many := []Guest{
+		{ID: "1", Name: "Ana Petrović"},
+		{ID: "2", Name: "Ana Petrovic"},
+		{ID: "3", Name: "Ane Petrović"},
+		{ID: "4", Name: "Ana Petrova"},
+	}
+	tests := []struct {
+		name   string
+		typed  string
+		guests []Guest
+		want   []string
+	}{
+		{"medium matches", "Milen Petrovic", matchTestGuests, []string{"Milena Petrović", "Milan Petrović"}},
+		{"single word", "Petrovic", matchTestGuests, nil},
+		{"single word with punctuation", "Petrović-", matchTestGuests, nil},
+		{"nothing close", "Bob Jones", matchTestGuests, nil},
+		{"capped at three", "Ana Petrovi", many, []string{"Ana Petrović", "Ana Petrovic", "Ana Petrova"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, g := range Suggestions(tt.typed, MatchGuests(tt.typed, tt.guests)) {
+				got = append(got, g.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Suggestions(%q) = %v, want %v", tt.typed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadNicknameFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nicknames.txt")
+	os.WriteFile(path, []byte("# family names\nMarija, Maja , Mara\n\nsolo\nDuško, Dule # uncle\n"), 0o644)
+
+	groups, err := loadNicknameFile(path)
+	if err != nil {
+		t.Fatalf("loadNicknameFile() error = %v", err)
+	}
+	want := [][]string{{"Marija", "Maja", "Mara"}, {"Duško", "Dule"}}
+	if !slices.EqualFunc(groups, want, slices.Equal[[]string]) {
+		t.Errorf("loadNicknameFile() = %v, want %v", groups, want)
+	}
+	if _, err := loadNicknameFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("loadNicknameFile(missing) succeeded")
+	}
+}
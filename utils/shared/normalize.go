@@ -0,0 +1,66 @@
+package shared
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// serbianCyrillic transliterates lowercase Serbian Cyrillic to Latin (Gaj's
+// alphabet). Diacritics are folded afterwards.
+var serbianCyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'ђ': "đ", 'е': "e",
+	'ж': "ž", 'з': "z", 'и': "i", 'ј': "j", 'к': "k", 'л': "l", 'љ': "lj",
+	'м': "m", 'н': "n", 'њ': "nj", 'о': "o", 'п': "p", 'р': "r", 'с': "s",
+	'т': "t", 'ћ': "ć", 'у': "u", 'ф': "f", 'х': "h", 'ц': "c", 'ч': "č",
+	'џ': "dž", 'ш': "š",
+}
+
+// latinLetters folds Latin letters that carry no combining mark, so
+// stripping diacritics alone leaves them untouched
+var latinLetters = map[rune]string{
+	'đ': "d", 'ł': "l", 'ø': "o", 'æ': "ae", 'œ': "oe", 'ß': "ss", 'þ': "th", 'ı': "i",
+}
+
+// stripMarks removes combining marks after canonical decomposition, so
+// "č" becomes "c"
+var stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// NormalizeString reduces a name to the form used for every comparison and
+// map key in the API: Unicode NFKC, lowercased, Serbian Cyrillic
+// transliterated to Latin, diacritics folded and whitespace collapsed.
+// "Đ" is spelt "Dj" or plain "D" without diacritics, so all three fold to
+// "d" and "Đorđe", "Djordje", "Dorde" and "Ђорђе" share one key. A name
+// spelt with "dj" for another reason, such as "Adjani", folds the same way,
+// which only matters if the list also holds an "Adani".
+func NormalizeString(s string) string {
+	s = strings.ToLower(norm.NFKC.String(s))
+
+	var b strings.Builder
+	for _, r := range s {
+		if latin, ok := serbianCyrillic[r]; ok {
+			b.WriteString(latin)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	folded, _, err := transform.String(stripMarks, b.String())
+	if err != nil {
+		folded = b.String()
+	}
+
+	b.Reset()
+	for _, r := range folded {
+		if latin, ok := latinLetters[r]; ok {
+			b.WriteString(latin)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.ReplaceAll(strings.Join(strings.Fields(b.String()), " "), "dj", "d")
+}
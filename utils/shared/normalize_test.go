@@ -0,0 +1,42 @@
+package shared
+
+import "testing"
+
+func TestNormalizeString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"lowercased", "John SMITH", "john smith"},
+		{"whitespace collapsed", "  John \t Smith\n", "john smith"},
+		{"diacritics folded", "Zoë Brontë", "zoe bronte"},
+		{"serbian latin", "Čedomir Šćepanović", "cedomir scepanovic"},
+		{"đ", "Đorđe Petrović", "dorde petrovic"},
+		{"serbian cyrillic", "Ђорђе Петровић", "dorde petrovic"},
+		{"cyrillic digraphs", "Љиљана Његош Џаја", "ljiljana njegos dzaja"},
+		{"dj spelling", "Djordje", "dorde"},
+		{"dj inside a name", "Isabelle Adjani", "isabelle adani"},
+		{"letters without marks", "Łukasz Østergård Straße", "lukasz ostergard strasse"},
+		{"nfkc", "ｊｏｈｎ ﬁnn", "john finn"},
+		{"decomposed input", "Zoë", "zoe"},
+		{"empty", "", ""},
+		{"spaces only", "   ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeString(tt.in); got != tt.want {
+				t.Errorf("NormalizeString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeStringDjordje(t *testing.T) {
+	want := NormalizeString("Đorđe")
+	for _, spelling := range []string{"Dorde", "Djordje", "DJORDJE", "Đorđe", "ĐORĐE", "Ђорђе"} {
+		if got := NormalizeString(spelling); got != want {
+			t.Errorf("NormalizeString(%q) = %q, want %q like Đorđe", spelling, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package shared
+
+// HouseholdPlusOnes returns how many plus-ones guest's household may bring
+// between them: the sum of every member's allowance
+func HouseholdPlusOnes(guest Guest, guestList []Guest) int {
+	if guest.HouseholdID == "" {
+		return guest.PlusOnes
+	}
+	total := 0
+	for _, g := range guestList {
+		if g.HouseholdID == guest.HouseholdID {
+			total += g.PlusOnes
+		}
+	}
+	return total
+}
+
+// PlusOneAllowance returns how many plus-ones an RSVP may name: the sum of
+// the allowances of its attending guests who are on the invite list. A
+// guest who isn't coming can't bring anyone.
+func PlusOneAllowance(attendingGuests []string, guestList []Guest) int {
+	counted := make(map[string]bool, len(attendingGuests))
+	total := 0
+	for _, name := range attendingGuests {
+		guest := FindGuest(name, guestList)
+		if guest == nil || counted[guest.ID] {
+			continue
+		}
+		counted[guest.ID] = true
+		total += guest.PlusOnes
+	}
+	return total
+}
+
+// CheckPlusOnes trims the plus-one names on an RSVP, dropping blanks, and
+// reports whether they fit within the attending guests' allowance (see
+// PlusOneAllowance)
+func CheckPlusOnes(plusOnes, attendingGuests []string, guestList []Guest) ([]string, bool) {
+	cleaned := cleanGuestNames(plusOnes)
+	return cleaned, len(cleaned) <= PlusOneAllowance(attendingGuests, guestList)
+}
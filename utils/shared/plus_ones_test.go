@@ -0,0 +1,57 @@
+package shared
+
+import (
+	"slices"
+	"testing"
+)
+
+var plusOneTestGuests = []Guest{
+	{ID: "1", Name: "Jane Smith", HouseholdID: "smiths", PlusOnes: 1},
+	{ID: "2", Name: "John Smith", HouseholdID: "smiths", PlusOnes: 2},
+	{ID: "3", Name: "Anna Smith", HouseholdID: "smiths"},
+	{ID: "4", Name: "Bob Jones", PlusOnes: 1},
+	{ID: "5", Name: "Eve Brown"},
+}
+
+func TestHouseholdPlusOnes(t *testing.T) {
+	tests := []struct {
+		guest Guest
+		want  int
+	}{
+		{plusOneTestGuests[2], 3},
+		{plusOneTestGuests[3], 1},
+		{plusOneTestGuests[4], 0},
+	}
+	for _, tt := range tests {
+		if got := HouseholdPlusOnes(tt.guest, plusOneTestGuests); got != tt.want {
+			t.Errorf("HouseholdPlusOnes(%s) = %d, want %d", tt.guest.Name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPlusOnes(t *testing.T) {
+	tests := []struct {
+		name      string
+		plusOnes  []string
+		attending []string
+		want      []string
+		wantOK    bool
+	}{
+		{"within allowance", []string{"Sam"}, []string{"Jane Smith"}, []string{"Sam"}, true},
+		{"allowances add up", []string{"Sam", "Kit", "Lee"}, []string{"Jane Smith", "John Smith"}, []string{"Sam", "Kit", "Lee"}, true},
+		{"over allowance", []string{"Sam", "Kit"}, []string{"Jane Smith"}, []string{"Sam", "Kit"}, false},
+		{"absent guest's allowance doesn't count", []string{"Sam"}, []string{"Anna Smith"}, []string{"Sam"}, false},
+		{"guest listed twice counts once", []string{"Sam", "Kit"}, []string{"Jane Smith", "jane smith"}, []string{"Sam", "Kit"}, false},
+		{"unlisted guest has none", []string{"Sam"}, []string{"Zed Stranger"}, []string{"Sam"}, false},
+		{"blanks dropped", []string{" Sam ", "", " "}, []string{"Bob Jones"}, []string{"Sam"}, true},
+		{"none", nil, []string{"Eve Brown"}, []string{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := CheckPlusOnes(tt.plusOnes, tt.attending, plusOneTestGuests)
+			if !slices.Equal(got, tt.want) || ok != tt.wantOK {
+				t.Errorf("CheckPlusOnes(%q, %q) = %q, %v, want %q, %v", tt.plusOnes, tt.attending, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
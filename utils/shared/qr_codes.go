@@ -0,0 +1,180 @@
+package shared
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QR code image formats
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+)
+
+// Sizes of PNG QR codes, in pixels. SVGs scale to any size.
+const (
+	DefaultQRSize = 512
+	MaxQRSize     = 4096
+)
+
+// QRContentType returns the MIME type of QR code images in format
+func QRContentType(format string) string {
+	if format == QRFormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// ParseQRFormat checks a format given by the admin ("" for PNG)
+func ParseQRFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "", QRFormatPNG:
+		return QRFormatPNG, nil
+	case QRFormatSVG:
+		return QRFormatSVG, nil
+	default:
+		return "", fmt.Errorf("unknown QR code format %q (expected png or svg)", format)
+	}
+}
+
+// WriteQRCode renders content as a QR code image: a size x size pixel PNG,
+// or an SVG drawn in modules that the designer can scale. Medium error
+// correction leaves room for a printed code to be scuffed.
+func WriteQRCode(w io.Writer, content, format string, size int) error {
+	qr, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("failed to encode QR code: %v", err)
+	}
+	if format != QRFormatSVG {
+		png, err := qr.PNG(size)
+		if err != nil {
+			return fmt.Errorf("failed to render QR code: %v", err)
+		}
+		_, err = w.Write(png)
+		return err
+	}
+
+	// One path of unit squares for the dark modules, quiet zone included
+	bitmap := qr.Bitmap()
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	_, err = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %[1]d %[1]d" width="%[2]d" height="%[2]d" shape-rendering="crispEdges">
+<rect width="%[1]d" height="%[1]d" fill="#fff"/>
+<path d="%[3]s" fill="#000"/>
+</svg>
+`, len(bitmap), size, path.String())
+	return err
+}
+
+// HouseholdInviteQRContent is what a household's invitation QR code
+// encodes: its invite link (see InviteURL), or "" if it has no invite code
+func HouseholdInviteQRContent(household Household) string {
+	if household.InviteCode == "" {
+		return ""
+	}
+	return InviteURL(household.InviteCode)
+}
+
+// WriteQRCodesZip writes a zip of QR codes for the stationery, one folder
+// per household named after it:
+//
+//	smiths/invite.png      the household's invite link
+//	smiths/john-smith.png  John Smith's check-in pass
+//
+// Guests outside a household get a folder of their own. Households without
+// an invite code get no invite.png; run the invite-codes tool first.
+func WriteQRCodesZip(w io.Writer, guests []Guest, households []Household, format string, size int) error {
+	signer, err := NewCheckInPassSigner()
+	if err != nil {
+		return err
+	}
+
+	members := make(map[string][]Guest)
+	for _, g := range guests {
+		members[g.HouseholdID] = append(members[g.HouseholdID], g)
+	}
+
+	out := zip.NewWriter(w)
+	folders := make(map[string]bool)
+	add := func(folder, name, content string) error {
+		file, err := out.Create(folder + "/" + name + "." + format)
+		if err != nil {
+			return err
+		}
+		return WriteQRCode(file, content, format, size)
+	}
+
+	for _, h := range households {
+		if len(members[h.ID]) == 0 {
+			continue
+		}
+		folder := uniqueFileName(FileNameSlug(h.Name, "household"), folders)
+		if content := HouseholdInviteQRContent(h); content != "" {
+			if err := add(folder, "invite", content); err != nil {
+				return err
+			}
+		} else {
+			log.Printf("⚠️  Household %s has no invite code - skipping its invite QR code", h.Name)
+		}
+		names := map[string]bool{"invite": true}
+		for _, g := range members[h.ID] {
+			name := uniqueFileName(FileNameSlug(g.Name, "guest"), names)
+			if err := add(folder, name, signer.URL(g.ID)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, g := range members[""] {
+		folder := uniqueFileName(FileNameSlug(g.Name, "guest"), folders)
+		if err := add(folder, FileNameSlug(g.Name, "guest"), signer.URL(g.ID)); err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// FileNameSlug turns a name into a lowercase, dash-separated file name,
+// such as "the-smiths" for "The Smiths", or fallback if nothing is left
+func FileNameSlug(name, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range NormalizeString(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return fallback
+	}
+	return b.String()
+}
+
+// uniqueFileName returns name, or name-2, name-3... if taken, and marks it
+// taken
+func uniqueFileName(name string, taken map[string]bool) string {
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
@@ -0,0 +1,107 @@
+package shared
+
+import (
+	"archive/zip"
+	"bytes"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseQRFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{"", QRFormatPNG, false},
+		{"PNG", QRFormatPNG, false},
+		{" svg ", QRFormatSVG, false},
+		{"gif", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseQRFormat(tt.format)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseQRFormat(%q) = %q, %v, want %q (error %v)", tt.format, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWriteQRCode(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteQRCode(&buf, "https://wedding.example.com/?code=K7QD-M3XR", QRFormatPNG, 256); err != nil {
+		t.Fatalf("WriteQRCode(png) error = %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("WriteQRCode(png) isn't a PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 256 {
+		t.Errorf("PNG is %dx%d, want 256x256", b.Dx(), b.Dy())
+	}
+
+	buf.Reset()
+	if err := WriteQRCode(&buf, "https://wedding.example.com/?code=K7QD-M3XR", QRFormatSVG, 256); err != nil {
+		t.Fatalf("WriteQRCode(svg) error = %v", err)
+	}
+	svg := buf.String()
+	if !strings.HasPrefix(svg, "<?xml") || !strings.Contains(svg, `width="256"`) || !strings.Contains(svg, "<path d=\"M") {
+		t.Errorf("WriteQRCode(svg) = %q", svg)
+	}
+}
+
+func TestFileNameSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"The Smiths", "the-smiths"},
+		{"  Đorđe  Petrović ", "dorde-petrovic"},
+		{"O'Brien & Co.", "o-brien-co"},
+		{"???", "guest"},
+	}
+	for _, tt := range tests {
+		if got := FileNameSlug(tt.name, "guest"); got != tt.want {
+			t.Errorf("FileNameSlug(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteQRCodesZip(t *testing.T) {
+	t.Setenv("CHECKIN_PASS_SECRET", "secret")
+	households := []Household{
+		{ID: "h1", Name: "The Smiths", InviteCode: "K7QD-M3XR"},
+		{ID: "h2", Name: "the smiths"},
+		{ID: "h3", Name: "Empty"},
+	}
+	guests := []Guest{
+		{ID: "g1", Name: "John Smith", HouseholdID: "h1"},
+		{ID: "g2", Name: "Invite", HouseholdID: "h1"},
+		{ID: "g3", Name: "Jane Smith", HouseholdID: "h2"},
+		{ID: "g4", Name: "Sam Solo"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteQRCodesZip(&buf, guests, households, QRFormatSVG, 128); err != nil {
+		t.Fatalf("WriteQRCodesZip() error = %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("WriteQRCodesZip() isn't a zip: %v", err)
+	}
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	want := []string{
+		"the-smiths/invite.svg",
+		"the-smiths/john-smith.svg",
+		"the-smiths/invite-2.svg",
+		"the-smiths-2/jane-smith.svg",
+		"sam-solo/sam-solo.svg",
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("zip holds %q, want %q", names, want)
+	}
+}
@@ -0,0 +1,328 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CodeRateLimited marks a response refused because the client has made too
+// many attempts; Retry-After says how long to wait
+const CodeRateLimited = "rate_limited"
+
+// RateLimitState is the attempt history behind one rate limit key, such as
+// "ip:203.0.113.7" or "name:john smith|ip:203.0.113.7"
+type RateLimitState struct {
+	Key          string    `json:"key"`
+	WindowStart  time.Time `json:"window_start"`
+	Count        int       `json:"count"`  // attempts since WindowStart
+	Misses       int       `json:"misses"` // consecutive attempts that failed
+	BlockedUntil time.Time `json:"blocked_until"`
+}
+
+// RateLimitStore keeps rate limit state. The default is in memory, which
+// only limits clients within one process; serverless deployments, where
+// each request may land on a fresh instance, use a shared store instead
+// (see NewRateLimitStore).
+//
+// Attempts are counted by reading a key's state and saving it back, not
+// with an atomic increment, so the limits are best-effort: requests racing
+// on the same key can each see the same count, letting a burst through a
+// few attempts over the limit. That's enough to stop a scraper working
+// through names, which is what they are for.
+type RateLimitStore interface {
+	// GetRateLimit returns the state for key, or nil if there is none
+	GetRateLimit(key string) (*RateLimitState, error)
+	// SaveRateLimit stores state under state.Key, replacing any previous state
+	SaveRateLimit(state RateLimitState) error
+}
+
+// MemoryRateLimitStore is a RateLimitStore held in process memory
+type MemoryRateLimitStore struct {
+	mu     sync.Mutex
+	states map[string]RateLimitState
+}
+
+// NewMemoryRateLimitStore returns an empty in-memory RateLimitStore
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{states: make(map[string]RateLimitState)}
+}
+
+// memoryRateLimitStaleAfter is how long an untouched key is kept before
+// SaveRateLimit clears it out
+const memoryRateLimitStaleAfter = 24 * time.Hour
+
+func (s *MemoryRateLimitStore) GetRateLimit(key string) (*RateLimitState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[key]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (s *MemoryRateLimitStore) SaveRateLimit(state RateLimitState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state.Key] = state
+
+	// A bot cycling through names or addresses shouldn't grow the map forever
+	if len(s.states) > 10000 {
+		cutoff := time.Now().Add(-memoryRateLimitStaleAfter)
+		for key, st := range s.states {
+			if st.WindowStart.Before(cutoff) && st.BlockedUntil.Before(cutoff) {
+				delete(s.states, key)
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	memoryRateLimits     *MemoryRateLimitStore
+	memoryRateLimitsOnce sync.Once
+)
+
+// NewRateLimitStore returns the RateLimitStore selected by the
+// RATE_LIMIT_BACKEND env var: "memory" (the default) keeps limits in this
+// process; "store" keeps them in the STORE_BACKEND store, so they hold
+// across serverless instances. The memory store backend is process-wide
+// anyway, so it uses memory limits; any other store that can't keep rate
+// limits is an error rather than a quiet fall back to per-instance limits.
+func NewRateLimitStore() (RateLimitStore, error) {
+	memoryRateLimitsOnce.Do(func() {
+		memoryRateLimits = NewMemoryRateLimitStore()
+	})
+
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("RATE_LIMIT_BACKEND")))
+	switch backend {
+	case "", "memory":
+		return memoryRateLimits, nil
+	case "store":
+		store, err := NewStore()
+		if err != nil {
+			return nil, err
+		}
+		return storeRateLimits(store)
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_BACKEND %q (expected memory or store)", backend)
+	}
+}
+
+// storeRateLimits returns the RateLimitStore that keeps limits in store
+func storeRateLimits(store Store) (RateLimitStore, error) {
+	switch store := store.(type) {
+	case *MemoryStore:
+		return memoryRateLimits, nil
+	case RateLimitStore:
+		return store, nil
+	default:
+		return nil, fmt.Errorf("RATE_LIMIT_BACKEND=store but the %T store can't keep rate limits", store)
+	}
+}
+
+// NameLookupLimits configures NameLookupLimiter. A Max of 0 turns that
+// limit off.
+type NameLookupLimits struct {
+	Window      time.Duration // how long attempts count towards IPMax and NameMax
+	IPMax       int           // lookups per IP address per Window
+	NameMax     int           // lookups of the same name from one IP address per Window
+	FreeMisses  int           // misses in a row from an IP before backing off
+	BackoffMin  time.Duration // the first backoff, doubled with each further miss
+	BackoffMax  time.Duration // the longest backoff
+	AlertMax    int           // admin alerts about unlisted guests per AlertWindow
+	AlertWindow time.Duration // how long alerts count towards AlertMax
+}
+
+// DefaultNameLookupLimits are the limits used when the env vars read by
+// LoadNameLookupLimits are unset
+var DefaultNameLookupLimits = NameLookupLimits{
+	Window:      15 * time.Minute,
+	IPMax:       20,
+	NameMax:     10,
+	FreeMisses:  3,
+	BackoffMin:  30 * time.Second,
+	BackoffMax:  time.Hour,
+	AlertMax:    10,
+	AlertWindow: time.Hour,
+}
+
+// LoadNameLookupLimits reads DefaultNameLookupLimits overridden by:
+//   - VERIFY_NAME_WINDOW: how long lookups count, e.g. "15m"
+//   - VERIFY_NAME_IP_LIMIT / VERIFY_NAME_NAME_LIMIT: lookups per window
+//     from one IP address, and of one name from one IP address
+//   - VERIFY_NAME_FREE_MISSES: misses in a row before backing off
+//   - VERIFY_NAME_BACKOFF / VERIFY_NAME_BACKOFF_MAX: the first and longest
+//     backoff, e.g. "30s" and "1h"
+//   - UNLISTED_ALERT_LIMIT: unlisted guest alerts emailed per
+//     UNLISTED_ALERT_WINDOW (default "1h"); the rest are only logged
+func LoadNameLookupLimits() (NameLookupLimits, error) {
+	limits := DefaultNameLookupLimits
+	for _, d := range []struct {
+		env string
+		out *time.Duration
+	}{
+		{"VERIFY_NAME_WINDOW", &limits.Window},
+		{"VERIFY_NAME_BACKOFF", &limits.BackoffMin},
+		{"VERIFY_NAME_BACKOFF_MAX", &limits.BackoffMax},
+		{"UNLISTED_ALERT_WINDOW", &limits.AlertWindow},
+	} {
+		if v := strings.TrimSpace(os.Getenv(d.env)); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return NameLookupLimits{}, fmt.Errorf("invalid %s %q: expected a duration like 15m", d.env, v)
+			}
+			*d.out = parsed
+		}
+	}
+	for _, n := range []struct {
+		env string
+		out *int
+	}{
+		{"VERIFY_NAME_IP_LIMIT", &limits.IPMax},
+		{"VERIFY_NAME_NAME_LIMIT", &limits.NameMax},
+		{"VERIFY_NAME_FREE_MISSES", &limits.FreeMisses},
+		{"UNLISTED_ALERT_LIMIT", &limits.AlertMax},
+	} {
+		if v := strings.TrimSpace(os.Getenv(n.env)); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed < 0 {
+				return NameLookupLimits{}, fmt.Errorf("invalid %s %q: expected a whole number", n.env, v)
+			}
+			*n.out = parsed
+		}
+	}
+	if limits.BackoffMax < limits.BackoffMin {
+		return NameLookupLimits{}, fmt.Errorf("VERIFY_NAME_BACKOFF_MAX must not be less than VERIFY_NAME_BACKOFF")
+	}
+	return limits, nil
+}
+
+// NameLookupLimiter stops verify-name being used to scrape the guest list:
+// lookups are limited per IP address and per name from each address, so
+// no one can lock a guest out by looking their name up, an IP that keeps
+// missing has to wait longer and longer between tries, and alerts to the
+// admin about unlisted guests are capped. It fails open: if the store
+// can't be read, lookups are allowed. Limits are best-effort (see
+// RateLimitStore).
+type NameLookupLimiter struct {
+	Store  RateLimitStore
+	Limits NameLookupLimits
+	Now    func() time.Time
+}
+
+// NewNameLookupLimiter returns a limiter using NewRateLimitStore and
+// LoadNameLookupLimits
+func NewNameLookupLimiter() (*NameLookupLimiter, error) {
+	limits, err := LoadNameLookupLimits()
+	if err != nil {
+		return nil, err
+	}
+	store, err := NewRateLimitStore()
+	if err != nil {
+		return nil, err
+	}
+	return &NameLookupLimiter{Store: store, Limits: limits, Now: time.Now}, nil
+}
+
+// Allow counts a lookup of name from ip and returns how long the client
+// must wait before trying again, or 0 if the lookup may go ahead
+func (l *NameLookupLimiter) Allow(ip, name string) time.Duration {
+	wait := l.hit("ip:"+ip, l.Limits.IPMax, l.Limits.Window)
+	if w := l.hit("name:"+NormalizeString(name)+"|ip:"+ip, l.Limits.NameMax, l.Limits.Window); w > wait {
+		wait = w
+	}
+	return wait
+}
+
+// AllowCode counts an invite code lookup from ip, limited like name
+// lookups from the same IP address, and returns how long the client must
+// wait before trying again, or 0
+func (l *NameLookupLimiter) AllowCode(ip string) time.Duration {
+	return l.hit("ip:"+ip, l.Limits.IPMax, l.Limits.Window)
+}
+
+// RecordResult notes whether a lookup from ip found a guest. Each miss in
+// a row beyond FreeMisses blocks ip for twice as long as the last, from
+// BackoffMin up to BackoffMax; a match starts the count again.
+func (l *NameLookupLimiter) RecordResult(ip string, found bool) {
+	state, ok := l.load("ip:" + ip)
+	if !ok {
+		return
+	}
+	if found {
+		if state.Misses == 0 {
+			return
+		}
+		state.Misses = 0
+	} else {
+		state.Misses++
+		if over := state.Misses - l.Limits.FreeMisses; over > 0 {
+			backoff := l.Limits.BackoffMin
+			for i := 1; i < over && backoff < l.Limits.BackoffMax; i++ {
+				backoff *= 2
+			}
+			backoff = min(backoff, l.Limits.BackoffMax)
+			state.BlockedUntil = l.Now().Add(backoff)
+			log.Printf("⚠️  %d name lookups in a row from %s found no guest - blocking for %s", state.Misses, ip, backoff)
+		}
+	}
+	l.save(state)
+}
+
+// AllowAlert reports whether another unlisted guest alert may be emailed
+// to the admin, counting it if so
+func (l *NameLookupLimiter) AllowAlert() bool {
+	return l.hit("alert:unlisted-guest", l.Limits.AlertMax, l.Limits.AlertWindow) == 0
+}
+
+// hit counts an attempt against key, allowing max per window, and returns
+// how long until the next is allowed, or 0 if this one is. A key blocked
+// by RecordResult waits out its backoff even when max is 0.
+func (l *NameLookupLimiter) hit(key string, max int, window time.Duration) time.Duration {
+	state, ok := l.load(key)
+	if !ok {
+		return 0
+	}
+	now := l.Now()
+	if now.Before(state.BlockedUntil) {
+		return state.BlockedUntil.Sub(now)
+	}
+	if max <= 0 {
+		return 0
+	}
+	if now.Sub(state.WindowStart) >= window {
+		state.WindowStart, state.Count = now, 0
+	}
+	if state.Count >= max {
+		return state.WindowStart.Add(window).Sub(now)
+	}
+	state.Count++
+	l.save(state)
+	return 0
+}
+
+// load returns the state for key, or a fresh one; ok is false if the store
+// couldn't be read
+func (l *NameLookupLimiter) load(key string) (state RateLimitState, ok bool) {
+	stored, err := l.Store.GetRateLimit(key)
+	if err != nil {
+		log.Printf("⚠️  Rate limit store unavailable, allowing request: %v", err)
+		return RateLimitState{}, false
+	}
+	if stored == nil {
+		return RateLimitState{Key: key, WindowStart: l.Now()}, true
+	}
+	return *stored, true
+}
+
+func (l *NameLookupLimiter) save(state RateLimitState) {
+	if err := l.Store.SaveRateLimit(state); err != nil {
+		log.Printf("⚠️  Failed to save rate limit for %s: %v", state.Key, err)
+	}
+}
@@ -0,0 +1,261 @@
+package shared
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRateLimitStores(t *testing.T) {
+	sqlite, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+
+	for name, store := range map[string]RateLimitStore{"memory": NewMemoryRateLimitStore(), "sqlite": sqlite} {
+		t.Run(name, func(t *testing.T) {
+			if state, err := store.GetRateLimit("ip:a"); err != nil || state != nil {
+				t.Fatalf("GetRateLimit() = %+v, %v, want nil", state, err)
+			}
+			start := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+			for _, state := range []RateLimitState{
+				{Key: "ip:a", WindowStart: start, Count: 1},
+				{Key: "ip:a", WindowStart: start, Count: 2, Misses: 4, BlockedUntil: start.Add(time.Minute)},
+			} {
+				if err := store.SaveRateLimit(state); err != nil {
+					t.Fatalf("SaveRateLimit() error = %v", err)
+				}
+			}
+			got, err := store.GetRateLimit("ip:a")
+			if err != nil {
+				t.Fatalf("GetRateLimit() error = %v", err)
+			}
+			if got == nil || got.Count != 2 || got.Misses != 4 || !got.WindowStart.Equal(start) || !got.BlockedUntil.Equal(start.Add(time.Minute)) {
+				t.Errorf("GetRateLimit() = %+v, want the second state", got)
+			}
+		})
+	}
+}
+
+// noRateLimitStore is a store that can't keep rate limits
+type noRateLimitStore struct {
+	Store
+}
+
+func TestNewRateLimitStore(t *testing.T) {
+	tests := []struct {
+		name       string
+		backend    string
+		wantMemory bool
+		wantErr    bool
+	}{
+		{"default", "", true, false},
+		{"memory", " Memory ", true, false},
+		{"memory store", "store", true, false},
+		{"unknown", "redis", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STORE_BACKEND", "memory")
+			t.Setenv("RATE_LIMIT_BACKEND", tt.backend)
+			store, err := NewRateLimitStore()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRateLimitStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, isMemory := store.(*MemoryRateLimitStore); isMemory != tt.wantMemory {
+				t.Errorf("NewRateLimitStore() = %T", store)
+			}
+		})
+	}
+
+	sqlite, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+	if store, err := storeRateLimits(sqlite); err != nil || store != RateLimitStore(sqlite) {
+		t.Errorf("storeRateLimits(sqlite) = %T, %v, want the SQLite store", store, err)
+	}
+	if store, err := storeRateLimits(noRateLimitStore{}); err == nil {
+		t.Errorf("storeRateLimits() = %T for a store without rate limits, want an error", store)
+	}
+}
+
+// limiterStep is one call on a NameLookupLimiter in a test sequence
+type limiterStep struct {
+	advance time.Duration // how far the clock moves before the call
+	ip      string
+	name    string // looked up with Allow; "" for AllowCode
+	result  string // "found" or "missed" records a lookup result instead
+	want    time.Duration
+}
+
+func TestNameLookupLimiter(t *testing.T) {
+	limits := NameLookupLimits{
+		Window:     time.Minute,
+		IPMax:      3,
+		NameMax:    2,
+		FreeMisses: 1,
+		BackoffMin: 10 * time.Second,
+		BackoffMax: 30 * time.Second,
+	}
+
+	tests := []struct {
+		name   string
+		limits NameLookupLimits
+		steps  []limiterStep
+	}{
+		{"ip limit", limits, []limiterStep{
+			{ip: "a", name: "ann a"},
+			{ip: "a", name: "bob b"},
+			{ip: "a", name: "cat c"},
+			{advance: 15 * time.Second, ip: "a", name: "dan d", want: 45 * time.Second},
+			{ip: "b", name: "dan d"},
+		}},
+		{"window resets", limits, []limiterStep{
+			{ip: "a", name: "ann a"},
+			{ip: "a", name: "bob b"},
+			{ip: "a", name: "cat c"},
+			{advance: time.Minute, ip: "a", name: "dan d"},
+		}},
+		{"name limit per ip", limits, []limiterStep{
+			{ip: "a", name: "John Smith"},
+			{ip: "a", name: " JOHN  smith"},
+			{ip: "a", name: "John Smith", want: time.Minute},
+			// Someone else looking the same name up isn't locked out
+			{ip: "b", name: "John Smith"},
+			{ip: "c", name: "John Smith"},
+		}},
+		{"invite codes share the ip limit", limits, []limiterStep{
+			{ip: "a"},
+			{ip: "a", name: "ann a"},
+			{ip: "a"},
+			{ip: "a", want: time.Minute},
+		}},
+		{"backoff doubles up to the max", limits, []limiterStep{
+			{ip: "a", result: "missed"}, // free
+			{ip: "a", name: "ann a"},
+			{ip: "a", result: "missed"},
+			{ip: "a", name: "bob b", want: 10 * time.Second},
+			{advance: 10 * time.Second, ip: "a", name: "bob b"},
+			{ip: "a", result: "missed"},
+			{ip: "a", name: "cat c", want: 20 * time.Second},
+			{advance: 20 * time.Second, ip: "a", result: "missed"},
+			{ip: "a", name: "dan d", want: 30 * time.Second},
+		}},
+		{"a match clears the misses", limits, []limiterStep{
+			{ip: "a", result: "missed"},
+			{ip: "a", result: "found"},
+			{ip: "a", result: "missed"},
+			{ip: "a", name: "ann a"},
+		}},
+		{"backoff outlasts a zero limit", NameLookupLimits{FreeMisses: 0, BackoffMin: time.Second, BackoffMax: time.Second}, []limiterStep{
+			{ip: "a", name: "ann a"},
+			{ip: "a", result: "missed"},
+			{ip: "a", name: "ann a", want: time.Second},
+			{advance: time.Second, ip: "a", name: "ann a"},
+		}},
+		{"limits off", NameLookupLimits{}, []limiterStep{
+			{ip: "a", name: "ann a"},
+			{ip: "a", name: "ann a"},
+			{ip: "a", name: "ann a"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+			limiter := &NameLookupLimiter{Store: NewMemoryRateLimitStore(), Limits: tt.limits, Now: func() time.Time { return now }}
+			for i, step := range tt.steps {
+				now = now.Add(step.advance)
+				if step.result != "" {
+					limiter.RecordResult(step.ip, step.result == "found")
+					continue
+				}
+				var got time.Duration
+				if step.name == "" {
+					got = limiter.AllowCode(step.ip)
+				} else {
+					got = limiter.Allow(step.ip, step.name)
+				}
+				if got != step.want {
+					t.Errorf("step %d (%s looking up %q): wait = %s, want %s", i, step.ip, step.name, got, step.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNameLookupLimiterAllowAlert(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := &NameLookupLimiter{
+		Store:  NewMemoryRateLimitStore(),
+		Limits: NameLookupLimits{AlertMax: 2, AlertWindow: time.Hour},
+		Now:    func() time.Time { return now },
+	}
+	for i, want := range []bool{true, true, false, false} {
+		if got := limiter.AllowAlert(); got != want {
+			t.Errorf("alert %d: AllowAlert() = %v, want %v", i, got, want)
+		}
+	}
+	now = now.Add(time.Hour)
+	if !limiter.AllowAlert() {
+		t.Errorf("AllowAlert() = false once the window has passed")
+	}
+}
+
+// brokenRateLimitStore fails every read
+type brokenRateLimitStore struct{}
+
+func (brokenRateLimitStore) GetRateLimit(string) (*RateLimitState, error) {
+	return nil, errors.New("unavailable")
+}
+
+func (brokenRateLimitStore) SaveRateLimit(RateLimitState) error {
+	return errors.New("unavailable")
+}
+
+func TestNameLookupLimiterFailsOpen(t *testing.T) {
+	limiter := &NameLookupLimiter{Store: brokenRateLimitStore{}, Limits: NameLookupLimits{Window: time.Minute, IPMax: 1, NameMax: 1}, Now: time.Now}
+	for i := 0; i < 3; i++ {
+		if wait := limiter.Allow("a", "ann a"); wait != 0 {
+			t.Fatalf("lookup %d: Allow() = %s with the store down, want 0", i, wait)
+		}
+	}
+}
+
+func TestLoadNameLookupLimits(t *testing.T) {
+	envs := []string{
+		"VERIFY_NAME_WINDOW", "VERIFY_NAME_BACKOFF", "VERIFY_NAME_BACKOFF_MAX", "UNLISTED_ALERT_WINDOW",
+		"VERIFY_NAME_IP_LIMIT", "VERIFY_NAME_NAME_LIMIT", "VERIFY_NAME_FREE_MISSES", "UNLISTED_ALERT_LIMIT",
+	}
+	tests := []struct {
+		name    string // looked up with Allow; "" for AllowCode
+		env     map[string]string
+		check   func(NameLookupLimits) bool
+		wantErr bool
+	}{
+		{"defaults", nil, func(l NameLookupLimits) bool { return l == DefaultNameLookupLimits }, false},
+		{"overrides", map[string]string{"VERIFY_NAME_WINDOW": "5m", "VERIFY_NAME_IP_LIMIT": "0", "VERIFY_NAME_NAME_LIMIT": " 4 "},
+			func(l NameLookupLimits) bool { return l.Window == 5*time.Minute && l.IPMax == 0 && l.NameMax == 4 }, false},
+		{"bad duration", map[string]string{"VERIFY_NAME_WINDOW": "15"}, nil, true},
+		{"zero duration", map[string]string{"VERIFY_NAME_BACKOFF": "0s"}, nil, true},
+		{"negative limit", map[string]string{"VERIFY_NAME_IP_LIMIT": "-1"}, nil, true},
+		{"max below min", map[string]string{"VERIFY_NAME_BACKOFF": "2m", "VERIFY_NAME_BACKOFF_MAX": "1m"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range envs {
+				t.Setenv(env, tt.env[env])
+			}
+			limits, err := LoadNameLookupLimits()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadNameLookupLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !tt.check(limits) {
+				t.Errorf("LoadNameLookupLimits() = %+v", limits)
+			}
+		})
+	}
+}
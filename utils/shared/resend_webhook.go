@@ -0,0 +1,60 @@
+package shared
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// resendWebhookTolerance is how old (or how far in the future) a webhook's
+// timestamp may be, so a captured request can't be replayed later
+const resendWebhookTolerance = 5 * time.Minute
+
+// ResendWebhookEvent is the part of a Resend webhook event the outbox uses
+type ResendWebhookEvent struct {
+	Type string `json:"type"` // e.g. "email.bounced"
+	Data struct {
+		EmailID string `json:"email_id"`
+		Bounce  struct {
+			Message string `json:"message"`
+		} `json:"bounce"`
+	} `json:"data"`
+}
+
+// VerifyResendWebhook checks the signature Resend puts on its webhooks
+// (the Svix scheme: svix-id, svix-timestamp and svix-signature headers)
+// against secret, the "whsec_..." signing secret of the webhook
+func VerifyResendWebhook(secret string, header http.Header, body []byte, now time.Time) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_"))
+	if err != nil {
+		return fmt.Errorf("invalid webhook secret: %v", err)
+	}
+	id, timestamp, signatures := header.Get("svix-id"), header.Get("svix-timestamp"), header.Get("svix-signature")
+	if id == "" || timestamp == "" || signatures == "" {
+		return fmt.Errorf("missing signature headers")
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > resendWebhookTolerance || age < -resendWebhookTolerance {
+		return fmt.Errorf("timestamp too far from now")
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id + "." + timestamp + "." + string(body)))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	// The header lists "v1,<signature>" entries separated by spaces, one per
+	// active secret
+	for _, sig := range strings.Fields(signatures) {
+		if version, value, ok := strings.Cut(sig, ","); ok && version == "v1" && hmac.Equal([]byte(value), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature doesn't match")
+}
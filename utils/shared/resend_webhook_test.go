@@ -0,0 +1,62 @@
+package shared
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// signResendWebhook returns the Svix headers Resend would send body with,
+// signed with key at time at
+func signResendWebhook(key []byte, id string, at time.Time, body string) http.Header {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id + "." + timestamp + "." + body))
+	header := http.Header{}
+	header.Set("svix-id", id)
+	header.Set("svix-timestamp", timestamp)
+	header.Set("svix-signature", "v1,"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return header
+}
+
+func TestVerifyResendWebhook(t *testing.T) {
+	key := []byte("webhook signing key")
+	secret := "whsec_" + base64.StdEncoding.EncodeToString(key)
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	body := `{"type":"email.bounced"}`
+
+	rotated := signResendWebhook(key, "msg_1", now, body)
+	rotated.Set("svix-signature", "v1,b2xk "+rotated.Get("svix-signature"))
+	missing := signResendWebhook(key, "msg_1", now, body)
+	missing.Del("svix-id")
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  http.Header
+		body    string
+		wantErr bool
+	}{
+		{"signed", secret, signResendWebhook(key, "msg_1", now, body), body, false},
+		{"a little old", secret, signResendWebhook(key, "msg_1", now.Add(-4*time.Minute), body), body, false},
+		{"one of several signatures", secret, rotated, body, false},
+		{"body changed", secret, signResendWebhook(key, "msg_1", now, body), `{"type":"email.sent"}`, true},
+		{"other key", secret, signResendWebhook([]byte("other"), "msg_1", now, body), body, true},
+		{"replayed", secret, signResendWebhook(key, "msg_1", now.Add(-10*time.Minute), body), body, true},
+		{"from the future", secret, signResendWebhook(key, "msg_1", now.Add(10*time.Minute), body), body, true},
+		{"missing header", secret, missing, body, true},
+		{"bad secret", "whsec_!!!", signResendWebhook(key, "msg_1", now, body), body, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyResendWebhook(tt.secret, tt.header, []byte(tt.body), now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyResendWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
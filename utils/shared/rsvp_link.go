@@ -0,0 +1,85 @@
+package shared
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RSVPEditLinkValidity is how long the "edit my RSVP" link in a
+// confirmation email keeps working
+const RSVPEditLinkValidity = 60 * 24 * time.Hour
+
+// rsvpLinkPurpose is mixed into every signature so an RSVP link can never
+// be passed off as an admin token, even when both share a secret
+const rsvpLinkPurpose = "rsvp-edit"
+
+// RSVPLinkSigner issues and checks the signed tokens in "edit my RSVP"
+// links.
+//
+// Token format: "<base64url(email)>.<expiryUnix>.<hmac-sha256-hex>"
+type RSVPLinkSigner struct {
+	secret   []byte
+	validity time.Duration
+}
+
+// NewRSVPLinkSigner builds a signer from RSVP_LINK_SECRET, falling back to
+// ADMIN_TOKEN_SECRET
+func NewRSVPLinkSigner() (*RSVPLinkSigner, error) {
+	secret := os.Getenv("RSVP_LINK_SECRET")
+	if secret == "" {
+		secret = os.Getenv("ADMIN_TOKEN_SECRET")
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("RSVP_LINK_SECRET not configured")
+	}
+	return &RSVPLinkSigner{secret: []byte(secret), validity: RSVPEditLinkValidity}, nil
+}
+
+// Sign returns a token granting access to the RSVP for email
+func (s *RSVPLinkSigner) Sign(email string) string {
+	payload := fmt.Sprintf("%s.%d",
+		base64.RawURLEncoding.EncodeToString([]byte(email)), time.Now().Add(s.validity).Unix())
+	return payload + "." + hmacSign(s.secret, rsvpLinkPurpose+":"+payload)
+}
+
+// Verify checks a token's signature and expiry and returns the email it
+// was issued for
+func (s *RSVPLinkSigner) Verify(token string) (string, bool) {
+	dot := strings.LastIndex(token, ".")
+	if dot < 0 {
+		return "", false
+	}
+	payload, sig := token[:dot], token[dot+1:]
+	if !hmac.Equal([]byte(sig), []byte(hmacSign(s.secret, rsvpLinkPurpose+":"+payload))) {
+		return "", false
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 2 {
+		return "", false
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().After(time.Unix(expiry, 0)) {
+		return "", false
+	}
+	email, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", false
+	}
+	return string(email), true
+}
+
+// RSVPEditURL returns the signed "edit my RSVP" page link for email
+func RSVPEditURL(email string) (string, error) {
+	signer, err := NewRSVPLinkSigner()
+	if err != nil {
+		return "", err
+	}
+	return siteBaseURL() + "/my-rsvp?token=" + url.QueryEscape(signer.Sign(email)), nil
+}
@@ -0,0 +1,82 @@
+package shared
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// signRSVPLink builds a token as Sign would, expiring at expiry
+func signRSVPLink(secret, email string, expiry time.Time) string {
+	payload := fmt.Sprintf("%s.%d", base64.RawURLEncoding.EncodeToString([]byte(email)), expiry.Unix())
+	return payload + "." + hmacSign([]byte(secret), rsvpLinkPurpose+":"+payload)
+}
+
+func TestRSVPLinkSignerVerify(t *testing.T) {
+	signer := &RSVPLinkSigner{secret: []byte("secret"), validity: RSVPEditLinkValidity}
+	now := time.Now()
+	adminToken := (&AdminAuth{secret: []byte("secret"), validity: AdminTokenValidity}).IssueToken("ana", RoleOwner)
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+		ok    bool
+	}{
+		{"signed", signer.Sign("jane@example.com"), "jane@example.com", true},
+		{"dots in email", signer.Sign("jane.smith@example.co.uk"), "jane.smith@example.co.uk", true},
+		{"not yet expired", signRSVPLink("secret", "jane@example.com", now.Add(time.Minute)), "jane@example.com", true},
+		{"expired", signRSVPLink("secret", "jane@example.com", now.Add(-time.Minute)), "", false},
+		{"other secret", signRSVPLink("other", "jane@example.com", now.Add(time.Hour)), "", false},
+		{"admin token with the same secret", adminToken, "", false},
+		{"unsigned", "amFuZUBleGFtcGxlLmNvbQ.9999999999", "", false},
+		{"no signature", "amFuZUBleGFtcGxlLmNvbQ", "", false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := signer.Verify(tt.token)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Verify(%q) = %q, %v, want %q, %v", tt.token, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	t.Run("email swapped", func(t *testing.T) {
+		token := signer.Sign("jane@example.com")
+		other := base64.RawURLEncoding.EncodeToString([]byte("eve@example.com"))
+		dot := len(base64.RawURLEncoding.EncodeToString([]byte("jane@example.com")))
+		if _, ok := signer.Verify(other + token[dot:]); ok {
+			t.Errorf("Verify accepted a token with its email changed")
+		}
+	})
+}
+
+func TestNewRSVPLinkSigner(t *testing.T) {
+	tests := []struct {
+		name        string
+		linkSecret  string
+		adminSecret string
+		wantSecret  string
+		wantErr     bool
+	}{
+		{"link secret", "link", "admin", "link", false},
+		{"falls back to admin secret", "", "admin", "admin", false},
+		{"neither", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RSVP_LINK_SECRET", tt.linkSecret)
+			t.Setenv("ADMIN_TOKEN_SECRET", tt.adminSecret)
+
+			signer, err := NewRSVPLinkSigner()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRSVPLinkSigner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && string(signer.secret) != tt.wantSecret {
+				t.Errorf("secret = %q, want %q", signer.secret, tt.wantSecret)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package shared
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultRSVPClosesAt is when the RSVP form closes if RSVP_CLOSES_AT isn't
+// set: the start of 1 August 2026, London time
+const DefaultRSVPClosesAt = "2026-08-01T00:00:00+01:00"
+
+// RSVP phases reported to the frontend
+const (
+	RSVPPhaseUpcoming = "upcoming" // before RSVP_OPENS_AT
+	RSVPPhaseOpen     = "open"     // guests can verify, RSVP and pick avatars
+	RSVPPhaseClosed   = "closed"   // after RSVP_CLOSES_AT - plaza only
+)
+
+// Error codes returned by guest endpoints outside the RSVP window
+const (
+	ErrCodeRSVPNotOpen = "rsvp_not_open"
+	ErrCodeRSVPClosed  = "rsvp_closed"
+)
+
+// CodeLateRSVPPending marks a successful submission that was held as a late
+// request for admin approval
+const CodeLateRSVPPending = "late_rsvp_pending"
+
+// RSVPSchedule is the window during which guests may RSVP. A zero OpensAt
+// or ClosesAt leaves that end of the window unbounded.
+type RSVPSchedule struct {
+	OpensAt  time.Time
+	ClosesAt time.Time
+}
+
+// LoadRSVPSchedule reads the RSVP window from the environment:
+//   - RSVP_OPENS_AT: RFC 3339 time before which RSVPs are refused (optional)
+//   - RSVP_CLOSES_AT: RFC 3339 time after which RSVPs are refused; defaults
+//     to DefaultRSVPClosesAt, "none" keeps RSVPs open indefinitely
+func LoadRSVPSchedule() (RSVPSchedule, error) {
+	var schedule RSVPSchedule
+
+	if opens := strings.TrimSpace(os.Getenv("RSVP_OPENS_AT")); opens != "" {
+		t, err := time.Parse(time.RFC3339, opens)
+		if err != nil {
+			return RSVPSchedule{}, fmt.Errorf("invalid RSVP_OPENS_AT %q: %v", opens, err)
+		}
+		schedule.OpensAt = t
+	}
+
+	closes := strings.TrimSpace(os.Getenv("RSVP_CLOSES_AT"))
+	if closes == "" {
+		closes = DefaultRSVPClosesAt
+	}
+	if !strings.EqualFold(closes, "none") {
+		t, err := time.Parse(time.RFC3339, closes)
+		if err != nil {
+			return RSVPSchedule{}, fmt.Errorf("invalid RSVP_CLOSES_AT %q: %v", closes, err)
+		}
+		schedule.ClosesAt = t
+	}
+
+	if !schedule.OpensAt.IsZero() && !schedule.ClosesAt.IsZero() && !schedule.OpensAt.Before(schedule.ClosesAt) {
+		return RSVPSchedule{}, fmt.Errorf("RSVP_OPENS_AT must be before RSVP_CLOSES_AT")
+	}
+	return schedule, nil
+}
+
+// PhaseAt returns the RSVP phase at time t
+func (s RSVPSchedule) PhaseAt(t time.Time) string {
+	if !s.OpensAt.IsZero() && t.Before(s.OpensAt) {
+		return RSVPPhaseUpcoming
+	}
+	if !s.ClosesAt.IsZero() && !t.Before(s.ClosesAt) {
+		return RSVPPhaseClosed
+	}
+	return RSVPPhaseOpen
+}
+
+// Phase returns the current RSVP phase
+func (s RSVPSchedule) Phase() string {
+	return s.PhaseAt(time.Now())
+}
+
+// WindowErrorAt returns the error code and guest-facing message for the
+// phase at time t, or "" for both while RSVPs are open
+func (s RSVPSchedule) WindowErrorAt(t time.Time) (code, message string) {
+	switch s.PhaseAt(t) {
+	case RSVPPhaseUpcoming:
+		return ErrCodeRSVPNotOpen, fmt.Sprintf("RSVPs open on %s", s.OpensAt.Format("2 January 2006"))
+	case RSVPPhaseClosed:
+		return ErrCodeRSVPClosed, "RSVPs have closed - please contact the couple directly"
+	default:
+		return "", ""
+	}
+}
+
+// WindowError returns the error code and guest-facing message for the
+// current phase, or "" for both while RSVPs are open
+func (s RSVPSchedule) WindowError() (code, message string) {
+	return s.WindowErrorAt(time.Now())
+}
@@ -0,0 +1,116 @@
+package shared
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadRSVPSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		opensAt    string
+		closesAt   string
+		wantOpens  string
+		wantCloses string
+		wantErr    bool
+	}{
+		{name: "defaults", wantCloses: DefaultRSVPClosesAt},
+		{name: "window", opensAt: "2026-03-01T09:00:00Z", closesAt: "2026-06-01T00:00:00+01:00",
+			wantOpens: "2026-03-01T09:00:00Z", wantCloses: "2026-06-01T00:00:00+01:00"},
+		{name: "no deadline", closesAt: "None"},
+		{name: "opens with no deadline", opensAt: "2026-03-01T09:00:00Z", closesAt: "none", wantOpens: "2026-03-01T09:00:00Z"},
+		{name: "padded", closesAt: " 2026-06-01T00:00:00Z ", wantCloses: "2026-06-01T00:00:00Z"},
+		{name: "bad opens", opensAt: "1 March", wantErr: true},
+		{name: "bad closes", closesAt: "2026-06-01", wantErr: true},
+		{name: "opens after closes", opensAt: "2026-07-01T00:00:00Z", closesAt: "2026-06-01T00:00:00Z", wantErr: true},
+		{name: "opens when closes", opensAt: "2026-06-01T00:00:00Z", closesAt: "2026-06-01T00:00:00Z", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RSVP_OPENS_AT", tt.opensAt)
+			t.Setenv("RSVP_CLOSES_AT", tt.closesAt)
+
+			schedule, err := LoadRSVPSchedule()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadRSVPSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !sameScheduleTime(schedule.OpensAt, tt.wantOpens) {
+				t.Errorf("OpensAt = %v, want %q", schedule.OpensAt, tt.wantOpens)
+			}
+			if !sameScheduleTime(schedule.ClosesAt, tt.wantCloses) {
+				t.Errorf("ClosesAt = %v, want %q", schedule.ClosesAt, tt.wantCloses)
+			}
+		})
+	}
+}
+
+// sameScheduleTime reports whether got is the RFC 3339 time want, or zero
+// when want is ""
+func sameScheduleTime(got time.Time, want string) bool {
+	if want == "" {
+		return got.IsZero()
+	}
+	w, err := time.Parse(time.RFC3339, want)
+	return err == nil && got.Equal(w)
+}
+
+func TestRSVPSchedulePhaseAt(t *testing.T) {
+	opens := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	closes := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule RSVPSchedule
+		at       time.Time
+		want     string
+	}{
+		{"before opening", RSVPSchedule{opens, closes}, opens.Add(-time.Second), RSVPPhaseUpcoming},
+		{"at opening", RSVPSchedule{opens, closes}, opens, RSVPPhaseOpen},
+		{"open", RSVPSchedule{opens, closes}, opens.Add(24 * time.Hour), RSVPPhaseOpen},
+		{"just before closing", RSVPSchedule{opens, closes}, closes.Add(-time.Second), RSVPPhaseOpen},
+		{"at closing", RSVPSchedule{opens, closes}, closes, RSVPPhaseClosed},
+		{"after closing", RSVPSchedule{opens, closes}, closes.Add(time.Hour), RSVPPhaseClosed},
+		{"no opening", RSVPSchedule{ClosesAt: closes}, opens.AddDate(-10, 0, 0), RSVPPhaseOpen},
+		{"no deadline", RSVPSchedule{OpensAt: opens}, closes.AddDate(10, 0, 0), RSVPPhaseOpen},
+		{"unbounded", RSVPSchedule{}, closes, RSVPPhaseOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.PhaseAt(tt.at); got != tt.want {
+				t.Errorf("PhaseAt(%v) = %q, want %q", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRSVPScheduleWindowError(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		schedule RSVPSchedule
+		wantCode string
+	}{
+		{"upcoming", RSVPSchedule{OpensAt: now.Add(time.Hour)}, ErrCodeRSVPNotOpen},
+		{"open", RSVPSchedule{OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour)}, ""},
+		{"opening now", RSVPSchedule{OpensAt: now}, ""},
+		{"closing now", RSVPSchedule{ClosesAt: now}, ErrCodeRSVPClosed},
+		{"closed", RSVPSchedule{ClosesAt: now.Add(-time.Hour)}, ErrCodeRSVPClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, message := tt.schedule.WindowErrorAt(now)
+			if code != tt.wantCode {
+				t.Errorf("WindowErrorAt() code = %q, want %q", code, tt.wantCode)
+			}
+			if (message == "") != (tt.wantCode == "") {
+				t.Errorf("WindowErrorAt() message = %q with code %q", message, code)
+			}
+			if phase := tt.schedule.PhaseAt(now); (phase == RSVPPhaseOpen) != (code == "") {
+				t.Errorf("PhaseAt() = %s but WindowErrorAt() code = %q", phase, code)
+			}
+		})
+	}
+}
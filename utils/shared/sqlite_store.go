@@ -0,0 +1,922 @@
+package shared
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore is a Store backed by a local SQLite file, for running the API
+// offline with data that survives restarts
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+var (
+	sqliteStore     *SQLiteStore
+	sqliteStoreErr  error
+	sqliteStoreOnce sync.Once
+)
+
+// sqliteSchema mirrors the Supabase migrations closely enough for the API.
+// diet_categories, events (on guests), attending_guests, plus_ones (on
+// rsvps), guest_diets, guest_meals, guest_events and avatar_data hold JSON
+// arrays.
+const sqliteSchema = `
+	CREATE TABLE IF NOT EXISTS guests (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		address TEXT NOT NULL DEFAULT '',
+		household_id TEXT NOT NULL DEFAULT '',
+		ceremony INTEGER NOT NULL DEFAULT 0,
+		plus_ones INTEGER NOT NULL DEFAULT 0,
+		table_name TEXT NOT NULL DEFAULT '',
+		dietary TEXT NOT NULL DEFAULT '',
+		diet_categories TEXT NOT NULL DEFAULT '[]',
+		events TEXT NOT NULL DEFAULT '[]',
+		created_at TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_guests_name ON guests(name);
+	CREATE INDEX IF NOT EXISTS idx_guests_address ON guests(address);
+
+	CREATE TABLE IF NOT EXISTS households (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		invite_code TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_households_name ON households(lower(name));
+
+	CREATE TABLE IF NOT EXISTS rsvps (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		email TEXT NOT NULL,
+		is_attending INTEGER NOT NULL DEFAULT 0,
+		attending_guests TEXT NOT NULL DEFAULT '[]',
+		plus_ones TEXT NOT NULL DEFAULT '[]',
+		guest_diets TEXT NOT NULL DEFAULT '[]',
+		guest_meals TEXT NOT NULL DEFAULT '[]',
+		guest_events TEXT NOT NULL DEFAULT '[]',
+		diet TEXT NOT NULL DEFAULT '',
+		submitted_at TEXT NOT NULL,
+		verified INTEGER NOT NULL DEFAULT 0,
+		avatar_data TEXT NOT NULL DEFAULT '[]',
+		late INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_rsvps_email ON rsvps(email);
+	CREATE INDEX IF NOT EXISTS idx_rsvps_submitted_at ON rsvps(submitted_at DESC);
+
+	CREATE TABLE IF NOT EXISTS menu_options (
+		id TEXT PRIMARY KEY,
+		course TEXT NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS events (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		venue TEXT NOT NULL DEFAULT '',
+		starts_at TEXT NOT NULL DEFAULT '',
+		capacity INTEGER NOT NULL DEFAULT 0,
+		all_guests INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS admins (
+		username TEXT PRIMARY KEY,
+		password_hash TEXT NOT NULL,
+		role TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS rate_limits (
+		key TEXT PRIMARY KEY,
+		window_start TEXT NOT NULL,
+		count INTEGER NOT NULL DEFAULT 0,
+		misses INTEGER NOT NULL DEFAULT 0,
+		blocked_until TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS email_outbox (
+		id TEXT PRIMARY KEY,
+		kind TEXT NOT NULL DEFAULT '',
+		recipient TEXT NOT NULL,
+		subject TEXT NOT NULL DEFAULT '',
+		text_body TEXT NOT NULL DEFAULT '',
+		html_body TEXT NOT NULL DEFAULT '',
+		template TEXT NOT NULL DEFAULT '',
+		template_data TEXT NOT NULL DEFAULT '{}',
+		status TEXT NOT NULL DEFAULT 'queued',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		resends INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		next_attempt_at TEXT NOT NULL DEFAULT '',
+		provider_id TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL,
+		sent_at TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox(status, next_attempt_at);
+`
+
+// sqliteColumnMigration adds a column introduced after a database file may
+// already have been created. backfill runs only when the column is added;
+// a "duplicate column" error means it already exists.
+type sqliteColumnMigration struct {
+	alter    string
+	backfill []string
+}
+
+var sqliteColumnMigrations = []sqliteColumnMigration{
+	{alter: `ALTER TABLE rsvps ADD COLUMN late INTEGER NOT NULL DEFAULT 0`},
+	{
+		alter: `ALTER TABLE guests ADD COLUMN household_id TEXT NOT NULL DEFAULT ''`,
+		// One household per distinct address, as guests used to be grouped
+		backfill: []string{
+			`INSERT OR IGNORE INTO households (id, name, created_at)
+				SELECT lower(hex(randomblob(16))), trim(address), strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+				FROM guests
+				WHERE lower(trim(address)) NOT IN ('', 'n/a', 'na', 'n.a.', 'n.a')
+				GROUP BY lower(trim(address))`,
+			`UPDATE guests SET household_id = (SELECT id FROM households WHERE lower(households.name) = lower(trim(guests.address)))
+				WHERE household_id = '' AND lower(trim(address)) IN (SELECT lower(name) FROM households)`,
+		},
+	},
+	{alter: `ALTER TABLE guests ADD COLUMN plus_ones INTEGER NOT NULL DEFAULT 0`},
+	{alter: `ALTER TABLE rsvps ADD COLUMN plus_ones TEXT NOT NULL DEFAULT '[]'`},
+	{alter: `ALTER TABLE rsvps ADD COLUMN guest_diets TEXT NOT NULL DEFAULT '[]'`},
+	{alter: `ALTER TABLE guests ADD COLUMN table_name TEXT NOT NULL DEFAULT ''`},
+	{alter: `ALTER TABLE guests ADD COLUMN diet_categories TEXT NOT NULL DEFAULT '[]'`},
+	{alter: `ALTER TABLE rsvps ADD COLUMN guest_meals TEXT NOT NULL DEFAULT '[]'`},
+	{alter: `ALTER TABLE rsvps ADD COLUMN guest_events TEXT NOT NULL DEFAULT '[]'`},
+	{
+		alter:    `ALTER TABLE guests ADD COLUMN events TEXT NOT NULL DEFAULT '[]'`,
+		backfill: []string{`UPDATE guests SET events = '["ceremony"]' WHERE ceremony = 1`},
+	},
+	{alter: `ALTER TABLE households ADD COLUMN invite_code TEXT NOT NULL DEFAULT ''`},
+}
+
+// NewSQLiteStore opens (creating if needed) the SQLite database at path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %v", err)
+	}
+	// SQLite allows one writer at a time; a single connection avoids
+	// SQLITE_BUSY errors under concurrent requests
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create SQLite schema: %v", err)
+	}
+	for _, migration := range sqliteColumnMigrations {
+		if _, err := db.Exec(migration.alter); err != nil {
+			if strings.Contains(err.Error(), "duplicate column") {
+				continue
+			}
+			db.Close()
+			return nil, fmt.Errorf("failed to migrate SQLite schema: %v", err)
+		}
+		for _, backfill := range migration.backfill {
+			if _, err := db.Exec(backfill); err != nil {
+				db.Close()
+				return nil, fmt.Errorf("failed to backfill SQLite schema: %v", err)
+			}
+		}
+	}
+
+	for _, e := range DefaultEvents {
+		if _, err := db.Exec(`INSERT OR IGNORE INTO events (id, name, venue, starts_at, capacity, all_guests) VALUES (?, ?, ?, ?, ?, ?)`,
+			e.ID, e.Name, e.Venue, e.StartsAt, e.Capacity, e.AllGuests); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to seed SQLite events: %v", err)
+		}
+	}
+
+	log.Printf("✓ Opened SQLite store at %s", path)
+	return &SQLiteStore{db: db}, nil
+}
+
+// defaultSQLiteStore returns the process-wide SQLite store at SQLITE_PATH
+// (default "jemarko.db")
+func defaultSQLiteStore() (*SQLiteStore, error) {
+	sqliteStoreOnce.Do(func() {
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = "jemarko.db"
+		}
+		sqliteStore, sqliteStoreErr = NewSQLiteStore(path)
+	})
+	return sqliteStore, sqliteStoreErr
+}
+
+// Close closes the underlying database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+const sqliteGuestColumns = `id, name, address, household_id, ceremony, plus_ones, table_name, dietary, diet_categories, events`
+
+// ListGuests returns every guest, ordered by address then name
+func (s *SQLiteStore) ListGuests() ([]Guest, error) {
+	rows, err := s.db.Query(`SELECT ` + sqliteGuestColumns + ` FROM guests ORDER BY address, name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch guests: %v", err)
+	}
+	defer rows.Close()
+
+	guests := []Guest{}
+	for rows.Next() {
+		var g Guest
+		var categories, events string
+		if err := rows.Scan(&g.ID, &g.Name, &g.Address, &g.HouseholdID, &g.Ceremony, &g.PlusOnes, &g.Table, &g.Dietary, &categories, &events); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(categories), &g.DietCategories); err != nil {
+			log.Printf("Error parsing diet_categories for %s: %v", g.Name, err)
+		}
+		if err := json.Unmarshal([]byte(events), &g.Events); err != nil {
+			log.Printf("Error parsing events for %s: %v", g.Name, err)
+		}
+		syncCeremony(&g)
+		guests = append(guests, g)
+	}
+	return guests, rows.Err()
+}
+
+// FindGuestByName looks up a guest by normalised name (see
+// NormalizeString), which SQLite can't compute itself
+func (s *SQLiteStore) FindGuestByName(name string) (*Guest, error) {
+	guests, err := s.ListGuests()
+	if err != nil {
+		return nil, err
+	}
+	return findGuestByNormalizedName(name, guests), nil
+}
+
+// AddGuest inserts a single guest
+func (s *SQLiteStore) AddGuest(guest Guest) error {
+	syncCeremony(&guest)
+	categories, _ := json.Marshal(nonNilStrings(guest.DietCategories))
+	events, _ := json.Marshal(nonNilStrings(guest.Events))
+	_, err := s.db.Exec(`INSERT INTO guests (id, name, address, household_id, ceremony, plus_ones, table_name, dietary, diet_categories, events, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		newID(), guest.Name, guest.Address, guest.HouseholdID, guest.Ceremony, guest.PlusOnes, guest.Table, guest.Dietary, string(categories), string(events),
+		time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to add guest: %v", err)
+	}
+	ClearGuestCache()
+	return nil
+}
+
+// SetGuestDietary replaces the dietary requirements of the guest with id
+func (s *SQLiteStore) SetGuestDietary(id, dietary string, categories []string) error {
+	categoriesJSON, _ := json.Marshal(nonNilStrings(categories))
+	result, err := s.db.Exec(`UPDATE guests SET dietary = ?, diet_categories = ? WHERE id = ?`, dietary, string(categoriesJSON), id)
+	if err != nil {
+		return fmt.Errorf("failed to update guest dietary: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("guest %s not found", id)
+	}
+	ClearGuestCache()
+	return nil
+}
+
+// UpdateGuest replaces the invite list details of the guest with guest.ID
+func (s *SQLiteStore) UpdateGuest(guest Guest) error {
+	syncCeremony(&guest)
+	events, _ := json.Marshal(nonNilStrings(guest.Events))
+	result, err := s.db.Exec(`UPDATE guests SET name = ?, address = ?, household_id = ?, ceremony = ?, events = ?, plus_ones = ?, table_name = ?
+		WHERE id = ?`,
+		guest.Name, guest.Address, guest.HouseholdID, guest.Ceremony, string(events), guest.PlusOnes, guest.Table, guest.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update guest: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("guest %s not found", guest.ID)
+	}
+	ClearGuestCache()
+	return nil
+}
+
+// DeleteGuest removes the guest with id from the invite list
+func (s *SQLiteStore) DeleteGuest(id string) error {
+	result, err := s.db.Exec(`DELETE FROM guests WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete guest: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("guest %s not found", id)
+	}
+	ClearGuestCache()
+	return nil
+}
+
+// ListHouseholds returns every household, ordered by name
+func (s *SQLiteStore) ListHouseholds() ([]Household, error) {
+	rows, err := s.db.Query(`SELECT id, name, invite_code, created_at FROM households ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch households: %v", err)
+	}
+	defer rows.Close()
+
+	households := []Household{}
+	for rows.Next() {
+		var h Household
+		if err := rows.Scan(&h.ID, &h.Name, &h.InviteCode, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		households = append(households, h)
+	}
+	return households, rows.Err()
+}
+
+// FindOrCreateHousehold looks up a household by normalised name, inserting
+// it if it doesn't exist
+func (s *SQLiteStore) FindOrCreateHousehold(name string) (*Household, error) {
+	name = strings.TrimSpace(name)
+	households, err := s.ListHouseholds()
+	if err != nil {
+		return nil, err
+	}
+	if h := findHouseholdByNormalizedName(name, households); h != nil {
+		return h, nil
+	}
+
+	h := Household{ID: newID(), Name: name, CreatedAt: time.Now().UTC().Format(time.RFC3339)}
+	if _, err := s.db.Exec(`INSERT INTO households (id, name, created_at) VALUES (?, ?, ?)`, h.ID, h.Name, h.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to create household: %v", err)
+	}
+	return &h, nil
+}
+
+// SetHouseholdInviteCode replaces the invite code of the household with id
+func (s *SQLiteStore) SetHouseholdInviteCode(id, code string) error {
+	res, err := s.db.Exec(`UPDATE households SET invite_code = ? WHERE id = ?`, code, id)
+	if err != nil {
+		return fmt.Errorf("failed to set invite code: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("household %s not found", id)
+	}
+	return nil
+}
+
+// scanRSVPs reads RSVP rows selected with the standard column order
+func scanRSVPs(rows *sql.Rows) ([]RSVPRecord, error) {
+	defer rows.Close()
+
+	rsvps := []RSVPRecord{}
+	for rows.Next() {
+		var r RSVPRecord
+		var attendingGuests, plusOnes, guestDiets, guestMeals, guestEvents, avatarData string
+		if err := rows.Scan(&r.ID, &r.Name, &r.Email, &r.IsAttending, &attendingGuests, &plusOnes, &guestDiets, &guestMeals, &guestEvents,
+			&r.Diet, &r.SubmittedAt, &r.Verified, &avatarData, &r.Late); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(attendingGuests), &r.AttendingGuests)
+		json.Unmarshal([]byte(plusOnes), &r.PlusOnes)
+		json.Unmarshal([]byte(guestDiets), &r.GuestDiets)
+		json.Unmarshal([]byte(guestMeals), &r.GuestMeals)
+		json.Unmarshal([]byte(guestEvents), &r.GuestEvents)
+		json.Unmarshal([]byte(avatarData), &r.AvatarData)
+		rsvps = append(rsvps, r)
+	}
+	return rsvps, rows.Err()
+}
+
+// ListMenuOptions returns the menu, ordered by course
+func (s *SQLiteStore) ListMenuOptions() ([]MenuOption, error) {
+	rows, err := s.db.Query(`SELECT id, course, name, description, created_at FROM menu_options ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch menu: %v", err)
+	}
+	defer rows.Close()
+
+	menu := []MenuOption{}
+	for rows.Next() {
+		var o MenuOption
+		if err := rows.Scan(&o.ID, &o.Course, &o.Name, &o.Description, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		menu = append(menu, o)
+	}
+	sortMenu(menu)
+	return menu, rows.Err()
+}
+
+// AddMenuOption inserts a dish into the menu
+func (s *SQLiteStore) AddMenuOption(option MenuOption) (*MenuOption, error) {
+	option.ID = newID()
+	option.CreatedAt = time.Now().UTC().Format(sqliteTimeFormat)
+	_, err := s.db.Exec(`INSERT INTO menu_options (id, course, name, description, created_at) VALUES (?, ?, ?, ?, ?)`,
+		option.ID, option.Course, option.Name, option.Description, option.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add menu option: %v", err)
+	}
+	return &option, nil
+}
+
+// DeleteMenuOption removes the dish with id from the menu
+func (s *SQLiteStore) DeleteMenuOption(id string) error {
+	result, err := s.db.Exec(`DELETE FROM menu_options WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete menu option: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("menu option %s not found", id)
+	}
+	return nil
+}
+
+// ListEvents returns every event, in running order
+func (s *SQLiteStore) ListEvents() ([]Event, error) {
+	rows, err := s.db.Query(`SELECT id, name, venue, starts_at, capacity, all_guests FROM events`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch events: %v", err)
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.Name, &e.Venue, &e.StartsAt, &e.Capacity, &e.AllGuests); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	sortEvents(events)
+	return events, rows.Err()
+}
+
+// SaveEvent inserts the event, or updates the one with the same ID
+func (s *SQLiteStore) SaveEvent(event Event) error {
+	_, err := s.db.Exec(`INSERT INTO events (id, name, venue, starts_at, capacity, all_guests) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, venue = excluded.venue, starts_at = excluded.starts_at,
+			capacity = excluded.capacity, all_guests = excluded.all_guests`,
+		event.ID, event.Name, event.Venue, event.StartsAt, event.Capacity, event.AllGuests)
+	if err != nil {
+		return fmt.Errorf("failed to save event: %v", err)
+	}
+	return nil
+}
+
+// sqliteTimeFormat is fixed-width so submitted_at sorts correctly as text
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+const sqliteRSVPColumns = `id, name, email, is_attending, attending_guests, plus_ones, guest_diets, guest_meals, guest_events, diet, submitted_at, verified, avatar_data, late`
+
+// ListRSVPs returns every RSVP, newest first
+func (s *SQLiteStore) ListRSVPs() ([]RSVPRecord, error) {
+	rows, err := s.db.Query(`SELECT ` + sqliteRSVPColumns + ` FROM rsvps ORDER BY submitted_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch RSVPs: %v", err)
+	}
+	return scanRSVPs(rows)
+}
+
+// SaveRSVP inserts an RSVP submission
+func (s *SQLiteStore) SaveRSVP(rsvp RSVPRequest) error {
+	attendingGuests, _ := json.Marshal(nonNilStrings(rsvp.AttendingGuests))
+	plusOnes, _ := json.Marshal(nonNilStrings(rsvp.PlusOnes))
+	guestDiets, _ := json.Marshal(nonNilDiets(rsvp.GuestDiets))
+	guestMeals, _ := json.Marshal(nonNilMeals(rsvp.GuestMeals))
+	guestEvents, _ := json.Marshal(nonNilEvents(rsvp.GuestEvents))
+	_, err := s.db.Exec(`INSERT INTO rsvps (id, name, email, is_attending, attending_guests, plus_ones, guest_diets, guest_meals, guest_events, diet, submitted_at, verified, late)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		newID(), rsvp.Name, rsvp.Email, rsvp.IsAttending, string(attendingGuests), string(plusOnes), string(guestDiets), string(guestMeals), string(guestEvents), rsvp.Diet,
+		time.Now().UTC().Format(sqliteTimeFormat), rsvp.Verified, rsvp.LateRequest)
+	if err != nil {
+		return fmt.Errorf("failed to save RSVP: %v", err)
+	}
+	return nil
+}
+
+// VerifyRSVPs marks every RSVP for email as verified and applies update
+func (s *SQLiteStore) VerifyRSVPs(email string, update RSVPUpdate) ([]RSVPRecord, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE rsvps SET verified = 1 WHERE email = ?`, email); err != nil {
+		return nil, err
+	}
+	if name := strings.TrimSpace(update.Name); name != "" {
+		if _, err := tx.Exec(`UPDATE rsvps SET name = ? WHERE email = ?`, name, email); err != nil {
+			return nil, err
+		}
+	}
+	if update.AttendingGuests != nil {
+		attendingGuests, _ := json.Marshal(cleanGuestNames(update.AttendingGuests))
+		if _, err := tx.Exec(`UPDATE rsvps SET attending_guests = ? WHERE email = ?`, string(attendingGuests), email); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := tx.Query(`SELECT `+sqliteRSVPColumns+` FROM rsvps WHERE email = ?`, email)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := scanRSVPs(rows)
+	if err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit()
+}
+
+// DeleteRSVPs removes every RSVP for email
+func (s *SQLiteStore) DeleteRSVPs(email string) error {
+	_, err := s.db.Exec(`DELETE FROM rsvps WHERE email = ?`, email)
+	return err
+}
+
+// DeleteRSVP removes the RSVP with id
+func (s *SQLiteStore) DeleteRSVP(id string) error {
+	_, err := s.db.Exec(`DELETE FROM rsvps WHERE id = ?`, id)
+	return err
+}
+
+// LatestRSVP returns the most recent RSVP for email
+func (s *SQLiteStore) LatestRSVP(email string) (*RSVPRecord, error) {
+	rows, err := s.db.Query(`SELECT `+sqliteRSVPColumns+` FROM rsvps WHERE email = ? ORDER BY submitted_at DESC LIMIT 1`, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch RSVP: %v", err)
+	}
+	rsvps, err := scanRSVPs(rows)
+	if err != nil || len(rsvps) == 0 {
+		return nil, err
+	}
+	return &rsvps[0], nil
+}
+
+// UpdateRSVP applies changes to the RSVP with id
+func (s *SQLiteStore) UpdateRSVP(id string, changes RSVPChanges) (*RSVPRecord, error) {
+	var sets []string
+	var args []interface{}
+	if changes.IsAttending != nil {
+		sets = append(sets, "is_attending = ?")
+		args = append(args, *changes.IsAttending)
+	}
+	if changes.AttendingGuests != nil {
+		attendingGuests, _ := json.Marshal(cleanGuestNames(changes.AttendingGuests))
+		sets = append(sets, "attending_guests = ?")
+		args = append(args, string(attendingGuests))
+	}
+	if changes.PlusOnes != nil {
+		plusOnes, _ := json.Marshal(cleanGuestNames(changes.PlusOnes))
+		sets = append(sets, "plus_ones = ?")
+		args = append(args, string(plusOnes))
+	}
+	if changes.GuestDiets != nil {
+		guestDiets, _ := json.Marshal(changes.GuestDiets)
+		sets = append(sets, "guest_diets = ?")
+		args = append(args, string(guestDiets))
+	}
+	if changes.GuestMeals != nil {
+		guestMeals, _ := json.Marshal(changes.GuestMeals)
+		sets = append(sets, "guest_meals = ?")
+		args = append(args, string(guestMeals))
+	}
+	if changes.GuestEvents != nil {
+		guestEvents, _ := json.Marshal(changes.GuestEvents)
+		sets = append(sets, "guest_events = ?")
+		args = append(args, string(guestEvents))
+	}
+	if changes.Diet != nil {
+		sets = append(sets, "diet = ?")
+		args = append(args, *changes.Diet)
+	}
+	if changes.AvatarData != nil {
+		avatarData, _ := json.Marshal(changes.AvatarData)
+		sets = append(sets, "avatar_data = ?")
+		args = append(args, string(avatarData))
+	}
+
+	if len(sets) > 0 {
+		args = append(args, id)
+		if _, err := s.db.Exec(`UPDATE rsvps SET `+strings.Join(sets, ", ")+` WHERE id = ?`, args...); err != nil {
+			return nil, fmt.Errorf("failed to update RSVP: %v", err)
+		}
+	}
+
+	rows, err := s.db.Query(`SELECT `+sqliteRSVPColumns+` FROM rsvps WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	rsvps, err := scanRSVPs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(rsvps) == 0 {
+		return nil, fmt.Errorf("RSVP %s not found", id)
+	}
+	return &rsvps[0], nil
+}
+
+// SaveAvatars stores avatars on the most recent RSVP for email
+func (s *SQLiteStore) SaveAvatars(email string, avatars []AvatarSelection) error {
+	avatarData, err := json.Marshal(avatars)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`UPDATE rsvps SET avatar_data = ? WHERE id = (
+		SELECT id FROM rsvps WHERE email = ? ORDER BY submitted_at DESC LIMIT 1)`,
+		string(avatarData), email)
+	return err
+}
+
+// ListAvatars returns the avatar selections of verified, attending RSVPs
+func (s *SQLiteStore) ListAvatars() ([]AvatarSelection, error) {
+	rows, err := s.db.Query(`SELECT avatar_data FROM rsvps WHERE is_attending = 1 AND verified = 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	avatars := []AvatarSelection{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var selections []AvatarSelection
+		if err := json.Unmarshal([]byte(data), &selections); err != nil {
+			log.Printf("Error parsing avatar_data: %v (data: %s)", err, data)
+			continue
+		}
+		avatars = append(avatars, selections...)
+	}
+	return avatars, rows.Err()
+}
+
+// SetOverride replaces any admin override for guestName with a new one
+func (s *SQLiteStore) SetOverride(guestName string, isAttending bool) error {
+	if err := s.ClearOverride(guestName); err != nil {
+		return err
+	}
+	return s.SaveRSVP(overrideRecord(guestName, isAttending))
+}
+
+// ClearOverride removes any admin override for guestName
+func (s *SQLiteStore) ClearOverride(guestName string) error {
+	return s.DeleteRSVPs(OverrideEmail(guestName))
+}
+
+// nonNilStrings returns s, or an empty slice if s is nil, so it encodes as []
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// nonNilDiets returns d, or an empty slice if d is nil, so it encodes as []
+func nonNilDiets(d []GuestDiet) []GuestDiet {
+	if d == nil {
+		return []GuestDiet{}
+	}
+	return d
+}
+
+// nonNilMeals returns m, or an empty slice if m is nil, so it encodes as []
+func nonNilMeals(m []GuestMeal) []GuestMeal {
+	if m == nil {
+		return []GuestMeal{}
+	}
+	return m
+}
+
+// nonNilEvents returns e, or an empty slice if e is nil, so it encodes as []
+func nonNilEvents(e []EventAttendance) []EventAttendance {
+	if e == nil {
+		return []EventAttendance{}
+	}
+	return e
+}
+
+// ListAdmins returns every admin account, ordered by username
+func (s *SQLiteStore) ListAdmins() ([]AdminAccount, error) {
+	rows, err := s.db.Query(`SELECT username, password_hash, role, created_at, updated_at FROM admins ORDER BY username`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch admins: %v", err)
+	}
+	defer rows.Close()
+
+	admins := []AdminAccount{}
+	for rows.Next() {
+		var a AdminAccount
+		if err := rows.Scan(&a.Username, &a.PasswordHash, &a.Role, &a.CreatedAt, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		admins = append(admins, a)
+	}
+	return admins, rows.Err()
+}
+
+// GetAdmin looks up an admin account by username
+func (s *SQLiteStore) GetAdmin(username string) (*AdminAccount, error) {
+	var a AdminAccount
+	err := s.db.QueryRow(`SELECT username, password_hash, role, created_at, updated_at FROM admins WHERE username = ?`,
+		NormalizeUsername(username)).Scan(&a.Username, &a.PasswordHash, &a.Role, &a.CreatedAt, &a.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// SaveAdmin creates or updates an admin account
+func (s *SQLiteStore) SaveAdmin(account AdminAccount) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := s.db.Exec(`INSERT INTO admins (username, password_hash, role, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(username) DO UPDATE SET password_hash = excluded.password_hash, role = excluded.role, updated_at = excluded.updated_at`,
+		NormalizeUsername(account.Username), account.PasswordHash, string(account.Role), now, now)
+	if err != nil {
+		return fmt.Errorf("failed to save admin: %v", err)
+	}
+	return nil
+}
+
+// GetRateLimit returns the rate limit state for key, or nil
+func (s *SQLiteStore) GetRateLimit(key string) (*RateLimitState, error) {
+	var state RateLimitState
+	var windowStart, blockedUntil string
+	err := s.db.QueryRow(`SELECT key, window_start, count, misses, blocked_until FROM rate_limits WHERE key = ?`, key).
+		Scan(&state.Key, &windowStart, &state.Count, &state.Misses, &blockedUntil)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rate limit: %v", err)
+	}
+	state.WindowStart, _ = time.Parse(time.RFC3339Nano, windowStart)
+	if blockedUntil != "" {
+		state.BlockedUntil, _ = time.Parse(time.RFC3339Nano, blockedUntil)
+	}
+	return &state, nil
+}
+
+// SaveRateLimit inserts the rate limit state, or replaces the one for the same key
+func (s *SQLiteStore) SaveRateLimit(state RateLimitState) error {
+	blockedUntil := ""
+	if !state.BlockedUntil.IsZero() {
+		blockedUntil = state.BlockedUntil.UTC().Format(time.RFC3339Nano)
+	}
+	_, err := s.db.Exec(`INSERT INTO rate_limits (key, window_start, count, misses, blocked_until) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET window_start = excluded.window_start, count = excluded.count,
+			misses = excluded.misses, blocked_until = excluded.blocked_until`,
+		state.Key, state.WindowStart.UTC().Format(time.RFC3339Nano), state.Count, state.Misses, blockedUntil)
+	if err != nil {
+		return fmt.Errorf("failed to save rate limit: %v", err)
+	}
+	return nil
+}
+
+const sqliteOutboxColumns = `id, kind, recipient, subject, text_body, html_body, template, template_data, status,
+	attempts, resends, last_error, next_attempt_at, provider_id, created_at, sent_at`
+
+// EnqueueEmail adds email to the outbox
+func (s *SQLiteStore) EnqueueEmail(email OutboxEmail) (*OutboxEmail, error) {
+	email.ID = newID()
+	email.CreatedAt = time.Now().UTC()
+	templateData, _ := json.Marshal(email.TemplateData)
+	_, err := s.db.Exec(`INSERT INTO email_outbox (`+sqliteOutboxColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		email.ID, email.Kind, email.To, email.Subject, email.Text, email.HTML, email.Template, string(templateData), email.Status,
+		email.Attempts, email.Resends, email.LastError, sqliteTime(email.NextAttemptAt), email.ProviderID,
+		sqliteTime(email.CreatedAt), sqliteTime(email.SentAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue email: %v", err)
+	}
+	return &email, nil
+}
+
+// UpdateOutboxEmail saves the delivery state of the outbox email with email.ID
+func (s *SQLiteStore) UpdateOutboxEmail(email OutboxEmail) error {
+	result, err := s.db.Exec(`UPDATE email_outbox SET status = ?, attempts = ?, resends = ?, last_error = ?,
+		next_attempt_at = ?, provider_id = ?, sent_at = ? WHERE id = ?`,
+		email.Status, email.Attempts, email.Resends, email.LastError,
+		sqliteTime(email.NextAttemptAt), email.ProviderID, sqliteTime(email.SentAt), email.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox email: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("outbox email %s not found", email.ID)
+	}
+	return nil
+}
+
+// GetOutboxEmail returns the outbox email with id, or nil
+func (s *SQLiteStore) GetOutboxEmail(id string) (*OutboxEmail, error) {
+	return s.findOutboxEmail(`id = ?`, id)
+}
+
+// FindOutboxEmailByProviderID returns the outbox email Resend knows as providerID, or nil
+func (s *SQLiteStore) FindOutboxEmailByProviderID(providerID string) (*OutboxEmail, error) {
+	if providerID == "" {
+		return nil, nil
+	}
+	return s.findOutboxEmail(`provider_id = ?`, providerID)
+}
+
+func (s *SQLiteStore) findOutboxEmail(where string, arg string) (*OutboxEmail, error) {
+	emails, err := s.queryOutbox(`SELECT `+sqliteOutboxColumns+` FROM email_outbox WHERE `+where+` LIMIT 1`, arg)
+	if err != nil || len(emails) == 0 {
+		return nil, err
+	}
+	return &emails[0], nil
+}
+
+// ListOutboxEmails returns the outbox emails with any of statuses, newest first
+func (s *SQLiteStore) ListOutboxEmails(statuses ...string) ([]OutboxEmail, error) {
+	query := `SELECT ` + sqliteOutboxColumns + ` FROM email_outbox`
+	args := make([]interface{}, len(statuses))
+	if len(statuses) > 0 {
+		query += ` WHERE status IN (?` + strings.Repeat(`, ?`, len(statuses)-1) + `)`
+		for i, status := range statuses {
+			args[i] = status
+		}
+	}
+	return s.queryOutbox(query+` ORDER BY created_at DESC`, args...)
+}
+
+// DueOutboxEmails returns up to limit queued emails due by now, and sending
+// ones whose lease ran out, oldest first
+func (s *SQLiteStore) DueOutboxEmails(now time.Time, limit int) ([]OutboxEmail, error) {
+	return s.queryOutbox(`SELECT `+sqliteOutboxColumns+` FROM email_outbox
+		WHERE status IN (?, ?) AND next_attempt_at <= ? ORDER BY created_at LIMIT ?`,
+		EmailQueued, EmailSending, sqliteTime(now), limit)
+}
+
+// ClaimOutboxEmail marks the outbox email with id as sending until
+// leaseUntil, if it is one of from and due by now. The single UPDATE is
+// the compare-and-set: of two senders racing, only one changes the row.
+func (s *SQLiteStore) ClaimOutboxEmail(id string, from []string, now, leaseUntil time.Time) (*OutboxEmail, error) {
+	if len(from) == 0 {
+		return nil, nil
+	}
+	args := []interface{}{EmailSending, sqliteTime(leaseUntil), id}
+	for _, status := range from {
+		args = append(args, status)
+	}
+	args = append(args, EmailQueued, EmailSending, sqliteTime(now))
+	result, err := s.db.Exec(`UPDATE email_outbox SET status = ?, next_attempt_at = ?
+		WHERE id = ? AND status IN (?`+strings.Repeat(`, ?`, len(from)-1)+`)
+		AND (status NOT IN (?, ?) OR next_attempt_at <= ?)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox email: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+	return s.GetOutboxEmail(id)
+}
+
+func (s *SQLiteStore) queryOutbox(query string, args ...interface{}) ([]OutboxEmail, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outbox emails: %v", err)
+	}
+	defer rows.Close()
+
+	emails := []OutboxEmail{}
+	for rows.Next() {
+		var e OutboxEmail
+		var templateData, nextAttemptAt, createdAt, sentAt string
+		if err := rows.Scan(&e.ID, &e.Kind, &e.To, &e.Subject, &e.Text, &e.HTML, &e.Template, &templateData, &e.Status,
+			&e.Attempts, &e.Resends, &e.LastError, &nextAttemptAt, &e.ProviderID, &createdAt, &sentAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(templateData), &e.TemplateData); err != nil {
+			log.Printf("Error parsing template_data for outbox email %s: %v", e.ID, err)
+		}
+		e.NextAttemptAt, _ = time.Parse(sqliteTimeFormat, nextAttemptAt)
+		e.CreatedAt, _ = time.Parse(sqliteTimeFormat, createdAt)
+		e.SentAt, _ = time.Parse(sqliteTimeFormat, sentAt)
+		emails = append(emails, e)
+	}
+	return emails, rows.Err()
+}
+
+// sqliteTime formats t for a TEXT column that sorts in time order, or ""
+// for the zero time
+func sqliteTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(sqliteTimeFormat)
+}
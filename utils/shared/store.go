@@ -0,0 +1,234 @@
+package shared
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// AdminOverrideEmailSuffix marks RSVP rows created by an admin setting a
+// guest's status from the dashboard rather than by a guest submission.
+const AdminOverrideEmailSuffix = "@admin.jemarko.internal"
+
+// Store is the persistence layer behind every API handler. Database (the
+// Supabase REST client) is the production implementation; MemoryStore and
+// SQLiteStore let the whole API run offline.
+type Store interface {
+	// ListGuests returns every guest on the invite list, ordered by address then name
+	ListGuests() ([]Guest, error)
+	// FindGuestByName returns the guest whose name matches after
+	// NormalizeString, or nil
+	FindGuestByName(name string) (*Guest, error)
+	// AddGuest inserts a single guest on the invite list
+	AddGuest(guest Guest) error
+	// SetGuestDietary replaces the dietary notes and categories stored
+	// against the guest with id
+	SetGuestDietary(id, dietary string, categories []string) error
+	// UpdateGuest replaces the invite list details (name, address,
+	// household, events, plus-ones and table) of the guest with guest.ID,
+	// leaving what guests set themselves, like dietary requirements, as is
+	UpdateGuest(guest Guest) error
+	// DeleteGuest removes the guest with id from the invite list
+	DeleteGuest(id string) error
+
+	// ListHouseholds returns every household, ordered by name
+	ListHouseholds() ([]Household, error)
+	// FindOrCreateHousehold returns the household whose name matches
+	// after NormalizeString, creating it if there is none
+	FindOrCreateHousehold(name string) (*Household, error)
+	// SetHouseholdInviteCode replaces the invite code of the household with id
+	SetHouseholdInviteCode(id, code string) error
+
+	// ListMenuOptions returns the reception menu, ordered by course
+	ListMenuOptions() ([]MenuOption, error)
+	// AddMenuOption adds a dish to the menu and returns it with its ID
+	AddMenuOption(option MenuOption) (*MenuOption, error)
+	// DeleteMenuOption removes the dish with id from the menu
+	DeleteMenuOption(id string) error
+
+	// ListEvents returns every event, in running order
+	ListEvents() ([]Event, error)
+	// SaveEvent adds the event, or updates it if its ID already exists
+	SaveEvent(event Event) error
+
+	// ListRSVPs returns every RSVP submission, newest first
+	ListRSVPs() ([]RSVPRecord, error)
+	// SaveRSVP inserts a new RSVP submission
+	SaveRSVP(rsvp RSVPRequest) error
+	// VerifyRSVPs marks every RSVP for email as verified, applies any
+	// corrections in update, and returns the updated rows
+	VerifyRSVPs(email string, update RSVPUpdate) ([]RSVPRecord, error)
+	// DeleteRSVPs removes every RSVP for email
+	DeleteRSVPs(email string) error
+	// DeleteRSVP removes the single RSVP with id, leaving any other RSVPs
+	// for the same email alone
+	DeleteRSVP(id string) error
+	// LatestRSVP returns the most recent RSVP for email, including its
+	// avatar selections, or nil
+	LatestRSVP(email string) (*RSVPRecord, error)
+	// UpdateRSVP applies a guest's changes to the RSVP with id in place and
+	// returns the updated row
+	UpdateRSVP(id string, changes RSVPChanges) (*RSVPRecord, error)
+
+	// SaveAvatars stores avatar selections on the most recent RSVP for email
+	SaveAvatars(email string, avatars []AvatarSelection) error
+	// ListAvatars returns the avatar selections of all verified, attending RSVPs
+	ListAvatars() ([]AvatarSelection, error)
+
+	// SetOverride records an admin-set RSVP status for a single guest,
+	// replacing any previous override for that guest
+	SetOverride(guestName string, isAttending bool) error
+	// ClearOverride removes any admin-set RSVP status for a guest
+	ClearOverride(guestName string) error
+
+	// ListAdmins returns every admin account, ordered by username
+	ListAdmins() ([]AdminAccount, error)
+	// GetAdmin returns the admin account for username, or nil
+	GetAdmin(username string) (*AdminAccount, error)
+	// SaveAdmin creates the admin account, or updates its password hash
+	// and role if the username already exists
+	SaveAdmin(account AdminAccount) error
+
+	// EnqueueEmail adds email to the outbox and returns it with its ID
+	EnqueueEmail(email OutboxEmail) (*OutboxEmail, error)
+	// UpdateOutboxEmail saves how sending the outbox email with email.ID
+	// has gone: its status, attempts, resends, last error, next attempt,
+	// provider ID and sent time
+	UpdateOutboxEmail(email OutboxEmail) error
+	// GetOutboxEmail returns the outbox email with id, or nil
+	GetOutboxEmail(id string) (*OutboxEmail, error)
+	// FindOutboxEmailByProviderID returns the outbox email Resend knows as
+	// providerID, or nil
+	FindOutboxEmailByProviderID(providerID string) (*OutboxEmail, error)
+	// ListOutboxEmails returns the outbox emails with any of statuses (all
+	// of them when none are given), newest first
+	ListOutboxEmails(statuses ...string) ([]OutboxEmail, error)
+	// DueOutboxEmails returns up to limit emails, oldest first, that are
+	// queued with their next attempt due by now, or sending under a lease
+	// that ran out by now
+	DueOutboxEmails(now time.Time, limit int) ([]OutboxEmail, error)
+	// ClaimOutboxEmail atomically marks the outbox email with id as
+	// EmailSending, leased until leaseUntil, if its status is one of from
+	// and, when it is queued or sending, its next attempt or lease is due by
+	// now. It returns the claimed email, or nil if it no longer qualifies
+	// (another sender claimed it first).
+	ClaimOutboxEmail(id string, from []string, now, leaseUntil time.Time) (*OutboxEmail, error)
+}
+
+// RSVPUpdate holds the corrections an admin may apply when verifying an RSVP
+type RSVPUpdate struct {
+	Name            string   // replaces the submitter name when non-empty
+	AttendingGuests []string // replaces the attending guests when non-nil
+}
+
+// RSVPChanges holds a guest's edits to their own RSVP. Nil fields are left
+// unchanged.
+type RSVPChanges struct {
+	IsAttending     *bool
+	AttendingGuests []string
+	PlusOnes        []string
+	GuestDiets      []GuestDiet
+	GuestMeals      []GuestMeal
+	GuestEvents     []EventAttendance
+	Diet            *string
+	AvatarData      []AvatarSelection
+}
+
+// NewStore returns the Store selected by the STORE_BACKEND env var:
+// "supabase" (the default), "memory", or "sqlite" (file at SQLITE_PATH).
+// The memory and SQLite stores are shared process-wide.
+func NewStore() (Store, error) {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("STORE_BACKEND")))
+	switch backend {
+	case "", "supabase":
+		db := NewDatabase()
+		if !db.IsConfigured() {
+			return nil, fmt.Errorf("Supabase not configured - please check SUPABASE_URL and SUPABASE_API_KEY")
+		}
+		return db, nil
+	case "memory":
+		return defaultMemoryStore(), nil
+	case "sqlite":
+		return defaultSQLiteStore()
+	default:
+		return nil, fmt.Errorf("unknown STORE_BACKEND %q (expected supabase, memory or sqlite)", backend)
+	}
+}
+
+// LoadGuests returns the invite list from store, served from a process-wide
+// cache after the first successful load. Use store.ListGuests directly where
+// freshness matters more than latency (e.g. the admin dashboard).
+func LoadGuests(store Store) ([]Guest, error) {
+	guestListMutex.RLock()
+	if len(guestListCache) > 0 {
+		guests := guestListCache
+		guestListMutex.RUnlock()
+		return guests, nil
+	}
+	guestListMutex.RUnlock()
+
+	guests, err := store.ListGuests()
+	if err != nil {
+		return nil, err
+	}
+
+	guestListMutex.Lock()
+	guestListCache = guests
+	guestListMutex.Unlock()
+
+	return guests, nil
+}
+
+// ClearGuestCache forces the next LoadGuests call to hit the store
+func ClearGuestCache() {
+	guestListMutex.Lock()
+	guestListCache = []Guest{}
+	guestListMutex.Unlock()
+}
+
+// OverrideEmail returns the synthetic email under which an admin override
+// RSVP for guestName is stored
+func OverrideEmail(guestName string) string {
+	safe := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(guestName), " ", "."))
+	return safe + AdminOverrideEmailSuffix
+}
+
+// overrideRecord builds the RSVP row that represents an admin override
+func overrideRecord(guestName string, isAttending bool) RSVPRequest {
+	attendingGuests := []string{}
+	if isAttending {
+		attendingGuests = []string{guestName}
+	}
+	return RSVPRequest{
+		Name:            guestName,
+		Email:           OverrideEmail(guestName),
+		IsAttending:     isAttending,
+		AttendingGuests: attendingGuests,
+		Verified:        true,
+	}
+}
+
+// cleanGuestNames trims names and drops empty entries
+func cleanGuestNames(names []string) []string {
+	cleaned := make([]string, 0, len(names))
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" {
+			cleaned = append(cleaned, n)
+		}
+	}
+	return cleaned
+}
+
+// newID returns a random UUID-formatted identifier for stores that don't
+// generate their own
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
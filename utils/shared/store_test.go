@@ -0,0 +1,635 @@
+package shared
+
+import (
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// forEachStore runs test against a fresh, empty store of each offline kind
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemoryStore(nil))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { store.Close() })
+		test(t, store)
+	})
+}
+
+// saveRSVPs saves each RSVP in turn, a moment apart so they order by
+// submission time
+func saveRSVPs(t *testing.T, store Store, rsvps ...RSVPRequest) {
+	t.Helper()
+	for _, rsvp := range rsvps {
+		if err := store.SaveRSVP(rsvp); err != nil {
+			t.Fatalf("SaveRSVP(%s) error = %v", rsvp.Email, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// rsvpsFor returns the RSVPs in store for email, newest first
+func rsvpsFor(t *testing.T, store Store, email string) []RSVPRecord {
+	t.Helper()
+	rsvps, err := store.ListRSVPs()
+	if err != nil {
+		t.Fatalf("ListRSVPs() error = %v", err)
+	}
+	var found []RSVPRecord
+	for _, r := range rsvps {
+		if r.Email == email {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+func TestStoreGuests(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		for _, g := range []Guest{
+			{Name: "Jane Smith", Address: "1 High St"},
+			{Name: "Bob Jones", Address: "9 Low Rd", Ceremony: true},
+			{Name: "Anna Smith", Address: "1 High St"},
+		} {
+			if err := store.AddGuest(g); err != nil {
+				t.Fatalf("AddGuest(%s) error = %v", g.Name, err)
+			}
+		}
+
+		guests, err := store.ListGuests()
+		if err != nil {
+			t.Fatalf("ListGuests() error = %v", err)
+		}
+		var names []string
+		for _, g := range guests {
+			if g.ID == "" {
+				t.Errorf("%s was stored without an ID", g.Name)
+			}
+			names = append(names, g.Name)
+		}
+		if want := []string{"Anna Smith", "Jane Smith", "Bob Jones"}; !slices.Equal(names, want) {
+			t.Errorf("ListGuests() = %v, want %v (by address, then name)", names, want)
+		}
+
+		tests := []struct {
+			name     string
+			want     string
+			ceremony bool
+		}{
+			{"Bob Jones", "Bob Jones", true},
+			{"  bob JONES ", "Bob Jones", true},
+			{"Jane Smith", "Jane Smith", false},
+			{"Bob", "", false},
+		}
+		for _, tt := range tests {
+			got, err := store.FindGuestByName(tt.name)
+			if err != nil {
+				t.Fatalf("FindGuestByName(%q) error = %v", tt.name, err)
+			}
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("FindGuestByName(%q) = %s, want nil", tt.name, got.Name)
+				}
+				continue
+			}
+			if got == nil || got.Name != tt.want || got.Ceremony != tt.ceremony {
+				t.Errorf("FindGuestByName(%q) = %+v, want %s (ceremony %v)", tt.name, got, tt.want, tt.ceremony)
+			}
+		}
+	})
+}
+
+func TestStoreUpdateGuest(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if err := store.AddGuest(Guest{Name: "Jane Smith", Address: "1 High St"}); err != nil {
+			t.Fatal(err)
+		}
+		jane, _ := store.FindGuestByName("Jane Smith")
+		if err := store.SetGuestDietary(jane.ID, "vegan", nil); err != nil {
+			t.Fatal(err)
+		}
+
+		update := Guest{ID: jane.ID, Name: "Jane Brown", Address: "2 High St", Ceremony: true, PlusOnes: 2, Table: "4", Dietary: "ignored"}
+		if err := store.UpdateGuest(update); err != nil {
+			t.Fatalf("UpdateGuest() error = %v", err)
+		}
+		got, _ := store.FindGuestByName("Jane Brown")
+		if got == nil || got.Address != "2 High St" || !slices.Equal(got.Events, []string{EventCeremony}) || got.PlusOnes != 2 || got.Table != "4" || got.Dietary != "vegan" {
+			t.Errorf("updated guest = %+v, want new details and her own dietary kept", got)
+		}
+		if err := store.UpdateGuest(Guest{ID: "missing", Name: "Nobody"}); err == nil {
+			t.Errorf("UpdateGuest(missing) succeeded")
+		}
+
+		if err := store.DeleteGuest(jane.ID); err != nil {
+			t.Fatalf("DeleteGuest() error = %v", err)
+		}
+		if guests, _ := store.ListGuests(); len(guests) != 0 {
+			t.Errorf("guests after delete = %+v", guests)
+		}
+		if err := store.DeleteGuest(jane.ID); err == nil {
+			t.Errorf("DeleteGuest(deleted) succeeded")
+		}
+	})
+}
+
+func TestStoreHouseholds(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		smiths, err := store.FindOrCreateHousehold(" The Smiths ")
+		if err != nil {
+			t.Fatalf("FindOrCreateHousehold() error = %v", err)
+		}
+		if smiths.ID == "" || smiths.Name != "The Smiths" {
+			t.Fatalf("FindOrCreateHousehold() = %+v", smiths)
+		}
+		again, err := store.FindOrCreateHousehold("the smiths")
+		if err != nil || again.ID != smiths.ID {
+			t.Errorf("FindOrCreateHousehold(other case) = %+v, %v, want %s", again, err, smiths.ID)
+		}
+		if _, err := store.FindOrCreateHousehold("Andersons"); err != nil {
+			t.Fatal(err)
+		}
+
+		households, err := store.ListHouseholds()
+		if err != nil {
+			t.Fatalf("ListHouseholds() error = %v", err)
+		}
+		if len(households) != 2 || households[0].Name != "Andersons" || households[1].Name != "The Smiths" {
+			t.Errorf("ListHouseholds() = %+v, want two by name", households)
+		}
+
+		if err := store.AddGuest(Guest{Name: "Jane Smith", HouseholdID: smiths.ID}); err != nil {
+			t.Fatal(err)
+		}
+		if jane, _ := store.FindGuestByName("Jane Smith"); jane == nil || jane.HouseholdID != smiths.ID {
+			t.Errorf("FindGuestByName() = %+v, want household %s", jane, smiths.ID)
+		}
+	})
+}
+
+func TestStoreNormalizedNames(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if err := store.AddGuest(Guest{Name: "Đorđe Petrović"}); err != nil {
+			t.Fatal(err)
+		}
+		for _, typed := range []string{"Djordje Petrovic", "ЂОРЂЕ ПЕТРОВИЋ", " dorde  petrović "} {
+			if g, err := store.FindGuestByName(typed); err != nil || g == nil || g.Name != "Đorđe Petrović" {
+				t.Errorf("FindGuestByName(%q) = %+v, %v", typed, g, err)
+			}
+		}
+
+		first, err := store.FindOrCreateHousehold("Đorđevići")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again, err := store.FindOrCreateHousehold("Djordjevici"); err != nil || again.ID != first.ID {
+			t.Errorf("FindOrCreateHousehold(Djordjevici) = %+v, %v, want %s", again, err, first.ID)
+		}
+	})
+}
+
+func TestStoreRSVPs(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		saveRSVPs(t, store,
+			RSVPRequest{Name: "Jane", Email: "jane@example.com", IsAttending: true, AttendingGuests: []string{"Jane Smith"}, Diet: "vegan"},
+			RSVPRequest{Name: "Bob", Email: "bob@example.com", LateRequest: true},
+			RSVPRequest{Name: "Jane", Email: "jane@example.com", IsAttending: true, AttendingGuests: []string{"Jane Smith", "Anna Smith"}},
+		)
+
+		rsvps, err := store.ListRSVPs()
+		if err != nil {
+			t.Fatalf("ListRSVPs() error = %v", err)
+		}
+		if len(rsvps) != 3 || rsvps[0].Email != "jane@example.com" || rsvps[1].Email != "bob@example.com" || len(rsvps[2].AttendingGuests) != 1 {
+			t.Fatalf("ListRSVPs() = %+v, want newest first", rsvps)
+		}
+		if first := rsvps[2]; first.Name != "Jane" || !first.IsAttending || first.Diet != "vegan" || first.Verified || first.Late || first.ID == "" || first.SubmittedAt == "" {
+			t.Errorf("saved RSVP = %+v", first)
+		}
+		if !rsvps[1].Late {
+			t.Errorf("late RSVP saved without Late")
+		}
+		if rsvps[1].AttendingGuests == nil {
+			t.Errorf("RSVP with no guests has nil AttendingGuests, want empty")
+		}
+
+		updated, err := store.VerifyRSVPs("jane@example.com", RSVPUpdate{Name: " Jane Smith ", AttendingGuests: []string{"Jane Smith", " "}})
+		if err != nil {
+			t.Fatalf("VerifyRSVPs() error = %v", err)
+		}
+		if len(updated) != 2 {
+			t.Fatalf("VerifyRSVPs() updated %d rows, want 2", len(updated))
+		}
+		for _, r := range rsvpsFor(t, store, "jane@example.com") {
+			if !r.Verified || r.Name != "Jane Smith" || !slices.Equal(r.AttendingGuests, []string{"Jane Smith"}) {
+				t.Errorf("verified RSVP = %+v", r)
+			}
+		}
+		if bob := rsvpsFor(t, store, "bob@example.com"); bob[0].Verified {
+			t.Errorf("VerifyRSVPs verified another email's RSVP")
+		}
+		if updated, _ := store.VerifyRSVPs("nobody@example.com", RSVPUpdate{}); len(updated) != 0 {
+			t.Errorf("VerifyRSVPs(unknown) = %+v, want none", updated)
+		}
+
+		bobRSVP := rsvpsFor(t, store, "bob@example.com")[0]
+		saveRSVPs(t, store, RSVPRequest{Name: "Bob Jones", Email: "bob@example.com", LateRequest: true})
+		late := rsvpsFor(t, store, "bob@example.com")[0]
+		if err := store.DeleteRSVP(late.ID); err != nil {
+			t.Fatalf("DeleteRSVP() error = %v", err)
+		}
+		if bob := rsvpsFor(t, store, "bob@example.com"); len(bob) != 1 || bob[0].ID != bobRSVP.ID {
+			t.Errorf("after DeleteRSVP, Bob's RSVPs = %+v, want only the first", bob)
+		}
+
+		if err := store.DeleteRSVPs("jane@example.com"); err != nil {
+			t.Fatalf("DeleteRSVPs() error = %v", err)
+		}
+		if rsvps, _ := store.ListRSVPs(); len(rsvps) != 1 || rsvps[0].Email != "bob@example.com" {
+			t.Errorf("after DeleteRSVPs, ListRSVPs() = %+v", rsvps)
+		}
+	})
+}
+
+func TestStoreUpdateRSVP(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if latest, err := store.LatestRSVP("jane@example.com"); err != nil || latest != nil {
+			t.Fatalf("LatestRSVP() before any RSVP = %+v, %v, want nil", latest, err)
+		}
+		saveRSVPs(t, store,
+			RSVPRequest{Name: "Jane", Email: "jane@example.com", IsAttending: true, AttendingGuests: []string{"Jane Smith"}},
+			RSVPRequest{Name: "Jane", Email: "jane@example.com", IsAttending: true, AttendingGuests: []string{"Jane Smith", "Anna Smith"}, Diet: "vegan"},
+		)
+		store.SaveAvatars("jane@example.com", []AvatarSelection{{GuestName: "Jane Smith", Avatar: "owl"}})
+
+		latest, err := store.LatestRSVP("jane@example.com")
+		if err != nil || latest == nil {
+			t.Fatalf("LatestRSVP() = %+v, %v", latest, err)
+		}
+		if len(latest.AttendingGuests) != 2 || len(latest.AvatarData) != 1 {
+			t.Fatalf("LatestRSVP() = %+v, want the newest RSVP with its avatars", latest)
+		}
+
+		diet := ""
+		updated, err := store.UpdateRSVP(latest.ID, RSVPChanges{AttendingGuests: []string{" Anna Smith ", ""}, Diet: &diet})
+		if err != nil {
+			t.Fatalf("UpdateRSVP() error = %v", err)
+		}
+		if !updated.IsAttending || !slices.Equal(updated.AttendingGuests, []string{"Anna Smith"}) || updated.Diet != "" || len(updated.AvatarData) != 1 {
+			t.Errorf("UpdateRSVP() = %+v, want only guests and diet changed", updated)
+		}
+
+		notAttending := false
+		if updated, err = store.UpdateRSVP(latest.ID, RSVPChanges{IsAttending: &notAttending, AvatarData: []AvatarSelection{}}); err != nil {
+			t.Fatalf("UpdateRSVP() error = %v", err)
+		}
+		if updated.IsAttending || len(updated.AvatarData) != 0 {
+			t.Errorf("UpdateRSVP() = %+v, want not attending with no avatars", updated)
+		}
+		if rsvps := rsvpsFor(t, store, "jane@example.com"); len(rsvps) != 2 || len(rsvps[1].AttendingGuests) != 1 || !rsvps[1].IsAttending {
+			t.Errorf("UpdateRSVP changed another row: %+v", rsvps)
+		}
+
+		if _, err := store.UpdateRSVP("missing", RSVPChanges{}); err == nil {
+			t.Errorf("UpdateRSVP(missing) succeeded")
+		}
+	})
+}
+
+func TestStorePlusOnes(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if err := store.AddGuest(Guest{Name: "Jane Smith", PlusOnes: 2}); err != nil {
+			t.Fatal(err)
+		}
+		if jane, _ := store.FindGuestByName("Jane Smith"); jane == nil || jane.PlusOnes != 2 {
+			t.Errorf("FindGuestByName() = %+v, want 2 plus-ones", jane)
+		}
+
+		saveRSVPs(t, store,
+			RSVPRequest{Name: "Jane", Email: "jane@example.com", IsAttending: true, AttendingGuests: []string{"Jane Smith"}, PlusOnes: []string{"Sam"}},
+			RSVPRequest{Name: "Bob", Email: "bob@example.com"},
+		)
+		jane, _ := store.LatestRSVP("jane@example.com")
+		if jane == nil || !slices.Equal(jane.PlusOnes, []string{"Sam"}) {
+			t.Fatalf("LatestRSVP() = %+v, want plus-one Sam", jane)
+		}
+		if bob, _ := store.LatestRSVP("bob@example.com"); bob == nil || len(bob.PlusOnes) != 0 {
+			t.Errorf("LatestRSVP() = %+v, want no plus-ones", bob)
+		}
+
+		updated, err := store.UpdateRSVP(jane.ID, RSVPChanges{PlusOnes: []string{" Kit ", ""}})
+		if err != nil || !slices.Equal(updated.PlusOnes, []string{"Kit"}) {
+			t.Errorf("UpdateRSVP() = %+v, %v, want plus-one Kit", updated, err)
+		}
+	})
+}
+
+func TestStoreDiets(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if err := store.AddGuest(Guest{Name: "Jane Smith"}); err != nil {
+			t.Fatal(err)
+		}
+		jane, _ := store.FindGuestByName("Jane Smith")
+		if err := store.SetGuestDietary(jane.ID, "no soy", []string{"vegan", "peanuts"}); err != nil {
+			t.Fatalf("SetGuestDietary() error = %v", err)
+		}
+		if jane, _ = store.FindGuestByName("Jane Smith"); jane.Dietary != "no soy" || !slices.Equal(jane.DietCategories, []string{"vegan", "peanuts"}) {
+			t.Errorf("dietary = %q %v, want no soy [vegan peanuts]", jane.Dietary, jane.DietCategories)
+		}
+
+		diets := []GuestDiet{{GuestName: "Jane Smith", Categories: []string{"vegan"}, Diet: "no soy"}}
+		saveRSVPs(t, store, RSVPRequest{Email: "jane@example.com", IsAttending: true, AttendingGuests: []string{"Jane Smith"}, GuestDiets: diets})
+		rsvp, _ := store.LatestRSVP("jane@example.com")
+		if !equalDiets(rsvp.GuestDiets, diets) {
+			t.Fatalf("saved diets = %+v, want %+v", rsvp.GuestDiets, diets)
+		}
+		updated, err := store.UpdateRSVP(rsvp.ID, RSVPChanges{GuestDiets: []GuestDiet{}})
+		if err != nil || len(updated.GuestDiets) != 0 {
+			t.Errorf("UpdateRSVP() = %+v, %v, want no diets", updated, err)
+		}
+	})
+}
+
+func TestStoreMenu(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		var ids []string
+		for _, option := range []MenuOption{
+			{Course: CourseDessert, Name: "Tart"},
+			{Course: CourseStarter, Name: "Soup", Description: "Leek and potato"},
+			{Course: CourseMain, Name: "Risotto"},
+		} {
+			added, err := store.AddMenuOption(option)
+			if err != nil || added.ID == "" {
+				t.Fatalf("AddMenuOption() = %+v, %v", added, err)
+			}
+			ids = append(ids, added.ID)
+		}
+		if err := store.DeleteMenuOption(ids[2]); err != nil {
+			t.Fatalf("DeleteMenuOption() error = %v", err)
+		}
+		if err := store.DeleteMenuOption(ids[2]); err == nil {
+			t.Errorf("DeleteMenuOption(deleted) succeeded")
+		}
+
+		menu, err := store.ListMenuOptions()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(menu) != 2 || menu[0].Name != "Soup" || menu[0].Description != "Leek and potato" || menu[1].Name != "Tart" {
+			t.Errorf("menu = %+v, want Soup then Tart", menu)
+		}
+
+		meals := []GuestMeal{{GuestName: "Jane Smith", Starter: ids[1], Dessert: ids[0]}}
+		saveRSVPs(t, store, RSVPRequest{Email: "jane@example.com", IsAttending: true, AttendingGuests: []string{"Jane Smith"}, GuestMeals: meals})
+		rsvp, _ := store.LatestRSVP("jane@example.com")
+		if !slices.Equal(rsvp.GuestMeals, meals) {
+			t.Fatalf("saved meals = %+v, want %+v", rsvp.GuestMeals, meals)
+		}
+		updated, err := store.UpdateRSVP(rsvp.ID, RSVPChanges{GuestMeals: []GuestMeal{}})
+		if err != nil || len(updated.GuestMeals) != 0 {
+			t.Errorf("UpdateRSVP() = %+v, %v, want no meals", updated, err)
+		}
+	})
+}
+
+func TestStoreEventList(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		events, err := store.ListEvents()
+		if err != nil || len(events) != 2 || events[0].ID != EventCeremony || !events[1].AllGuests {
+			t.Fatalf("ListEvents() = %+v, %v, want the default events", events, err)
+		}
+
+		brunch := Event{ID: "brunch", Name: "Brunch", Venue: "The Café", StartsAt: "2026-08-02T11:00:00+01:00", Capacity: 40}
+		drinks := Event{ID: "drinks", Name: "Drinks", StartsAt: "2026-07-31T19:00:00+01:00"}
+		for _, e := range []Event{brunch, drinks} {
+			if err := store.SaveEvent(e); err != nil {
+				t.Fatalf("SaveEvent(%s) error = %v", e.ID, err)
+			}
+		}
+		brunch.Capacity = 30
+		if err := store.SaveEvent(brunch); err != nil {
+			t.Fatalf("SaveEvent(update) error = %v", err)
+		}
+
+		events, _ = store.ListEvents()
+		var ids []string
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		if want := []string{"drinks", EventCeremony, EventReception, "brunch"}; !slices.Equal(ids, want) {
+			t.Errorf("ListEvents() = %v, want %v (by start time)", ids, want)
+		}
+		if got := events[3]; got != brunch {
+			t.Errorf("brunch = %+v, want %+v", got, brunch)
+		}
+
+		for _, g := range []Guest{
+			{Name: "Jane Smith", Ceremony: true},
+			{Name: "Bob Jones", Events: []string{"brunch", EventCeremony}},
+		} {
+			if err := store.AddGuest(g); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if jane, _ := store.FindGuestByName("Jane Smith"); !slices.Equal(jane.Events, []string{EventCeremony}) {
+			t.Errorf("Jane Smith events = %v, want the ceremony", jane.Events)
+		}
+		if bob, _ := store.FindGuestByName("Bob Jones"); !bob.Ceremony || !slices.Equal(bob.Events, []string{"brunch", EventCeremony}) {
+			t.Errorf("Bob Jones = %+v, want brunch and the ceremony", bob)
+		}
+	})
+}
+
+func TestStoreEvents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		events := []EventAttendance{{GuestName: "Jane Smith", Events: []string{EventReception}}}
+		saveRSVPs(t, store, RSVPRequest{Email: "jane@example.com", IsAttending: true, AttendingGuests: []string{"Jane Smith"}, GuestEvents: events})
+		rsvp, _ := store.LatestRSVP("jane@example.com")
+		if len(rsvp.GuestEvents) != 1 || rsvp.GuestEvents[0].GuestName != "Jane Smith" || !slices.Equal(rsvp.GuestEvents[0].Events, events[0].Events) {
+			t.Fatalf("saved events = %+v, want %+v", rsvp.GuestEvents, events)
+		}
+		updated, err := store.UpdateRSVP(rsvp.ID, RSVPChanges{GuestEvents: []EventAttendance{}})
+		if err != nil || len(updated.GuestEvents) != 0 {
+			t.Errorf("UpdateRSVP() = %+v, %v, want no events", updated, err)
+		}
+	})
+}
+
+// TestSQLiteStoreMigrates opens a database file created before the late
+// column existed
+func TestSQLiteStoreMigrates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE rsvps (id TEXT PRIMARY KEY, name TEXT NOT NULL DEFAULT '', email TEXT NOT NULL,
+		is_attending INTEGER NOT NULL DEFAULT 0, attending_guests TEXT NOT NULL DEFAULT '[]', diet TEXT NOT NULL DEFAULT '',
+		submitted_at TEXT NOT NULL, verified INTEGER NOT NULL DEFAULT 0, avatar_data TEXT NOT NULL DEFAULT '[]');
+		INSERT INTO rsvps (id, email, submitted_at) VALUES ('1', 'old@example.com', '2026-01-01T00:00:00.000000Z');
+		CREATE TABLE guests (id TEXT PRIMARY KEY, name TEXT NOT NULL, address TEXT NOT NULL DEFAULT '',
+			ceremony INTEGER NOT NULL DEFAULT 0, dietary TEXT NOT NULL DEFAULT '', created_at TEXT NOT NULL);
+		INSERT INTO guests (id, name, ceremony, created_at) VALUES ('1', 'Jane Smith', 1, '2026-01-01T00:00:00Z')`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		store, err := NewSQLiteStore(path)
+		if err != nil {
+			t.Fatalf("NewSQLiteStore() on an old file error = %v", err)
+		}
+		rsvps, err := store.ListRSVPs()
+		if err != nil || len(rsvps) != 1 || rsvps[0].Late {
+			t.Errorf("ListRSVPs() = %+v, %v, want the old RSVP, not late", rsvps, err)
+		}
+		guests, err := store.ListGuests()
+		store.Close()
+		if err != nil || len(guests) != 1 || !slices.Equal(guests[0].Events, []string{EventCeremony}) {
+			t.Errorf("ListGuests() = %+v, %v, want Jane invited to the ceremony", guests, err)
+		}
+	}
+}
+
+func TestStoreAvatars(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		saveRSVPs(t, store,
+			RSVPRequest{Email: "jane@example.com", IsAttending: true, Verified: true},
+			RSVPRequest{Email: "jane@example.com", IsAttending: true, Verified: true},
+			RSVPRequest{Email: "eve@example.com", IsAttending: true},
+			RSVPRequest{Email: "bob@example.com", Verified: true},
+		)
+		jane := []AvatarSelection{{GuestName: "Jane Smith", Avatar: "owl", Message: "Hi!"}}
+		for email, avatars := range map[string][]AvatarSelection{
+			"jane@example.com": jane,
+			"eve@example.com":  {{GuestName: "Eve", Avatar: "crow"}},
+			"bob@example.com":  {{GuestName: "Bob", Avatar: "wren"}},
+		} {
+			if err := store.SaveAvatars(email, avatars); err != nil {
+				t.Fatalf("SaveAvatars(%s) error = %v", email, err)
+			}
+		}
+
+		janes := rsvpsFor(t, store, "jane@example.com")
+		if len(janes[0].AvatarData) != 1 || len(janes[1].AvatarData) != 0 {
+			t.Errorf("SaveAvatars didn't go on the newest RSVP only: %+v", janes)
+		}
+
+		// Only verified, attending RSVPs are shown
+		avatars, err := store.ListAvatars()
+		if err != nil {
+			t.Fatalf("ListAvatars() error = %v", err)
+		}
+		if len(avatars) != 1 || avatars[0] != jane[0] {
+			t.Errorf("ListAvatars() = %+v, want %+v", avatars, jane)
+		}
+	})
+}
+
+func TestStoreOverrides(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		email := OverrideEmail("Jane Smith")
+		if err := store.SetOverride("Jane Smith", true); err != nil {
+			t.Fatalf("SetOverride() error = %v", err)
+		}
+		if err := store.SetOverride("Jane Smith", false); err != nil {
+			t.Fatalf("SetOverride() error = %v", err)
+		}
+		overrides := rsvpsFor(t, store, email)
+		if len(overrides) != 1 {
+			t.Fatalf("%d override rows after two SetOverride calls, want 1", len(overrides))
+		}
+		if o := overrides[0]; o.IsAttending || !o.Verified || o.Name != "Jane Smith" || len(o.AttendingGuests) != 0 {
+			t.Errorf("override = %+v, want a verified decline", o)
+		}
+
+		if err := store.ClearOverride("Jane Smith"); err != nil {
+			t.Fatalf("ClearOverride() error = %v", err)
+		}
+		if overrides := rsvpsFor(t, store, email); len(overrides) != 0 {
+			t.Errorf("ClearOverride left %+v", overrides)
+		}
+	})
+}
+
+func TestOverrideEmail(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Jane Smith", "jane.smith" + AdminOverrideEmailSuffix},
+		{" Mary Anne Williams ", "mary.anne.williams" + AdminOverrideEmailSuffix},
+	}
+	for _, tt := range tests {
+		if got := OverrideEmail(tt.name); got != tt.want {
+			t.Errorf("OverrideEmail(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	tests := []struct {
+		backend  string
+		supabase string
+		wantErr  bool
+	}{
+		{"", "https://example.supabase.co", false},
+		{"supabase", "", true},
+		{" Memory ", "", false},
+		{"postgres", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			t.Setenv("STORE_BACKEND", tt.backend)
+			t.Setenv("SUPABASE_URL", tt.supabase)
+			t.Setenv("SUPABASE_API_KEY", "key")
+			if _, err := NewStore(); (err != nil) != tt.wantErr {
+				t.Errorf("NewStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStoreAdmins(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if admins, err := store.ListAdmins(); err != nil || len(admins) != 0 {
+			t.Fatalf("ListAdmins() = %+v, %v, want none", admins, err)
+		}
+		for _, a := range []AdminAccount{
+			{Username: "Zoe", PasswordHash: "h1", Role: RoleViewer},
+			{Username: "ana", PasswordHash: "h2", Role: RoleOwner},
+			{Username: " ZOE ", PasswordHash: "h3", Role: RoleEditor},
+		} {
+			if err := store.SaveAdmin(a); err != nil {
+				t.Fatalf("SaveAdmin(%s) error = %v", a.Username, err)
+			}
+		}
+
+		admins, err := store.ListAdmins()
+		if err != nil {
+			t.Fatalf("ListAdmins() error = %v", err)
+		}
+		if len(admins) != 2 || admins[0].Username != "ana" || admins[1].Username != "zoe" {
+			t.Fatalf("ListAdmins() = %+v, want ana then zoe", admins)
+		}
+
+		zoe, err := store.GetAdmin("Zoe")
+		if err != nil {
+			t.Fatalf("GetAdmin() error = %v", err)
+		}
+		if zoe == nil || zoe.PasswordHash != "h3" || zoe.Role != RoleEditor || zoe.CreatedAt == "" {
+			t.Errorf("GetAdmin(Zoe) = %+v, want the updated account", zoe)
+		}
+		if missing, _ := store.GetAdmin("bob"); missing != nil {
+			t.Errorf("GetAdmin(bob) = %+v, want nil", missing)
+		}
+	})
+}
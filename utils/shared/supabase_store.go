@@ -0,0 +1,788 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// rsvpColumns is the column list selected whenever full RSVP rows are read
+const rsvpColumns = "id,name,email,is_attending,attending_guests,plus_ones,guest_diets,guest_meals,guest_events,diet,submitted_at,verified,late"
+
+// request sends an authenticated PostgREST request for path (relative to
+// /rest/v1/). body is JSON-encoded when non-nil; prefer sets the Prefer
+// header when non-empty.
+func (db *Database) request(method, path string, body interface{}, prefer string) (*http.Response, error) {
+	if !db.IsConfigured() {
+		return nil, fmt.Errorf("Supabase not configured - please check SUPABASE_URL and SUPABASE_API_KEY")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %v", err)
+		}
+		reader = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/rest/v1/%s", db.url, path), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("apikey", db.apiKey)
+	req.Header.Set("Authorization", "Bearer "+db.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if prefer != "" {
+		req.Header.Set("Prefer", prefer)
+	}
+
+	return db.client.Do(req)
+}
+
+// fetch GETs path and decodes the JSON response into out
+func (db *Database) fetch(path string, out interface{}) error {
+	resp, err := db.request("GET", path, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Supabase returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
+
+// guestColumns is the column list selected whenever guests are read
+const guestColumns = "id,name,address,household_id,ceremony,plus_ones,table_name,dietary,diet_categories,events"
+
+// toGuest converts a guests row to a Guest
+func (r GuestRecord) toGuest() Guest {
+	g := Guest{
+		ID: r.ID, Name: r.Name, Address: r.Address, Ceremony: r.Ceremony, PlusOnes: r.PlusOnes, Table: r.TableName,
+		Dietary: r.Dietary, DietCategories: r.DietCategories, Events: r.Events,
+	}
+	if r.HouseholdID != nil {
+		g.HouseholdID = *r.HouseholdID
+	}
+	syncCeremony(&g)
+	return g
+}
+
+// ListGuests fetches the full invite list from Supabase
+func (db *Database) ListGuests() ([]Guest, error) {
+	var records []GuestRecord
+	if err := db.fetch("guests?select="+guestColumns+"&order=address.asc,name.asc", &records); err != nil {
+		return nil, fmt.Errorf("failed to fetch guests: %v", err)
+	}
+
+	guests := make([]Guest, len(records))
+	for i, record := range records {
+		guests[i] = record.toGuest()
+	}
+
+	log.Printf("✓ Loaded %d guests from Supabase", len(guests))
+	return guests, nil
+}
+
+// FindGuestByName looks up a guest by normalised name (see
+// NormalizeString). PostgREST can't apply that normalisation, so the whole
+// (small) invite list is fetched and compared here.
+func (db *Database) FindGuestByName(name string) (*Guest, error) {
+	guests, err := db.ListGuests()
+	if err != nil {
+		return nil, err
+	}
+	return findGuestByNormalizedName(name, guests), nil
+}
+
+// AddGuest inserts a single guest into Supabase
+func (db *Database) AddGuest(guest Guest) error {
+	resp, err := db.request("POST", "guests", newGuestRecord(guest), "return=minimal")
+	if err != nil {
+		return fmt.Errorf("failed to add guest: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Supabase returned status %d", resp.StatusCode)
+	}
+
+	db.ClearCache()
+	return nil
+}
+
+// SetGuestDietary PATCHes the dietary columns of the guest with id
+func (db *Database) SetGuestDietary(id, dietary string, categories []string) error {
+	patch := map[string]interface{}{"dietary": dietary, "diet_categories": nonNilStrings(categories)}
+	resp, err := db.request("PATCH", "guests?id=eq."+url.QueryEscape(id), patch, "return=minimal")
+	if err != nil {
+		return fmt.Errorf("failed to update guest dietary: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Supabase PATCH returned %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	db.ClearCache()
+	return nil
+}
+
+// UpdateGuest PATCHes the invite list columns of the guest with guest.ID
+func (db *Database) UpdateGuest(guest Guest) error {
+	record := newGuestRecord(guest)
+	patch := map[string]interface{}{
+		"name": record.Name, "address": record.Address, "household_id": record.HouseholdID,
+		"ceremony": record.Ceremony, "events": record.Events, "plus_ones": record.PlusOnes, "table_name": record.TableName,
+	}
+	resp, err := db.request("PATCH", "guests?id=eq."+url.QueryEscape(guest.ID), patch, "return=representation")
+	if err != nil {
+		return fmt.Errorf("failed to update guest: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Supabase PATCH returned %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	var updated []GuestRecord
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err == nil && len(updated) == 0 {
+		return fmt.Errorf("guest %s not found", guest.ID)
+	}
+
+	db.ClearCache()
+	return nil
+}
+
+// DeleteGuest deletes the guest with id from the guests table
+func (db *Database) DeleteGuest(id string) error {
+	resp, err := db.request("DELETE", "guests?id=eq."+url.QueryEscape(id), nil, "return=representation")
+	if err != nil {
+		return fmt.Errorf("failed to delete guest: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Supabase DELETE returned %d", resp.StatusCode)
+	}
+	var deleted []GuestRecord
+	if err := json.NewDecoder(resp.Body).Decode(&deleted); err == nil && len(deleted) == 0 {
+		return fmt.Errorf("guest %s not found", id)
+	}
+
+	db.ClearCache()
+	return nil
+}
+
+// newGuestRecord converts a Guest to a guests row for insertion
+func newGuestRecord(guest Guest) GuestRecord {
+	syncCeremony(&guest)
+	record := GuestRecord{
+		Name:           guest.Name,
+		Address:        guest.Address,
+		Ceremony:       guest.Ceremony,
+		PlusOnes:       guest.PlusOnes,
+		TableName:      guest.Table,
+		Dietary:        guest.Dietary,
+		DietCategories: guest.DietCategories,
+		Events:         nonNilStrings(guest.Events),
+	}
+	if guest.HouseholdID != "" {
+		id := guest.HouseholdID
+		record.HouseholdID = &id
+	}
+	return record
+}
+
+// ListHouseholds fetches every household from Supabase
+func (db *Database) ListHouseholds() ([]Household, error) {
+	var households []Household
+	if err := db.fetch("households?select=id,name,invite_code,created_at&order=name.asc", &households); err != nil {
+		return nil, fmt.Errorf("failed to fetch households: %v", err)
+	}
+	return households, nil
+}
+
+// FindOrCreateHousehold looks up a household by normalised name, inserting
+// it if it doesn't exist
+func (db *Database) FindOrCreateHousehold(name string) (*Household, error) {
+	name = strings.TrimSpace(name)
+	households, err := db.ListHouseholds()
+	if err != nil {
+		return nil, err
+	}
+	if h := findHouseholdByNormalizedName(name, households); h != nil {
+		return h, nil
+	}
+
+	resp, err := db.request("POST", "households?select=id,name,invite_code,created_at", Household{Name: name}, "return=representation")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create household: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Supabase returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&households); err != nil || len(households) == 0 {
+		return nil, fmt.Errorf("failed to decode created household: %v", err)
+	}
+	return &households[0], nil
+}
+
+// SetHouseholdInviteCode sets invite_code on the household with id
+func (db *Database) SetHouseholdInviteCode(id, code string) error {
+	patch := map[string]interface{}{"invite_code": nil}
+	if code != "" {
+		patch["invite_code"] = code
+	}
+	resp, err := db.request("PATCH", "households?id=eq."+url.QueryEscape(id), patch, "return=representation")
+	if err != nil {
+		return fmt.Errorf("failed to set invite code: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Supabase returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	var updated []Household
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	if len(updated) == 0 {
+		return fmt.Errorf("household %s not found", id)
+	}
+	return nil
+}
+
+// menuColumns is the column list selected whenever menu options are read
+const menuColumns = "id,course,name,description,created_at"
+
+// ListMenuOptions fetches the menu from Supabase, ordered by course
+func (db *Database) ListMenuOptions() ([]MenuOption, error) {
+	var menu []MenuOption
+	if err := db.fetch("menu_options?select="+menuColumns+"&order=created_at.asc", &menu); err != nil {
+		return nil, fmt.Errorf("failed to fetch menu: %v", err)
+	}
+	sortMenu(menu)
+	return menu, nil
+}
+
+// AddMenuOption inserts a dish into the menu_options table
+func (db *Database) AddMenuOption(option MenuOption) (*MenuOption, error) {
+	option.ID, option.CreatedAt = "", ""
+	resp, err := db.request("POST", "menu_options?select="+menuColumns, option, "return=representation")
+	if err != nil {
+		return nil, fmt.Errorf("failed to add menu option: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Supabase returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	var created []MenuOption
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil || len(created) == 0 {
+		return nil, fmt.Errorf("failed to decode created menu option: %v", err)
+	}
+	return &created[0], nil
+}
+
+// DeleteMenuOption deletes the dish with id from the menu_options table
+func (db *Database) DeleteMenuOption(id string) error {
+	resp, err := db.request("DELETE", "menu_options?id=eq."+url.QueryEscape(id), nil, "return=representation")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Supabase DELETE returned %d", resp.StatusCode)
+	}
+	var deleted []MenuOption
+	if err := json.NewDecoder(resp.Body).Decode(&deleted); err == nil && len(deleted) == 0 {
+		return fmt.Errorf("menu option %s not found", id)
+	}
+	return nil
+}
+
+// eventColumns is the column list selected whenever events are read
+const eventColumns = "id,name,venue,starts_at,capacity,all_guests"
+
+// ListEvents fetches every event from Supabase, in running order
+func (db *Database) ListEvents() ([]Event, error) {
+	var events []Event
+	if err := db.fetch("events?select="+eventColumns, &events); err != nil {
+		return nil, fmt.Errorf("failed to fetch events: %v", err)
+	}
+	sortEvents(events)
+	return events, nil
+}
+
+// SaveEvent upserts the event into the events table by ID
+func (db *Database) SaveEvent(event Event) error {
+	row := map[string]interface{}{
+		"id": event.ID, "name": event.Name, "venue": event.Venue,
+		"starts_at": nil, "capacity": event.Capacity, "all_guests": event.AllGuests,
+	}
+	if event.StartsAt != "" {
+		row["starts_at"] = event.StartsAt
+	}
+	resp, err := db.request("POST", "events", row, "resolution=merge-duplicates,return=minimal")
+	if err != nil {
+		return fmt.Errorf("failed to save event: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Supabase returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	return nil
+}
+
+// ListRSVPs fetches every RSVP submission from Supabase, newest first
+func (db *Database) ListRSVPs() ([]RSVPRecord, error) {
+	var rsvps []RSVPRecord
+	if err := db.fetch("rsvps?select="+rsvpColumns+"&order=submitted_at.desc", &rsvps); err != nil {
+		return nil, fmt.Errorf("failed to fetch RSVPs: %v", err)
+	}
+	return rsvps, nil
+}
+
+// VerifyRSVPs PATCHes the rsvps row(s) for email, setting verified=true and
+// any corrected name/attending_guests
+func (db *Database) VerifyRSVPs(email string, update RSVPUpdate) ([]RSVPRecord, error) {
+	patch := map[string]interface{}{"verified": true}
+	if name := strings.TrimSpace(update.Name); name != "" {
+		patch["name"] = name
+	}
+	if update.AttendingGuests != nil {
+		patch["attending_guests"] = cleanGuestNames(update.AttendingGuests)
+	}
+
+	// return=representation so callers know whether any row matched and can
+	// send the confirmation email without a second fetch
+	path := fmt.Sprintf("rsvps?email=eq.%s&select=%s", url.QueryEscape(email), rsvpColumns)
+	resp, err := db.request("PATCH", path, patch, "return=representation")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Supabase PATCH returned %d", resp.StatusCode)
+	}
+
+	var rows []RSVPRecord
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// DeleteRSVPs removes every RSVP row for email
+func (db *Database) DeleteRSVPs(email string) error {
+	resp, err := db.request("DELETE", "rsvps?email=eq."+url.QueryEscape(email), nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("Supabase DELETE returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// DeleteRSVP removes the RSVP row with id
+func (db *Database) DeleteRSVP(id string) error {
+	resp, err := db.request("DELETE", "rsvps?id=eq."+url.QueryEscape(id), nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("Supabase DELETE returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// LatestRSVP fetches the most recent RSVP row for email, with avatar_data
+func (db *Database) LatestRSVP(email string) (*RSVPRecord, error) {
+	var rows []RSVPRecord
+	path := fmt.Sprintf("rsvps?select=%s,avatar_data&email=eq.%s&order=submitted_at.desc&limit=1",
+		rsvpColumns, url.QueryEscape(email))
+	if err := db.fetch(path, &rows); err != nil {
+		return nil, fmt.Errorf("failed to fetch RSVP: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// UpdateRSVP PATCHes a single RSVP row by id
+func (db *Database) UpdateRSVP(id string, changes RSVPChanges) (*RSVPRecord, error) {
+	patch := map[string]interface{}{}
+	if changes.IsAttending != nil {
+		patch["is_attending"] = *changes.IsAttending
+	}
+	if changes.AttendingGuests != nil {
+		patch["attending_guests"] = cleanGuestNames(changes.AttendingGuests)
+	}
+	if changes.PlusOnes != nil {
+		patch["plus_ones"] = cleanGuestNames(changes.PlusOnes)
+	}
+	if changes.GuestDiets != nil {
+		patch["guest_diets"] = changes.GuestDiets
+	}
+	if changes.GuestMeals != nil {
+		patch["guest_meals"] = changes.GuestMeals
+	}
+	if changes.GuestEvents != nil {
+		patch["guest_events"] = changes.GuestEvents
+	}
+	if changes.Diet != nil {
+		patch["diet"] = *changes.Diet
+	}
+	if changes.AvatarData != nil {
+		patch["avatar_data"] = changes.AvatarData
+	}
+
+	path := fmt.Sprintf("rsvps?id=eq.%s&select=%s,avatar_data", url.QueryEscape(id), rsvpColumns)
+	resp, err := db.request("PATCH", path, patch, "return=representation")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Supabase PATCH returned %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var rows []RSVPRecord
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("RSVP %s not found", id)
+	}
+	return &rows[0], nil
+}
+
+// SaveAvatars updates avatar_data on the most recent RSVP for email
+func (db *Database) SaveAvatars(email string, avatars []AvatarSelection) error {
+	patch := map[string]interface{}{"avatar_data": avatars}
+	path := fmt.Sprintf("rsvps?email=eq.%s&order=submitted_at.desc&limit=1", url.QueryEscape(email))
+
+	resp, err := db.request("PATCH", path, patch, "return=minimal")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Supabase PATCH returned %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	return nil
+}
+
+// ListAvatars fetches avatar_data from every verified, attending RSVP.
+// Rows whose avatar_data is null, empty or malformed are skipped.
+func (db *Database) ListAvatars() ([]AvatarSelection, error) {
+	// json.RawMessage so one bad row doesn't fail the whole decode
+	var rows []struct {
+		AvatarData json.RawMessage `json:"avatar_data"`
+	}
+	if err := db.fetch("rsvps?select=avatar_data&is_attending=eq.true&verified=eq.true", &rows); err != nil {
+		return nil, err
+	}
+
+	avatars := []AvatarSelection{}
+	for _, row := range rows {
+		if row.AvatarData == nil || string(row.AvatarData) == "null" || string(row.AvatarData) == "[]" {
+			continue
+		}
+		var selections []AvatarSelection
+		if err := json.Unmarshal(row.AvatarData, &selections); err != nil {
+			log.Printf("Error parsing avatar_data: %v (data: %s)", err, string(row.AvatarData))
+			continue
+		}
+		avatars = append(avatars, selections...)
+	}
+	return avatars, nil
+}
+
+// SetOverride replaces any admin override for guestName with a new one
+func (db *Database) SetOverride(guestName string, isAttending bool) error {
+	if err := db.ClearOverride(guestName); err != nil {
+		return err
+	}
+	return db.SaveRSVP(overrideRecord(guestName, isAttending))
+}
+
+// ClearOverride deletes any admin override row for guestName
+func (db *Database) ClearOverride(guestName string) error {
+	return db.DeleteRSVPs(OverrideEmail(guestName))
+}
+
+// ListAdmins fetches every admin account from Supabase
+func (db *Database) ListAdmins() ([]AdminAccount, error) {
+	var admins []AdminAccount
+	if err := db.fetch("admins?select=*&order=username.asc", &admins); err != nil {
+		return nil, fmt.Errorf("failed to fetch admins: %v", err)
+	}
+	return admins, nil
+}
+
+// GetAdmin fetches a single admin account by username
+func (db *Database) GetAdmin(username string) (*AdminAccount, error) {
+	var admins []AdminAccount
+	path := "admins?select=*&limit=1&username=eq." + url.QueryEscape(NormalizeUsername(username))
+	if err := db.fetch(path, &admins); err != nil {
+		return nil, fmt.Errorf("failed to fetch admin: %v", err)
+	}
+	if len(admins) == 0 {
+		return nil, nil
+	}
+	return &admins[0], nil
+}
+
+// SaveAdmin upserts an admin account on username
+func (db *Database) SaveAdmin(account AdminAccount) error {
+	record := map[string]interface{}{
+		"username":      NormalizeUsername(account.Username),
+		"password_hash": account.PasswordHash,
+		"role":          account.Role,
+		"updated_at":    time.Now().UTC().Format(time.RFC3339),
+	}
+
+	resp, err := db.request("POST", "admins?on_conflict=username", record, "resolution=merge-duplicates,return=minimal")
+	if err != nil {
+		return fmt.Errorf("failed to save admin: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Supabase returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	return nil
+}
+
+// GetRateLimit fetches the rate limit state for key, or nil
+func (db *Database) GetRateLimit(key string) (*RateLimitState, error) {
+	var states []RateLimitState
+	path := "rate_limits?select=key,window_start,count,misses,blocked_until&limit=1&key=eq." + url.QueryEscape(key)
+	if err := db.fetch(path, &states); err != nil {
+		return nil, fmt.Errorf("failed to fetch rate limit: %v", err)
+	}
+	if len(states) == 0 {
+		return nil, nil
+	}
+	return &states[0], nil
+}
+
+// SaveRateLimit upserts the rate limit state on key
+func (db *Database) SaveRateLimit(state RateLimitState) error {
+	record := map[string]interface{}{
+		"key":           state.Key,
+		"window_start":  state.WindowStart.UTC().Format(time.RFC3339Nano),
+		"count":         state.Count,
+		"misses":        state.Misses,
+		"blocked_until": nil,
+		"updated_at":    time.Now().UTC().Format(time.RFC3339),
+	}
+	if !state.BlockedUntil.IsZero() {
+		record["blocked_until"] = state.BlockedUntil.UTC().Format(time.RFC3339Nano)
+	}
+
+	resp, err := db.request("POST", "rate_limits?on_conflict=key", record, "resolution=merge-duplicates,return=minimal")
+	if err != nil {
+		return fmt.Errorf("failed to save rate limit: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Supabase returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	return nil
+}
+
+// outboxColumns is the column list selected whenever outbox emails are read
+const outboxColumns = "id,kind,recipient,subject,text_body,html_body,template,template_data,status," +
+	"attempts,resends,last_error,next_attempt_at,provider_id,created_at,sent_at"
+
+// EnqueueEmail inserts email into the email_outbox table
+func (db *Database) EnqueueEmail(email OutboxEmail) (*OutboxEmail, error) {
+	record := map[string]interface{}{
+		"kind":            email.Kind,
+		"recipient":       email.To,
+		"subject":         email.Subject,
+		"text_body":       email.Text,
+		"html_body":       email.HTML,
+		"template":        email.Template,
+		"template_data":   email.TemplateData,
+		"status":          email.Status,
+		"attempts":        email.Attempts,
+		"next_attempt_at": supabaseTime(email.NextAttemptAt),
+	}
+	resp, err := db.request("POST", "email_outbox?select="+outboxColumns, record, "return=representation")
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue email: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Supabase returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	var created []OutboxEmail
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil || len(created) == 0 {
+		return nil, fmt.Errorf("failed to decode queued email: %v", err)
+	}
+	return &created[0], nil
+}
+
+// UpdateOutboxEmail saves the delivery state of the outbox email with email.ID
+func (db *Database) UpdateOutboxEmail(email OutboxEmail) error {
+	record := map[string]interface{}{
+		"status":          email.Status,
+		"attempts":        email.Attempts,
+		"resends":         email.Resends,
+		"last_error":      email.LastError,
+		"next_attempt_at": supabaseTime(email.NextAttemptAt),
+		"provider_id":     email.ProviderID,
+		"sent_at":         supabaseTime(email.SentAt),
+		"updated_at":      time.Now().UTC().Format(time.RFC3339),
+	}
+	resp, err := db.request("PATCH", "email_outbox?id=eq."+url.QueryEscape(email.ID), record, "return=representation")
+	if err != nil {
+		return fmt.Errorf("failed to update outbox email: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Supabase returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	var updated []OutboxEmail
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err == nil && len(updated) == 0 {
+		return fmt.Errorf("outbox email %s not found", email.ID)
+	}
+	return nil
+}
+
+// GetOutboxEmail fetches the outbox email with id, or nil
+func (db *Database) GetOutboxEmail(id string) (*OutboxEmail, error) {
+	return db.findOutboxEmail("id=eq." + url.QueryEscape(id))
+}
+
+// FindOutboxEmailByProviderID fetches the outbox email Resend knows as providerID, or nil
+func (db *Database) FindOutboxEmailByProviderID(providerID string) (*OutboxEmail, error) {
+	if providerID == "" {
+		return nil, nil
+	}
+	return db.findOutboxEmail("provider_id=eq." + url.QueryEscape(providerID))
+}
+
+func (db *Database) findOutboxEmail(filter string) (*OutboxEmail, error) {
+	var emails []OutboxEmail
+	if err := db.fetch("email_outbox?select="+outboxColumns+"&limit=1&"+filter, &emails); err != nil {
+		return nil, fmt.Errorf("failed to fetch outbox email: %v", err)
+	}
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	return &emails[0], nil
+}
+
+// ListOutboxEmails fetches the outbox emails with any of statuses, newest first
+func (db *Database) ListOutboxEmails(statuses ...string) ([]OutboxEmail, error) {
+	path := "email_outbox?select=" + outboxColumns + "&order=created_at.desc"
+	if len(statuses) > 0 {
+		path += "&status=in.(" + url.QueryEscape(strings.Join(statuses, ",")) + ")"
+	}
+	emails := []OutboxEmail{}
+	if err := db.fetch(path, &emails); err != nil {
+		return nil, fmt.Errorf("failed to fetch outbox emails: %v", err)
+	}
+	return emails, nil
+}
+
+// DueOutboxEmails fetches up to limit queued emails due by now, and sending
+// ones whose lease ran out, oldest first
+func (db *Database) DueOutboxEmails(now time.Time, limit int) ([]OutboxEmail, error) {
+	path := fmt.Sprintf("email_outbox?select=%s&status=in.(%s,%s)&next_attempt_at=lte.%s&order=created_at.asc&limit=%d",
+		outboxColumns, EmailQueued, EmailSending, url.QueryEscape(now.UTC().Format(time.RFC3339Nano)), limit)
+	emails := []OutboxEmail{}
+	if err := db.fetch(path, &emails); err != nil {
+		return nil, fmt.Errorf("failed to fetch queued emails: %v", err)
+	}
+	return emails, nil
+}
+
+// ClaimOutboxEmail PATCHes the outbox email with id to sending until
+// leaseUntil, filtered on it being one of from and due by now, so that of
+// two senders racing only one matches the row
+func (db *Database) ClaimOutboxEmail(id string, from []string, now, leaseUntil time.Time) (*OutboxEmail, error) {
+	if len(from) == 0 {
+		return nil, nil
+	}
+	record := map[string]interface{}{
+		"status":          EmailSending,
+		"next_attempt_at": supabaseTime(leaseUntil),
+		"updated_at":      time.Now().UTC().Format(time.RFC3339),
+	}
+	path := fmt.Sprintf("email_outbox?select=%s&id=eq.%s&status=in.(%s)&or=(status.not.in.(%s,%s),next_attempt_at.lte.%s)",
+		outboxColumns, url.QueryEscape(id), url.QueryEscape(strings.Join(from, ",")),
+		EmailQueued, EmailSending, url.QueryEscape(now.UTC().Format(time.RFC3339Nano)))
+	resp, err := db.request("PATCH", path, record, "return=representation")
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox email: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Supabase returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	var claimed []OutboxEmail
+	if err := json.NewDecoder(resp.Body).Decode(&claimed); err != nil {
+		return nil, fmt.Errorf("failed to decode claimed email: %v", err)
+	}
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+	return &claimed[0], nil
+}
+
+// supabaseTime formats t for a timestamp column, or nil (NULL) for the zero time
+func supabaseTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,439 @@
+package shared
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// supabaseCall is one PostgREST request the client made
+type supabaseCall struct {
+	Method string
+	Table  string
+	Query  map[string]string // the filters that matter; other params are ignored
+	Prefer string
+	Body   map[string]interface{}
+}
+
+// fakeSupabase serves PostgREST requests with reply, recording each one
+func fakeSupabase(t *testing.T, reply func(r *http.Request) (int, string)) (*Database, *[]supabaseCall) {
+	t.Helper()
+	var calls []supabaseCall
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("apikey") != "key" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("%s %s sent without the API key", r.Method, r.URL)
+		}
+		call := supabaseCall{Method: r.Method, Table: r.URL.Path[len("/rest/v1/"):], Prefer: r.Header.Get("Prefer"), Query: map[string]string{}}
+		for k, v := range r.URL.Query() {
+			call.Query[k] = v[0]
+		}
+		if body, _ := io.ReadAll(r.Body); len(body) > 0 {
+			json.Unmarshal(body, &call.Body)
+		}
+		calls = append(calls, call)
+
+		status, body := reply(r)
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return &Database{url: server.URL, apiKey: "key", client: server.Client()}, &calls
+}
+
+// okReply answers reads with an empty list, updates with one empty row and
+// other writes with no content
+func okReply(r *http.Request) (int, string) {
+	switch r.Method {
+	case http.MethodGet:
+		return http.StatusOK, "[]"
+	case http.MethodPatch:
+		return http.StatusOK, "[{}]"
+	case http.MethodPost:
+		return http.StatusCreated, ""
+	default:
+		return http.StatusNoContent, ""
+	}
+}
+
+func TestDatabaseRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(db *Database) error
+		want []supabaseCall
+	}{
+		{
+			"add guest",
+			func(db *Database) error {
+				return db.AddGuest(Guest{Name: "Jane Smith", Address: "1 High St", Ceremony: true})
+			},
+			[]supabaseCall{{Method: "POST", Table: "guests", Prefer: "return=minimal",
+				Body: map[string]interface{}{"name": "Jane Smith", "address": "1 High St", "ceremony": true}}},
+		},
+		{
+			"save late RSVP",
+			func(db *Database) error {
+				return db.SaveRSVP(RSVPRequest{Name: "Jane", Email: "jane@example.com", LateRequest: true})
+			},
+			[]supabaseCall{{Method: "POST", Table: "rsvps",
+				Body: map[string]interface{}{"name": "Jane", "email": "jane@example.com", "verified": false, "late": true}}},
+		},
+		{
+			"add guest to a household",
+			func(db *Database) error { return db.AddGuest(Guest{Name: "Jane Smith", HouseholdID: "h1"}) },
+			[]supabaseCall{{Method: "POST", Table: "guests", Body: map[string]interface{}{"name": "Jane Smith", "household_id": "h1"}}},
+		},
+		{
+			"add guest without a household",
+			func(db *Database) error { return db.AddGuest(Guest{Name: "Jane Smith"}) },
+			[]supabaseCall{{Method: "POST", Table: "guests", Body: map[string]interface{}{"name": "Jane Smith", "household_id": nil}}},
+		},
+		{
+			"save RSVP with plus-ones",
+			func(db *Database) error {
+				return db.SaveRSVP(RSVPRequest{Email: "jane@example.com", IsAttending: true, PlusOnes: []string{"Sam"}})
+			},
+			[]supabaseCall{{Method: "POST", Table: "rsvps", Body: map[string]interface{}{"plus_ones": []interface{}{"Sam"}}}},
+		},
+		{
+			"add guest with plus-ones",
+			func(db *Database) error { return db.AddGuest(Guest{Name: "Jane Smith", PlusOnes: 2}) },
+			[]supabaseCall{{Method: "POST", Table: "guests", Body: map[string]interface{}{"name": "Jane Smith", "plus_ones": 2}}},
+		},
+		{
+			"set guest dietary",
+			func(db *Database) error { return db.SetGuestDietary("7", "no soy", []string{"vegan"}) },
+			[]supabaseCall{{Method: "PATCH", Table: "guests", Query: map[string]string{"id": "eq.7"},
+				Body: map[string]interface{}{"dietary": "no soy", "diet_categories": []interface{}{"vegan"}}}},
+		},
+		{
+			"clear guest dietary",
+			func(db *Database) error { return db.SetGuestDietary("7", "", nil) },
+			[]supabaseCall{{Method: "PATCH", Table: "guests", Query: map[string]string{"id": "eq.7"},
+				Body: map[string]interface{}{"dietary": "", "diet_categories": []interface{}{}}}},
+		},
+		{
+			"save RSVP with guest diets",
+			func(db *Database) error {
+				return db.SaveRSVP(RSVPRequest{Email: "jane@example.com", IsAttending: true, GuestDiets: []GuestDiet{{GuestName: "Jane", Categories: []string{"vegan"}, Diet: "no soy"}}})
+			},
+			[]supabaseCall{{Method: "POST", Table: "rsvps",
+				Body: map[string]interface{}{"guest_diets": []interface{}{map[string]interface{}{"guestName": "Jane", "categories": []interface{}{"vegan"}, "diet": "no soy"}}}}},
+		},
+		{
+			"save RSVP with guest meals",
+			func(db *Database) error {
+				return db.SaveRSVP(RSVPRequest{Email: "jane@example.com", IsAttending: true, GuestMeals: []GuestMeal{{GuestName: "Jane", Main: "m1"}}})
+			},
+			[]supabaseCall{{Method: "POST", Table: "rsvps",
+				Body: map[string]interface{}{"guest_meals": []interface{}{map[string]interface{}{"guestName": "Jane", "main": "m1"}}}}},
+		},
+		{
+			"add guest invited to events",
+			func(db *Database) error {
+				return db.AddGuest(Guest{Name: "Jane Smith", Ceremony: true, Events: []string{"brunch"}})
+			},
+			[]supabaseCall{{Method: "POST", Table: "guests",
+				Body: map[string]interface{}{"ceremony": true, "events": []interface{}{"brunch", "ceremony"}}}},
+		},
+		{
+			"save event",
+			func(db *Database) error { return db.SaveEvent(Event{ID: "brunch", Name: "Brunch", Capacity: 30}) },
+			[]supabaseCall{{Method: "POST", Table: "events", Prefer: "resolution=merge-duplicates,return=minimal",
+				Body: map[string]interface{}{"id": "brunch", "name": "Brunch", "starts_at": nil, "capacity": 30, "all_guests": false}}},
+		},
+		{
+			"update guest",
+			func(db *Database) error {
+				return db.UpdateGuest(Guest{ID: "7", Name: "Jane Smith", Ceremony: true, Table: "4"})
+			},
+			[]supabaseCall{{Method: "PATCH", Table: "guests", Query: map[string]string{"id": "eq.7"},
+				Body: map[string]interface{}{"name": "Jane Smith", "household_id": nil, "ceremony": true, "events": []interface{}{"ceremony"}, "table_name": "4"}}},
+		},
+		{
+			"verify RSVPs",
+			func(db *Database) error {
+				_, err := db.VerifyRSVPs("jane+1@example.com", RSVPUpdate{Name: " Jane ", AttendingGuests: []string{"Jane Smith", ""}})
+				return err
+			},
+			[]supabaseCall{{Method: "PATCH", Table: "rsvps", Query: map[string]string{"email": "eq.jane+1@example.com"}, Prefer: "return=representation",
+				Body: map[string]interface{}{"verified": true, "name": "Jane", "attending_guests": []interface{}{"Jane Smith"}}}},
+		},
+		{
+			"delete RSVPs",
+			func(db *Database) error { return db.DeleteRSVPs("jane@example.com") },
+			[]supabaseCall{{Method: "DELETE", Table: "rsvps", Query: map[string]string{"email": "eq.jane@example.com"}}},
+		},
+		{
+			"latest RSVP",
+			func(db *Database) error { _, err := db.LatestRSVP("jane@example.com"); return err },
+			[]supabaseCall{{Method: "GET", Table: "rsvps",
+				Query: map[string]string{"email": "eq.jane@example.com", "order": "submitted_at.desc", "limit": "1"}}},
+		},
+		{
+			"update RSVP patches only what changed",
+			func(db *Database) error {
+				diet := "vegan"
+				_, err := db.UpdateRSVP("42", RSVPChanges{Diet: &diet})
+				return err
+			},
+			[]supabaseCall{{Method: "PATCH", Table: "rsvps", Query: map[string]string{"id": "eq.42"}, Prefer: "return=representation",
+				Body: map[string]interface{}{"diet": "vegan"}}},
+		},
+		{
+			"save avatars on the newest RSVP",
+			func(db *Database) error {
+				return db.SaveAvatars("jane@example.com", []AvatarSelection{{GuestName: "Jane", Avatar: "owl"}})
+			},
+			[]supabaseCall{{Method: "PATCH", Table: "rsvps", Prefer: "return=minimal",
+				Query: map[string]string{"email": "eq.jane@example.com", "order": "submitted_at.desc", "limit": "1"},
+				Body:  map[string]interface{}{"avatar_data": []interface{}{map[string]interface{}{"guestName": "Jane", "avatar": "owl", "message": ""}}}}},
+		},
+		{
+			"get admin",
+			func(db *Database) error { _, err := db.GetAdmin(" Ana "); return err },
+			[]supabaseCall{{Method: "GET", Table: "admins", Query: map[string]string{"username": "eq.ana", "limit": "1"}}},
+		},
+		{
+			"save admin upserts on username",
+			func(db *Database) error {
+				return db.SaveAdmin(AdminAccount{Username: "Ana", PasswordHash: "hash", Role: RoleEditor})
+			},
+			[]supabaseCall{{Method: "POST", Table: "admins", Query: map[string]string{"on_conflict": "username"},
+				Prefer: "resolution=merge-duplicates,return=minimal",
+				Body:   map[string]interface{}{"username": "ana", "password_hash": "hash", "role": "editor"}}},
+		},
+		{
+			"set household invite code",
+			func(db *Database) error { return db.SetHouseholdInviteCode("h1", "K7QD-M3XR") },
+			[]supabaseCall{{Method: "PATCH", Table: "households", Query: map[string]string{"id": "eq.h1"},
+				Body: map[string]interface{}{"invite_code": "K7QD-M3XR"}}},
+		},
+		{
+			"clear household invite code",
+			func(db *Database) error { return db.SetHouseholdInviteCode("h1", "") },
+			[]supabaseCall{{Method: "PATCH", Table: "households", Query: map[string]string{"id": "eq.h1"},
+				Body: map[string]interface{}{"invite_code": nil}}},
+		},
+		{
+			"get rate limit",
+			func(db *Database) error { _, err := db.GetRateLimit("name:jane smith"); return err },
+			[]supabaseCall{{Method: "GET", Table: "rate_limits", Query: map[string]string{"key": "eq.name:jane smith", "limit": "1"}}},
+		},
+		{
+			"save rate limit upserts on key",
+			func(db *Database) error {
+				return db.SaveRateLimit(RateLimitState{Key: "ip:a", WindowStart: time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC), Count: 3})
+			},
+			[]supabaseCall{{Method: "POST", Table: "rate_limits", Query: map[string]string{"on_conflict": "key"},
+				Prefer: "resolution=merge-duplicates,return=minimal",
+				Body:   map[string]interface{}{"key": "ip:a", "window_start": "2026-06-01T12:00:00Z", "count": 3, "misses": 0, "blocked_until": nil}}},
+		},
+		{
+			"update outbox email",
+			func(db *Database) error {
+				return db.UpdateOutboxEmail(OutboxEmail{ID: "e1", Status: EmailSent, Attempts: 2, ProviderID: "re_123"})
+			},
+			[]supabaseCall{{Method: "PATCH", Table: "email_outbox", Query: map[string]string{"id": "eq.e1"},
+				Body: map[string]interface{}{"status": "sent", "attempts": 2, "provider_id": "re_123", "next_attempt_at": nil, "sent_at": nil}}},
+		},
+		{
+			"list outbox emails by status",
+			func(db *Database) error { _, err := db.ListOutboxEmails(EmailFailed, EmailBounced); return err },
+			[]supabaseCall{{Method: "GET", Table: "email_outbox", Query: map[string]string{"status": "in.(failed,bounced)", "order": "created_at.desc"}}},
+		},
+		{
+			"due outbox emails",
+			func(db *Database) error {
+				_, err := db.DueOutboxEmails(time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC), 25)
+				return err
+			},
+			[]supabaseCall{{Method: "GET", Table: "email_outbox",
+				Query: map[string]string{"status": "in.(queued,sending)", "next_attempt_at": "lte.2026-06-01T12:00:00Z", "order": "created_at.asc", "limit": "25"}}},
+		},
+		{
+			"claim outbox email",
+			func(db *Database) error {
+				now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+				_, err := db.ClaimOutboxEmail("e1", []string{EmailQueued, EmailSending}, now, now.Add(emailSendLease))
+				return err
+			},
+			[]supabaseCall{{Method: "PATCH", Table: "email_outbox", Prefer: "return=representation",
+				Query: map[string]string{"id": "eq.e1", "status": "in.(queued,sending)",
+					"or": "(status.not.in.(queued,sending),next_attempt_at.lte.2026-06-01T12:00:00Z)"},
+				Body: map[string]interface{}{"status": "sending", "next_attempt_at": "2026-06-01T12:02:00Z"}}},
+		},
+		{
+			"override replaces the previous one",
+			func(db *Database) error { return db.SetOverride("Jane Smith", false) },
+			[]supabaseCall{
+				{Method: "DELETE", Table: "rsvps", Query: map[string]string{"email": "eq." + OverrideEmail("Jane Smith")}},
+				{Method: "POST", Table: "rsvps", Body: map[string]interface{}{"name": "Jane Smith", "email": OverrideEmail("Jane Smith"), "verified": true}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, calls := fakeSupabase(t, okReply)
+			if err := tt.call(db); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if len(*calls) != len(tt.want) {
+				t.Fatalf("made %d requests (%+v), want %d", len(*calls), *calls, len(tt.want))
+			}
+			for i, want := range tt.want {
+				checkSupabaseCall(t, (*calls)[i], want)
+			}
+		})
+	}
+}
+
+// checkSupabaseCall compares got with the parts of want that are set
+func checkSupabaseCall(t *testing.T, got, want supabaseCall) {
+	t.Helper()
+	if got.Method != want.Method || got.Table != want.Table {
+		t.Errorf("request = %s %s, want %s %s", got.Method, got.Table, want.Method, want.Table)
+	}
+	for k, v := range want.Query {
+		if got.Query[k] != v {
+			t.Errorf("%s %s: %s = %q, want %q", got.Method, got.Table, k, got.Query[k], v)
+		}
+	}
+	if want.Prefer != "" && got.Prefer != want.Prefer {
+		t.Errorf("%s %s: Prefer = %q, want %q", got.Method, got.Table, got.Prefer, want.Prefer)
+	}
+	for k, v := range want.Body {
+		gotJSON, _ := json.Marshal(got.Body[k])
+		wantJSON, _ := json.Marshal(v)
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("%s %s: body %s = %s, want %s", got.Method, got.Table, k, gotJSON, wantJSON)
+		}
+	}
+}
+
+func TestDatabaseFindGuestByName(t *testing.T) {
+	db, calls := fakeSupabase(t, func(r *http.Request) (int, string) {
+		return http.StatusOK, `[{"id": "1", "name": "Jane Smith"}, {"id": "2", "name": "Đorđe Petrović", "household_id": "h1"}]`
+	})
+	tests := []struct {
+		typed  string
+		wantID string
+	}{
+		{"Đorđe Petrović", "2"},
+		{" djordje  PETROVIC ", "2"},
+		{"Dorde Petrovic", "2"},
+		{"Jane Smyth", ""},
+	}
+	for _, tt := range tests {
+		guest, err := db.FindGuestByName(tt.typed)
+		if err != nil {
+			t.Fatalf("FindGuestByName(%q) error = %v", tt.typed, err)
+		}
+		gotID := ""
+		if guest != nil {
+			gotID = guest.ID
+		}
+		if gotID != tt.wantID {
+			t.Errorf("FindGuestByName(%q) = %q, want %q", tt.typed, gotID, tt.wantID)
+		}
+	}
+	checkSupabaseCall(t, (*calls)[0], supabaseCall{Method: "GET", Table: "guests"})
+}
+
+func TestDatabaseFindOrCreateHousehold(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // households GET reply
+		want     []supabaseCall
+	}{
+		{"existing", `[{"id": "h0", "name": "Andersons"}, {"id": "h1", "name": "The Smiths"}]`, []supabaseCall{
+			{Method: "GET", Table: "households"},
+		}},
+		{"new", `[]`, []supabaseCall{
+			{Method: "GET", Table: "households"},
+			{Method: "POST", Table: "households", Prefer: "return=representation", Body: map[string]interface{}{"name": "the  smiths"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, calls := fakeSupabase(t, func(r *http.Request) (int, string) {
+				if r.Method == http.MethodPost {
+					return http.StatusCreated, `[{"id": "h1", "name": "The Smiths"}]`
+				}
+				return http.StatusOK, tt.existing
+			})
+			household, err := db.FindOrCreateHousehold(" the  smiths ")
+			if err != nil || household.ID != "h1" {
+				t.Fatalf("FindOrCreateHousehold() = %+v, %v", household, err)
+			}
+			if len(*calls) != len(tt.want) {
+				t.Fatalf("made %d requests (%+v), want %d", len(*calls), *calls, len(tt.want))
+			}
+			for i, want := range tt.want {
+				checkSupabaseCall(t, (*calls)[i], want)
+			}
+		})
+	}
+}
+
+func TestDatabaseListAvatars(t *testing.T) {
+	db, calls := fakeSupabase(t, func(r *http.Request) (int, string) {
+		return http.StatusOK, `[{"avatar_data": null}, {"avatar_data": []}, {"avatar_data": "not a list"},
+			{"avatar_data": [{"guestName": "Jane", "avatar": "owl", "message": "Hi"}]}]`
+	})
+	avatars, err := db.ListAvatars()
+	if err != nil {
+		t.Fatalf("ListAvatars() error = %v", err)
+	}
+	if len(avatars) != 1 || avatars[0].GuestName != "Jane" {
+		t.Errorf("ListAvatars() = %+v, want Jane's only", avatars)
+	}
+	checkSupabaseCall(t, (*calls)[0], supabaseCall{Method: "GET", Table: "rsvps",
+		Query: map[string]string{"is_attending": "eq.true", "verified": "eq.true"}})
+}
+
+func TestDatabaseMenu(t *testing.T) {
+	reply := `[{"id": "m1", "course": "main", "name": "Risotto"}]`
+	db, calls := fakeSupabase(t, func(r *http.Request) (int, string) {
+		if r.Method == http.MethodPost {
+			return http.StatusCreated, reply
+		}
+		return http.StatusOK, reply
+	})
+	added, err := db.AddMenuOption(MenuOption{Course: CourseMain, Name: "Risotto"})
+	if err != nil || added.ID != "m1" {
+		t.Fatalf("AddMenuOption() = %+v, %v", added, err)
+	}
+	if err := db.DeleteMenuOption("m1"); err != nil {
+		t.Fatalf("DeleteMenuOption() error = %v", err)
+	}
+	checkSupabaseCall(t, (*calls)[0], supabaseCall{Method: "POST", Table: "menu_options",
+		Body: map[string]interface{}{"course": "main", "name": "Risotto"}})
+	checkSupabaseCall(t, (*calls)[1], supabaseCall{Method: "DELETE", Table: "menu_options", Query: map[string]string{"id": "eq.m1"}})
+
+	reply = "[]"
+	if err := db.DeleteMenuOption("m2"); err == nil {
+		t.Errorf("DeleteMenuOption(missing) succeeded")
+	}
+}
+
+func TestDatabaseErrors(t *testing.T) {
+	db, _ := fakeSupabase(t, func(r *http.Request) (int, string) {
+		return http.StatusInternalServerError, `{"message": "down"}`
+	})
+	if _, err := db.ListGuests(); err == nil {
+		t.Errorf("ListGuests() succeeded on a 500")
+	}
+	if _, err := db.VerifyRSVPs("jane@example.com", RSVPUpdate{}); err == nil {
+		t.Errorf("VerifyRSVPs() succeeded on a 500")
+	}
+	if err := db.AddGuest(Guest{Name: "Jane"}); err == nil {
+		t.Errorf("AddGuest() succeeded on a 500")
+	}
+
+	if _, err := (&Database{}).ListRSVPs(); err == nil {
+		t.Errorf("ListRSVPs() succeeded with Supabase not configured")
+	}
+}
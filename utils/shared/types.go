@@ -2,12 +2,19 @@ package shared
 
 // Guest represents a guest on the invite list
 type Guest struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Address  string `json:"address,omitempty"`
-	Ceremony bool   `json:"ceremony"`
-	Avatar   string `json:"avatar,omitempty"`
-	Message  string `json:"message,omitempty"`
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Address        string   `json:"address,omitempty"`
+	HouseholdID    string   `json:"householdId,omitempty"`    // empty for guests who RSVP on their own
+	Household      string   `json:"household,omitempty"`      // household name from an import file, resolved to HouseholdID on save
+	Ceremony       bool     `json:"ceremony"`                 // invited to the ceremony; kept in step with Events
+	Events         []string `json:"events,omitempty"`         // IDs of events they are invited to, beyond those open to all guests
+	PlusOnes       int      `json:"plusOnes,omitempty"`       // how many unnamed guests they may bring
+	Dietary        string   `json:"dietary,omitempty"`        // free-text notes from their latest verified RSVP
+	DietCategories []string `json:"dietCategories,omitempty"` // IDs from DietCategories, from the same RSVP
+	Table          string   `json:"table,omitempty"`          // reception table, if seated
+	Avatar         string   `json:"avatar,omitempty"`
+	Message        string   `json:"message,omitempty"`
 }
 
 // VerifyNameRequest represents a name verification request
@@ -16,33 +23,65 @@ type VerifyNameRequest struct {
 	Email string `json:"email,omitempty"`
 }
 
+// VerifyCodeRequest looks up a household by the invite code on its invitation
+type VerifyCodeRequest struct {
+	Code        string `json:"code"`
+	LateRequest bool   `json:"lateRequest,omitempty"`
+}
+
 // FamilyMember represents a family member in the verification response
 type FamilyMember struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Events []string `json:"events"` // IDs of the events they are invited to
 }
 
 // VerifyNameResponse represents a name verification response
 type VerifyNameResponse struct {
 	Success       bool           `json:"success"`
 	Message       string         `json:"message,omitempty"`
+	Code          string         `json:"code,omitempty"`
+	ChallengeType string         `json:"challengeType,omitempty"` // with CodeChallengeRequired/Failed: what to ask for
+	MatchedName   string         `json:"matchedName,omitempty"`   // guest-list spelling of the name that was found
+	Household     string         `json:"household,omitempty"`     // verify-code only: the household the code belongs to
+	Suggestions   []string       `json:"suggestions,omitempty"`   // "did you mean" names when there was no confident match
 	FamilyMembers []FamilyMember `json:"familyMembers,omitempty"`
+	PlusOneSlots  int            `json:"plusOneSlots,omitempty"` // plus-ones the household may name
+	Menu          []MenuOption   `json:"menu,omitempty"`         // dishes to choose from for each course
+	Events        []Event        `json:"events,omitempty"`       // every event, for naming those in FamilyMembers
 }
 
 // RSVPRequest represents an RSVP submission
 type RSVPRequest struct {
-	Name            string   `json:"name"`
-	Email           string   `json:"email"`
-	IsAttending     bool     `json:"isAttending"`
-	AttendingGuests []string `json:"attendingGuests,omitempty"`
-	Diet            string   `json:"diet,omitempty"`
-	Verified        bool     `json:"verified,omitempty"`
+	Name            string            `json:"name"`
+	Email           string            `json:"email"`
+	IsAttending     bool              `json:"isAttending"`
+	AttendingGuests []string          `json:"attendingGuests,omitempty"`
+	PlusOnes        []string          `json:"plusOnes,omitempty"`    // names of plus-ones, counted against the attending guests' allowance
+	GuestDiets      []GuestDiet       `json:"guestDiets,omitempty"`  // dietary requirements per attending guest or plus-one
+	GuestMeals      []GuestMeal       `json:"guestMeals,omitempty"`  // menu choices per attending guest or plus-one
+	GuestEvents     []EventAttendance `json:"guestEvents,omitempty"` // events per attending guest or plus-one
+	Diet            string            `json:"diet,omitempty"`        // free-text note for the whole party
+	Verified        bool              `json:"verified,omitempty"`
+	LateRequest     bool              `json:"lateRequest,omitempty"`     // asks to RSVP after the deadline, pending admin approval
+	SkipSuggestions bool              `json:"skipSuggestions,omitempty"` // verify-name only: the guest rejected the "did you mean" names
+	ChallengeAnswer string            `json:"challengeAnswer,omitempty"` // verify-name only: postcode or invite code, see HOUSEHOLD_CHALLENGE
+	InviteCode      string            `json:"inviteCode,omitempty"`      // the code the household was found by, which verifies its guests
 }
 
 // RSVPResponse represents an RSVP submission response
 type RSVPResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+}
+
+// GuestDiet is one attending guest's dietary requirements: categories from
+// the fixed taxonomy (see DietCategories) and any free-text notes
+type GuestDiet struct {
+	GuestName  string   `json:"guestName"`
+	Categories []string `json:"categories,omitempty"`
+	Diet       string   `json:"diet"`
 }
 
 // AvatarSelection represents a single guest's avatar selection
@@ -62,6 +101,7 @@ type SaveAvatarsRequest struct {
 type SaveAvatarsResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
 }
 
 // GuestAvatar represents a guest with their avatar
@@ -4,11 +4,6 @@ import (
 	"strings"
 )
 
-// NormalizeString converts a string to lowercase and trims whitespace for comparison
-func NormalizeString(s string) string {
-	return strings.ToLower(strings.TrimSpace(s))
-}
-
 // IsValidEmail performs basic email validation
 func IsValidEmail(email string) bool {
 	email = strings.TrimSpace(email)
@@ -28,19 +23,33 @@ func IsValidEmail(email string) bool {
 	return true
 }
 
-// IsGuestInList checks if a guest name exists in the guest list
+// IsGuestInList checks if a guest name matches someone on the guest list
+// (see FindGuest)
 func IsGuestInList(name string, guestList []Guest) bool {
-	normalizedName := NormalizeString(name)
-	for _, guest := range guestList {
-		if NormalizeString(guest.Name) == normalizedName {
-			return true
+	return FindGuest(name, guestList) != nil
+}
+
+// FindGuest returns the guest whose name matches exactly (case-insensitive)
+// or, failing that, the single high-confidence fuzzy match from
+// MatchGuests. It returns nil when the best match is only a suggestion.
+func FindGuest(name string, guestList []Guest) *Guest {
+	if guest := findGuestByNormalizedName(name, guestList); guest != nil {
+		return guest
+	}
+
+	if matches := MatchGuests(name, guestList); len(matches) > 0 && matches[0].Confidence == MatchHigh {
+		for i := range guestList {
+			if guestList[i].ID == matches[0].Guest.ID {
+				return &guestList[i]
+			}
 		}
 	}
-	return false
+	return nil
 }
 
-// FindGuest searches for a guest by name in the guest list
-func FindGuest(name string, guestList []Guest) *Guest {
+// findGuestByNormalizedName returns the guest whose name is equal to name
+// after NormalizeString, or nil
+func findGuestByNormalizedName(name string, guestList []Guest) *Guest {
 	normalizedName := NormalizeString(name)
 	for i := range guestList {
 		if NormalizeString(guestList[i].Name) == normalizedName {
@@ -49,3 +58,15 @@ func FindGuest(name string, guestList []Guest) *Guest {
 	}
 	return nil
 }
+
+// findHouseholdByNormalizedName returns the household whose name is equal
+// to name after NormalizeString, or nil
+func findHouseholdByNormalizedName(name string, households []Household) *Household {
+	normalizedName := NormalizeString(name)
+	for i := range households {
+		if NormalizeString(households[i].Name) == normalizedName {
+			return &households[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package shared
+
+import (
+	"bufio"
+	"io"
+	"mime/quotedprintable"
+	"strconv"
+	"strings"
+)
+
+// VCardImporter reads contacts exported from a phone or address book
+// (vCard 2.1, 3.0 or 4.0), one guest per contact:
+//
+//   - FN is the name, or N when there is no FN
+//   - ADR is the address, preferring the home one, with its parts joined
+//     by commas
+//   - CATEGORIES (contact groups) name the events they are invited to, such
+//     as "Ceremony"; groups that aren't events are ignored on import
+//   - X-HOUSEHOLD, X-PLUS-ONES and X-TABLE fill in the columns of the same
+//     name in the CSV file
+type VCardImporter struct{}
+
+// vcardProperty is one line of a vCard: NAME;PARAMS:VALUE
+type vcardProperty struct {
+	Name   string
+	Params string // uppercased, e.g. "TYPE=HOME;CHARSET=UTF-8"
+	Value  string
+}
+
+func (VCardImporter) ReadGuests(r io.Reader) ([]Guest, error) {
+	lines, err := unfoldVCardLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	guests := []Guest{}
+	var card []vcardProperty
+	inCard := false
+	for _, line := range lines {
+		prop, ok := parseVCardLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VCARD"):
+			inCard, card = true, nil
+		case prop.Name == "END" && strings.EqualFold(prop.Value, "VCARD"):
+			if g, ok := guestFromVCard(card); ok {
+				g.ID = strconv.Itoa(len(guests) + 1)
+				guests = append(guests, g)
+			}
+			inCard = false
+		case inCard:
+			card = append(card, prop)
+		}
+	}
+	return guests, nil
+}
+
+// unfoldVCardLines splits a vCard file into logical lines, joining lines
+// folded onto the next by a leading space or tab, and quoted-printable
+// values continued by a trailing "="
+func unfoldVCardLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if n := len(lines); n > 0 {
+			prev := lines[n-1]
+			if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				lines[n-1] = prev + line[1:]
+				continue
+			}
+			if strings.HasSuffix(prev, "=") && strings.Contains(strings.ToUpper(prev), "QUOTED-PRINTABLE") {
+				lines[n-1] = prev + "\n" + line
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseVCardLine splits a line into its property name (without any group
+// prefix such as "item1."), parameters and value, decoding
+// quoted-printable values
+func parseVCardLine(line string) (vcardProperty, bool) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return vcardProperty{}, false
+	}
+	name, params, _ := strings.Cut(line[:colon], ";")
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		name = name[dot+1:]
+	}
+	prop := vcardProperty{
+		Name:   strings.ToUpper(strings.TrimSpace(name)),
+		Params: strings.ToUpper(params),
+		Value:  line[colon+1:],
+	}
+	if strings.Contains(prop.Params, "QUOTED-PRINTABLE") {
+		value := strings.ReplaceAll(prop.Value, "=\n", "")
+		if decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(value))); err == nil {
+			prop.Value = string(decoded)
+		}
+	}
+	return prop, true
+}
+
+// guestFromVCard makes a guest from a contact's properties. Contacts
+// without a name are skipped.
+func guestFromVCard(card []vcardProperty) (Guest, bool) {
+	var g Guest
+	var nameParts []string
+	homeAddress := false
+	for _, prop := range card {
+		switch prop.Name {
+		case "FN":
+			g.Name = strings.TrimSpace(unescapeVCard(prop.Value))
+		case "N":
+			// Family;Given;Additional;Prefix;Suffix
+			nameParts = splitVCardValue(prop.Value, ';')
+		case "ADR":
+			home := strings.Contains(prop.Params, "HOME")
+			if g.Address != "" && (homeAddress || !home) {
+				continue
+			}
+			// PO box;Extended;Street;Locality;Region;Postal code;Country
+			var parts []string
+			for _, part := range splitVCardValue(prop.Value, ';') {
+				for _, line := range strings.Split(part, "\n") {
+					if line = strings.TrimSpace(line); line != "" {
+						parts = append(parts, line)
+					}
+				}
+			}
+			g.Address = strings.Join(parts, ", ")
+			homeAddress = home
+		case "CATEGORIES":
+			for _, category := range splitVCardValue(prop.Value, ',') {
+				if category = strings.TrimSpace(category); category != "" {
+					g.Events = append(g.Events, category)
+				}
+			}
+		case "X-HOUSEHOLD":
+			g.Household = strings.TrimSpace(unescapeVCard(prop.Value))
+		case "X-PLUS-ONES":
+			g.PlusOnes = parsePlusOnes(unescapeVCard(prop.Value))
+		case "X-TABLE":
+			g.Table = strings.TrimSpace(unescapeVCard(prop.Value))
+		}
+	}
+
+	if g.Name == "" && len(nameParts) > 0 {
+		var parts []string
+		for _, i := range []int{1, 2, 0} {
+			if i < len(nameParts) && strings.TrimSpace(nameParts[i]) != "" {
+				parts = append(parts, strings.TrimSpace(nameParts[i]))
+			}
+		}
+		g.Name = strings.Join(parts, " ")
+	}
+	return g, g.Name != ""
+}
+
+// splitVCardValue splits a structured value on unescaped sep, unescaping
+// each part
+func splitVCardValue(value string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, unescapeVCard(value[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, unescapeVCard(value[start:]))
+}
+
+// unescapeVCard undoes vCard text escaping: \n, \, \; and \\
+func unescapeVCard(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' || s[i] == 'N' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
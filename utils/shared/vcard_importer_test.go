@@ -0,0 +1,54 @@
+package shared
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVCardImporter(t *testing.T) {
+	vcf := strings.Join([]string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"FN:Jane Smith",
+		"N:Smith;Jane;;;",
+		"item1.ADR;TYPE=WORK:;;1 Office Park;London;;;",
+		"ADR;TYPE=HOME:;;1 High St\\nFlat 2;London;;SW1 1AA;UK",
+		"CATEGORIES:Ceremony,Brunch\\, late",
+		"X-HOUSEHOLD:The Smiths",
+		"X-PLUS-ONES:yes",
+		"X-TABLE: 4 ",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"VERSION:2.1",
+		"N;CHARSET=UTF-8;ENCODING=QUOTED-PRINTABLE:Petrovi=C4=87;=C4=90or=C4=91e;;;",
+		"NOTE:a long note that is",
+		" folded onto the next line",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"EMAIL:nobody@example.com",
+		"END:VCARD",
+		"FN:Outside a card",
+	}, "\r\n")
+
+	got, err := VCardImporter{}.ReadGuests(strings.NewReader(vcf))
+	if err != nil {
+		t.Fatalf("ReadGuests() error = %v", err)
+	}
+	want := []Guest{
+		{ID: "1", Name: "Jane Smith", Address: "1 High St, Flat 2, London, SW1 1AA, UK", Household: "The Smiths",
+			Events: []string{"Ceremony", "Brunch, late"}, PlusOnes: 1, Table: "4"},
+		{ID: "2", Name: "Đorđe Petrović"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadGuests() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSplitVCardValue(t *testing.T) {
+	got := splitVCardValue(`a\;b;c\;;d\,e`, ';')
+	if want := []string{"a;b", "c;", "d,e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("splitVCardValue() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"utils/shared"
+)
+
+func main() {
+	// Parse command line flags
+	csvFile := flag.String("file", "invite_list_export.csv", "Path to write the CSV to, or - for stdout")
+	flag.Parse()
+
+	log.Printf("CSV Export Tool")
+	log.Printf("===============")
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Fatalf("❌ Store not configured: %v", err)
+	}
+
+	guests, err := store.ListGuests()
+	if err != nil {
+		log.Fatalf("❌ Failed to load guests: %v", err)
+	}
+	households, err := store.ListHouseholds()
+	if err != nil {
+		log.Fatalf("❌ Failed to load households: %v", err)
+	}
+	events, err := store.ListEvents()
+	if err != nil {
+		log.Fatalf("❌ Failed to load events: %v", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *csvFile != "-" {
+		file, err := os.Create(*csvFile)
+		if err != nil {
+			log.Fatalf("❌ Cannot create %s: %v", *csvFile, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := shared.WriteGuestsCSV(out, guests, households, events); err != nil {
+		log.Fatalf("❌ Export failed: %v", err)
+	}
+
+	log.Printf("✅ Exported %d guests to %s", len(guests), *csvFile)
+}
@@ -10,40 +10,45 @@ import (
 
 func main() {
 	// Parse command line flags
-	csvFile := flag.String("file", "../invite_list.csv", "Path to CSV file to import")
+	guestFile := flag.String("file", "../invite_list.csv", "Path to the guest list to import (.csv, .xlsx, .vcf or .json)")
+	dryRun := flag.Bool("dry-run", false, "Print the guests that would be added, changed and removed without writing anything")
+	update := flag.Bool("update", false, "Update guests whose details differ from the file")
+	prune := flag.Bool("prune", false, "Remove guests missing from the file")
+	force := flag.Bool("force", false, "With --prune, also remove guests who have RSVPed")
 	flag.Parse()
 
-	log.Printf("CSV Import Tool")
-	log.Printf("===============")
-	log.Printf("CSV File: %s", *csvFile)
-	log.Printf("")
+	opts := shared.ImportOptions{DryRun: *dryRun, Update: *update, Prune: *prune, Force: *force}
 
-	// Check if file exists
-	if _, err := os.Stat(*csvFile); os.IsNotExist(err) {
-		log.Fatalf("❌ CSV file not found: %s", *csvFile)
+	log.Printf("Guest Import Tool")
+	log.Printf("=================")
+	log.Printf("File: %s", *guestFile)
+	if opts.DryRun {
+		log.Printf("Dry run: nothing will be written")
 	}
+	log.Printf("")
 
-	// Create database connection
-	db := shared.NewDatabase()
-
-	if !db.IsConfigured() {
-		log.Println("⚠️  Supabase not configured!")
-		log.Println("Please set SUPABASE_URL and SUPABASE_API_KEY environment variables")
-		log.Println("")
-		log.Println("You can set them in api/.env file:")
-		log.Println("  SUPABASE_URL=your-supabase-url")
-		log.Println("  SUPABASE_API_KEY=your-api-key")
-		log.Fatal("❌ Cannot import without database configuration")
+	// Check if file exists and is in a format we can read
+	if _, err := os.Stat(*guestFile); os.IsNotExist(err) {
+		log.Fatalf("❌ File not found: %s", *guestFile)
+	}
+	if _, err := shared.GuestImporterFor(*guestFile); err != nil {
+		log.Fatalf("❌ %v", err)
 	}
 
-	log.Printf("✓ Database configured")
-	log.Printf("")
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Fatalf("❌ Store not configured: %v", err)
+	}
 
-	// Import CSV to database
-	if err := shared.ImportCSVToDatabase(*csvFile, db); err != nil {
+	// Import the file to database
+	if err := shared.ImportFileToDatabase(*guestFile, store, opts); err != nil {
 		log.Fatalf("❌ Import failed: %v", err)
 	}
 
 	log.Printf("")
-	log.Printf("✅ Import completed successfully!")
+	if opts.DryRun {
+		log.Printf("✅ Dry run complete - run again without --dry-run to apply")
+	} else {
+		log.Printf("✅ Import completed successfully!")
+	}
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"utils/shared"
+)
+
+func main() {
+	// Parse command line flags
+	generate := flag.Bool("generate", false, "Give every household without an invite code a new one")
+	regenerate := flag.Bool("regenerate", false, "Replace every household's invite code (old codes stop working)")
+	csvFile := flag.String("file", "invite_codes.csv", "Path to write the codes to, or - for stdout")
+	flag.Parse()
+
+	log.Printf("Invite Code Tool")
+	log.Printf("================")
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Fatalf("❌ Store not configured: %v", err)
+	}
+
+	if *generate || *regenerate {
+		housed, skipped, err := shared.HouseholdsForUnhousedGuests(store)
+		if err != nil {
+			log.Fatalf("❌ Gave %d guests their own household before failing: %v", len(housed), err)
+		}
+		for _, g := range housed {
+			log.Printf("  + household for %s", g.Name)
+		}
+		for _, g := range skipped {
+			log.Printf("⚠️  %s isn't in a household, but one already has their name - skipped", g.Name)
+		}
+		if len(housed) > 0 {
+			log.Printf("✓ Gave %d guests outside a household their own", len(housed))
+		}
+
+		generated, err := shared.GenerateInviteCodes(store, *regenerate)
+		if err != nil {
+			log.Fatalf("❌ Generated %d invite codes before failing: %v", generated, err)
+		}
+		log.Printf("✓ Generated %d invite codes", generated)
+	}
+
+	guests, err := store.ListGuests()
+	if err != nil {
+		log.Fatalf("❌ Failed to load guests: %v", err)
+	}
+	households, err := store.ListHouseholds()
+	if err != nil {
+		log.Fatalf("❌ Failed to load households: %v", err)
+	}
+
+	// Codes belong to households, so guests still outside one can't be
+	// sent one
+	unhoused := 0
+	for _, g := range guests {
+		if g.HouseholdID == "" {
+			log.Printf("⚠️  Skipped %s: not in a household, so has no invite code", g.Name)
+			unhoused++
+		}
+	}
+	if unhoused > 0 && !*generate && !*regenerate {
+		log.Printf("⚠️  %d guests aren't in a household - run with -generate to give them their own", unhoused)
+	}
+	missing := 0
+	for _, h := range households {
+		if h.InviteCode == "" {
+			missing++
+		}
+	}
+	if missing > 0 {
+		log.Printf("⚠️  %d households have no invite code - run with -generate to create them", missing)
+	}
+
+	var out io.Writer = os.Stdout
+	if *csvFile != "-" {
+		file, err := os.Create(*csvFile)
+		if err != nil {
+			log.Fatalf("❌ Cannot create %s: %v", *csvFile, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := shared.WriteInviteCodesCSV(out, guests, households); err != nil {
+		log.Fatalf("❌ Export failed: %v", err)
+	}
+
+	log.Printf("✅ Exported invite codes for %d households to %s", len(households), *csvFile)
+}
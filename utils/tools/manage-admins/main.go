@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"utils/shared"
+)
+
+func main() {
+	// Parse command line flags
+	list := flag.Bool("list", false, "List admin accounts")
+	create := flag.String("create", "", "Create an admin account with this username")
+	reset := flag.String("reset", "", "Reset the password (and, with -role, the role) of this admin")
+	roleFlag := flag.String("role", "", "Role for -create or -reset: owner, editor or viewer")
+	flag.Parse()
+
+	log.Printf("Admin Account Tool")
+	log.Printf("==================")
+
+	store, err := shared.NewStore()
+	if err != nil {
+		log.Fatalf("❌ Store not configured: %v", err)
+	}
+
+	switch {
+	case *list:
+		listAdmins(store)
+	case *create != "":
+		createAdmin(store, *create, *roleFlag)
+	case *reset != "":
+		resetAdmin(store, *reset, *roleFlag)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// listAdmins prints every account with its role
+func listAdmins(store shared.Store) {
+	admins, err := store.ListAdmins()
+	if err != nil {
+		log.Fatalf("❌ Failed to list admins: %v", err)
+	}
+	if len(admins) == 0 {
+		log.Printf("No admin accounts - %s can log in with ADMIN_PASSWORD until one is created", shared.LegacyAdminUsername)
+		return
+	}
+	for _, a := range admins {
+		log.Printf("  %-20s %-7s updated %s", a.Username, a.Role, a.UpdatedAt)
+	}
+}
+
+// createAdmin adds a new account, refusing to overwrite an existing one
+func createAdmin(store shared.Store, username, roleName string) {
+	username = shared.NormalizeUsername(username)
+	if username == "" || strings.ContainsAny(username, ": \t") {
+		log.Fatalf("❌ Invalid username %q - usernames can't contain spaces or colons", username)
+	}
+
+	role := shared.RoleViewer
+	if roleName != "" {
+		var err error
+		if role, err = shared.ParseAdminRole(roleName); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+
+	existing, err := store.GetAdmin(username)
+	if err != nil {
+		log.Fatalf("❌ Failed to check for existing admin: %v", err)
+	}
+	if existing != nil {
+		log.Fatalf("❌ Admin %s already exists - use -reset to change their password", username)
+	}
+
+	hash := promptPasswordHash()
+	if err := store.SaveAdmin(shared.AdminAccount{Username: username, PasswordHash: hash, Role: role}); err != nil {
+		log.Fatalf("❌ Failed to create admin: %v", err)
+	}
+	log.Printf("✅ Created %s admin %s", role, username)
+}
+
+// resetAdmin sets a new password on an existing account, optionally
+// changing its role too
+func resetAdmin(store shared.Store, username, roleName string) {
+	account, err := store.GetAdmin(username)
+	if err != nil {
+		log.Fatalf("❌ Failed to look up admin: %v", err)
+	}
+	if account == nil {
+		log.Fatalf("❌ No admin named %s - use -create to add them", shared.NormalizeUsername(username))
+	}
+
+	if roleName != "" {
+		if account.Role, err = shared.ParseAdminRole(roleName); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+
+	account.PasswordHash = promptPasswordHash()
+	if err := store.SaveAdmin(*account); err != nil {
+		log.Fatalf("❌ Failed to update admin: %v", err)
+	}
+	log.Printf("✅ Reset password for %s admin %s", account.Role, account.Username)
+	log.Printf("   Tokens issued before now stay valid until they expire (%s)", shared.AdminTokenValidity)
+}
+
+// promptPasswordHash reads a new password and returns its hash. On a
+// terminal the password is read twice without echo; otherwise the first
+// line of stdin is used, so the tool can be scripted.
+func promptPasswordHash() string {
+	var password string
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "New password: ")
+		first, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			log.Fatalf("❌ Failed to read password: %v", err)
+		}
+		fmt.Fprint(os.Stderr, "Confirm password: ")
+		second, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			log.Fatalf("❌ Failed to read password: %v", err)
+		}
+		if string(first) != string(second) {
+			log.Fatal("❌ Passwords don't match")
+		}
+		password = string(first)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatalf("❌ Failed to read password from stdin: %v", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	hash, err := shared.HashPassword(password)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	return hash
+}